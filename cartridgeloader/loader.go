@@ -58,6 +58,10 @@ type Loader struct {
 // defined in the cartridge package. The exception is the DPC+ format which
 // requires the file extension "DP+"
 //
+// The file extensions ".WAV" and ".MP3" will set the Mapping field to "AR". The
+// file is assumed to be a recording of a Supercharger tape. Note that MP3
+// recordings are not currently supported by the Supercharger emulation.
+//
 // File extensions ".BIN" and "A26" will set the Mapping field to "AUTO".
 //
 // Alphabetic characters in file extensions can be in upper or lower case or a
@@ -106,14 +110,48 @@ func NewLoader(filename string, mapping string) Loader {
 			fallthrough
 		case ".E7":
 			fallthrough
+		case ".E78K":
+			fallthrough
 		case ".3F":
 			fallthrough
+		case ".3E":
+			fallthrough
+		case ".4A50":
+			fallthrough
 		case ".AR":
 			fallthrough
+		case ".0840":
+			fallthrough
+		case ".UA":
+			fallthrough
+		case ".SB":
+			fallthrough
+		case ".X07":
+			fallthrough
+		case ".EF":
+			fallthrough
+		case ".EFSC":
+			fallthrough
+		case ".DF":
+			fallthrough
+		case ".DFSC":
+			fallthrough
+		case ".BF":
+			fallthrough
+		case ".BFSC":
+			fallthrough
 		case ".DPC":
+			fallthrough
+		case ".CDF":
+			fallthrough
+		case ".CDFJ":
 			cl.Mapping = ext[1:]
 		case "DP+":
 			cl.Mapping = "DPC+"
+		case ".WAV":
+			fallthrough
+		case ".MP3":
+			cl.Mapping = "AR"
 		}
 	}
 
@@ -58,15 +58,23 @@ type breaker struct {
 
 func (bk breaker) String() string {
 	s := strings.Builder{}
-	s.WriteString(fmt.Sprintf("%s->%s", bk.target.Label(), bk.target.FormatValue(bk.value)))
+	s.WriteString(bk.describe())
 	n := bk.next
 	for n != nil {
-		s.WriteString(fmt.Sprintf(" & %s->%s", n.target.Label(), n.target.FormatValue(n.value)))
+		s.WriteString(fmt.Sprintf(" & %s", n.describe()))
 		n = n.next
 	}
 	return s.String()
 }
 
+// describe a single node of the breaker
+func (bk breaker) describe() string {
+	if bk.target.condition {
+		return bk.target.Label()
+	}
+	return fmt.Sprintf("%s->%s", bk.target.Label(), bk.target.FormatValue(bk.value))
+}
+
 // compares two breakers for equality. returns true if the two breakers are
 // logically the same.
 func (bk breaker) cmp(ck breaker) bool {
@@ -214,6 +222,27 @@ func (bp *breakpoints) check(previousResult string) string {
 	return checkString.String()
 }
 
+// any returns true if there are any breakpoints defined
+func (bp *breakpoints) any() bool {
+	return len(bp.breaks) > 0
+}
+
+// matches returns true if the condition of any breakpoint is currently met.
+// unlike check(), the state of the breakpoints is not changed and so matches()
+// can be called at any time
+func (bp *breakpoints) matches() bool {
+	for i := range bp.breaks {
+		m := true
+		for b := &bp.breaks[i]; b != nil && m; b = b.next {
+			m = b.target.TargetValue() == b.value
+		}
+		if m {
+			return true
+		}
+	}
+	return false
+}
+
 // list currently defined breakpoints
 func (bp breakpoints) list() {
 	if len(bp.breaks) == 0 {
@@ -243,8 +272,15 @@ func (bp breakpoints) list() {
 //
 //	& SL 100 HP 0 X 10
 //
+// if the tokens form an expression (see isExpression()) then the breakpoint
+// is a condition created from the expression
+//
 // !!TODO: simplify breakpoints parser to match help description
 func (bp *breakpoints) parseCommand(tokens *commandline.Tokens) error {
+	if isExpression(tokens.Remainder()) {
+		return bp.parseCondition(tokens)
+	}
+
 	andBreaks := false
 
 	// default target of CPU PC. meaning that "BREAK n" will cause a breakpoint
@@ -381,6 +417,24 @@ func (bp *breakpoints) parseCommand(tokens *commandline.Tokens) error {
 	return nil
 }
 
+// parse remaining tokens as an expression and add a breakpoint that will
+// halt execution when the expression becomes true
+func (bp *breakpoints) parseCondition(tokens *commandline.Tokens) error {
+	ex, err := parseExpression(bp.dbg, tokens)
+	if err != nil {
+		return err
+	}
+
+	nb := breaker{target: conditionTarget(ex), value: true}
+
+	if i := bp.checkBreaker(nb); i != noBreakEqualivalent {
+		return errors.New(errors.CommandError, fmt.Sprintf("already exists (%s)", bp.breaks[i]))
+	}
+	bp.breaks = append(bp.breaks, nb)
+
+	return nil
+}
+
 const noBreakEqualivalent = -1
 
 // checkBreaker returns the index number of the matching breakpoint. returns
@@ -481,3 +535,33 @@ func (bp *breakpoints) togglePCBreak(e *disassembly.Entry) {
 
 	bp.breaks = append(bp.breaks, nb)
 }
+
+// addPCBreak adds a breakpoint for the address in any bank. it is not an error
+// if an equivalent breakpoint already exists
+func (bp *breakpoints) addPCBreak(address uint16) {
+	ai := bp.dbg.dbgmem.mapAddress(address, true)
+	nb := breaker{
+		target: bp.checkPcBreak,
+
+		// see hasBreak() for casting commentary
+		value: int(ai.mappedAddress),
+	}
+
+	if bp.checkBreaker(nb) == noBreakEqualivalent {
+		bp.breaks = append(bp.breaks, nb)
+	}
+}
+
+// dropPCBreak removes the breakpoint added by addPCBreak(). it is not an error
+// if the breakpoint does not exist
+func (bp *breakpoints) dropPCBreak(address uint16) {
+	ai := bp.dbg.dbgmem.mapAddress(address, true)
+	nb := breaker{
+		target: bp.checkPcBreak,
+		value:  int(ai.mappedAddress),
+	}
+
+	if i := bp.checkBreaker(nb); i != noBreakEqualivalent {
+		_ = bp.drop(i)
+	}
+}
@@ -53,4 +53,32 @@ func (trm *mockTerm) testBreakpoints() {
 
 	trm.sndInput("BREAK HP 100")
 	trm.cmpOutput("")
+
+	// add an expression break. the expression is listed in normalised form
+	trm.sndInput("BREAK [$80] & $0F == 3")
+	trm.cmpOutput("")
+
+	trm.sndInput("LIST BREAKS")
+	trm.cmpOutput(" 3: [$80] & $0f == 3")
+
+	// the same expression written differently is not added
+	trm.sndInput("BREAK [0x80]&0x0f=3")
+	trm.cmpOutput("already exists ([$80] & $0f == 3)")
+
+	// combine conditions with AND/OR
+	trm.sndInput("BREAK [$81] > $40 AND (SL > 200 OR NOT X)")
+	trm.cmpOutput("")
+
+	trm.sndInput("LIST BREAKS")
+	trm.cmpOutput(" 4: [$81] > $40 && (SL > 200 || !X)")
+
+	// TIA and RIOT targets
+	trm.sndInput("BREAK P0.POS 80 & TIMER.INTERVAL 8")
+	trm.cmpOutput("")
+
+	trm.sndInput("LIST BREAKS")
+	trm.cmpOutput(" 5: P0.POS->80 & TIMER.INTERVAL->8")
+
+	trm.sndInput("BREAK P9.POS 80")
+	trm.cmpOutput("invalid target (P9.POS)")
 }
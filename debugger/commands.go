@@ -18,6 +18,7 @@ package debugger
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,6 +37,8 @@ import (
 	"github.com/jetsetilly/gopher2600/logger"
 	"github.com/jetsetilly/gopher2600/patch"
 	"github.com/jetsetilly/gopher2600/symbols"
+	"github.com/jetsetilly/gopher2600/television"
+	"github.com/jetsetilly/gopher2600/videocapture"
 )
 
 var debuggerCommands *commandline.Commands
@@ -194,6 +197,13 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 		case "VIDEO":
 			// changes quantum
 			dbg.quantum = QuantumVideo
+		case "BACK":
+			// step back is always by CPU instruction regardless of quantum
+			err := dbg.rewind.StepBack()
+			if err != nil {
+				return false, err
+			}
+			return false, dbg.resyncLastResult()
 		default:
 			// does not change quantum
 			tokens.Unget()
@@ -206,6 +216,43 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 
 		return true, nil
 
+	case cmdRewind:
+		arg, ok := tokens.Get()
+		if !ok {
+			dbg.printLine(terminal.StyleFeedback, dbg.rewind.String())
+			return false, nil
+		}
+
+		if arg == "BREAK" {
+			if !dbg.breakpoints.any() {
+				return false, errors.New(errors.CommandError, "no breakpoints defined")
+			}
+			found, err := dbg.rewind.RunBackUntil(dbg.breakpoints.matches)
+			if err != nil {
+				return false, err
+			}
+			if !found {
+				dbg.printLine(terminal.StyleFeedback, "no breakpoint found in rewind history")
+			}
+		} else {
+			frames, err := strconv.Atoi(arg)
+			if err != nil {
+				return false, errors.New(errors.CommandError, fmt.Sprintf("frames value must be a number (%s)", arg))
+			}
+			err = dbg.rewind.RewindFrames(frames)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		err := dbg.resyncLastResult()
+		if err != nil {
+			return false, err
+		}
+
+		fn, _ := dbg.tv.GetState(television.ReqFramenum)
+		dbg.printLine(terminal.StyleFeedback, "rewound to frame %d", fn)
+
 	case cmdQuantum:
 		mode, _ := tokens.Get()
 		mode = strings.ToUpper(mode)
@@ -265,6 +312,53 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 			}
 		}
 
+	case cmdState:
+		option, _ := tokens.Get()
+		filename, _ := tokens.Get()
+
+		switch option {
+		case "SAVE":
+			f, err := os.Create(filename)
+			if err != nil {
+				return false, errors.New(errors.CommandError, err)
+			}
+
+			err = dbg.VCS.Snapshot().Save(f)
+			if err != nil {
+				_ = f.Close()
+				return false, err
+			}
+
+			err = f.Close()
+			if err != nil {
+				return false, errors.New(errors.CommandError, err)
+			}
+
+			dbg.printLine(terminal.StyleFeedback, "state saved to %s", filename)
+
+		case "LOAD":
+			f, err := os.Open(filename)
+			if err != nil {
+				return false, errors.New(errors.CommandError, err)
+			}
+			defer f.Close()
+
+			err = dbg.VCS.LoadState(f)
+			if err != nil {
+				return false, err
+			}
+
+			err = dbg.resyncLastResult()
+			if err != nil {
+				return false, err
+			}
+
+			// the rewind history may not be compatible with the loaded state
+			dbg.rewind.Reset()
+
+			dbg.printLine(terminal.StyleFeedback, "state loaded from %s", filename)
+		}
+
 	case cmdInsert:
 		cart, _ := tokens.Get()
 		err := dbg.loadCartridge(cartridgeloader.NewLoader(cart, "AUTO"))
@@ -945,6 +1039,48 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 					s.WriteString(" (auto)")
 				}
 				dbg.printLine(terminal.StyleInstrument, s.String())
+			case "RESIZER":
+				resizer, _ := tokens.Get()
+
+				var id television.FrameResizeID
+				switch strings.ToUpper(resizer) {
+				case "NONE":
+					id = television.FrameResizerNone
+				case "SIMPLE":
+					id = television.FrameResizerSimple
+				case "STATISTICAL":
+					id = television.FrameResizerStatistical
+				default:
+					return false, errors.New(errors.CommandError, "resizer must be one of NONE, SIMPLE or STATISTICAL")
+				}
+
+				err := dbg.tv.SetResizer(id)
+				if err != nil {
+					return false, err
+				}
+			case "BOUNDS":
+				arg, _ := tokens.Get()
+
+				var top, bottom int
+				if strings.ToUpper(arg) != "OFF" {
+					var err error
+
+					top, err = strconv.Atoi(arg)
+					if err != nil {
+						return false, errors.New(errors.CommandError, fmt.Sprintf("top value must be a number (%s)", arg))
+					}
+
+					arg, _ = tokens.Get()
+					bottom, err = strconv.Atoi(arg)
+					if err != nil {
+						return false, errors.New(errors.CommandError, fmt.Sprintf("bottom value must be a number (%s)", arg))
+					}
+				}
+
+				err := dbg.tv.SetFixedBounds(top, bottom)
+				if err != nil {
+					return false, err
+				}
 			default:
 				// already caught by command line ValidateTokens()
 			}
@@ -1091,17 +1227,35 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 
 		controller, ok := tokens.Get()
 		if ok {
+			var err error
+
 			switch strings.ToLower(controller) {
 			case "auto":
 				p.SetAuto(true)
 			case "noauto":
 				p.SetAuto(false)
 			case "joystick":
-				p.SwitchType(input.JoystickType)
+				err = p.SwitchType(input.JoystickType)
 			case "paddle":
-				p.SwitchType(input.PaddleType)
+				err = p.SwitchType(input.PaddleType)
 			case "keypad":
-				p.SwitchType(input.KeypadType)
+				err = p.SwitchType(input.KeypadType)
+			case "driving":
+				err = p.SwitchType(input.DrivingType)
+			case "boostergrip":
+				err = p.SwitchType(input.BoosterGripType)
+			case "trackball":
+				err = p.SwitchType(input.TrackballType)
+			case "mindlink":
+				err = p.SwitchType(input.MindlinkType)
+			case "savekey":
+				err = p.SwitchType(input.SaveKeyType)
+			case "atarivox":
+				err = p.SwitchType(input.AtariVoxType)
+			}
+
+			if err != nil {
+				return false, errors.New(errors.CommandError, err)
 			}
 		}
 
@@ -1114,6 +1268,18 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 			s.WriteString("Paddle")
 		case input.KeypadType:
 			s.WriteString("Keypad")
+		case input.DrivingType:
+			s.WriteString("Driving")
+		case input.BoosterGripType:
+			s.WriteString("BoosterGrip")
+		case input.TrackballType:
+			s.WriteString("Trackball")
+		case input.MindlinkType:
+			s.WriteString("Mindlink")
+		case input.SaveKeyType:
+			s.WriteString("SaveKey")
+		case input.AtariVoxType:
+			s.WriteString("AtariVox")
 		default:
 			s.WriteString("Unknown")
 		}
@@ -1258,6 +1424,113 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 			return false, err
 		}
 
+	case cmdDriving:
+		player, _ := tokens.Get()
+		direction, _ := tokens.Get()
+
+		steps := 1
+		if arg, ok := tokens.Get(); ok {
+			var err error
+			steps, err = strconv.Atoi(arg)
+			if err != nil {
+				return false, errors.New(errors.CommandError, fmt.Sprintf("steps value must be a number (%s)", arg))
+			}
+		}
+
+		if strings.ToUpper(direction) == "LEFT" {
+			steps = -steps
+		}
+
+		err := dbg.handController(player).Handle(input.DrivingTurn, float32(steps))
+		if err != nil {
+			return false, err
+		}
+
+	case cmdBoosterGrip:
+		player, _ := tokens.Get()
+		action, _ := tokens.Get()
+
+		var event input.Event
+		var value bool
+
+		switch strings.ToUpper(action) {
+		case "TRIGGER":
+			event = input.BoosterGripTrigger
+			value = true
+		case "BOOSTER":
+			event = input.BoosterGripBooster
+			value = true
+		case "NOTRIGGER":
+			event = input.BoosterGripTrigger
+			value = false
+		case "NOBOOSTER":
+			event = input.BoosterGripBooster
+			value = false
+		}
+
+		err := dbg.handController(player).Handle(event, value)
+		if err != nil {
+			return false, err
+		}
+
+	case cmdTrackball:
+		player, _ := tokens.Get()
+		direction, _ := tokens.Get()
+
+		amount := 1
+		if arg, ok := tokens.Get(); ok {
+			var err error
+			amount, err = strconv.Atoi(arg)
+			if err != nil {
+				return false, errors.New(errors.CommandError, fmt.Sprintf("amount value must be a number (%s)", arg))
+			}
+		}
+
+		var event input.Event
+
+		switch strings.ToUpper(direction) {
+		case "LEFT":
+			event = input.TrackballHorizontal
+			amount = -amount
+		case "RIGHT":
+			event = input.TrackballHorizontal
+		case "UP":
+			event = input.TrackballVertical
+			amount = -amount
+		case "DOWN":
+			event = input.TrackballVertical
+		}
+
+		err := dbg.handController(player).Handle(event, float32(amount))
+		if err != nil {
+			return false, err
+		}
+
+	case cmdMindlink:
+		player, _ := tokens.Get()
+		arg, _ := tokens.Get()
+
+		hc := dbg.handController(player)
+
+		var err error
+
+		switch strings.ToUpper(arg) {
+		case "START":
+			err = hc.Handle(input.Fire, true)
+		case "NOSTART":
+			err = hc.Handle(input.Fire, false)
+		default:
+			position, convErr := strconv.Atoi(arg)
+			if convErr != nil || position < 0 || position > 100 {
+				return false, errors.New(errors.CommandError, fmt.Sprintf("position must be between 0 and 100 (%s)", arg))
+			}
+			err = hc.Handle(input.MindlinkSet, float32(position)/100.0)
+		}
+
+		if err != nil {
+			return false, err
+		}
+
 	case cmdBreak:
 		err := dbg.breakpoints.parseCommand(tokens)
 		if err != nil {
@@ -1450,7 +1723,135 @@ func (dbg *Debugger) processTokens(tokens *commandline.Tokens) (bool, error) {
 				dbg.printLine(terminal.StyleFeedback, "log is empty")
 			}
 		}
+
+	case cmdCapture:
+		option, ok := tokens.Get()
+		if ok {
+			switch option {
+			case "START":
+				filename, ok := tokens.Get()
+				if !ok {
+					return false, errors.New(errors.CommandError, "filename required for CAPTURE START")
+				}
+
+				if dbg.capture == nil {
+					dbg.capture = videocapture.NewCapture(dbg.tv)
+				}
+
+				err := dbg.capture.Start(filename)
+				if err != nil {
+					return false, errors.New(errors.CommandError, err)
+				}
+				dbg.printLine(terminal.StyleFeedback, "capturing to %s", filename)
+
+			case "STOP":
+				if dbg.capture == nil {
+					dbg.printLine(terminal.StyleFeedback, "not capturing")
+					return false, nil
+				}
+
+				capturing, filename := dbg.capture.IsCapturing()
+				if !capturing {
+					dbg.printLine(terminal.StyleFeedback, "not capturing")
+					return false, nil
+				}
+
+				err := dbg.capture.Stop()
+				if err != nil {
+					return false, errors.New(errors.CommandError, err)
+				}
+				dbg.printLine(terminal.StyleFeedback, "capture to %s stopped", filename)
+			}
+		} else {
+			if dbg.capture != nil {
+				if ok, filename := dbg.capture.IsCapturing(); ok {
+					dbg.printLine(terminal.StyleFeedback, "capturing to %s", filename)
+					return false, nil
+				}
+			}
+			dbg.printLine(terminal.StyleFeedback, "not capturing")
+		}
+
+	case cmdTraceLog:
+		option, ok := tokens.Get()
+		if ok {
+			switch option {
+			case "START":
+				filename, ok := tokens.Get()
+				if !ok {
+					return false, errors.New(errors.CommandError, "filename required for TRACELOG START")
+				}
+
+				err := dbg.StartTraceLog(filename)
+				if err != nil {
+					return false, errors.New(errors.CommandError, err)
+				}
+				dbg.printLine(terminal.StyleFeedback, "logging instructions to %s", filename)
+
+			case "STOP":
+				if dbg.tracelog == nil {
+					dbg.printLine(terminal.StyleFeedback, "not logging instructions")
+					return false, nil
+				}
+
+				filename := dbg.tracelog.filename
+				err := dbg.stopTraceLog()
+				if err != nil {
+					return false, errors.New(errors.CommandError, err)
+				}
+				dbg.printLine(terminal.StyleFeedback, "instruction log to %s stopped", filename)
+			}
+		} else {
+			if dbg.tracelog != nil {
+				dbg.printLine(terminal.StyleFeedback, "logging instructions to %s", dbg.tracelog.filename)
+				return false, nil
+			}
+			dbg.printLine(terminal.StyleFeedback, "not logging instructions")
+		}
+
+	case cmdScreenshot:
+		req := gui.Screenshot{}
+
+		option, ok := tokens.Get()
+		for ok {
+			switch strings.ToUpper(option) {
+			case "DEBUG":
+				req.DebugColors = true
+			case "OVERLAY":
+				req.Overlay = true
+			case "FULL":
+				req.Full = true
+			default:
+				req.Filename = option
+			}
+			option, ok = tokens.Get()
+		}
+
+		if req.Filename == "" {
+			req.Filename = gui.ScreenshotFilename(dbg.VCS.Mem.Cart.Filename)
+		}
+
+		// fall back to the screenshot renderer if the GUI doesn't support
+		// screenshots. for example, when the debugger is running headless
+		err := dbg.scr.ReqFeature(gui.ReqScreenshot, req)
+		if err != nil && errors.Is(err, errors.UnsupportedGUIRequest) {
+			err = dbg.screenshot.Save(req)
+		}
+		if err != nil {
+			return false, errors.New(errors.CommandError, err)
+		}
+		dbg.printLine(terminal.StyleFeedback, "screenshot saved to %s", req.Filename)
 	}
 
 	return false, nil
 }
+
+// handController returns the hand controller for the player argument of a
+// command. the player argument is always "0" or "1" because of the command
+// template.
+func (dbg *Debugger) handController(player string) *input.HandController {
+	if player == "1" {
+		return dbg.VCS.HandController1
+	}
+	return dbg.VCS.HandController0
+}
@@ -41,7 +41,31 @@ command.
 
 In the above example, the emulation will run until the next frame is reached.
 Think of target stepping as a single use trap. Note that breakpoints, watches
-and traps still trigger a halt during a target step.`,
+and traps still trigger a halt during a target step.
+
+The BACK argument steps the emulation back by one CPU instruction, regardless of the
+current quantum. See the help for the REWIND command for more information.`,
+
+	cmdRewind: `Wind the emulation backwards. With a numeric argument the emulation will be
+wound back by that number of frames, to the start of the frame.
+
+	REWIND 10
+
+With the BREAK argument, the emulation will run backwards until the most recent point
+where a breakpoint condition started to be met. Traps and watches are not considered.
+
+	REWIND BREAK
+
+Without an argument, a summary of the rewind history is printed.
+
+The rewind history is made up of snapshots of the emulation, taken at the start of
+every frame. Points between snapshots are reached by running the emulation forward
+from the nearest snapshot. The history covers only the most recent frames and is
+discarded when a new cartridge is inserted or a state is loaded with the STATE command.
+
+Controller and panel input is not recorded. When running forward from a snapshot,
+the input is as it was when the snapshot was taken, so input that arrived part way
+through a frame is lost by STEP BACK and REWIND BREAK.`,
 
 	cmdQuantum: `Change or view stepping quantum. The stepping quantum defines the frequency
 at which the emulation is checked and reported upon by the debugger.
@@ -184,7 +208,20 @@ Video and CPU cycles are counted from the beginning of the current scanline.`,
 	cmdTV: `Display the current TV state. Optional argument SPEC will display the currently
 selected TV specification. Supplying an argument to the TV SPEC command will set the TV to that
 specification. AUTO indicates that the specification will change if the condition of the TV signal
-suggest that it should.`,
+suggest that it should.
+
+Automatic changes are between NTSC and PAL, and between PAL and PAL60. The
+colour system can not be detected from the signal so PAL-M and SECAM must be
+specified explicitly.
+
+The RESIZER argument selects the method by which the visible screen adapts to
+the TV signal. The SIMPLE resizer grows the screen as soon as a frame requires
+it. The STATISTICAL resizer observes many frames and accommodates most of them,
+ignoring outliers. NONE disables resizing.
+
+The BOUNDS argument fixes the top and bottom scanlines of the visible screen.
+Only the STATISTICAL resizer honours fixed bounds. BOUNDS OFF removes the fixed
+bounds.`,
 
 	cmdPlayer: `Display the current state of the player sprites. The player information to
 display can be selected with 0 or 1 arguments. Omitting this argument will show
@@ -354,7 +391,14 @@ process key video events were triggered.`,
 
 	// user input
 	cmdController: `Change the current controller type for the specified player. Specifying a
-controller turns off AUTO changing. Turn AUTO changing back on with the AUTO flag.`,
+controller turns off AUTO changing. Turn AUTO changing back on with the AUTO flag.
+
+The DRIVING, BOOSTERGRIP, TRACKBALL and MINDLINK controllers are never selected
+by AUTO changing and must be specified explicitly.
+
+SAVEKEY and ATARIVOX attach the EEPROM devices of the same name to the port.
+The contents of the EEPROM are saved to disk whenever they are written to. The
+AtariVox speech data is logged but not otherwise emulated.`,
 
 	cmdPanel: "Inspect and set front panel settings. Switches can be set or toggled..",
 
@@ -371,6 +415,35 @@ video cycles.
 
 Specify the player with the 0 or 1 arguments.`,
 
+	cmdDriving: `Turn the driving controller for Player 0 or Player 1. The controller is
+turned one step unless the number of steps is specified. There are four steps
+in the controller's gray code sequence.
+
+The fire button is set with the JOYSTICK command.
+
+The controller must have been selected with the CONTROLLER command.`,
+
+	cmdBoosterGrip: `Set the TRIGGER and BOOSTER buttons of the booster grip for Player 0 or
+Player 1 for the next and subsequent video cycles.
+
+The stick and the fire button are set with the JOYSTICK command.
+
+The controller must have been selected with the CONTROLLER command.`,
+
+	cmdTrackball: `Move the trackball for Player 0 or Player 1. The trackball is moved by one
+unit unless the amount is specified. Movement is presented to the VCS at a rate
+of one unit per scanline.
+
+The fire button is set with the JOYSTICK command.
+
+The controller must have been selected with the CONTROLLER command.`,
+
+	cmdMindlink: `Set the position of the mindlink for Player 0 or Player 1. The position is a
+value between 0 and 100. START and NOSTART set and clear the signal used to
+start the game.
+
+The controller must have been selected with the CONTROLLER command.`,
+
 	// halt conditions
 	cmdBreak: `Halt execution of the emulation when a specific value is "loaded" into a named
 target. A target is a part of the emulation hardware that can be interegated
@@ -380,6 +453,30 @@ about its state. Current targets are:
 	the TV state (FRAMENUM, SCANLINE, HORIZPOS)
 	cartidge BANK
 	CPU result (RESULT MNEMONIC, RESULT EFFECT, RESULT PAGEFAULT, RESULT BUG)
+	the TIA state (see below)
+	the RIOT state (TIMER, TIMER.INTERVAL, TIMER.TICKS, SWCHA, SWCHB)
+
+The TIA targets for the sprites are prefixed with the sprite name (P0, P1, M0,
+M1 or BL):
+
+	P0.POS		the pixel at which the sprite will begin drawing
+	P0.RESET	the pixel at which the sprite was last reset
+	P0.NUSIZ	the NUSIZ value (not available for the ball)
+	BL.CTRLPF	the CTRLPF value (ball only)
+	P0.HMOVE	the HMOVE value of the sprite (0 to 15)
+	P0.MOREHMOVE	the sprite is receiving HMOVE clocks (true or false)
+
+The remaining TIA targets are:
+
+	HMOVE.CT	the HMOVE counter (15 to 0, or -1 if inactive)
+	HMOVE.LATCH	HMOVE has been triggered this scanline (true or false)
+	VSYNC, VBLANK	the VSYNC and VBLANK signals (true or false)
+	the collision latches (CXM0P, CXM1P, CXP0FB, CXP1FB, CXM0FB, CXM1FB, CXBLPF, CXPPMM)
+	the audio registers (AUDC0, AUDC1, AUDF0, AUDF1, AUDV0, AUDV1)
+
+For example, to halt when the timer reaches zero:
+
+	BREAK TIMER 0
 
 Specifying an address without a target will be assumed to be break on the PC
 and the current cartridge bank. So:
@@ -409,6 +506,34 @@ execution breaks on SL 10 & X 255. After resumption, the break will not apply
 until X changes from 255 to something else and then back again, or SL is hit on
 the next frame and X again (or still) has a value of 255.i
 
+More complex conditions can be specified with an expression. Any condition
+containing an operator (other than the & used above) is treated as an
+expression. Execution will halt when the expression becomes true. For example:
+
+	BREAK [$81] > $40 && SL > 200
+
+This break will halt execution when the value in memory at address $81 is
+greater than $40 and the TV is on a scanline greater than 200. Square brackets
+read the value in memory at the address given inside the brackets.
+
+Expressions can contain numbers, targets, symbols and the following operators:
+
+	arithmetic: + - * / %
+	bitwise:    & | ^ ~ << >>
+	comparison: == != < <= > >=
+	logical:    && || ! (or AND OR NOT)
+
+Unlike in the simple form of BREAK, the & operator in an expression is a bitwise
+AND. So, to test the lower nibble of an address:
+
+	BREAK [$80] & $0F == 3
+
+Note that the bitwise operators take precedence over the comparison operators.
+Symbols resolve to the address of the symbol, so the value of a symbol is read
+with square brackets. Only targets with numeric values can be used in an
+expression. Where a target and a symbol share a name (for example SWCHA) the
+target is used.
+
 Existing breakpoints can be reviewed with the LIST command and deleted with the
 DROP or CLEAR commands`,
 
@@ -417,6 +542,13 @@ to any other value. Traps are very similar to breakpoints in some ways.  They
 can be applied to the same set of targets as BREAK (see help for BREAK command
 for details).
 
+A trap can also be applied to the value of an expression. For example, the
+following will halt execution when the lower nibble of address $80 changes:
+
+	TRAP [$80] & $0F
+
+See the help for the BREAK command for a description of expressions.
+
 Existing traps can be reviewed with the LIST command and deleted with the
 DROP or CLEAR commands`,
 
@@ -485,6 +617,56 @@ about an address.`,
 	cmdDrop:  "Drop a specific BREAK, TRAP, WATCH or TRACE condition, using the number of the condition reported by LIST.",
 	cmdClear: "Clear all BREAKS, TRAPS, WATCHES and TRACES.",
 
+	cmdState: `Save and load the state of the emulation to and from a file.
+
+	STATE SAVE start.state
+	STATE LOAD start.state
+
+A state can only be loaded when the same cartridge that was attached when the
+state was saved is attached to the emulation. State files are not guaranteed to
+be compatible between different versions of the emulator.
+
+The contents of a SaveKey or AtariVox EEPROM are not part of the state. Data
+saved to the EEPROM is not lost when a state is loaded.`,
+
 	cmdPref: "Set preferences for debugger.",
 	cmdLog:  "Print log to terminal.",
+
+	cmdCapture: `Capture the television output to a video file. The type of the file is decided
+by the filename extension:
+
+	.avi	uncompressed video and audio
+	.png	animated PNG (video only)
+
+	CAPTURE START game.avi
+	CAPTURE STOP
+
+Capture begins at the start of the next frame and continues, frame by frame, until
+stopped or the debugger exits. With no arguments the current capture status is printed.`,
+
+	cmdTraceLog: `Write every instruction executed by the CPU to a file. Each line shows the
+frame, scanline and horizontal position, the cartridge bank, the address and disassembly of the
+instruction, the CPU registers and flags as they were before the instruction was executed, the
+CPU cycle count, the number of cycles taken by the instruction and the memory address accessed.
+
+	TRACELOG START trace.log
+	TRACELOG STOP
+
+The format is similar to the trace output of Stella so the two can be compared with a diff tool.
+A marker line is written to the log after a REWIND, a STEP BACK or a STATE LOAD, to show that
+the emulation has jumped to a different point. The log is stopped when the debugger exits. With no arguments the current status is printed.`,
+
+	cmdScreenshot: `Save the television image to a PNG file. The image is saved exactly as it is at
+the moment the command is run, so in a script or an ONHALT command, a screenshot can
+be taken part way through a frame.
+
+The DEBUG argument saves the image using debug colors and OVERLAY draws the current
+overlay on top of the image. By default only the visible area of the screen is saved.
+The FULL argument saves the entire television image, including the blanking areas.
+
+If no filename is given then a unique filename is created based on the name of the
+cartridge and the current time.
+
+Screenshots can be saved when the GUI does not support them, for example when the
+debugger is running headless. The OVERLAY argument is not available in this case.`,
 }
@@ -23,8 +23,10 @@ const (
 	cmdRun     = "RUN"
 	cmdStep    = "STEP"
 	cmdHalt    = "HALT"
+	cmdRewind  = "REWIND"
 	cmdQuantum = "QUANTUM"
 	cmdScript  = "SCRIPT"
+	cmdState   = "STATE"
 
 	cmdInsert      = "INSERT"
 	cmdCartridge   = "CARTRIDGE"
@@ -53,10 +55,14 @@ const (
 	cmdDisplay     = "DISPLAY"
 
 	// user input
-	cmdController = "CONTROLLER"
-	cmdPanel      = "PANEL"
-	cmdJoystick   = "JOYSTICK"
-	cmdKeypad     = "KEYPAD"
+	cmdController  = "CONTROLLER"
+	cmdPanel       = "PANEL"
+	cmdJoystick    = "JOYSTICK"
+	cmdKeypad      = "KEYPAD"
+	cmdDriving     = "DRIVING"
+	cmdBoosterGrip = "BOOSTERGRIP"
+	cmdTrackball   = "TRACKBALL"
+	cmdMindlink    = "MINDLINK"
 
 	// halt conditions
 	cmdBreak = "BREAK"
@@ -68,8 +74,11 @@ const (
 	cmdClear = "CLEAR"
 
 	// meta
-	cmdPref = "PREF"
-	cmdLog  = "LOG"
+	cmdPref       = "PREF"
+	cmdLog        = "LOG"
+	cmdCapture    = "CAPTURE"
+	cmdTraceLog   = "TRACELOG"
+	cmdScreenshot = "SCREENSHOT"
 )
 
 const cmdHelp = "HELP"
@@ -79,10 +88,12 @@ var commandTemplate = []string{
 	cmdQuit,
 
 	cmdRun,
-	cmdStep + " (CPU|VIDEO|%<target>S)",
+	cmdStep + " (CPU|VIDEO|BACK|%<target>S)",
 	cmdHalt,
+	cmdRewind + " (%<frames>N|BREAK)",
 	cmdQuantum + " (CPU|VIDEO)",
 	cmdScript + " [RECORD %<new file>F|END|%<file>F]",
+	cmdState + " [SAVE %<file>F|LOAD %<file>F]",
 
 	cmdInsert + " %<cartridge>F",
 	cmdCartridge + " (BANK|STATIC|REGISTERS|RAM)",
@@ -103,7 +114,7 @@ var commandTemplate = []string{
 	cmdTimer,
 	cmdTIA,
 	cmdAudio,
-	cmdTV + " (SPEC (PAL|NTSC|AUTO))",
+	cmdTV + " (SPEC (NTSC|PAL|PAL-M|PAL60|SECAM|AUTO)|RESIZER (NONE|SIMPLE|STATISTICAL)|BOUNDS [OFF|%<top>N %<bottom>N])",
 	cmdPlayer + " (0|1)",
 	cmdMissile + " (0|1)",
 	cmdBall,
@@ -111,14 +122,18 @@ var commandTemplate = []string{
 	cmdDisplay + " (ON|OFF|SCALE [%<scale value>P]|MASKING (ON|OFF)|ALT (ON|OFF)|OVERLAY (ON|OFF))", // see notes
 
 	// user input
-	cmdController + " [0|1] (AUTO|NOAUTO|JOYSTICK|PADDLE|KEYPAD)",
+	cmdController + " [0|1] (AUTO|NOAUTO|JOYSTICK|PADDLE|KEYPAD|DRIVING|BOOSTERGRIP|TRACKBALL|MINDLINK|SAVEKEY|ATARIVOX)",
 	cmdPanel + " (SET [P0PRO|P1PRO|P0AM|P1AM|COL|BW]|TOGGLE [P0|P1|COL]|[HOLD|RELEASE] [SELECT|RESET])",
 	cmdJoystick + " [0|1] [LEFT|RIGHT|UP|DOWN|FIRE|NOLEFT|NORIGHT|NOUP|NODOWN|NOFIRE]",
 	cmdKeypad + " [0|1] [none|0|1|2|3|4|5|6|7|8|9|*|#]",
+	cmdDriving + " [0|1] [LEFT|RIGHT] (%<steps>N)",
+	cmdBoosterGrip + " [0|1] [TRIGGER|BOOSTER|NOTRIGGER|NOBOOSTER]",
+	cmdTrackball + " [0|1] [LEFT|RIGHT|UP|DOWN] (%<amount>N)",
+	cmdMindlink + " [0|1] [START|NOSTART|%<position>N]",
 
 	// halt conditions
-	cmdBreak + " [%<target>S %<value>N|%<pc value>S] {& %<target>S %<value>S|& %<value>S}",
-	cmdTrap + " [%<target>S] {%<targets>S}",
+	cmdBreak + " [%<target>S %<value>N|%<pc value>S|%<condition>S] {%<condition>S}",
+	cmdTrap + " [%<target>S|%<expression>S] {%<targets>S}",
 	cmdWatch + " (READ|WRITE) (MIRRORS|ANY) [%<address>S] (%<value>S)",
 	cmdTrace + " (%<address>S)",
 	cmdList + " [BREAKS|TRAPS|WATCHES|TRACES|ALL]",
@@ -128,6 +143,9 @@ var commandTemplate = []string{
 	// meta
 	cmdPref + " ([LOAD|SAVE]|[SET|UNSET|TOGGLE] [RANDSTART|RANDPINS|FXXXMIRROR])",
 	cmdLog + " (CLEAR)",
+	cmdCapture + " (START %<file>F|STOP)",
+	cmdTraceLog + " (START %<file>F|STOP)",
+	cmdScreenshot + " (DEBUG) (OVERLAY) (FULL) (%<file>F)",
 }
 
 // list of commands that should not be executed when recording/playing scripts
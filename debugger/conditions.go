@@ -0,0 +1,151 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/debugger/expression"
+	"github.com/jetsetilly/gopher2600/debugger/terminal/commandline"
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/symbols"
+)
+
+// exprEnvironment implements the expression.Environment interface. identifiers
+// in expressions can be debugger targets or symbols
+type exprEnvironment struct {
+	dbg *Debugger
+}
+
+// Identifier implements the expression.Environment interface
+func (env exprEnvironment) Identifier(name string) (func() (int, error), error) {
+	// targets take priority over symbols
+	trg, err := parseTarget(env.dbg, commandline.TokeniseInput(name))
+	if err == nil && trg != nil {
+		if _, ok := trg.TargetValue().(string); ok {
+			return nil, errors.New(errors.CommandError, fmt.Sprintf("target (%s) cannot be used in an expression", name))
+		}
+
+		return func() (int, error) {
+			switch v := trg.TargetValue().(type) {
+			case int:
+				return v, nil
+			case bool:
+				if v {
+					return 1, nil
+				}
+				return 0, nil
+			case error:
+				return 0, v
+			default:
+				return 0, fmt.Errorf("unsupported value type (%T) for target (%s)", v, trg.Label())
+			}
+		}, nil
+	}
+
+	// symbols resolve to their address
+	_, _, addr, err := env.dbg.dbgmem.symtable.SearchSymbol(name, symbols.UnspecifiedSymTable)
+	if err != nil {
+		return nil, errors.New(errors.CommandError, fmt.Sprintf("unrecognised target or symbol (%s)", name))
+	}
+
+	return func() (int, error) {
+		return int(addr), nil
+	}, nil
+}
+
+// Peek implements the expression.Environment interface
+func (env exprEnvironment) Peek(address int) (int, error) {
+	if address < 0 || address > 0xffff {
+		return 0, errors.New(errors.UnpeekableAddress, address)
+	}
+
+	ai, err := env.dbg.dbgmem.peek(uint16(address))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(ai.data), nil
+}
+
+// the characters that cannot appear in the simple "target value" form of the
+// BREAK and TRAP commands. the & and | symbols are used by the simple form if
+// they appear on their own
+const expressionChars = "=<>!~+*/%^()[]"
+
+// isExpression returns true if the input to BREAK or TRAP should be parsed as
+// an expression rather than as a list of targets and values
+func isExpression(input string) bool {
+	if strings.ContainsAny(input, expressionChars) {
+		return true
+	}
+
+	for _, f := range strings.Fields(input) {
+		switch strings.ToUpper(f) {
+		case "AND", "OR", "NOT", "&&", "||":
+			return true
+		}
+
+		// & and | attached to an operand are bitwise operators
+		if len(f) > 1 && strings.ContainsAny(f, "&|") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseExpression parses the remaining tokens as an expression
+func parseExpression(dbg *Debugger, tokens *commandline.Tokens) (*expression.Expression, error) {
+	input := tokens.Remainder()
+	tokens.End()
+	return expression.Parse(input, exprEnvironment{dbg: dbg})
+}
+
+// conditionTarget creates a target from an expression for use with the BREAK
+// command. the value of the target is true when the expression evaluates to a
+// non-zero value and false otherwise, including when the expression cannot be
+// evaluated
+func conditionTarget(ex *expression.Expression) *target {
+	return &target{
+		label: ex.String(),
+		currentValue: func() interface{} {
+			v, err := ex.Evaluate()
+			return err == nil && v != 0
+		},
+		condition: true,
+	}
+}
+
+// expressionTarget creates a target from an expression for use with the TRAP
+// command. the value of the target is the value of the expression or the
+// error message if the expression cannot be evaluated.
+//
+// note that the error itself is not used as the value because target values
+// must be comparable
+func expressionTarget(ex *expression.Expression) *target {
+	return &target{
+		label: ex.String(),
+		currentValue: func() interface{} {
+			v, err := ex.Evaluate()
+			if err != nil {
+				return err.Error()
+			}
+			return v
+		},
+	}
+}
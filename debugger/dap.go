@@ -0,0 +1,95 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/cartridgeloader"
+	"github.com/jetsetilly/gopher2600/debugger/dap"
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// ListenDAP starts a Debug Adapter Protocol server on the address. Once
+// started, the debugger will take its input from the DAP client rather than
+// from the terminal. Output is still sent to the terminal.
+//
+// The server listens on localhost if the address has no host. Addresses that
+// are not loopback addresses are refused unless allowRemote is true.
+//
+// Must be called before Start().
+func (dbg *Debugger) ListenDAP(address string, allowRemote bool) error {
+	address, err := remoteAddress(address, allowRemote)
+	if err != nil {
+		return err
+	}
+
+	dbg.remote, err = dap.NewServer(address, &dapTarget{remoteTarget{dbg: dbg}})
+	if err != nil {
+		return errors.New(errors.DebuggerError, err)
+	}
+	return nil
+}
+
+// dapTarget implements the dap.Target interface
+type dapTarget struct {
+	remoteTarget
+}
+
+// GetRegisters implements the dap.Target interface
+func (tgt *dapTarget) GetRegisters() []dap.Register {
+	cpu := tgt.dbg.VCS.CPU
+	return []dap.Register{
+		{Name: "PC", Value: cpu.PC.Value(), Bits: cpu.PC.BitWidth()},
+		{Name: "A", Value: uint16(cpu.A.Value()), Bits: cpu.A.BitWidth()},
+		{Name: "X", Value: uint16(cpu.X.Value()), Bits: cpu.X.BitWidth()},
+		{Name: "Y", Value: uint16(cpu.Y.Value()), Bits: cpu.Y.BitWidth()},
+		{Name: "SP", Value: uint16(cpu.SP.Value()), Bits: cpu.SP.BitWidth()},
+		{Name: "P", Value: uint16(cpu.Status.Value()), Bits: 8, Detail: cpu.Status.String()},
+	}
+}
+
+// SetRegister implements the dap.Target interface
+func (tgt *dapTarget) SetRegister(name string, value uint16) error {
+	cpu := tgt.dbg.VCS.CPU
+	switch name {
+	case "PC":
+		cpu.PC.Load(value)
+	case "A":
+		cpu.A.Load(uint8(value))
+	case "X":
+		cpu.X.Load(uint8(value))
+	case "Y":
+		cpu.Y.Load(uint8(value))
+	case "SP":
+		cpu.SP.Load(uint8(value))
+	case "P":
+		cpu.Status.FromValue(uint8(value))
+	default:
+		return errors.New(errors.DebuggerError, fmt.Sprintf("unknown register (%s)", name))
+	}
+	return nil
+}
+
+// CartridgeFilename implements the dap.Target interface
+func (tgt *dapTarget) CartridgeFilename() string {
+	return tgt.dbg.VCS.Mem.Cart.Filename
+}
+
+// LoadCartridge implements the dap.Target interface
+func (tgt *dapTarget) LoadCartridge(filename string) error {
+	return tgt.dbg.loadCartridge(cartridgeloader.NewLoader(filename, "AUTO"))
+}
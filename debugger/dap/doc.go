@@ -0,0 +1,40 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package dap implements a server for the Debug Adapter Protocol. It allows
+// editors that understand the protocol (VS Code and many others) to control
+// the debugger over a local TCP connection.
+//
+// The Server type satisfies the terminal.Input interface in the same way as
+// the gdbremote.Server type. The debugger reads from the server instead of the
+// terminal. Requests from the client to continue or to step the emulation are
+// converted into debugger commands, namely RUN and STEP CPU. Requests to pause
+// a running emulation are converted into the HALT command. All other requests
+// are serviced through the Target interface.
+//
+// Requests are read from the connection in a separate goroutine and sent to
+// the debugger's RawEvents channel. This means that requests are serviced in
+// the debugger's goroutine whether the emulation is halted or running.
+//
+// Stepping over a subroutine (the "next" request) and stepping out of a
+// subroutine (the "stepOut" request) are implemented with a temporary
+// breakpoint that is removed when the emulation next halts.
+//
+// Source breakpoints and source locations in stack traces require a listing
+// file produced by the DASM assembler. See the Listing type.
+//
+// Only one client can be connected at once. When a client disconnects any
+// breakpoints it added are removed and the server waits for a new connection.
+package dap
@@ -0,0 +1,252 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package dap
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// SourceLine identifies a line in a source file
+type SourceLine struct {
+	File string
+	Line int
+}
+
+// Listing contains the mapping between source lines and addresses, as found in
+// a listing file created by DASM (the -l option).
+//
+// Addresses are stored without regard to cartridge banking. If more than one
+// bank uses the same origin then a source line for an address will be the
+// first one found in the listing.
+type Listing struct {
+	// source filenames in the listing are relative to the directory of the
+	// listing file
+	dir string
+
+	// the address of every line that generates data. keyed by filename and
+	// line number
+	lines map[string]map[int]uint16
+
+	// the first source line to generate data at an address
+	addresses map[uint16]SourceLine
+}
+
+// the columns of a line in a DASM listing file (after tab expansion). the
+// address field is followed by the symbol flags, which we're not interested
+// in, and then up to four bytes of generated data.
+const (
+	lstLineNumEnd  = 7
+	lstMacroFlag   = 8
+	lstAddress     = 9
+	lstAddressEnd  = 13
+	lstGenData     = 31
+	lstGenDataEnd  = 43
+	lstFileMarker  = "------- FILE "
+	lstMacroMarker = '+'
+)
+
+// only the lower 13 bits of an address are seen by the 6507
+const addressMask = 0x1fff
+
+// ReadListing reads and parses a DASM listing file
+func ReadListing(filename string) (*Listing, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.New(errors.DAP, err)
+	}
+	defer f.Close()
+
+	lst := &Listing{
+		dir:       filepath.Dir(filename),
+		lines:     make(map[string]map[int]uint16),
+		addresses: make(map[uint16]SourceLine),
+	}
+
+	file := ""
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		s := expandTabs(scanner.Text())
+
+		// change of source file
+		if strings.HasPrefix(s, lstFileMarker) {
+			fields := strings.Fields(s[len(lstFileMarker):])
+			if len(fields) > 0 {
+				file = fields[0]
+			}
+			continue // for loop
+		}
+
+		if file == "" || len(s) < lstGenData {
+			continue // for loop
+		}
+
+		// lines generated by macro expansion refer to the line in the macro
+		// definition and not to the source line
+		if s[lstMacroFlag] == lstMacroMarker {
+			continue // for loop
+		}
+
+		line, err := strconv.Atoi(strings.TrimSpace(s[:lstLineNumEnd]))
+		if err != nil {
+			continue // for loop
+		}
+
+		address, err := strconv.ParseUint(s[lstAddress:lstAddressEnd], 16, 16)
+		if err != nil {
+			continue // for loop
+		}
+
+		// lines that do not generate data are not interesting
+		end := lstGenDataEnd
+		if len(s) < end {
+			end = len(s)
+		}
+		if !isGenData(s[lstGenData:end]) {
+			continue // for loop
+		}
+
+		if lst.lines[file] == nil {
+			lst.lines[file] = make(map[int]uint16)
+		}
+		lst.lines[file][line] = uint16(address)
+
+		a := uint16(address) & addressMask
+		if _, ok := lst.addresses[a]; !ok {
+			lst.addresses[a] = SourceLine{File: file, Line: line}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New(errors.DAP, err)
+	}
+
+	if len(lst.addresses) == 0 {
+		return nil, errors.New(errors.DAP, fmt.Sprintf("no source lines in listing (%s)", filename))
+	}
+
+	return lst, nil
+}
+
+// DASM compresses spaces in the listing file into tabs, assuming a tab width
+// of eight
+func expandTabs(s string) string {
+	if !strings.ContainsRune(s, '\t') {
+		return s
+	}
+
+	b := strings.Builder{}
+	col := 0
+	for _, r := range s {
+		if r == '\t' {
+			n := 8 - col%8
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		} else {
+			b.WriteRune(r)
+			col++
+		}
+	}
+	return b.String()
+}
+
+// returns true if the string is a non-empty list of two-digit hex values
+func isGenData(s string) bool {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, f := range fields {
+		if len(f) != 2 {
+			return false
+		}
+		if _, err := strconv.ParseUint(f, 16, 8); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Source returns the source line for the address
+func (lst *Listing) Source(address uint16) (SourceLine, bool) {
+	sl, ok := lst.addresses[address&addressMask]
+	return sl, ok
+}
+
+// Path returns the path of a file named in the listing
+func (lst *Listing) Path(file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(lst.dir, file)
+}
+
+// Address returns the address for the source line. The path can be a path to
+// the source file or the filename as it appears in the listing. If the line
+// does not generate any data then the address of the next line that does is
+// returned. The returned line number is the line that the address belongs to.
+func (lst *Listing) Address(path string, line int) (uint16, int, bool) {
+	lines, ok := lst.file(path)
+	if !ok {
+		return 0, 0, false
+	}
+
+	// the last line in the file that generates data
+	last := 0
+	for l := range lines {
+		if l > last {
+			last = l
+		}
+	}
+
+	for l := line; l <= last; l++ {
+		if a, ok := lines[l]; ok {
+			return a, l, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// find the lines for the file specified by path
+func (lst *Listing) file(path string) (map[int]uint16, bool) {
+	if lines, ok := lst.lines[path]; ok {
+		return lines, true
+	}
+
+	path = filepath.Clean(path)
+	for f, lines := range lst.lines {
+		if filepath.Clean(lst.Path(f)) == path {
+			return lines, true
+		}
+	}
+
+	// fall back to matching the end of the path
+	for f, lines := range lst.lines {
+		if strings.HasSuffix(path, string(filepath.Separator)+filepath.Clean(f)) {
+			return lines, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,88 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package dap_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/debugger/dap"
+)
+
+func TestListing(t *testing.T) {
+	lst, err := dap.ReadListing(filepath.Join("testdata", "test.lst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// source lines for addresses. the mapping ignores the upper bits of the
+	// address
+	for _, tst := range []struct {
+		address uint16
+		line    int
+	}{
+		{0xf000, 7},
+		{0xf002, 9},
+		{0xf007, 13},
+		{0x100a, 14},
+		{0xfffc, 16},
+	} {
+		sl, ok := lst.Source(tst.address)
+		if !ok {
+			t.Errorf("no source line for address (%#04x)", tst.address)
+			continue // for loop
+		}
+		if sl.File != "test.asm" || sl.Line != tst.line {
+			t.Errorf("unexpected source line for address (%#04x): %s:%d", tst.address, sl.File, sl.Line)
+		}
+	}
+
+	// the macro expansion line should not be in the mapping
+	if _, ok := lst.Source(0xf009); ok {
+		t.Errorf("unexpected source line for macro expansion")
+	}
+
+	// addresses for source lines. lines that do not generate data resolve to
+	// the next line that does
+	for _, tst := range []struct {
+		path    string
+		line    int
+		address uint16
+		actual  int
+	}{
+		{"test.asm", 7, 0xf000, 7},
+		{"test.asm", 1, 0xf000, 7},
+		{"test.asm", 11, 0xf005, 12},
+		{filepath.Join("testdata", "test.asm"), 14, 0xf00a, 14},
+		{filepath.Join("some", "other", "dir", "test.asm"), 13, 0xf007, 13},
+	} {
+		a, l, ok := lst.Address(tst.path, tst.line)
+		if !ok {
+			t.Errorf("no address for source line (%s:%d)", tst.path, tst.line)
+			continue // for loop
+		}
+		if a != tst.address || l != tst.actual {
+			t.Errorf("unexpected address for source line (%s:%d): %#04x line %d", tst.path, tst.line, a, l)
+		}
+	}
+
+	if _, _, ok := lst.Address("test.asm", 18); ok {
+		t.Errorf("unexpected address for line after the last line of code")
+	}
+	if _, _, ok := lst.Address("defs.h", 1); ok {
+		t.Errorf("unexpected address for file that generates no data")
+	}
+}
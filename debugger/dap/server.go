@@ -0,0 +1,878 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package dap
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/debugger/terminal"
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/logger"
+)
+
+// the debugger commands issued by the server in response to requests from the
+// client
+const (
+	cmdContinue = "QUANTUM CPU; RUN"
+	cmdStep     = "STEP CPU"
+	cmdHalt     = "HALT"
+	cmdQuit     = "QUIT"
+)
+
+// there is only one thread of execution
+const threadID = 1
+
+// the variables references for the scopes
+const (
+	varsRegisters = iota + 1
+	varsRAM
+)
+
+// the range of addresses shown in the RAM scope
+const (
+	ramOrigin = 0x80
+	ramMemtop = 0xff
+)
+
+// opcode for the JSR instruction. used when stepping over subroutines
+const opcodeJSR = 0x20
+
+// the page the 6507 stack is in
+const stackPage = 0x0100
+
+// client represents a single connection to the server
+type client struct {
+	conn net.Conn
+}
+
+// Server listens for and services connections from a DAP client. Server
+// satisfies the terminal.Input interface and should be used in place of the
+// usual terminal by the debugger.
+type Server struct {
+	target   Target
+	listener net.Listener
+
+	// new connections are accepted in a separate goroutine and sent to the
+	// server over this channel
+	connections chan net.Conn
+
+	// the currently connected client. nil if there is no connection
+	client *client
+
+	// sequence number of the last message sent to the client
+	seq int
+
+	// source mapping. will be nil if no listing file is available
+	listing *Listing
+
+	// breakpoints added to the target by the server. an address can be
+	// requested by more than one source line, by an instruction breakpoint
+	// and by the temporary breakpoint. the value is the number of requests
+	breakpoints map[uint16]int
+
+	// the source lines requested by the client, keyed by source path, and the
+	// addresses that the lines resolved to
+	sourceRequests    map[string][]int
+	sourceBreakpoints map[string][]uint16
+
+	// addresses of instruction breakpoints requested by the client
+	instructionBreakpoints []uint16
+
+	// temporary breakpoint used when stepping over or out of subroutines
+	tempBreak   bool
+	tempAddress uint16
+
+	// launch sequence. the emulation is started when the client has sent
+	// both the launch (or attach) request and the configurationDone request
+	launched    bool
+	configured  bool
+	stopOnEntry bool
+
+	// debugger command to be returned by the next call to TermRead()
+	command string
+
+	// the emulation has been resumed on behalf of the client. the client
+	// should be sent a stopped event when the emulation halts
+	resumed    bool
+	stopReason string
+}
+
+// NewServer is the preferred method of initialisation for the Server type.
+// The address is in the form expected by net.Listen(), for example
+// "localhost:2600".
+func NewServer(address string, target Target) (*Server, error) {
+	srv := &Server{
+		target:      target,
+		connections: make(chan net.Conn),
+	}
+	srv.resetBreakpoints()
+
+	var err error
+
+	srv.listener, err = net.Listen("tcp", address)
+	if err != nil {
+		return nil, errors.New(errors.DAP, err)
+	}
+
+	go func() {
+		for {
+			conn, err := srv.listener.Accept()
+			if err != nil {
+				close(srv.connections)
+				return
+			}
+			srv.connections <- conn
+		}
+	}()
+
+	logger.Log("dap", fmt.Sprintf("listening on %s", srv.listener.Addr()))
+
+	return srv, nil
+}
+
+func (srv *Server) resetBreakpoints() {
+	srv.breakpoints = make(map[uint16]int)
+	srv.sourceRequests = make(map[string][]int)
+	srv.sourceBreakpoints = make(map[string][]uint16)
+	srv.instructionBreakpoints = nil
+	srv.tempBreak = false
+}
+
+// Addr returns the network address the server is listening on
+func (srv *Server) Addr() net.Addr {
+	return srv.listener.Addr()
+}
+
+// Close the connection to any client and stop listening for new connections
+func (srv *Server) Close() {
+	if srv.client != nil {
+		srv.sendEvent("terminated", nil)
+		srv.disconnect(srv.client)
+	}
+	_ = srv.listener.Close()
+	for conn := range srv.connections {
+		_ = conn.Close()
+	}
+}
+
+// end the connection with the client and remove any breakpoints the client
+// added. if the emulation is running on behalf of the client then it is
+// halted
+func (srv *Server) disconnect(cl *client) {
+	if cl != srv.client {
+		return
+	}
+
+	for address := range srv.breakpoints {
+		err := srv.target.RemoveBreakpoint(address)
+		if err != nil {
+			logger.Log("dap", err.Error())
+		}
+	}
+	srv.resetBreakpoints()
+
+	_ = srv.client.conn.Close()
+	srv.client = nil
+	srv.launched = false
+	srv.configured = false
+
+	if srv.resumed && srv.command == "" {
+		srv.command = cmdHalt
+	}
+	srv.resumed = false
+
+	logger.Log("dap", "client disconnected")
+}
+
+// read requests from the client. requests are serviced in the debugger's
+// goroutine by sending them over the rawEvents channel
+func (srv *Server) read(cl *client, rawEvents chan func()) {
+	r := bufio.NewReader(cl.conn)
+	for {
+		req, err := readMessage(r)
+		if err != nil {
+			rawEvents <- func() {
+				srv.disconnect(cl)
+			}
+			return
+		}
+		rawEvents <- func() {
+			srv.service(cl, req)
+		}
+	}
+}
+
+// TermRead implements the terminal.Input interface. It returns only when the
+// client has requested that the emulation should continue in some way. In the
+// meantime, requests from the client are serviced through the Target
+// interface.
+func (srv *Server) TermRead(buffer []byte, _ terminal.Prompt, events *terminal.ReadEvents) (int, error) {
+	if srv.command != "" {
+		return srv.issueCommand(buffer), nil
+	}
+
+	// the emulation has halted so the client needs a stopped event
+	if srv.resumed {
+		srv.resumed = false
+
+		if srv.tempBreak {
+			if srv.stopReason == "step" && srv.pc() != srv.tempAddress {
+				srv.stopReason = "breakpoint"
+			}
+			srv.removeBreakpoint(srv.tempAddress)
+			srv.tempBreak = false
+		}
+
+		srv.sendEvent("stopped", stoppedBody{
+			Reason:            srv.stopReason,
+			ThreadID:          threadID,
+			AllThreadsStopped: true,
+		})
+	}
+
+	for {
+		select {
+		case conn, ok := <-srv.connections:
+			if !ok {
+				return 0, errors.New(errors.DAP, "server is no longer listening")
+			}
+
+			// only one client at a time
+			if srv.client != nil {
+				logger.Log("dap", fmt.Sprintf("refusing connection from %s", conn.RemoteAddr()))
+				_ = conn.Close()
+				break // select
+			}
+
+			srv.client = &client{conn: conn}
+			srv.seq = 0
+			go srv.read(srv.client, events.RawEvents)
+			logger.Log("dap", fmt.Sprintf("connection from %s", conn.RemoteAddr()))
+
+		case ev := <-events.RawEvents:
+			ev()
+
+		case ev := <-events.GuiEvents:
+			err := events.GuiEventHandler(ev)
+			if err != nil {
+				return 0, err
+			}
+
+		case <-events.IntEvents:
+			return 0, errors.New(errors.UserInterrupt)
+		}
+
+		if srv.command != "" {
+			return srv.issueCommand(buffer), nil
+		}
+	}
+}
+
+// TermReadCheck implements the terminal.Input interface. It returns true if a
+// request from the client requires the emulation to halt.
+func (srv *Server) TermReadCheck() bool {
+	return srv.command != ""
+}
+
+// IsInteractive implements the terminal.Input interface
+func (srv *Server) IsInteractive() bool {
+	return false
+}
+
+// copy pending command to buffer in the way expected of a TermRead()
+// implementation
+func (srv *Server) issueCommand(buffer []byte) int {
+	n := copy(buffer, srv.command+"\n")
+	srv.command = ""
+	return n
+}
+
+// resume the emulation with the debugger command. the reason is used in the
+// stopped event when the emulation next halts
+func (srv *Server) resume(cmd string, reason string) {
+	srv.command = cmd
+	srv.resumed = true
+	srv.stopReason = reason
+}
+
+func (srv *Server) send(msg interface{}) {
+	if srv.client == nil {
+		return
+	}
+	err := writeMessage(srv.client.conn, msg)
+	if err != nil {
+		logger.Log("dap", err.Error())
+	}
+}
+
+func (srv *Server) sendEvent(name string, body interface{}) {
+	srv.seq++
+	srv.send(event{
+		message: message{Seq: srv.seq, Type: "event"},
+		Event:   name,
+		Body:    body,
+	})
+}
+
+func (srv *Server) sendResponse(req *request, body interface{}) {
+	srv.seq++
+	srv.send(response{
+		message:    message{Seq: srv.seq, Type: "response"},
+		RequestSeq: req.Seq,
+		Success:    true,
+		Command:    req.Command,
+		Body:       body,
+	})
+}
+
+func (srv *Server) sendError(req *request, err error) {
+	srv.seq++
+	srv.send(response{
+		message:    message{Seq: srv.seq, Type: "response"},
+		RequestSeq: req.Seq,
+		Success:    false,
+		Command:    req.Command,
+		Message:    err.Error(),
+	})
+}
+
+// add breakpoint to target if it has not been added already
+func (srv *Server) addBreakpoint(address uint16) {
+	if srv.breakpoints[address] == 0 {
+		err := srv.target.AddBreakpoint(address)
+		if err != nil {
+			logger.Log("dap", err.Error())
+			return
+		}
+	}
+	srv.breakpoints[address]++
+}
+
+// remove breakpoint from target if it is no longer required
+func (srv *Server) removeBreakpoint(address uint16) {
+	if srv.breakpoints[address] == 0 {
+		return
+	}
+	srv.breakpoints[address]--
+	if srv.breakpoints[address] == 0 {
+		delete(srv.breakpoints, address)
+		err := srv.target.RemoveBreakpoint(address)
+		if err != nil {
+			logger.Log("dap", err.Error())
+		}
+	}
+}
+
+// the current value of the program counter
+func (srv *Server) pc() uint16 {
+	for _, r := range srv.target.GetRegisters() {
+		if r.Name == "PC" {
+			return r.Value
+		}
+	}
+	return 0
+}
+
+// the current value of the stack pointer
+func (srv *Server) sp() uint16 {
+	for _, r := range srv.target.GetRegisters() {
+		if r.Name == "SP" {
+			return r.Value
+		}
+	}
+	return 0
+}
+
+// service a single request from the client
+func (srv *Server) service(cl *client, req *request) {
+	// the request is from a client that has since been disconnected
+	if cl != srv.client {
+		return
+	}
+
+	if req.Type != "request" {
+		return
+	}
+
+	err := srv.serviceRequest(req)
+	if err != nil {
+		srv.sendError(req, err)
+	}
+}
+
+// service the request and send the response. returns an error if an error
+// response should be sent instead
+func (srv *Server) serviceRequest(req *request) error {
+	switch req.Command {
+	case "initialize":
+		srv.sendResponse(req, capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsSetVariable:              true,
+			SupportsReadMemoryRequest:        true,
+			SupportsWriteMemoryRequest:       true,
+			SupportsInstructionBreakpoints:   true,
+			SupportsEvaluateForHovers:        true,
+			SupportTerminateDebuggee:         true,
+		})
+		srv.sendEvent("initialized", nil)
+
+	case "launch", "attach":
+		var args launchArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		if req.Command == "launch" && args.Program != "" {
+			err := srv.target.LoadCartridge(args.Program)
+			if err != nil {
+				return err
+			}
+		}
+
+		listing := args.Listing
+		if listing == "" {
+			listing = listingFilename(srv.target.CartridgeFilename())
+		}
+		srv.loadListing(listing)
+
+		srv.stopOnEntry = args.StopOnEntry
+		srv.launched = true
+		srv.sendResponse(req, nil)
+		srv.start()
+
+	case "configurationDone":
+		srv.configured = true
+		srv.sendResponse(req, nil)
+		srv.start()
+
+	case "setBreakpoints":
+		var args setBreakpointsArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		path := args.Source.Path
+		if path == "" {
+			path = args.Source.Name
+		}
+
+		lines := make([]int, len(args.Breakpoints))
+		for i := range args.Breakpoints {
+			lines[i] = args.Breakpoints[i].Line
+		}
+
+		srv.sourceRequests[path] = lines
+		srv.sendResponse(req, breakpointsBody{Breakpoints: srv.applySourceBreakpoints(path)})
+
+	case "setInstructionBreakpoints":
+		var args setInstructionBreakpointsArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		for _, a := range srv.instructionBreakpoints {
+			srv.removeBreakpoint(a)
+		}
+		srv.instructionBreakpoints = srv.instructionBreakpoints[:0]
+
+		bps := make([]breakpoint, 0, len(args.Breakpoints))
+		for _, b := range args.Breakpoints {
+			a, err := parseAddress(b.InstructionReference)
+			if err != nil {
+				bps = append(bps, breakpoint{Verified: false, Message: err.Error()})
+				continue // for loop
+			}
+			a += uint16(b.Offset)
+			srv.addBreakpoint(a)
+			srv.instructionBreakpoints = append(srv.instructionBreakpoints, a)
+			bps = append(bps, breakpoint{Verified: true, InstructionReference: formatAddress(a)})
+		}
+
+		srv.sendResponse(req, breakpointsBody{Breakpoints: bps})
+
+	case "setExceptionBreakpoints":
+		srv.sendResponse(req, breakpointsBody{Breakpoints: []breakpoint{}})
+
+	case "threads":
+		srv.sendResponse(req, threadsBody{Threads: []thread{{ID: threadID, Name: "6507"}}})
+
+	case "stackTrace":
+		pc := srv.pc()
+		frm := stackFrame{
+			Name:                        fmt.Sprintf("$%04x", pc),
+			InstructionPointerReference: formatAddress(pc),
+		}
+		if srv.listing != nil {
+			if sl, ok := srv.listing.Source(pc); ok {
+				frm.Source = &source{Name: filepath.Base(sl.File), Path: srv.listing.Path(sl.File)}
+				frm.Line = sl.Line
+				frm.Column = 1
+			}
+		}
+		srv.sendResponse(req, stackTraceBody{StackFrames: []stackFrame{frm}, TotalFrames: 1})
+
+	case "scopes":
+		srv.sendResponse(req, scopesBody{Scopes: []scope{
+			{Name: "Registers", VariablesReference: varsRegisters},
+			{Name: "RAM", VariablesReference: varsRAM},
+		}})
+
+	case "variables":
+		var args variablesArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		vars := make([]variable, 0)
+
+		switch args.VariablesReference {
+		case varsRegisters:
+			for _, r := range srv.target.GetRegisters() {
+				vars = append(vars, variable{Name: r.Name, Value: formatRegister(r)})
+			}
+		case varsRAM:
+			for a := uint16(ramOrigin); a <= ramMemtop; a++ {
+				d, err := srv.target.ReadMemory(a)
+				if err != nil {
+					return err
+				}
+				vars = append(vars, variable{
+					Name:            fmt.Sprintf("$%02x", a),
+					Value:           fmt.Sprintf("$%02x", d),
+					MemoryReference: formatAddress(a),
+				})
+			}
+		}
+
+		srv.sendResponse(req, variablesBody{Variables: vars})
+
+	case "setVariable":
+		var args setVariableArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		v, err := parseValue(args.Value)
+		if err != nil {
+			return err
+		}
+
+		switch args.VariablesReference {
+		case varsRegisters:
+			err = srv.target.SetRegister(args.Name, v)
+			if err != nil {
+				return err
+			}
+			for _, r := range srv.target.GetRegisters() {
+				if r.Name == args.Name {
+					srv.sendResponse(req, setVariableBody{Value: formatRegister(r)})
+				}
+			}
+		case varsRAM:
+			a, err := parseValue(args.Name)
+			if err != nil {
+				return err
+			}
+			err = srv.target.WriteMemory(a, uint8(v))
+			if err != nil {
+				return err
+			}
+			srv.sendResponse(req, setVariableBody{Value: fmt.Sprintf("$%02x", uint8(v))})
+		default:
+			return fmt.Errorf("unknown variables reference (%d)", args.VariablesReference)
+		}
+
+	case "readMemory":
+		var args readMemoryArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		a, err := parseAddress(args.MemoryReference)
+		if err != nil {
+			return err
+		}
+		a += uint16(args.Offset)
+
+		data := make([]byte, 0, args.Count)
+		for i := 0; i < args.Count; i++ {
+			d, err := srv.target.ReadMemory(a + uint16(i))
+			if err != nil {
+				break // for loop
+			}
+			data = append(data, d)
+		}
+
+		srv.sendResponse(req, readMemoryBody{
+			Address:         formatAddress(a),
+			Data:            base64.StdEncoding.EncodeToString(data),
+			UnreadableBytes: args.Count - len(data),
+		})
+
+	case "writeMemory":
+		var args writeMemoryArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		a, err := parseAddress(args.MemoryReference)
+		if err != nil {
+			return err
+		}
+		a += uint16(args.Offset)
+
+		data, err := base64.StdEncoding.DecodeString(args.Data)
+		if err != nil {
+			return err
+		}
+
+		for i := range data {
+			err = srv.target.WriteMemory(a+uint16(i), data[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		srv.sendResponse(req, writeMemoryBody{BytesWritten: len(data)})
+
+	case "evaluate":
+		var args evaluateArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		// expressions in watch and hover contexts are treated as addresses
+		cmd := args.Expression
+		if args.Context == "watch" || args.Context == "hover" {
+			cmd = fmt.Sprintf("PEEK %s", cmd)
+		}
+
+		out, err := srv.target.Command(cmd)
+		if err != nil {
+			if errors.Is(err, errors.UserQuit) {
+				srv.command = cmdQuit
+			}
+			return err
+		}
+
+		srv.sendResponse(req, evaluateBody{Result: strings.TrimRight(out, "\n")})
+
+	case "continue":
+		srv.sendResponse(req, continueBody{AllThreadsContinued: true})
+		srv.resume(cmdContinue, "breakpoint")
+
+	case "next":
+		srv.sendResponse(req, nil)
+
+		// step over subroutines by running until the instruction after the
+		// JSR instruction
+		pc := srv.pc()
+		if op, err := srv.target.ReadMemory(pc); err == nil && op == opcodeJSR {
+			srv.setTempBreak(pc + 3)
+			srv.resume(cmdContinue, "step")
+		} else {
+			srv.resume(cmdStep, "step")
+		}
+
+	case "stepIn":
+		srv.sendResponse(req, nil)
+		srv.resume(cmdStep, "step")
+
+	case "stepOut":
+		srv.sendResponse(req, nil)
+
+		// run until the return address on the stack. the return address
+		// pushed by the JSR instruction is the address of the last byte of
+		// the JSR instruction
+		sp := srv.sp()
+		lo, errLo := srv.target.ReadMemory(stackPage | ((sp + 1) & 0xff))
+		hi, errHi := srv.target.ReadMemory(stackPage | ((sp + 2) & 0xff))
+		if errLo == nil && errHi == nil {
+			srv.setTempBreak((uint16(hi)<<8 | uint16(lo)) + 1)
+			srv.resume(cmdContinue, "step")
+		} else {
+			srv.resume(cmdStep, "step")
+		}
+
+	case "pause":
+		srv.sendResponse(req, nil)
+		if srv.resumed {
+			srv.command = cmdHalt
+			srv.stopReason = "pause"
+		} else {
+			srv.sendEvent("stopped", stoppedBody{Reason: "pause", ThreadID: threadID, AllThreadsStopped: true})
+		}
+
+	case "disconnect", "terminate":
+		var args disconnectArguments
+		if err := unmarshalArguments(req, &args); err != nil {
+			return err
+		}
+
+		srv.sendResponse(req, nil)
+		srv.disconnect(srv.client)
+
+		if args.TerminateDebuggee || req.Command == "terminate" {
+			srv.command = cmdQuit
+		}
+
+	default:
+		return fmt.Errorf("unsupported request (%s)", req.Command)
+	}
+
+	return nil
+}
+
+// start the emulation once the launch sequence has completed
+func (srv *Server) start() {
+	if !srv.launched || !srv.configured {
+		return
+	}
+
+	if srv.stopOnEntry {
+		srv.sendEvent("stopped", stoppedBody{Reason: "entry", ThreadID: threadID, AllThreadsStopped: true})
+	} else {
+		srv.resume(cmdContinue, "breakpoint")
+	}
+}
+
+func (srv *Server) setTempBreak(address uint16) {
+	srv.tempBreak = true
+	srv.tempAddress = address
+	srv.addBreakpoint(address)
+}
+
+// load listing file. failure to load the listing is not fatal but source
+// breakpoints will not be available
+func (srv *Server) loadListing(filename string) {
+	lst, err := ReadListing(filename)
+	if err != nil {
+		logger.Log("dap", err.Error())
+		return
+	}
+	srv.listing = lst
+
+	// source breakpoints requested before the listing was loaded need to be
+	// resolved again
+	for path := range srv.sourceRequests {
+		_ = srv.applySourceBreakpoints(path)
+	}
+}
+
+// resolve the requested source lines for path into addresses and add the
+// breakpoints to the target. previous breakpoints for the path are removed
+func (srv *Server) applySourceBreakpoints(path string) []breakpoint {
+	for _, a := range srv.sourceBreakpoints[path] {
+		srv.removeBreakpoint(a)
+	}
+	srv.sourceBreakpoints[path] = srv.sourceBreakpoints[path][:0]
+
+	lines := srv.sourceRequests[path]
+	bps := make([]breakpoint, 0, len(lines))
+
+	for _, l := range lines {
+		if srv.listing == nil {
+			bps = append(bps, breakpoint{Verified: false, Line: l, Message: "no listing file"})
+			continue // for loop
+		}
+
+		a, line, ok := srv.listing.Address(path, l)
+		if !ok {
+			bps = append(bps, breakpoint{Verified: false, Line: l, Message: "no code at this line"})
+			continue // for loop
+		}
+
+		srv.addBreakpoint(a)
+		srv.sourceBreakpoints[path] = append(srv.sourceBreakpoints[path], a)
+		bps = append(bps, breakpoint{Verified: true, Line: line, InstructionReference: formatAddress(a)})
+	}
+
+	return bps
+}
+
+// the name of the listing file for the cartridge file. the listing file is
+// expected to be alongside the cartridge file and share the same name, with
+// an extension of ".lst". returns the empty string if no file can be found
+func listingFilename(cartridge string) string {
+	if cartridge == "" {
+		return ""
+	}
+
+	base := strings.TrimSuffix(cartridge, filepath.Ext(cartridge))
+	for _, ext := range []string{".lst", ".LST"} {
+		fn := base + ext
+		if _, err := os.Stat(fn); err == nil {
+			return fn
+		}
+	}
+
+	return ""
+}
+
+func unmarshalArguments(req *request, args interface{}) error {
+	if len(req.Arguments) == 0 {
+		return nil
+	}
+	return json.Unmarshal(req.Arguments, args)
+}
+
+func formatAddress(a uint16) string {
+	return fmt.Sprintf("0x%04x", a)
+}
+
+func formatRegister(r Register) string {
+	var s string
+	if r.Bits > 8 {
+		s = fmt.Sprintf("$%04x", r.Value)
+	} else {
+		s = fmt.Sprintf("$%02x", r.Value)
+	}
+	if r.Detail != "" {
+		s = fmt.Sprintf("%s (%s)", s, r.Detail)
+	}
+	return s
+}
+
+// parse a value entered by the user. hexadecimal values can be prefixed with
+// either $ or 0x
+func parseValue(s string) (uint16, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "$") {
+		s = "0x" + s[1:]
+	}
+	v, err := strconv.ParseUint(s, 0, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value (%s)", s)
+	}
+	return uint16(v), nil
+}
+
+// parse a memory or instruction reference. references are numeric addresses
+func parseAddress(s string) (uint16, error) {
+	v, err := parseValue(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid reference (%s)", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,465 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package dap_test
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jetsetilly/gopher2600/debugger/dap"
+	"github.com/jetsetilly/gopher2600/debugger/terminal"
+	"github.com/jetsetilly/gopher2600/gui"
+)
+
+type mockTarget struct {
+	regs        map[string]uint16
+	mem         [0x2000]uint8
+	breakpoints map[uint16]bool
+	commands    []string
+}
+
+func (tgt *mockTarget) GetRegisters() []dap.Register {
+	r := make([]dap.Register, 0)
+	for _, n := range []string{"PC", "A", "X", "Y", "SP", "P"} {
+		bits := 8
+		if n == "PC" {
+			bits = 16
+		}
+		r = append(r, dap.Register{Name: n, Value: tgt.regs[n], Bits: bits})
+	}
+	return r
+}
+
+func (tgt *mockTarget) SetRegister(name string, value uint16) error {
+	if _, ok := tgt.regs[name]; !ok {
+		return fmt.Errorf("unknown register")
+	}
+	tgt.regs[name] = value
+	return nil
+}
+
+// the cartridge is mirrored at $f000. all other addresses above $2000 are
+// unreadable
+func (tgt *mockTarget) ReadMemory(address uint16) (uint8, error) {
+	if address >= 0xf000 {
+		address &= 0x1fff
+	}
+	if int(address) >= len(tgt.mem) {
+		return 0, fmt.Errorf("unreadable address")
+	}
+	return tgt.mem[address], nil
+}
+
+func (tgt *mockTarget) WriteMemory(address uint16, data uint8) error {
+	if address >= 0xf000 {
+		address &= 0x1fff
+	}
+	if int(address) >= len(tgt.mem) {
+		return fmt.Errorf("unwritable address")
+	}
+	tgt.mem[address] = data
+	return nil
+}
+
+func (tgt *mockTarget) AddBreakpoint(address uint16) error {
+	tgt.breakpoints[address] = true
+	return nil
+}
+
+func (tgt *mockTarget) RemoveBreakpoint(address uint16) error {
+	delete(tgt.breakpoints, address)
+	return nil
+}
+
+func (tgt *mockTarget) Command(cmd string) (string, error) {
+	tgt.commands = append(tgt.commands, cmd)
+	return fmt.Sprintf("ran %s\n", cmd), nil
+}
+
+func (tgt *mockTarget) CartridgeFilename() string {
+	return ""
+}
+
+func (tgt *mockTarget) LoadCartridge(filename string) error {
+	return fmt.Errorf("cannot load cartridges")
+}
+
+// received is a message received by the mock client
+type received struct {
+	Seq     int             `json:"seq"`
+	Type    string          `json:"type"`
+	Command string          `json:"command"`
+	Event   string          `json:"event"`
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Body    json.RawMessage `json:"body"`
+}
+
+// mockClient is a minimal DAP client
+type mockClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+	seq  int
+
+	// events received while waiting for a response
+	events []received
+}
+
+func (cl *mockClient) send(command string, args interface{}) {
+	cl.seq++
+	b, err := json.Marshal(map[string]interface{}{
+		"seq":       cl.seq,
+		"type":      "request",
+		"command":   command,
+		"arguments": args,
+	})
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+	_, err = cl.conn.Write([]byte(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(b), b)))
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+}
+
+func (cl *mockClient) receive() received {
+	_ = cl.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	hdr, err := textproto.NewReader(cl.r).ReadMIMEHeader()
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+	n, err := strconv.Atoi(hdr.Get("Content-Length"))
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(cl.r, b)
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+	var msg received
+	err = json.Unmarshal(b, &msg)
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+	return msg
+}
+
+// send request and wait for the response. the response body is unmarshalled
+// into body if it is not nil
+func (cl *mockClient) request(command string, args interface{}, body interface{}) received {
+	cl.t.Helper()
+	cl.send(command, args)
+	for {
+		msg := cl.receive()
+		if msg.Type == "event" {
+			cl.events = append(cl.events, msg)
+			continue // for loop
+		}
+		if msg.Command != command {
+			cl.t.Fatalf("%s: unexpected response (%s)", command, msg.Command)
+		}
+		if !msg.Success {
+			cl.t.Errorf("%s: unexpected failure (%s)", command, msg.Message)
+		}
+		if body != nil {
+			if err := json.Unmarshal(msg.Body, body); err != nil {
+				cl.t.Fatal(err)
+			}
+		}
+		return msg
+	}
+}
+
+// wait for the named event, ignoring any responses. the event body is
+// unmarshalled into body if it is not nil
+func (cl *mockClient) event(name string, body interface{}) {
+	cl.t.Helper()
+	var msg received
+	if len(cl.events) > 0 {
+		msg = cl.events[0]
+		cl.events = cl.events[1:]
+	} else {
+		msg = cl.receive()
+		for msg.Type == "response" {
+			msg = cl.receive()
+		}
+	}
+	if msg.Type != "event" || msg.Event != name {
+		cl.t.Fatalf("unexpected message (%s %s%s) should be event (%s)", msg.Type, msg.Command, msg.Event, name)
+	}
+	if body != nil {
+		if err := json.Unmarshal(msg.Body, body); err != nil {
+			cl.t.Fatal(err)
+		}
+	}
+}
+
+type readResult struct {
+	input string
+	err   error
+}
+
+type stopped struct {
+	Reason string `json:"reason"`
+}
+
+type breakpoints struct {
+	Breakpoints []struct {
+		Verified bool `json:"verified"`
+		Line     int  `json:"line"`
+	} `json:"breakpoints"`
+}
+
+func TestServer(t *testing.T) {
+	tgt := &mockTarget{
+		regs:        map[string]uint16{"PC": 0xf007, "A": 0x01, "X": 0x02, "Y": 0x03, "SP": 0xfd, "P": 0x24},
+		breakpoints: make(map[uint16]bool),
+	}
+	tgt.mem[0x80] = 0xaa
+	tgt.mem[0x81] = 0xbb
+
+	// JSR instruction at $f007
+	tgt.mem[0x1007] = 0x20
+
+	srv, err := dap.NewServer("localhost:0", tgt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	events := &terminal.ReadEvents{
+		GuiEvents:       make(chan gui.Event),
+		GuiEventHandler: func(gui.Event) error { return nil },
+		IntEvents:       make(chan os.Signal),
+		RawEvents:       make(chan func(), 1024),
+	}
+
+	// TermRead() is called in a goroutine in the same way as the debugger
+	// would call it
+	read := func() chan readResult {
+		ch := make(chan readResult, 1)
+		go func() {
+			buffer := make([]byte, 255)
+			n, err := srv.TermRead(buffer, terminal.Prompt{}, events)
+			ch <- readResult{input: string(buffer[:n]), err: err}
+		}()
+		return ch
+	}
+
+	waitFor := func(ch chan readResult, input string) {
+		t.Helper()
+		select {
+		case r := <-ch:
+			if r.err != nil {
+				t.Fatal(r.err)
+			}
+			if r.input != input {
+				t.Errorf("unexpected input (%q) should be (%q)", r.input, input)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for input (%q)", input)
+		}
+	}
+
+	checkBreakpoints := func(addresses ...uint16) {
+		t.Helper()
+		if len(tgt.breakpoints) != len(addresses) {
+			t.Errorf("unexpected breakpoints (%v)", tgt.breakpoints)
+			return
+		}
+		for _, a := range addresses {
+			if !tgt.breakpoints[a] {
+				t.Errorf("missing breakpoint (%#04x)", a)
+			}
+		}
+	}
+
+	result := read()
+
+	conn, err := net.Dial("tcp", srv.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cl := &mockClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	// launch sequence
+	cl.request("initialize", map[string]interface{}{"adapterID": "gopher2600"}, nil)
+	cl.event("initialized", nil)
+	cl.request("launch", map[string]interface{}{
+		"listing":     filepath.Join("testdata", "test.lst"),
+		"stopOnEntry": true,
+	}, nil)
+
+	// source breakpoints
+	var bps breakpoints
+	cl.request("setBreakpoints", map[string]interface{}{
+		"source":      map[string]interface{}{"path": filepath.Join("testdata", "test.asm")},
+		"breakpoints": []map[string]interface{}{{"line": 6}, {"line": 11}, {"line": 13}, {"line": 30}},
+	}, &bps)
+	if len(bps.Breakpoints) != 4 {
+		t.Fatalf("unexpected number of breakpoints (%d)", len(bps.Breakpoints))
+	}
+	for i, l := range []int{7, 12, 13} {
+		if !bps.Breakpoints[i].Verified || bps.Breakpoints[i].Line != l {
+			t.Errorf("unexpected breakpoint (%v) should be at line (%d)", bps.Breakpoints[i], l)
+		}
+	}
+	if bps.Breakpoints[3].Verified {
+		t.Errorf("breakpoint without code should not be verified")
+	}
+
+	cl.request("setInstructionBreakpoints", map[string]interface{}{
+		"breakpoints": []map[string]interface{}{{"instructionReference": "0xf005"}},
+	}, nil)
+
+	var stop stopped
+	cl.request("configurationDone", nil, nil)
+	cl.event("stopped", &stop)
+	if stop.Reason != "entry" {
+		t.Errorf("unexpected stop reason (%s) should be (entry)", stop.Reason)
+	}
+
+	// stack and source location
+	var stack struct {
+		StackFrames []struct {
+			Line   int `json:"line"`
+			Source struct {
+				Path string `json:"path"`
+			} `json:"source"`
+		} `json:"stackFrames"`
+	}
+	cl.request("stackTrace", map[string]interface{}{"threadId": 1}, &stack)
+	if len(stack.StackFrames) != 1 || stack.StackFrames[0].Line != 13 ||
+		stack.StackFrames[0].Source.Path != filepath.Join("testdata", "test.asm") {
+		t.Errorf("unexpected stack trace (%v)", stack)
+	}
+
+	// registers and RAM
+	var vars struct {
+		Variables []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"variables"`
+	}
+	cl.request("variables", map[string]interface{}{"variablesReference": 1}, &vars)
+	if len(vars.Variables) != 6 || vars.Variables[0].Value != "$f007" || vars.Variables[1].Value != "$01" {
+		t.Errorf("unexpected registers (%v)", vars.Variables)
+	}
+	cl.request("setVariable", map[string]interface{}{"variablesReference": 1, "name": "A", "value": "$7f"}, nil)
+	cl.request("variables", map[string]interface{}{"variablesReference": 2}, &vars)
+	if len(vars.Variables) != 128 || vars.Variables[1].Name != "$81" || vars.Variables[1].Value != "$bb" {
+		t.Errorf("unexpected RAM (%v)", vars.Variables)
+	}
+	cl.request("setVariable", map[string]interface{}{"variablesReference": 2, "name": "$81", "value": "0x10"}, nil)
+
+	// memory
+	var mem struct {
+		Data            string `json:"data"`
+		UnreadableBytes int    `json:"unreadableBytes"`
+	}
+	cl.request("writeMemory", map[string]interface{}{
+		"memoryReference": "0x0082",
+		"data":            base64.StdEncoding.EncodeToString([]byte{0x01, 0x02}),
+	}, nil)
+	cl.request("readMemory", map[string]interface{}{"memoryReference": "0x0080", "count": 4}, &mem)
+	if mem.Data != base64.StdEncoding.EncodeToString([]byte{0xaa, 0x10, 0x01, 0x02}) {
+		t.Errorf("unexpected memory data (%s)", mem.Data)
+	}
+	cl.request("readMemory", map[string]interface{}{"memoryReference": "0x1fff", "count": 4}, &mem)
+	if mem.UnreadableBytes != 3 {
+		t.Errorf("unexpected number of unreadable bytes (%d)", mem.UnreadableBytes)
+	}
+
+	// debugger commands
+	var eval struct {
+		Result string `json:"result"`
+	}
+	cl.request("evaluate", map[string]interface{}{"expression": "CPU", "context": "repl"}, &eval)
+	if eval.Result != "ran CPU" {
+		t.Errorf("unexpected evaluate result (%s)", eval.Result)
+	}
+	cl.request("evaluate", map[string]interface{}{"expression": "$80", "context": "watch"}, &eval)
+	if eval.Result != "ran PEEK $80" {
+		t.Errorf("unexpected evaluate result (%s)", eval.Result)
+	}
+
+	// step over JSR instruction
+	cl.send("next", map[string]interface{}{"threadId": 1})
+	waitFor(result, "QUANTUM CPU; RUN\n")
+	checkBreakpoints(0xf000, 0xf005, 0xf007, 0xf00a)
+	if tgt.regs["A"] != 0x7f {
+		t.Errorf("register not set")
+	}
+	tgt.regs["PC"] = 0xf00a
+	result = read()
+	cl.event("stopped", &stop)
+	if stop.Reason != "step" {
+		t.Errorf("unexpected stop reason (%s) should be (step)", stop.Reason)
+	}
+
+	// single step
+	cl.send("stepIn", map[string]interface{}{"threadId": 1})
+	waitFor(result, "STEP CPU\n")
+	checkBreakpoints(0xf000, 0xf005, 0xf007)
+	result = read()
+	cl.event("stopped", &stop)
+
+	// continue and pause. requests are serviced through the RawEvents channel
+	// while the emulation is running
+	cl.send("continue", map[string]interface{}{"threadId": 1})
+	waitFor(result, "QUANTUM CPU; RUN\n")
+	cl.send("pause", map[string]interface{}{"threadId": 1})
+	timeout := time.After(5 * time.Second)
+	for !srv.TermReadCheck() {
+		select {
+		case ev := <-events.RawEvents:
+			ev()
+		case <-timeout:
+			t.Fatalf("timeout waiting for pause")
+		}
+	}
+	result = read()
+	waitFor(result, "HALT\n")
+	result = read()
+	cl.event("stopped", &stop)
+	if stop.Reason != "pause" {
+		t.Errorf("unexpected stop reason (%s) should be (pause)", stop.Reason)
+	}
+
+	// removing source breakpoints leaves the instruction breakpoint
+	cl.request("setBreakpoints", map[string]interface{}{
+		"source":      map[string]interface{}{"path": filepath.Join("testdata", "test.asm")},
+		"breakpoints": []map[string]interface{}{},
+	}, nil)
+
+	// disconnecting removes all breakpoints
+	cl.send("disconnect", map[string]interface{}{"terminateDebuggee": true})
+	waitFor(result, "QUIT\n")
+	checkBreakpoints()
+}
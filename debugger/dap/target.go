@@ -0,0 +1,60 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package dap
+
+// Register describes a single CPU register
+type Register struct {
+	Name  string
+	Value uint16
+
+	// the width of the register in bits
+	Bits int
+
+	// additional information about the value. for example, the individual
+	// flags of the status register
+	Detail string
+}
+
+// Target defines the operations required by the server in order to service
+// requests from a client. All Target functions are called from the goroutine
+// that called Server.TermRead(), ie. the debugger's goroutine.
+type Target interface {
+	// the current register values, in the order they should be presented
+	GetRegisters() []Register
+
+	// change the named register value
+	SetRegister(name string, value uint16) error
+
+	// read/write a single byte of memory. the address is a CPU address
+	ReadMemory(address uint16) (uint8, error)
+	WriteMemory(address uint16, data uint8) error
+
+	// add or remove a breakpoint for the CPU address. adding a breakpoint
+	// that already exists, or removing a breakpoint that does not exist,
+	// should not be considered to be an error
+	AddBreakpoint(address uint16) error
+	RemoveBreakpoint(address uint16) error
+
+	// run a debugger command and return the output. commands that would
+	// continue the emulation should not be run
+	Command(cmd string) (string, error)
+
+	// the filename of the currently attached cartridge
+	CartridgeFilename() string
+
+	// attach a new cartridge
+	LoadCartridge(filename string) error
+}
@@ -32,9 +32,11 @@ import (
 	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/banks"
 	"github.com/jetsetilly/gopher2600/logger"
 	"github.com/jetsetilly/gopher2600/reflection"
+	"github.com/jetsetilly/gopher2600/rewind"
 	"github.com/jetsetilly/gopher2600/setup"
 	"github.com/jetsetilly/gopher2600/symbols"
 	"github.com/jetsetilly/gopher2600/television"
+	"github.com/jetsetilly/gopher2600/videocapture"
 )
 
 const defaultOnHalt = "CPU; TV"
@@ -59,6 +61,10 @@ type Debugger struct {
 	scr  gui.GUI
 	term terminal.Terminal
 
+	// if remote is not nil then input is taken from the remote server (GDB
+	// or DAP) rather than the terminal
+	remote remoteInput
+
 	// interface to the vcs memory with additional debugging functions
 	// - access to vcs memory from the debugger (eg. peeking and poking) is
 	// most fruitfully performed through this structure
@@ -69,6 +75,10 @@ type Debugger struct {
 	// required
 	reflect *reflection.Monitor
 
+	// screenshots are saved with the screenshot renderer if the GUI does not
+	// support the ReqScreenshot request
+	screenshot *gui.ScreenshotRenderer
+
 	// frame limiter
 	lmtr *limiter
 
@@ -82,6 +92,17 @@ type Debugger struct {
 	// things like "STEP FRAME".
 	stepTraps *traps
 
+	// history of emulation states, allowing the emulation to be wound
+	// backwards
+	rewind *rewind.Rewind
+
+	// video capture started with the CAPTURE command. created on first use
+	capture *videocapture.Capture
+
+	// log of every executed instruction started with the TRACELOG command or
+	// StartTraceLog(). nil if there is no log in progress
+	tracelog *traceLog
+
 	// commandOnHalt is the sequence of commands that runs when emulation
 	// halts
 	commandOnHalt       []*commandline.Tokens
@@ -158,6 +179,11 @@ func NewDebugger(tv television.Television, scr gui.GUI, term terminal.Terminal)
 		return nil, errors.New(errors.DebuggerError, err)
 	}
 
+	dbg.rewind, err = rewind.NewRewind(dbg.VCS, rewind.DefaultLength, rewind.DefaultFrequency)
+	if err != nil {
+		return nil, errors.New(errors.DebuggerError, err)
+	}
+
 	// create a new disassembly instance
 	dbg.Disasm, err = disassembly.NewDisassembly()
 	if err != nil {
@@ -177,9 +203,16 @@ func NewDebugger(tv television.Television, scr gui.GUI, term terminal.Terminal)
 		return err
 	})
 
-	// setup reflection monitor
+	// set up screenshot renderer
+	dbg.screenshot = gui.NewScreenshotRenderer(tv)
+
+	// setup reflection monitor. if the GUI doesn't supply a reflection
+	// renderer then use the screenshot renderer so that screenshots can be
+	// saved using debug colors
 	if b, ok := scr.(reflection.Broker); ok {
 		dbg.reflect = reflection.NewMonitor(dbg.VCS, b.GetReflectionRenderer())
+	} else {
+		dbg.reflect = reflection.NewMonitor(dbg.VCS, dbg.screenshot)
 	}
 
 	// set up breakpoints/traps
@@ -265,9 +298,24 @@ func (dbg *Debugger) Start(initScript string, cartload cartridgeloader.Loader) e
 		}
 	}()
 
+	// make sure the trace log is flushed to disk
+	defer func() {
+		if err := dbg.stopTraceLog(); err != nil {
+			dbg.printLine(terminal.StyleError, "%s", err)
+		}
+	}()
+
+	// input for the main loop is from the terminal unless a remote server has
+	// been started
+	var inputter terminal.Input = dbg.term
+	if dbg.remote != nil {
+		inputter = dbg.remote
+		defer dbg.remote.Close()
+	}
+
 	// prepare and run main input loop. inputLoop will not return until
 	// debugging session is to be terminated
-	err = dbg.inputLoop(dbg.term, false)
+	err = dbg.inputLoop(inputter, false)
 	if err != nil {
 		return errors.New(errors.DebuggerError, err)
 	}
@@ -308,6 +356,29 @@ func (dbg *Debugger) loadCartridge(cartload cartridgeloader.Loader) error {
 	// repoint debug memory's symbol table
 	dbg.dbgmem.symtable = dbg.Disasm.Symtable
 
+	// rewind history is meaningless with the new cartridge
+	dbg.rewind.Reset()
+
+	return nil
+}
+
+// resyncLastResult should be called when the state of the emulation has been
+// changed outside of the normal stepping process. for example, when a
+// snapshot has been restored
+func (dbg *Debugger) resyncLastResult() error {
+	var err error
+
+	dbg.lastBank = dbg.VCS.Mem.Cart.GetBank(dbg.VCS.CPU.LastResult.Address)
+	dbg.lastResult, err = dbg.Disasm.FormatResult(dbg.lastBank, dbg.VCS.CPU.LastResult, disassembly.EntryLevelExecuted)
+	if err != nil {
+		return errors.New(errors.DebuggerError, err)
+	}
+
+	// the instruction log is no longer continuous
+	if dbg.tracelog != nil {
+		return dbg.tracelog.discontinuity()
+	}
+
 	return nil
 }
 
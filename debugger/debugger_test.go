@@ -45,6 +45,9 @@ func (t *mockTV) AddPixelRenderer(_ television.PixelRenderer) {
 func (t *mockTV) AddAudioMixer(_ television.AudioMixer) {
 }
 
+func (t *mockTV) AddFrameTrigger(_ television.FrameTrigger) {
+}
+
 func (t *mockTV) Signal(_ television.SignalAttributes) error {
 	return nil
 }
@@ -61,6 +64,14 @@ func (t *mockTV) GetSpec() (*television.Specification, bool) {
 	return television.SpecNTSC, false
 }
 
+func (t *mockTV) SetResizer(_ television.FrameResizeID) error {
+	return nil
+}
+
+func (t *mockTV) SetFixedBounds(_ int, _ int) error {
+	return nil
+}
+
 func (t *mockTV) IsStable() bool {
 	return true
 }
@@ -91,6 +102,14 @@ func (t *mockTV) GetLastSignal() television.SignalAttributes {
 	return television.SignalAttributes{}
 }
 
+func (t *mockTV) Snapshot() *television.State {
+	return &television.State{}
+}
+
+func (t *mockTV) Restore(_ *television.State) error {
+	return nil
+}
+
 func (g *mockGUI) Destroy(_ io.Writer) {
 }
 
@@ -212,6 +231,7 @@ func (trm *mockTerm) testSequence() {
 	trm.testBreakpoints()
 	trm.testTraps()
 	trm.testWatches()
+	trm.testTV()
 }
 
 func TestDebugger_withNonExistantInitScript(t *testing.T) {
@@ -245,3 +265,26 @@ func TestDebugger(t *testing.T) {
 		t.Fatalf(err.Error())
 	}
 }
+
+func TestListenAddress(t *testing.T) {
+	dbg, err := debugger.NewDebugger(&mockTV{}, &mockGUI{}, newMockTerm(t))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// addresses that would accept connections from other machines are
+	// refused unless remote connections have been allowed
+	for _, address := range []string{"0.0.0.0:2600", "192.168.0.1:2600", "[::]:2600", "example.com:2600"} {
+		if err := dbg.ListenGDB(address, false); err == nil {
+			t.Errorf("expected error for non-loopback address (%s)", address)
+		}
+		if err := dbg.ListenDAP(address, false); err == nil {
+			t.Errorf("expected error for non-loopback address (%s)", address)
+		}
+	}
+
+	// malformed address
+	if err := dbg.ListenGDB("localhost:2600:1", false); err == nil {
+		t.Errorf("expected error for malformed address")
+	}
+}
@@ -0,0 +1,48 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package expression implements the expression language used by the
+// debugger for conditional breakpoints and traps.
+//
+// Expressions are made up of numbers, identifiers, memory dereferences and
+// operators. Numbers can be decimal or hexadecimal. Hexadecimal numbers are
+// prefixed with either $ or 0x. Binary numbers are prefixed with 0b.
+//
+// Identifiers are resolved through the Environment interface. In the
+// debugger, identifiers are the names of debugger targets (eg. PC, SL) or
+// symbols from the cartridge's symbol table. Symbols resolve to their
+// address.
+//
+// A memory dereference is an expression in square brackets. The value of the
+// dereference is the value of memory at the address given by the expression.
+// For example:
+//
+//	[$80] & $0f == 3
+//
+// Operators, in order of precedence from highest to lowest, are:
+//
+//	unary:          -  !  ~  NOT
+//	multiplicative: *  /  %  <<  >>  &
+//	additive:       +  -  |  ^
+//	comparison:     ==  !=  <  <=  >  >=
+//	logical and:    &&  AND
+//	logical or:     ||  OR
+//
+// Note that, unlike C, the bitwise operators bind more tightly than the
+// comparison operators. The = operator is accepted as an alternative to ==.
+//
+// All values are integers. Comparison and logical operators result in 1 for
+// true and 0 for false. For logical operators, any non-zero value is true.
+package expression
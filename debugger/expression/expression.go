@@ -0,0 +1,412 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// Environment is used to resolve the identifiers and memory dereferences in
+// an expression
+type Environment interface {
+	// Identifier resolves the named identifier. The name will be in upper
+	// case. The returned function is called every time the expression is
+	// evaluated.
+	Identifier(name string) (func() (int, error), error)
+
+	// Peek returns the value in memory at the address
+	Peek(address int) (int, error)
+}
+
+// Expression is a parsed expression, ready for evaluation
+type Expression struct {
+	root node
+	env  Environment
+}
+
+// Parse the input string. Identifiers are resolved immediately and so an
+// error will be returned for unknown identifiers.
+func Parse(input string, env Environment) (*Expression, error) {
+	toks, err := lex(input)
+	if err != nil {
+		return nil, errors.New(errors.ExpressionError, err)
+	}
+
+	p := &parser{toks: toks, env: env}
+
+	if p.peek().typ == tokEnd {
+		return nil, errors.New(errors.ExpressionError, "empty expression")
+	}
+
+	root, err := p.parseBinary(1)
+	if err != nil {
+		return nil, errors.New(errors.ExpressionError, err)
+	}
+
+	if t := p.peek(); t.typ != tokEnd {
+		return nil, errors.New(errors.ExpressionError, fmt.Sprintf("unexpected %s", t.val))
+	}
+
+	return &Expression{root: root, env: env}, nil
+}
+
+// String returns a normalised representation of the expression. Expressions
+// that differ only in spacing, case or redundant brackets will have the same
+// string representation.
+func (ex Expression) String() string {
+	return ex.root.String()
+}
+
+// Evaluate the expression with the current state of the environment
+func (ex Expression) Evaluate() (int, error) {
+	v, err := ex.root.eval(ex.env)
+	if err != nil {
+		return 0, errors.New(errors.ExpressionError, err)
+	}
+	return v, nil
+}
+
+// binary operators and their precedence
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4, "|": 4, "^": 4,
+	"*": 5, "/": 5, "%": 5, "<<": 5, ">>": 5, "&": 5,
+}
+
+// the precedence of unary operators and operands
+const precPrimary = 6
+
+type parser struct {
+	toks []token
+	curr int
+	env  Environment
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.curr]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.curr]
+	if t.typ != tokEnd {
+		p.curr++
+	}
+	return t
+}
+
+// parse binary operators of at least the specified precedence
+func (p *parser) parseBinary(minPrec int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.typ != tokOperator {
+			break // for loop
+		}
+
+		prec, ok := precedence[t.val]
+		if !ok || prec < minPrec {
+			break // for loop
+		}
+		p.next()
+
+		right, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binary{op: t.val, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	if t.typ == tokOperator && (t.val == "-" || t.val == "!" || t.val == "~") {
+		p.next()
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{op: t.val, arg: arg}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.typ {
+	case tokEnd:
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	case tokNumber:
+		return &number{val: t.val, num: t.num}, nil
+
+	case tokIdentifier:
+		value, err := p.env.Identifier(t.val)
+		if err != nil {
+			return nil, err
+		}
+		return &identifier{name: t.val, value: value}, nil
+
+	case tokOperator:
+		switch t.val {
+		case "(":
+			n, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if p.next().val != ")" {
+				return nil, fmt.Errorf("missing )")
+			}
+			return n, nil
+
+		case "[":
+			n, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if p.next().val != "]" {
+				return nil, fmt.Errorf("missing ]")
+			}
+			return &dereference{address: n}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %s", t.val)
+}
+
+type node interface {
+	String() string
+	eval(env Environment) (int, error)
+	prec() int
+}
+
+type number struct {
+	val string
+	num int
+}
+
+// numbers are normalised to the base they were specified in. hexadecimal
+// numbers always use the $ prefix
+func (n number) String() string {
+	v := strings.ToLower(n.val)
+	switch {
+	case strings.HasPrefix(v, "$"), strings.HasPrefix(v, "0x"):
+		return fmt.Sprintf("$%02x", n.num)
+	case strings.HasPrefix(v, "0b"):
+		return fmt.Sprintf("0b%b", n.num)
+	}
+	return fmt.Sprintf("%d", n.num)
+}
+
+func (n number) eval(_ Environment) (int, error) {
+	return n.num, nil
+}
+
+func (n number) prec() int {
+	return precPrimary
+}
+
+type identifier struct {
+	name  string
+	value func() (int, error)
+}
+
+func (n identifier) String() string {
+	return n.name
+}
+
+func (n identifier) eval(_ Environment) (int, error) {
+	return n.value()
+}
+
+func (n identifier) prec() int {
+	return precPrimary
+}
+
+type dereference struct {
+	address node
+}
+
+func (n dereference) String() string {
+	return fmt.Sprintf("[%s]", n.address)
+}
+
+func (n dereference) eval(env Environment) (int, error) {
+	a, err := n.address.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	return env.Peek(a)
+}
+
+func (n dereference) prec() int {
+	return precPrimary
+}
+
+type unary struct {
+	op  string
+	arg node
+}
+
+func (n unary) String() string {
+	if n.arg.prec() < precPrimary {
+		return fmt.Sprintf("%s(%s)", n.op, n.arg)
+	}
+	return fmt.Sprintf("%s%s", n.op, n.arg)
+}
+
+func (n unary) eval(env Environment) (int, error) {
+	v, err := n.arg.eval(env)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "-":
+		return -v, nil
+	case "!":
+		return boolToInt(v == 0), nil
+	case "~":
+		return ^v, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator (%s)", n.op)
+}
+
+func (n unary) prec() int {
+	return precPrimary
+}
+
+type binary struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n binary) String() string {
+	l := n.left.String()
+	if n.left.prec() < n.prec() {
+		l = fmt.Sprintf("(%s)", l)
+	}
+
+	// operators are left associative so the right operand needs brackets if
+	// it is of the same precedence
+	r := n.right.String()
+	if n.right.prec() <= n.prec() {
+		r = fmt.Sprintf("(%s)", r)
+	}
+
+	return fmt.Sprintf("%s %s %s", l, n.op, r)
+}
+
+func (n binary) prec() int {
+	return precedence[n.op]
+}
+
+func (n binary) eval(env Environment) (int, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return 0, err
+	}
+
+	// logical operators short-circuit
+	switch n.op {
+	case "&&":
+		if l == 0 {
+			return 0, nil
+		}
+	case "||":
+		if l != 0 {
+			return 1, nil
+		}
+	}
+
+	r, err := n.right.eval(env)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "||", "&&":
+		return boolToInt(r != 0), nil
+	case "==":
+		return boolToInt(l == r), nil
+	case "!=":
+		return boolToInt(l != r), nil
+	case "<":
+		return boolToInt(l < r), nil
+	case "<=":
+		return boolToInt(l <= r), nil
+	case ">":
+		return boolToInt(l > r), nil
+	case ">=":
+		return boolToInt(l >= r), nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "|":
+		return l | r, nil
+	case "^":
+		return l ^ r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l % r, nil
+	case "<<":
+		if r < 0 {
+			return 0, fmt.Errorf("negative shift")
+		}
+		return l << uint(r), nil
+	case ">>":
+		if r < 0 {
+			return 0, fmt.Errorf("negative shift")
+		}
+		return l >> uint(r), nil
+	case "&":
+		return l & r, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator (%s)", n.op)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package expression_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/debugger/expression"
+)
+
+type mockEnv struct {
+	values map[string]int
+	mem    [0x100]int
+}
+
+func (env *mockEnv) Identifier(name string) (func() (int, error), error) {
+	if _, ok := env.values[name]; !ok {
+		return nil, fmt.Errorf("unknown identifier (%s)", name)
+	}
+	return func() (int, error) {
+		return env.values[name], nil
+	}, nil
+}
+
+func (env *mockEnv) Peek(address int) (int, error) {
+	if address < 0 || address >= len(env.mem) {
+		return 0, fmt.Errorf("unpeekable address")
+	}
+	return env.mem[address], nil
+}
+
+func TestExpression(t *testing.T) {
+	env := &mockEnv{values: map[string]int{
+		"SL":     201,
+		"X":      255,
+		"P0.POS": 40,
+	}}
+	env.mem[0x80] = 0x43
+	env.mem[0x81] = 0x41
+
+	for _, tst := range []struct {
+		input  string
+		result int
+		norm   string
+	}{
+		{"1 + 2 * 3", 7, "1 + 2 * 3"},
+		{"(1 + 2) * 3", 9, "(1 + 2) * 3"},
+		{"10 - 4 - 3", 3, "10 - 4 - 3"},
+		{"10 - (4 - 3)", 9, "10 - (4 - 3)"},
+		{"$10 + 0x10 + 0b011 + 007", 42, "$10 + $10 + 0b11 + 7"},
+		{"[$80] & $0F == 3", 1, "[$80] & $0f == 3"},
+		{"[0x80]&0x0F!=3", 0, "[$80] & $0f != 3"},
+		{"[$81] > $40 && sl > 200", 1, "[$81] > $40 && SL > 200"},
+		{"[$81] > $40 AND SL > 220", 0, "[$81] > $40 && SL > 220"},
+		{"X < 10 OR SL >= 201", 1, "X < 10 || SL >= 201"},
+		{"NOT (X = 255)", 0, "!(X == 255)"},
+		{"!X", 0, "!X"},
+		{"~0 & $FF", 255, "~0 & $ff"},
+		{"-X + 256", 1, "-X + 256"},
+		{"[[$7f] + $80]", 0x43, "[[$7f] + $80]"},
+		{"$1234", 0x1234, "$1234"},
+		{"1 << 4 | 1", 17, "1 << 4 | 1"},
+		{"p0.pos % 16", 8, "P0.POS % 16"},
+	} {
+		ex, err := expression.Parse(tst.input, env)
+		if err != nil {
+			t.Errorf("%s: %v", tst.input, err)
+			continue // for loop
+		}
+		v, err := ex.Evaluate()
+		if err != nil {
+			t.Errorf("%s: %v", tst.input, err)
+			continue // for loop
+		}
+		if v != tst.result {
+			t.Errorf("%s: unexpected result (%d) should be (%d)", tst.input, v, tst.result)
+		}
+		if ex.String() != tst.norm {
+			t.Errorf("%s: unexpected normalisation (%s) should be (%s)", tst.input, ex.String(), tst.norm)
+		}
+	}
+
+	// parse errors
+	for _, input := range []string{
+		"", "1 +", "(1 + 2", "[$80", "1 2", "FOO", "$", "0xZZ", "1 # 2", ")",
+	} {
+		if _, err := expression.Parse(input, env); err == nil {
+			t.Errorf("%s: expected parse error", input)
+		}
+	}
+
+	// evaluation errors
+	for _, input := range []string{
+		"1 / 0", "1 % (X - 255)", "[$100]", "1 << -1",
+	} {
+		ex, err := expression.Parse(input, env)
+		if err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue // for loop
+		}
+		if _, err := ex.Evaluate(); err == nil {
+			t.Errorf("%s: expected evaluation error", input)
+		}
+	}
+
+	// identifiers are evaluated every time the expression is evaluated
+	ex, err := expression.Parse("SL > 200", env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.values["SL"] = 10
+	if v, _ := ex.Evaluate(); v != 0 {
+		t.Errorf("identifier value not updated")
+	}
+}
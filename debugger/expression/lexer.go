@@ -0,0 +1,157 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	tokEnd tokenType = iota
+	tokNumber
+	tokIdentifier
+	tokOperator
+)
+
+type token struct {
+	typ tokenType
+	val string
+
+	// the value of a tokNumber
+	num int
+}
+
+// operators in order of length. the lexer must try the longest operators
+// first
+var operators = []string{
+	"||", "&&", "==", "!=", "<=", ">=", "<<", ">>",
+	"<", ">", "=", "+", "-", "*", "/", "%", "&", "|", "^", "!", "~",
+	"(", ")", "[", "]",
+}
+
+// word operators and the symbolic operator they are equivalent to. word
+// operators are case insensitive
+var wordOperators = map[string]string{
+	"AND": "&&",
+	"OR":  "||",
+	"NOT": "!",
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentifierChar(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9') || c == '.'
+}
+
+func isNumberChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// divide input into tokens. the last token is always of type tokEnd
+func lex(input string) ([]token, error) {
+	toks := make([]token, 0)
+
+	i := 0
+	for i < len(input) {
+		c := input[i]
+
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == '$' || (c >= '0' && c <= '9'):
+			start := i
+			i++
+			for i < len(input) && isNumberChar(input[i]) {
+				i++
+			}
+
+			s := input[start:i]
+			n, err := parseNumber(s)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{typ: tokNumber, val: s, num: n})
+
+		case isIdentifierStart(c):
+			start := i
+			for i < len(input) && isIdentifierChar(input[i]) {
+				i++
+			}
+
+			s := strings.ToUpper(input[start:i])
+			if op, ok := wordOperators[s]; ok {
+				toks = append(toks, token{typ: tokOperator, val: op})
+			} else {
+				toks = append(toks, token{typ: tokIdentifier, val: s})
+			}
+
+		default:
+			found := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					if op == "=" {
+						op = "=="
+						i++
+					} else {
+						i += len(op)
+					}
+					toks = append(toks, token{typ: tokOperator, val: op})
+					found = true
+					break // for loop
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("unexpected character (%c)", c)
+			}
+		}
+	}
+
+	toks = append(toks, token{typ: tokEnd})
+
+	return toks, nil
+}
+
+// parse a number with an optional base prefix
+func parseNumber(s string) (int, error) {
+	var n uint64
+	var err error
+
+	ls := strings.ToLower(s)
+	switch {
+	case strings.HasPrefix(ls, "$"):
+		n, err = strconv.ParseUint(ls[1:], 16, 32)
+	case strings.HasPrefix(ls, "0x"):
+		n, err = strconv.ParseUint(ls[2:], 16, 32)
+	case strings.HasPrefix(ls, "0b"):
+		n, err = strconv.ParseUint(ls[2:], 2, 32)
+	default:
+		n, err = strconv.ParseUint(ls, 10, 32)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid number (%s)", s)
+	}
+
+	return int(n), nil
+}
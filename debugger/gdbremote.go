@@ -0,0 +1,92 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/debugger/gdbremote"
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// ListenGDB starts a GDB remote server on the address. Once started, the
+// debugger will take its input from the remote client rather than from the
+// terminal. Output is still sent to the terminal.
+//
+// The server listens on localhost if the address has no host. Addresses that
+// are not loopback addresses are refused unless allowRemote is true.
+//
+// Must be called before Start().
+func (dbg *Debugger) ListenGDB(address string, allowRemote bool) error {
+	address, err := remoteAddress(address, allowRemote)
+	if err != nil {
+		return err
+	}
+
+	dbg.remote, err = gdbremote.NewServer(address, &gdbTarget{remoteTarget{dbg: dbg}})
+	if err != nil {
+		return errors.New(errors.DebuggerError, err)
+	}
+	return nil
+}
+
+// gdbTarget implements the gdbremote.Target interface
+type gdbTarget struct {
+	remoteTarget
+}
+
+// GetRegisters implements the gdbremote.Target interface
+func (tgt *gdbTarget) GetRegisters() gdbremote.Registers {
+	cpu := tgt.dbg.VCS.CPU
+	return gdbremote.Registers{
+		A:  cpu.A.Value(),
+		X:  cpu.X.Value(),
+		Y:  cpu.Y.Value(),
+		SP: cpu.SP.Value(),
+		P:  cpu.Status.Value(),
+		PC: cpu.PC.Value(),
+	}
+}
+
+// SetRegisters implements the gdbremote.Target interface
+func (tgt *gdbTarget) SetRegisters(r gdbremote.Registers) {
+	cpu := tgt.dbg.VCS.CPU
+	cpu.A.Load(r.A)
+	cpu.X.Load(r.X)
+	cpu.Y.Load(r.Y)
+	cpu.SP.Load(r.SP)
+	cpu.Status.FromValue(r.P)
+	cpu.PC.Load(r.PC)
+}
+
+// GetQuantum implements the gdbremote.Target interface
+func (tgt *gdbTarget) GetQuantum() string {
+	return tgt.dbg.quantum.String()
+}
+
+// SetQuantum implements the gdbremote.Target interface
+func (tgt *gdbTarget) SetQuantum(quantum string) error {
+	switch strings.ToUpper(quantum) {
+	case "CPU":
+		tgt.dbg.quantum = QuantumCPU
+	case "VIDEO":
+		tgt.dbg.quantum = QuantumVideo
+	default:
+		return errors.New(errors.DebuggerError, fmt.Sprintf("unrecognised quantum (%s)", quantum))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package gdbremote implements a server for the GDB Remote Serial Protocol.
+// It allows front-ends that understand the protocol (GDB itself and the many
+// editor integrations built on it) to control the debugger over a local TCP
+// connection.
+//
+// The Server type satisfies the terminal.Input interface. The debugger reads
+// from the server instead of the terminal and so the server is able to drive
+// the debugger in the same way as a user would. Requests from the remote
+// client to continue or to step the emulation are converted into debugger
+// commands, namely RUN and STEP CPU. Requests to interrupt a running emulation
+// are converted into the HALT command. All other requests are serviced
+// through the Target interface.
+//
+// The STEP CPU command, and the QUANTUM CPU command that precedes RUN, change
+// the debugger's quantum. The quantum in effect before the emulation was
+// resumed is restored once the emulation has halted.
+//
+// The 6507 is not an architecture that GDB knows about so the register layout
+// is described in a target description, sent to the client on request. The
+// registers are, in order: A, X, Y, SP, P (the status register) and the 16 bit
+// PC.
+//
+// Only one client can be connected at once. When a client detaches (or the
+// connection is lost) any breakpoints it added are removed and the server
+// waits for a new connection.
+package gdbremote
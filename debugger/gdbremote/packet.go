@@ -0,0 +1,161 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package gdbremote
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// the interrupt character sent by the client when the emulation is running
+const interruptChar = 0x03
+
+// client represents a single connection to the server. packets are read from
+// the connection in their own goroutine and passed to the server over the
+// packets channel
+type client struct {
+	conn net.Conn
+
+	// decoded packets (without framing or checksum)
+	packets chan string
+
+	// the client has sent an interrupt character
+	interrupt chan bool
+
+	// closed when the connection is being closed by the server
+	done chan bool
+}
+
+func newClient(conn net.Conn) *client {
+	cl := &client{
+		conn:      conn,
+		packets:   make(chan string),
+		interrupt: make(chan bool, 1),
+		done:      make(chan bool),
+	}
+	go cl.read()
+	return cl
+}
+
+// close connection and wait for read goroutine to end
+func (cl *client) close() {
+	close(cl.done)
+	_ = cl.conn.Close()
+	for range cl.packets {
+	}
+}
+
+// read packets from the connection until the connection is closed. the
+// packets channel is closed on return
+func (cl *client) read() {
+	defer close(cl.packets)
+
+	// acknowledgments are sent for every packet until the client requests
+	// otherwise
+	noAck := false
+
+	r := bufio.NewReader(cl.conn)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+
+		switch b {
+		case interruptChar:
+			select {
+			case cl.interrupt <- true:
+			default:
+			}
+
+		case '$':
+			data, err := r.ReadString('#')
+			if err != nil {
+				return
+			}
+			data = data[:len(data)-1]
+
+			chk := make([]byte, 2)
+			for i := range chk {
+				chk[i], err = r.ReadByte()
+				if err != nil {
+					return
+				}
+			}
+
+			v, err := strconv.ParseUint(string(chk), 16, 8)
+			if err != nil || uint8(v) != checksum(data) {
+				if !noAck {
+					_, _ = cl.conn.Write([]byte("-"))
+				}
+				continue // for loop
+			}
+
+			if !noAck {
+				_, _ = cl.conn.Write([]byte("+"))
+			}
+
+			// the acknowledgement for the QStartNoAckMode packet is the last
+			// acknowledgement to be sent
+			if data == "QStartNoAckMode" {
+				noAck = true
+			}
+
+			select {
+			case cl.packets <- data:
+			case <-cl.done:
+				return
+			}
+
+		default:
+			// acknowledgments from the client are ignored. we never resend
+			// packets
+		}
+	}
+}
+
+// send data to the client as a packet. binary data should be escaped with the
+// escape() function before sending
+func (cl *client) send(data string) error {
+	_, err := cl.conn.Write([]byte(fmt.Sprintf("$%s#%02x", data, checksum(data))))
+	return err
+}
+
+// the checksum is the sum of every character in the packet data, modulo 256
+func checksum(data string) uint8 {
+	var c uint8
+	for i := 0; i < len(data); i++ {
+		c += data[i]
+	}
+	return c
+}
+
+// characters with special meaning in the protocol must be escaped when sending
+// binary data
+func escape(data string) string {
+	s := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '#', '$', '}', '*':
+			s = append(s, '}', data[i]^0x20)
+		default:
+			s = append(s, data[i])
+		}
+	}
+	return string(s)
+}
@@ -0,0 +1,614 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package gdbremote
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/debugger/terminal"
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/logger"
+)
+
+// the debugger commands issued by the server in response to requests from the
+// client
+const (
+	cmdContinue = "QUANTUM CPU; RUN"
+	cmdStep     = "STEP CPU"
+	cmdHalt     = "HALT"
+)
+
+// the signal numbers used in stop replies
+const (
+	sigInt  = 2
+	sigTrap = 5
+)
+
+// the maximum size of packet the client should send
+const packetSize = 4096
+
+// description of the register layout. the 6507 is not an architecture known
+// to GDB and so no architecture element is specified
+const targetXML = `<?xml version="1.0"?>
+<!DOCTYPE target SYSTEM "gdb-target.dtd">
+<target version="1.0">
+  <feature name="org.gopher2600.6507">
+    <reg name="a" bitsize="8" regnum="0" type="uint8"/>
+    <reg name="x" bitsize="8" type="uint8"/>
+    <reg name="y" bitsize="8" type="uint8"/>
+    <reg name="sp" bitsize="8" type="uint8"/>
+    <reg name="p" bitsize="8" type="uint8"/>
+    <reg name="pc" bitsize="16" type="code_ptr"/>
+  </feature>
+</target>
+`
+
+// Server listens for and services connections from a GDB remote client.
+// Server satisfies the terminal.Input interface and should be used in place
+// of the usual terminal by the debugger.
+type Server struct {
+	target   Target
+	listener net.Listener
+
+	// new connections are accepted in a separate goroutine and sent to the
+	// server over this channel
+	connections chan net.Conn
+
+	// the currently connected client. nil if there is no connection
+	client *client
+
+	// breakpoints added by the client. these are removed when the client
+	// disconnects
+	breakpoints map[uint16]bool
+
+	// the emulation has been resumed by the client and the client is waiting
+	// for a stop reply
+	resumed bool
+
+	// the quantum of the emulation before it was resumed by the client. it
+	// is restored once the emulation halts
+	quantum string
+
+	// the HALT command has been issued in response to an interrupt from the
+	// client
+	halting bool
+}
+
+// NewServer is the preferred method of initialisation for the Server type.
+// The address is in the form expected by net.Listen(), for example
+// "localhost:2600".
+func NewServer(address string, target Target) (*Server, error) {
+	srv := &Server{
+		target:      target,
+		connections: make(chan net.Conn),
+		breakpoints: make(map[uint16]bool),
+	}
+
+	var err error
+
+	srv.listener, err = net.Listen("tcp", address)
+	if err != nil {
+		return nil, errors.New(errors.GDBRemote, err)
+	}
+
+	go func() {
+		for {
+			conn, err := srv.listener.Accept()
+			if err != nil {
+				close(srv.connections)
+				return
+			}
+			srv.connections <- conn
+		}
+	}()
+
+	logger.Log("gdb remote", fmt.Sprintf("listening on %s", srv.listener.Addr()))
+
+	return srv, nil
+}
+
+// Addr returns the network address the server is listening on
+func (srv *Server) Addr() net.Addr {
+	return srv.listener.Addr()
+}
+
+// Close the connection to any client and stop listening for new connections
+func (srv *Server) Close() {
+	srv.disconnect()
+	_ = srv.listener.Close()
+	for conn := range srv.connections {
+		_ = conn.Close()
+	}
+}
+
+// end the connection with the current client (if any) and remove any
+// breakpoints the client added
+func (srv *Server) disconnect() {
+	if srv.client == nil {
+		return
+	}
+
+	for address := range srv.breakpoints {
+		err := srv.target.RemoveBreakpoint(address)
+		if err != nil {
+			logger.Log("gdb remote", err.Error())
+		}
+	}
+	srv.breakpoints = make(map[uint16]bool)
+
+	if srv.resumed {
+		srv.restoreQuantum()
+	}
+
+	srv.client.close()
+	srv.client = nil
+	srv.resumed = false
+	srv.halting = false
+
+	logger.Log("gdb remote", "client disconnected")
+}
+
+// TermRead implements the terminal.Input interface. It returns only when the
+// client has requested that the emulation should continue in some way. In the
+// meantime, requests from the client are serviced through the Target
+// interface.
+func (srv *Server) TermRead(buffer []byte, _ terminal.Prompt, events *terminal.ReadEvents) (int, error) {
+	if srv.resumed && srv.client != nil {
+		// an interrupt from the client is acted upon by returning the HALT
+		// command. the stop reply will be sent on the next call to TermRead()
+		if !srv.halting {
+			select {
+			case <-srv.client.interrupt:
+				srv.halting = true
+				return command(buffer, cmdHalt), nil
+			default:
+			}
+		}
+
+		// the emulation has halted so the client needs a stop reply
+		sig := sigTrap
+		if srv.halting {
+			sig = sigInt
+		}
+		srv.resumed = false
+		srv.halting = false
+		srv.restoreQuantum()
+		srv.reply(stopReply(sig))
+	}
+
+	for {
+		// the channels for the current client. if there is no client then
+		// these will be nil and the select statement will ignore them
+		var packets chan string
+		var interrupt chan bool
+		if srv.client != nil {
+			packets = srv.client.packets
+			interrupt = srv.client.interrupt
+		}
+
+		select {
+		case conn, ok := <-srv.connections:
+			if !ok {
+				return 0, errors.New(errors.GDBRemote, "server is no longer listening")
+			}
+
+			// only one client at a time
+			if srv.client != nil {
+				logger.Log("gdb remote", fmt.Sprintf("refusing connection from %s", conn.RemoteAddr()))
+				_ = conn.Close()
+				break // select
+			}
+
+			srv.client = newClient(conn)
+			logger.Log("gdb remote", fmt.Sprintf("connection from %s", conn.RemoteAddr()))
+
+		case pkt, ok := <-packets:
+			if !ok {
+				srv.disconnect()
+				break // select
+			}
+
+			cmd, err := srv.service(pkt)
+			if err != nil {
+				return 0, err
+			}
+
+			if cmd != "" {
+				srv.quantum = srv.target.GetQuantum()
+				srv.resumed = true
+				return command(buffer, cmd), nil
+			}
+
+		case <-interrupt:
+			// the emulation is not running so there is nothing to interrupt.
+			// the client is still expecting a stop reply however
+			srv.reply(stopReply(sigInt))
+
+		case ev := <-events.GuiEvents:
+			err := events.GuiEventHandler(ev)
+			if err != nil {
+				return 0, err
+			}
+
+		case ev := <-events.RawEvents:
+			ev()
+
+		case <-events.IntEvents:
+			return 0, errors.New(errors.UserInterrupt)
+		}
+	}
+}
+
+// restore the quantum that was in effect before the emulation was resumed
+func (srv *Server) restoreQuantum() {
+	err := srv.target.SetQuantum(srv.quantum)
+	if err != nil {
+		logger.Log("gdb remote", err.Error())
+	}
+}
+
+// TermReadCheck implements the terminal.Input interface. It returns true if
+// the client has sent an interrupt.
+func (srv *Server) TermReadCheck() bool {
+	return srv.client != nil && len(srv.client.interrupt) > 0
+}
+
+// IsInteractive implements the terminal.Input interface
+func (srv *Server) IsInteractive() bool {
+	return false
+}
+
+// copy command to buffer in the way expected of a TermRead() implementation
+func command(buffer []byte, cmd string) int {
+	n := copy(buffer, cmd+"\n")
+	return n
+}
+
+func stopReply(sig int) string {
+	return fmt.Sprintf("S%02x", sig)
+}
+
+// send reply to client. a failure to send the reply is not fatal, the
+// connection will be closed by the reading goroutine
+func (srv *Server) reply(data string) {
+	err := srv.client.send(data)
+	if err != nil {
+		logger.Log("gdb remote", err.Error())
+	}
+}
+
+// service the packet. returns a debugger command if the emulation should be
+// resumed
+func (srv *Server) service(pkt string) (string, error) {
+	if len(pkt) == 0 {
+		srv.reply("")
+		return "", nil
+	}
+
+	switch pkt[0] {
+	case '?':
+		srv.reply(stopReply(sigTrap))
+
+	case 'g':
+		r := srv.target.GetRegisters()
+		srv.reply(fmt.Sprintf("%02x%02x%02x%02x%02x%02x%02x", r.A, r.X, r.Y, r.SP, r.P, uint8(r.PC), uint8(r.PC>>8)))
+
+	case 'G':
+		b, err := hex.DecodeString(pkt[1:])
+		if err != nil || len(b) < numRegisters+1 {
+			srv.reply("E01")
+			break // switch
+		}
+		srv.target.SetRegisters(Registers{
+			A: b[0], X: b[1], Y: b[2], SP: b[3], P: b[4],
+			PC: uint16(b[5]) | uint16(b[6])<<8,
+		})
+		srv.reply("OK")
+
+	case 'p':
+		n, err := strconv.ParseUint(pkt[1:], 16, 8)
+		if err != nil || n >= numRegisters {
+			srv.reply("E01")
+			break // switch
+		}
+		r := srv.target.GetRegisters()
+		switch n {
+		case 0:
+			srv.reply(fmt.Sprintf("%02x", r.A))
+		case 1:
+			srv.reply(fmt.Sprintf("%02x", r.X))
+		case 2:
+			srv.reply(fmt.Sprintf("%02x", r.Y))
+		case 3:
+			srv.reply(fmt.Sprintf("%02x", r.SP))
+		case 4:
+			srv.reply(fmt.Sprintf("%02x", r.P))
+		case 5:
+			srv.reply(fmt.Sprintf("%02x%02x", uint8(r.PC), uint8(r.PC>>8)))
+		}
+
+	case 'P':
+		f := strings.SplitN(pkt[1:], "=", 2)
+		if len(f) != 2 {
+			srv.reply("E01")
+			break // switch
+		}
+		n, err := strconv.ParseUint(f[0], 16, 8)
+		if err != nil || n >= numRegisters {
+			srv.reply("E01")
+			break // switch
+		}
+		b, err := hex.DecodeString(f[1])
+		if err != nil || len(b) == 0 || (n == 5 && len(b) < 2) {
+			srv.reply("E01")
+			break // switch
+		}
+		r := srv.target.GetRegisters()
+		switch n {
+		case 0:
+			r.A = b[0]
+		case 1:
+			r.X = b[0]
+		case 2:
+			r.Y = b[0]
+		case 3:
+			r.SP = b[0]
+		case 4:
+			r.P = b[0]
+		case 5:
+			r.PC = uint16(b[0]) | uint16(b[1])<<8
+		}
+		srv.target.SetRegisters(r)
+		srv.reply("OK")
+
+	case 'm':
+		address, length, err := parseAddressLength(pkt[1:])
+		if err != nil {
+			srv.reply("E01")
+			break // switch
+		}
+		if length > packetSize/2 {
+			length = packetSize / 2
+		}
+
+		// reply with as much memory as can be read. an error is only returned
+		// if the first byte cannot be read
+		s := strings.Builder{}
+		for i := 0; i < length; i++ {
+			d, err := srv.target.ReadMemory(address + uint16(i))
+			if err != nil {
+				break // for loop
+			}
+			s.WriteString(fmt.Sprintf("%02x", d))
+		}
+		if s.Len() == 0 {
+			srv.reply("E14")
+		} else {
+			srv.reply(s.String())
+		}
+
+	case 'M':
+		f := strings.SplitN(pkt[1:], ":", 2)
+		if len(f) != 2 {
+			srv.reply("E01")
+			break // switch
+		}
+		address, length, err := parseAddressLength(f[0])
+		if err != nil {
+			srv.reply("E01")
+			break // switch
+		}
+		b, err := hex.DecodeString(f[1])
+		if err != nil || len(b) != length {
+			srv.reply("E01")
+			break // switch
+		}
+		for i := range b {
+			err = srv.target.WriteMemory(address+uint16(i), b[i])
+			if err != nil {
+				break // for loop
+			}
+		}
+		if err != nil {
+			srv.reply("E14")
+		} else {
+			srv.reply("OK")
+		}
+
+	case 'c', 's':
+		// optional resume address
+		if len(pkt) > 1 {
+			address, err := strconv.ParseUint(pkt[1:], 16, 16)
+			if err != nil {
+				srv.reply("E01")
+				break // switch
+			}
+			r := srv.target.GetRegisters()
+			r.PC = uint16(address)
+			srv.target.SetRegisters(r)
+		}
+		if pkt[0] == 's' {
+			return cmdStep, nil
+		}
+		return cmdContinue, nil
+
+	case 'v':
+		switch {
+		case pkt == "vCont?":
+			srv.reply("vCont;c;C;s;S")
+		case strings.HasPrefix(pkt, "vCont;"):
+			// there is only one thread so only the first action is
+			// meaningful
+			action := strings.SplitN(pkt[6:], ";", 2)[0]
+			switch {
+			case strings.HasPrefix(action, "c"), strings.HasPrefix(action, "C"):
+				return cmdContinue, nil
+			case strings.HasPrefix(action, "s"), strings.HasPrefix(action, "S"):
+				return cmdStep, nil
+			default:
+				srv.reply("E01")
+			}
+		default:
+			srv.reply("")
+		}
+
+	case 'Z', 'z':
+		// only software and hardware breakpoints are supported. the two are
+		// treated identically
+		if len(pkt) < 2 || (pkt[1] != '0' && pkt[1] != '1') {
+			srv.reply("")
+			break // switch
+		}
+		f := strings.Split(pkt[1:], ",")
+		if len(f) < 2 {
+			srv.reply("E01")
+			break // switch
+		}
+		address, err := strconv.ParseUint(f[1], 16, 16)
+		if err != nil {
+			srv.reply("E01")
+			break // switch
+		}
+		if pkt[0] == 'Z' {
+			err = srv.target.AddBreakpoint(uint16(address))
+			if err == nil {
+				srv.breakpoints[uint16(address)] = true
+			}
+		} else {
+			err = srv.target.RemoveBreakpoint(uint16(address))
+			if err == nil {
+				delete(srv.breakpoints, uint16(address))
+			}
+		}
+		if err != nil {
+			logger.Log("gdb remote", err.Error())
+			srv.reply("E01")
+		} else {
+			srv.reply("OK")
+		}
+
+	case 'q', 'Q':
+		return "", srv.query(pkt)
+
+	case 'H', 'T':
+		// there is only one thread
+		srv.reply("OK")
+
+	case 'D':
+		srv.reply("OK")
+		srv.disconnect()
+
+	case 'k':
+		srv.disconnect()
+		return "", errors.New(errors.UserQuit)
+
+	default:
+		// an empty reply indicates that the request is not supported
+		srv.reply("")
+	}
+
+	return "", nil
+}
+
+// service query packets
+func (srv *Server) query(pkt string) error {
+	switch {
+	case strings.HasPrefix(pkt, "qSupported"):
+		srv.reply(fmt.Sprintf("PacketSize=%x;qXfer:features:read+;QStartNoAckMode+", packetSize))
+
+	case pkt == "QStartNoAckMode":
+		srv.reply("OK")
+
+	case strings.HasPrefix(pkt, "qXfer:features:read:target.xml:"):
+		offset, length, err := parseAddressLength(pkt[len("qXfer:features:read:target.xml:"):])
+		if err != nil {
+			srv.reply("E01")
+			break // switch
+		}
+		if int(offset) >= len(targetXML) {
+			srv.reply("l")
+			break // switch
+		}
+		data := targetXML[offset:]
+		if len(data) > length {
+			srv.reply("m" + escape(data[:length]))
+		} else {
+			srv.reply("l" + escape(data))
+		}
+
+	case pkt == "qAttached":
+		srv.reply("1")
+
+	case pkt == "qC":
+		srv.reply("QC1")
+
+	case pkt == "qfThreadInfo":
+		srv.reply("m1")
+
+	case pkt == "qsThreadInfo":
+		srv.reply("l")
+
+	case strings.HasPrefix(pkt, "qRcmd,"):
+		cmd, err := hex.DecodeString(pkt[len("qRcmd,"):])
+		if err != nil {
+			srv.reply("E01")
+			break // switch
+		}
+
+		out, err := srv.target.Command(string(cmd))
+		if err != nil {
+			if errors.Is(err, errors.UserQuit) {
+				srv.reply("OK")
+				srv.disconnect()
+				return err
+			}
+			out = fmt.Sprintf("%s%s\n", out, err)
+		}
+
+		if out == "" {
+			srv.reply("OK")
+		} else {
+			srv.reply(hex.EncodeToString([]byte(out)))
+		}
+
+	default:
+		srv.reply("")
+	}
+
+	return nil
+}
+
+// parse strings of the form "address,length" where both values are
+// hexadecimal
+func parseAddressLength(s string) (uint16, int, error) {
+	f := strings.SplitN(s, ",", 2)
+	if len(f) != 2 {
+		return 0, 0, fmt.Errorf("expected address,length")
+	}
+	address, err := strconv.ParseUint(f[0], 16, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	length, err := strconv.ParseUint(f[1], 16, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint16(address), int(length), nil
+}
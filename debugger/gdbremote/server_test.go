@@ -0,0 +1,282 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package gdbremote_test
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jetsetilly/gopher2600/debugger/gdbremote"
+	"github.com/jetsetilly/gopher2600/debugger/terminal"
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/gui"
+)
+
+type mockTarget struct {
+	regs        gdbremote.Registers
+	mem         [0x2000]uint8
+	breakpoints map[uint16]bool
+	commands    []string
+	quantum     string
+}
+
+func (tgt *mockTarget) GetRegisters() gdbremote.Registers {
+	return tgt.regs
+}
+
+func (tgt *mockTarget) SetRegisters(r gdbremote.Registers) {
+	tgt.regs = r
+}
+
+func (tgt *mockTarget) ReadMemory(address uint16) (uint8, error) {
+	if int(address) >= len(tgt.mem) {
+		return 0, fmt.Errorf("unreadable address")
+	}
+	return tgt.mem[address], nil
+}
+
+func (tgt *mockTarget) WriteMemory(address uint16, data uint8) error {
+	if int(address) >= len(tgt.mem) {
+		return fmt.Errorf("unwritable address")
+	}
+	tgt.mem[address] = data
+	return nil
+}
+
+func (tgt *mockTarget) AddBreakpoint(address uint16) error {
+	tgt.breakpoints[address] = true
+	return nil
+}
+
+func (tgt *mockTarget) RemoveBreakpoint(address uint16) error {
+	delete(tgt.breakpoints, address)
+	return nil
+}
+
+func (tgt *mockTarget) GetQuantum() string {
+	return tgt.quantum
+}
+
+func (tgt *mockTarget) SetQuantum(quantum string) error {
+	tgt.quantum = quantum
+	return nil
+}
+
+func (tgt *mockTarget) Command(cmd string) (string, error) {
+	tgt.commands = append(tgt.commands, cmd)
+	return fmt.Sprintf("ran %s\n", cmd), nil
+}
+
+// mockClient is a minimal remote protocol client
+type mockClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (cl *mockClient) send(data string) {
+	var c uint8
+	for i := 0; i < len(data); i++ {
+		c += data[i]
+	}
+	_, err := cl.conn.Write([]byte(fmt.Sprintf("$%s#%02x", data, c)))
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+}
+
+// receive the next packet, skipping any acknowledgements
+func (cl *mockClient) receive() string {
+	_ = cl.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		b, err := cl.r.ReadByte()
+		if err != nil {
+			cl.t.Fatal(err)
+		}
+		if b == '$' {
+			break // for loop
+		}
+	}
+	data, err := cl.r.ReadString('#')
+	if err != nil {
+		cl.t.Fatal(err)
+	}
+	_, _ = cl.r.ReadByte()
+	_, _ = cl.r.ReadByte()
+	return data[:len(data)-1]
+}
+
+func (cl *mockClient) expect(request string, reply string) {
+	cl.t.Helper()
+	cl.send(request)
+	if r := cl.receive(); r != reply {
+		cl.t.Errorf("%s: unexpected reply (%s) should be (%s)", request, r, reply)
+	}
+}
+
+type readResult struct {
+	input string
+	err   error
+}
+
+func TestServer(t *testing.T) {
+	tgt := &mockTarget{
+		regs:        gdbremote.Registers{A: 0x01, X: 0x02, Y: 0x03, SP: 0xfd, P: 0x24, PC: 0xf012},
+		breakpoints: make(map[uint16]bool),
+		quantum:     "VIDEO",
+	}
+	tgt.mem[0x80] = 0xaa
+	tgt.mem[0x81] = 0xbb
+
+	srv, err := gdbremote.NewServer("localhost:0", tgt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	events := &terminal.ReadEvents{
+		GuiEvents:       make(chan gui.Event),
+		GuiEventHandler: func(gui.Event) error { return nil },
+		IntEvents:       make(chan os.Signal),
+		RawEvents:       make(chan func()),
+	}
+
+	// TermRead() is called in a goroutine in the same way as the debugger
+	// would call it
+	read := func() chan readResult {
+		ch := make(chan readResult, 1)
+		go func() {
+			buffer := make([]byte, 255)
+			n, err := srv.TermRead(buffer, terminal.Prompt{}, events)
+			ch <- readResult{input: string(buffer[:n]), err: err}
+		}()
+		return ch
+	}
+
+	waitFor := func(ch chan readResult, input string) {
+		t.Helper()
+		select {
+		case r := <-ch:
+			if r.err != nil {
+				t.Fatal(r.err)
+			}
+			if r.input != input {
+				t.Errorf("unexpected input (%q) should be (%q)", r.input, input)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for input (%q)", input)
+		}
+	}
+
+	result := read()
+
+	conn, err := net.Dial("tcp", srv.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := &mockClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	cl.expect("qSupported:multiprocess+", "PacketSize=1000;qXfer:features:read+;QStartNoAckMode+")
+	cl.expect("?", "S05")
+
+	// registers
+	cl.expect("g", "010203fd2412f0")
+	cl.expect("p5", "12f0")
+	cl.expect("P0=7f", "OK")
+	cl.expect("G0a0b0cfe2500f1", "OK")
+	cl.expect("g", "0a0b0cfe2500f1")
+
+	// memory
+	cl.expect("m80,2", "aabb")
+	cl.expect("M80,2:0102", "OK")
+	cl.expect("m80,2", "0102")
+	cl.expect("m1fff,4", "00")
+	cl.expect("m2000,1", "E14")
+
+	// breakpoints
+	cl.expect("Z0,f020,1", "OK")
+	cl.expect("Z0,f030,1", "OK")
+	cl.expect("z0,f030,1", "OK")
+	cl.expect("Z2,80,1", "")
+	if len(tgt.breakpoints) != 1 || !tgt.breakpoints[0xf020] {
+		t.Errorf("unexpected breakpoints (%v)", tgt.breakpoints)
+	}
+
+	// monitor command
+	cl.expect("qRcmd,"+hex.EncodeToString([]byte("CPU")), hex.EncodeToString([]byte("ran CPU\n")))
+
+	// single step. the STEP CPU command changes the quantum in the debugger.
+	// the original quantum should be restored once the emulation has halted
+	cl.send("s")
+	waitFor(result, "STEP CPU\n")
+	tgt.quantum = "CPU"
+	result = read()
+	if r := cl.receive(); r != "S05" {
+		t.Errorf("unexpected stop reply (%s) should be (S05)", r)
+	}
+	if tgt.quantum != "VIDEO" {
+		t.Errorf("quantum not restored after step (%s)", tgt.quantum)
+	}
+
+	// continue and interrupt
+	cl.send("vCont;c")
+	waitFor(result, "QUANTUM CPU; RUN\n")
+	tgt.quantum = "CPU"
+	_, _ = conn.Write([]byte{0x03})
+	for !srv.TermReadCheck() {
+		time.Sleep(time.Millisecond)
+	}
+	result = read()
+	waitFor(result, "HALT\n")
+	result = read()
+	if r := cl.receive(); r != "S02" {
+		t.Errorf("unexpected stop reply (%s) should be (S02)", r)
+	}
+	if tgt.quantum != "VIDEO" {
+		t.Errorf("quantum not restored after interrupt (%s)", tgt.quantum)
+	}
+
+	// detaching removes breakpoints
+	cl.expect("D", "OK")
+	_ = conn.Close()
+
+	// kill request from a new client
+	conn, err = net.Dial("tcp", srv.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl = &mockClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+	cl.expect("?", "S05")
+	if len(tgt.breakpoints) != 0 {
+		t.Errorf("breakpoints not removed on detach (%v)", tgt.breakpoints)
+	}
+	cl.send("k")
+
+	select {
+	case r := <-result:
+		if !errors.Is(r.err, errors.UserQuit) {
+			t.Errorf("unexpected result of kill request (%v)", r.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for kill request")
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,63 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package gdbremote
+
+// Registers is the register set of the 6507 as understood by the remote
+// protocol
+type Registers struct {
+	A  uint8
+	X  uint8
+	Y  uint8
+	SP uint8
+	P  uint8
+	PC uint16
+}
+
+// the number of registers in the Registers type
+const numRegisters = 6
+
+// Target defines the operations required by the server in order to service
+// requests from a remote client. All Target functions are called from the
+// goroutine that called Server.TermRead(), ie. the debugger's goroutine.
+type Target interface {
+	// the current register values
+	GetRegisters() Registers
+
+	// change the register values
+	SetRegisters(Registers)
+
+	// read/write a single byte of memory. the address is a CPU address
+	ReadMemory(address uint16) (uint8, error)
+	WriteMemory(address uint16, data uint8) error
+
+	// add or remove a breakpoint for the CPU address. adding a breakpoint
+	// that already exists, or removing a breakpoint that does not exist,
+	// should not be considered to be an error
+	AddBreakpoint(address uint16) error
+	RemoveBreakpoint(address uint16) error
+
+	// the quantum of the emulation, as used as the argument to the QUANTUM
+	// debugger command. the server changes the quantum to CPU when the
+	// emulation is continued or stepped and restores the original value once
+	// the emulation has halted
+	GetQuantum() string
+	SetQuantum(quantum string) error
+
+	// run a debugger command (as requested by a "monitor" command in GDB) and
+	// return the output. commands that would continue the emulation should
+	// not be run
+	Command(cmd string) (string, error)
+}
@@ -34,6 +34,9 @@ func (dbg *Debugger) inputLoop(inputter terminal.Input, videoCycle bool) error {
 	// vcsStep is to be called every video cycle when the quantum mode
 	// is set to CPU
 	vcsStep := func() error {
+		if dbg.tracelog != nil {
+			dbg.tracelog.tick()
+		}
 		if dbg.reflect == nil {
 			return nil
 		}
@@ -285,6 +288,12 @@ func (dbg *Debugger) inputLoop(inputter terminal.Input, videoCycle bool) error {
 			// to happen before we call the VCS.Step() function
 			dbg.lastBank = dbg.VCS.Mem.Cart.GetBank(dbg.VCS.CPU.PC.Address())
 
+			// note state of machine for the trace log before the instruction
+			// is executed
+			if dbg.tracelog != nil {
+				dbg.tracelog.prepare()
+			}
+
 			// not using the err variable because we'll clobber it before we
 			// get to check the result of VCS.Step()
 			var stepErr error
@@ -378,6 +387,21 @@ func (dbg *Debugger) inputLoop(inputter terminal.Input, videoCycle bool) error {
 						return errors.New(errors.DebuggerError, err)
 					}
 				}
+
+				// take a snapshot for the rewind history if required
+				dbg.rewind.Check()
+			}
+
+			// write executed instruction to trace log. the log is stopped if
+			// there is a problem writing to it
+			if dbg.tracelog != nil {
+				err = dbg.tracelog.write(dbg.lastResult)
+				if err != nil {
+					dbg.printLine(terminal.StyleError, "%s", err)
+					if err := dbg.stopTraceLog(); err != nil {
+						dbg.printLine(terminal.StyleError, "%s", err)
+					}
+				}
 			}
 
 			if dbg.commandOnStep != nil {
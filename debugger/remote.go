@@ -0,0 +1,143 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/debugger/terminal"
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// remoteInput is implemented by the servers that allow the debugger to be
+// driven by a remote client. see ListenGDB() and ListenDAP()
+type remoteInput interface {
+	terminal.Input
+	Close()
+}
+
+// remoteTarget implements the functions common to the gdbremote.Target and
+// dap.Target interfaces
+type remoteTarget struct {
+	dbg *Debugger
+}
+
+// ReadMemory implements the gdbremote.Target and dap.Target interfaces
+func (tgt *remoteTarget) ReadMemory(address uint16) (uint8, error) {
+	ai, err := tgt.dbg.dbgmem.peek(address)
+	if err != nil {
+		return 0, err
+	}
+	return ai.data, nil
+}
+
+// WriteMemory implements the gdbremote.Target and dap.Target interfaces
+func (tgt *remoteTarget) WriteMemory(address uint16, data uint8) error {
+	_, err := tgt.dbg.dbgmem.poke(address, data)
+	return err
+}
+
+// AddBreakpoint implements the gdbremote.Target and dap.Target interfaces
+func (tgt *remoteTarget) AddBreakpoint(address uint16) error {
+	tgt.dbg.breakpoints.addPCBreak(address)
+	return nil
+}
+
+// RemoveBreakpoint implements the gdbremote.Target and dap.Target interfaces
+func (tgt *remoteTarget) RemoveBreakpoint(address uint16) error {
+	tgt.dbg.breakpoints.dropPCBreak(address)
+	return nil
+}
+
+// Command implements the gdbremote.Target and dap.Target interfaces
+func (tgt *remoteTarget) Command(cmd string) (string, error) {
+	// redirect terminal output for the duration of the command
+	capture := &captureTerm{Terminal: tgt.dbg.term}
+	tgt.dbg.term = capture
+	defer func() {
+		tgt.dbg.term = capture.Terminal
+	}()
+
+	cont, err := tgt.dbg.parseInput(cmd, false, false)
+
+	// emulation can only be resumed by the remote client itself
+	if cont || tgt.dbg.runUntilHalt {
+		tgt.dbg.runUntilHalt = false
+		return capture.output.String(), errors.New(errors.DebuggerError, "remote commands cannot resume the emulation")
+	}
+
+	if !tgt.dbg.running {
+		return capture.output.String(), errors.New(errors.UserQuit)
+	}
+
+	return capture.output.String(), err
+}
+
+// captureTerm collects the output sent to a terminal
+type captureTerm struct {
+	terminal.Terminal
+	output strings.Builder
+}
+
+// TermPrintLine implements the terminal.Output interface
+func (ct *captureTerm) TermPrintLine(sty terminal.Style, s string) {
+	if sty == terminal.StyleEcho {
+		return
+	}
+	ct.output.WriteString(s)
+	ct.output.WriteString("\n")
+}
+
+// remoteAddress returns the address that a remote server should listen on. An
+// address without a host, including a port number on its own, listens on
+// localhost.
+//
+// Remote clients have complete control of the emulation and there is no
+// authentication so addresses that are not loopback addresses are refused
+// unless allowRemote is true.
+func remoteAddress(address string, allowRemote bool) (string, error) {
+	if !strings.Contains(address, ":") {
+		address = ":" + address
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", errors.New(errors.DebuggerError, err)
+	}
+
+	if host == "" {
+		host = "localhost"
+	}
+
+	if !allowRemote && !isLoopback(host) {
+		return "", errors.New(errors.DebuggerError, fmt.Sprintf("remote connections are not allowed on a non-loopback address (%s)", host))
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// isLoopback returns true if host is "localhost" or a loopback IP address.
+// other host names are not resolved and are not considered to be loopback
+// addresses
+func isLoopback(host string) bool {
+	if strings.ToLower(host) == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -30,6 +30,11 @@ type target struct {
 	// must be a comparable type
 	currentValue interface{}
 	format       string
+
+	// the target is a condition created from an expression. the label of the
+	// target is the expression and the value is always true (see
+	// conditionTarget())
+	condition bool
 }
 
 func (trg target) Label() string {
@@ -219,6 +224,18 @@ func parseTarget(dbg *Debugger, tokens *commandline.Tokens) (*target, error) {
 			}
 
 		default:
+			// TIA and RIOT targets
+			if ht, ok := hardwareTargets[keyword]; ok {
+				trg = &target{
+					label: keyword,
+					currentValue: func() interface{} {
+						return ht.value(dbg)
+					},
+					format: ht.format,
+				}
+				break // switch
+			}
+
 			return nil, errors.New(errors.InvalidTarget, keyword)
 		}
 	}
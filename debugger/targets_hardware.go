@@ -0,0 +1,120 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"github.com/jetsetilly/gopher2600/hardware/memory/addresses"
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// hardwareTarget describes a target for the internal state of the TIA or RIOT.
+// the value function is called every time the target value is required so it
+// should not hold on to any part of the VCS (which may be replaced by a
+// snapshot)
+type hardwareTarget struct {
+	value  func(dbg *Debugger) interface{}
+	format string
+}
+
+// the hmove value as stored by the sprite types is the value of the HMxx
+// register shifted and with the sign bit flipped. we present it as the value
+// of the upper nibble of the register
+func normaliseHmove(hmove uint8) int {
+	return int(hmove ^ 0x08)
+}
+
+func peekRIOT(dbg *Debugger, reg addresses.ChipRegister) interface{} {
+	// address is always peekable. no need to check for errors
+	v, _ := dbg.VCS.Mem.RIOT.Peek(memorymap.OriginRIOT | uint16(reg))
+	return int(v)
+}
+
+// hardwareTargets lists the TIA and RIOT targets. the key is the keyword used
+// to specify the target and is also used as the target label.
+var hardwareTargets = map[string]hardwareTarget{
+	// sprite positions. the pixel at which the sprite will begin drawing,
+	// taking into account any HMOVE adjustment
+	"P0.POS": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Player0.HmovedPixel }},
+	"P1.POS": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Player1.HmovedPixel }},
+	"M0.POS": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Missile0.HmovedPixel }},
+	"M1.POS": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Missile1.HmovedPixel }},
+	"BL.POS": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Ball.HmovedPixel }},
+
+	// the pixel at which the sprite was most recently reset
+	"P0.RESET": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Player0.ResetPixel }},
+	"P1.RESET": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Player1.ResetPixel }},
+	"M0.RESET": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Missile0.ResetPixel }},
+	"M1.RESET": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Missile1.ResetPixel }},
+	"BL.RESET": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Ball.ResetPixel }},
+
+	// sprite sizes and copies
+	"P0.NUSIZ":  {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Player0.Nusiz) }, format: "%#02x"},
+	"P1.NUSIZ":  {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Player1.Nusiz) }, format: "%#02x"},
+	"M0.NUSIZ":  {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Missile0.Nusiz) }, format: "%#02x"},
+	"M1.NUSIZ":  {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Missile1.Nusiz) }, format: "%#02x"},
+	"BL.CTRLPF": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Ball.Ctrlpf) }, format: "%#02x"},
+
+	// the HMOVE value of each sprite (see normaliseHmove())
+	"P0.HMOVE": {value: func(dbg *Debugger) interface{} { return normaliseHmove(dbg.VCS.TIA.Video.Player0.Hmove) }, format: "%#1x"},
+	"P1.HMOVE": {value: func(dbg *Debugger) interface{} { return normaliseHmove(dbg.VCS.TIA.Video.Player1.Hmove) }, format: "%#1x"},
+	"M0.HMOVE": {value: func(dbg *Debugger) interface{} { return normaliseHmove(dbg.VCS.TIA.Video.Missile0.Hmove) }, format: "%#1x"},
+	"M1.HMOVE": {value: func(dbg *Debugger) interface{} { return normaliseHmove(dbg.VCS.TIA.Video.Missile1.Hmove) }, format: "%#1x"},
+	"BL.HMOVE": {value: func(dbg *Debugger) interface{} { return normaliseHmove(dbg.VCS.TIA.Video.Ball.Hmove) }, format: "%#1x"},
+
+	// whether the sprite is still receiving extra HMOVE clocks
+	"P0.MOREHMOVE": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Player0.MoreHMOVE }},
+	"P1.MOREHMOVE": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Player1.MoreHMOVE }},
+	"M0.MOREHMOVE": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Missile0.MoreHMOVE }},
+	"M1.MOREHMOVE": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Missile1.MoreHMOVE }},
+	"BL.MOREHMOVE": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.Video.Ball.MoreHMOVE }},
+
+	// the HMOVE counter counts from 15 to -1. a value of -1 indicates that
+	// the counter is inactive
+	"HMOVE.CT":    {value: func(dbg *Debugger) interface{} { return int(int8(dbg.VCS.TIA.HmoveCt)) }},
+	"HMOVE.LATCH": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TIA.HmoveLatch }},
+
+	// collision latches
+	"CXM0P":  {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXM0P) }, format: "%#02x"},
+	"CXM1P":  {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXM1P) }, format: "%#02x"},
+	"CXP0FB": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXP0FB) }, format: "%#02x"},
+	"CXP1FB": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXP1FB) }, format: "%#02x"},
+	"CXM0FB": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXM0FB) }, format: "%#02x"},
+	"CXM1FB": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXM1FB) }, format: "%#02x"},
+	"CXBLPF": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXBLPF) }, format: "%#02x"},
+	"CXPPMM": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.TIA.Video.Collisions.CXPPMM) }, format: "%#02x"},
+
+	// the VSYNC and VBLANK state as most recently sent to the television
+	"VSYNC":  {value: func(dbg *Debugger) interface{} { return dbg.VCS.TV.GetLastSignal().VSync }},
+	"VBLANK": {value: func(dbg *Debugger) interface{} { return dbg.VCS.TV.GetLastSignal().VBlank }},
+
+	// audio registers
+	"AUDC0": {value: func(dbg *Debugger) interface{} { c, _, _ := dbg.VCS.TIA.Audio.Registers(0); return int(c) }, format: "%#02x"},
+	"AUDC1": {value: func(dbg *Debugger) interface{} { c, _, _ := dbg.VCS.TIA.Audio.Registers(1); return int(c) }, format: "%#02x"},
+	"AUDF0": {value: func(dbg *Debugger) interface{} { _, f, _ := dbg.VCS.TIA.Audio.Registers(0); return int(f) }, format: "%#02x"},
+	"AUDF1": {value: func(dbg *Debugger) interface{} { _, f, _ := dbg.VCS.TIA.Audio.Registers(1); return int(f) }, format: "%#02x"},
+	"AUDV0": {value: func(dbg *Debugger) interface{} { _, _, v := dbg.VCS.TIA.Audio.Registers(0); return int(v) }, format: "%#02x"},
+	"AUDV1": {value: func(dbg *Debugger) interface{} { _, _, v := dbg.VCS.TIA.Audio.Registers(1); return int(v) }, format: "%#02x"},
+
+	// RIOT timer. the interval is the number of CPU cycles between each
+	// decrease of the timer value (1, 8, 64 or 1024)
+	"TIMER":          {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.RIOT.Timer.INTIMvalue) }, format: "%#02x"},
+	"TIMER.INTERVAL": {value: func(dbg *Debugger) interface{} { return int(dbg.VCS.RIOT.Timer.Divider) }},
+	"TIMER.TICKS":    {value: func(dbg *Debugger) interface{} { return dbg.VCS.RIOT.Timer.TicksRemaining }},
+
+	// RIOT ports
+	"SWCHA": {value: func(dbg *Debugger) interface{} { return peekRIOT(dbg, addresses.SWCHA) }, format: "%#02x"},
+	"SWCHB": {value: func(dbg *Debugger) interface{} { return peekRIOT(dbg, addresses.SWCHB) }, format: "%#02x"},
+}
@@ -0,0 +1,233 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/jetsetilly/gopher2600/disassembly"
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/cpu/instructions"
+	"github.com/jetsetilly/gopher2600/hardware/cpu/registers"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// traceLog writes a line to file for every instruction executed by the CPU.
+// the format is modelled on the trace output of Stella so that the two logs
+// can be compared with a diff tool. each line records the state of the
+// machine at the moment the instruction began:
+//
+//	frame scanline horizpos bank PC bytecode mnemonic operand A X Y SP P cycles
+//
+// where cycles is the total number of CPU cycles since the log was started
+// (including cycles lost to WSYNC). the line ends with the number of cycles
+// the instruction took and, for instructions that read or write memory, the
+// address that was accessed. for example:
+//
+//	0   0  -44 0  f005  a9 02     LDA  #$02      A=00 X=ff Y=00 SP=ff P=Nv-bdIzc        8 2
+//	0   0  -38 0  f007  85 00     STA  VSYNC     A=02 X=ff Y=00 SP=ff P=nv-bdIzc       10 3  W $0000
+//	0   0  -29 0  f009  85 02     STA  WSYNC     A=02 X=ff Y=00 SP=ff P=nv-bdIzc       13 3  W $0002
+//
+// the flags are printed in the Stella style, upper case meaning the flag is
+// set.
+//
+// if the state of the emulation changes outside of the normal stepping process
+// (a rewind, a STEP BACK or a state load) then a marker line is written to the
+// log. the cycle count is not reset by the change.
+type traceLog struct {
+	dbg      *Debugger
+	filename string
+	f        *os.File
+	w        *bufio.Writer
+
+	// machine state at the start of the instruction. recorded by prepare()
+	frame    int
+	scanline int
+	horizpos int
+	a        uint8
+	x        uint8
+	y        uint8
+	sp       uint8
+	status   registers.StatusRegister
+
+	// number of video cycles since the log was started. incremented by
+	// tick() and used to derive the CPU cycle count
+	clocks int
+
+	// CPU cycle count at the start of the instruction
+	cycles int
+}
+
+// newTraceLog is the preferred method of initialisation for the traceLog type
+func newTraceLog(dbg *Debugger, filename string) (*traceLog, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, errors.New(errors.DebuggerError, err)
+	}
+
+	tl := &traceLog{
+		dbg:      dbg,
+		filename: filename,
+		f:        f,
+		w:        bufio.NewWriter(f),
+	}
+
+	return tl, nil
+}
+
+// tick should be called every video cycle
+func (tl *traceLog) tick() {
+	tl.clocks++
+}
+
+// prepare should be called before every CPU instruction is executed
+func (tl *traceLog) prepare() {
+	tl.cycles = tl.clocks / 3
+
+	tl.frame, _ = tl.dbg.VCS.TV.GetState(television.ReqFramenum)
+	tl.scanline, _ = tl.dbg.VCS.TV.GetState(television.ReqScanline)
+	tl.horizpos, _ = tl.dbg.VCS.TV.GetState(television.ReqHorizPos)
+
+	cpu := tl.dbg.VCS.CPU
+	tl.a = cpu.A.Value()
+	tl.x = cpu.X.Value()
+	tl.y = cpu.Y.Value()
+	tl.sp = cpu.SP.Value()
+	tl.status = *cpu.Status
+}
+
+// write should be called after every CPU instruction has been executed. the
+// formatted result of the instruction is supplied as an argument. nothing is
+// written if the instruction did not complete.
+func (tl *traceLog) write(e *disassembly.Entry) error {
+	if e == nil || !e.Result.Final || e.Result.Defn == nil {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(tl.w, "%5d %3d %4d %-2s %04x  %-8s  %-4s %-8s  A=%02x X=%02x Y=%02x SP=%02x P=%s  %7d %d",
+		tl.frame, tl.scanline, tl.horizpos, tl.dbg.lastBank,
+		e.Result.Address, e.Bytecode, e.Mnemonic, e.Operand,
+		tl.a, tl.x, tl.y, tl.sp, tl.flags(),
+		tl.cycles, e.Result.ActualCycles)
+	if err != nil {
+		return errors.New(errors.DebuggerError, err)
+	}
+
+	// the memory address touched by the instruction. the last memory access
+	// is only meaningful for instructions that read or write memory as part
+	// of their operation
+	switch e.Result.Defn.Effect {
+	case instructions.Read, instructions.Write, instructions.RMW:
+		switch e.Result.Defn.AddressingMode {
+		case instructions.Implied, instructions.Immediate:
+		default:
+			mem := tl.dbg.VCS.Mem
+			if mem.LastAccessWrite {
+				_, err = fmt.Fprintf(tl.w, "  W $%04x", mem.LastAccessAddress)
+			} else {
+				_, err = fmt.Fprintf(tl.w, "  R $%04x", mem.LastAccessAddress)
+			}
+			if err != nil {
+				return errors.New(errors.DebuggerError, err)
+			}
+		}
+	}
+
+	_, err = tl.w.WriteString("\n")
+	if err != nil {
+		return errors.New(errors.DebuggerError, err)
+	}
+
+	return nil
+}
+
+// discontinuity should be called when the state of the emulation has been
+// changed outside of the normal stepping process. for example, after a rewind.
+// a marker line is written so that the log is not mistaken for a continuous
+// run of instructions. the position given is the new position of the
+// emulation.
+func (tl *traceLog) discontinuity() error {
+	frame, _ := tl.dbg.VCS.TV.GetState(television.ReqFramenum)
+	scanline, _ := tl.dbg.VCS.TV.GetState(television.ReqScanline)
+	horizpos, _ := tl.dbg.VCS.TV.GetState(television.ReqHorizPos)
+
+	_, err := fmt.Fprintf(tl.w, "---- emulation state changed: now at frame %d scanline %d horizpos %d ----\n", frame, scanline, horizpos)
+	if err != nil {
+		return errors.New(errors.DebuggerError, err)
+	}
+
+	return nil
+}
+
+// flags returns the status register in the Stella style. the
+// StatusRegister.String() function uses S for the sign flag but Stella uses
+// N.
+func (tl *traceLog) flags() string {
+	s := []byte(tl.status.String())
+	if tl.status.Sign {
+		s[0] = 'N'
+	} else {
+		s[0] = 'n'
+	}
+	return string(s)
+}
+
+// close flushes any buffered output and closes the file
+func (tl *traceLog) close() error {
+	err := tl.w.Flush()
+	if err != nil {
+		_ = tl.f.Close()
+		return errors.New(errors.DebuggerError, err)
+	}
+
+	err = tl.f.Close()
+	if err != nil {
+		return errors.New(errors.DebuggerError, err)
+	}
+
+	return nil
+}
+
+// StartTraceLog writes every executed CPU instruction to the named file. Any
+// trace log already in progress is stopped first. Can be called before or
+// after Start().
+func (dbg *Debugger) StartTraceLog(filename string) error {
+	err := dbg.stopTraceLog()
+	if err != nil {
+		return err
+	}
+
+	dbg.tracelog, err = newTraceLog(dbg, filename)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// stopTraceLog closes the current trace log, if there is one
+func (dbg *Debugger) stopTraceLog() error {
+	if dbg.tracelog == nil {
+		return nil
+	}
+
+	tl := dbg.tracelog
+	dbg.tracelog = nil
+
+	return tl.close()
+}
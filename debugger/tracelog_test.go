@@ -0,0 +1,156 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/cartridgeloader"
+	"github.com/jetsetilly/gopher2600/debugger"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// a 4k cartridge that loops over a short sequence of instructions. the
+// sequence includes instructions that read and write memory
+func testTraceLogCartridge(t *testing.T, dir string) string {
+	t.Helper()
+
+	data := make([]byte, 4096)
+	copy(data, []byte{
+		0xa9, 0x02, // LDA #$02
+		0x85, 0x80, // STA $80
+		0xa5, 0x80, // LDA $80
+		0xe6, 0x81, // INC $81
+		0x4c, 0x00, 0xf0, // JMP $f000
+	})
+
+	// reset vector
+	data[0xffc] = 0x00
+	data[0xffd] = 0xf0
+
+	fn := filepath.Join(dir, "trace.bin")
+	err := ioutil.WriteFile(fn, data, 0644)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return fn
+}
+
+// compare the contents of the trace log with the expected lines
+func cmpTraceLog(t *testing.T, fn string, expected []string) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Errorf("unexpected number of lines in trace log (%d) should be (%d)", len(lines), len(expected))
+		return
+	}
+
+	for i := range lines {
+		if lines[i] != expected[i] {
+			t.Errorf("unexpected trace log line (%s) should be (%s)", lines[i], expected[i])
+		}
+	}
+}
+
+func (trm *mockTerm) testTraceLog(dir string) {
+	defer func() { trm.sndInput("QUIT") }()
+
+	fn := filepath.Join(dir, "trace.log")
+
+	trm.sndInput("TRACELOG START " + fn)
+	trm.cmpOutput("logging instructions to " + fn)
+
+	for i := 0; i < 5; i++ {
+		trm.sndInput("STEP")
+		trm.rcvOutput()
+	}
+
+	trm.sndInput("TRACELOG STOP")
+	trm.cmpOutput("instruction log to " + fn + " stopped")
+
+	// the access column shows the address written to by STA and INC and the
+	// address read by LDA. the immediate mode LDA and the JMP instruction
+	// have no access column
+	cmpTraceLog(trm.t, fn, []string{
+		"    0   0  -68 0  f000  a9 02     LDA  #$02      A=00 X=00 Y=00 SP=ff P=nv-bdiZc        0 2",
+		"    0   0  -62 0  f002  85 80     STA  $80       A=02 X=00 Y=00 SP=ff P=nv-bdizc        2 3  W $0080",
+		"    0   0  -53 0  f004  a5 80     LDA  $80       A=02 X=00 Y=00 SP=ff P=nv-bdizc        5 3  R $0080",
+		"    0   0  -44 0  f006  e6 81     INC  $81       A=02 X=00 Y=00 SP=ff P=nv-bdizc        8 5  W $0081",
+		"    0   0  -29 0  f008  4c 00 f0  JMP  $f000     A=02 X=00 Y=00 SP=ff P=nv-bdizc       13 3",
+	})
+
+	// stepping back writes a marker line to the log before the instruction is
+	// executed again
+	fn = filepath.Join(dir, "rewind.log")
+
+	trm.sndInput("TRACELOG START " + fn)
+	trm.cmpOutput("logging instructions to " + fn)
+	trm.sndInput("STEP")
+	trm.rcvOutput()
+	trm.sndInput("STEP BACK")
+	trm.cmpOutput("")
+	trm.sndInput("STEP")
+	trm.rcvOutput()
+	trm.sndInput("TRACELOG STOP")
+	trm.cmpOutput("instruction log to " + fn + " stopped")
+
+	cmpTraceLog(trm.t, fn, []string{
+		"    0   0  -20 0  f000  a9 02     LDA  #$02      A=02 X=00 Y=00 SP=ff P=nv-bdizc        0 2",
+		"---- emulation state changed: now at frame 0 scanline 0 horizpos -20 ----",
+		"    0   0  -20 0  f000  a9 02     LDA  #$02      A=02 X=00 Y=00 SP=ff P=nv-bdizc        2 2",
+	})
+}
+
+func TestDebugger_traceLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracelog")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	// a real television is required so that STEP BACK can find its position
+	// in the rewind history
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	trm := newMockTerm(t)
+
+	dbg, err := debugger.NewDebugger(tv, &mockGUI{}, trm)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	go trm.testTraceLog(dir)
+
+	err = dbg.Start("", cartridgeloader.NewLoader(testTraceLogCartridge(t, dir), "AUTO"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+}
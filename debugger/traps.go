@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jetsetilly/gopher2600/debugger/expression"
 	"github.com/jetsetilly/gopher2600/debugger/terminal"
 	"github.com/jetsetilly/gopher2600/debugger/terminal/commandline"
 	"github.com/jetsetilly/gopher2600/errors"
@@ -101,11 +102,23 @@ func (tr traps) list() {
 	}
 }
 
-// parse tokens and add new trap
+// parse tokens and add new trap. if the tokens form an expression (see
+// isExpression()) then a single trap is added for the value of the expression
 func (tr *traps) parseCommand(tokens *commandline.Tokens) error {
 	_, present := tokens.Peek()
 	for present {
-		tgt, err := parseTarget(tr.dbg, tokens)
+		var tgt *target
+		var err error
+
+		if isExpression(tokens.Remainder()) {
+			var ex *expression.Expression
+			ex, err = parseExpression(tr.dbg, tokens)
+			if err == nil {
+				tgt = expressionTarget(ex)
+			}
+		} else {
+			tgt, err = parseTarget(tr.dbg, tokens)
+		}
 		if err != nil {
 			return err
 		}
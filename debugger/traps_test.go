@@ -31,4 +31,11 @@ func (trm *mockTerm) testTraps() {
 	// list traps. compare last line.
 	trm.sndInput("LIST TRAPS")
 	trm.cmpOutput(" 0: A")
+
+	// trap on the value of an expression
+	trm.sndInput("TRAP [$80] & $0F")
+	trm.cmpOutput("")
+
+	trm.sndInput("LIST TRAPS")
+	trm.cmpOutput(" 1: [$80] & $0f")
 }
@@ -0,0 +1,32 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package debugger_test
+
+func (trm *mockTerm) testTV() {
+	trm.sndInput("TV RESIZER STATISTICAL")
+	trm.cmpOutput("")
+
+	trm.sndInput("TV RESIZER none")
+	trm.cmpOutput("")
+
+	// the resizer must be specified
+	trm.sndInput("TV RESIZER")
+	trm.cmpOutput("resizer must be one of NONE, SIMPLE or STATISTICAL")
+
+	// and must be one of the valid options
+	trm.sndInput("TV RESIZER FOO")
+	trm.cmpOutput("unrecognised argument (FOO) for TV")
+}
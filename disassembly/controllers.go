@@ -0,0 +1,327 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package disassembly
+
+import (
+	"bytes"
+
+	"github.com/jetsetilly/gopher2600/hardware/cpu/instructions"
+	"github.com/jetsetilly/gopher2600/hardware/memory/addresses"
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// ControllerHint is the type of controller suggested by the static analysis
+// of the disassembly.
+type ControllerHint int
+
+// List of valid ControllerHint values. ControllerUnknown means that there is
+// no evidence for any particular controller type. In practice this means that
+// a joystick should be used.
+const (
+	ControllerUnknown ControllerHint = iota
+	ControllerPaddle
+	ControllerKeypad
+	ControllerDriving
+)
+
+func (c ControllerHint) String() string {
+	switch c {
+	case ControllerPaddle:
+		return "Paddle"
+	case ControllerKeypad:
+		return "Keypad"
+	case ControllerDriving:
+		return "Driving"
+	}
+	return "Unknown"
+}
+
+// Controllers is the result of the DetectControllers() function.
+type Controllers struct {
+	Left  ControllerHint
+	Right ControllerHint
+}
+
+// the evidence found for a single controller port
+type portEvidence struct {
+	// the paddle (or keypad) inputs have been read
+	inpt bool
+
+	// the paddle inputs have been read and the sign bit of the value tested
+	// by the following instruction. this is how the paddle capacitor is
+	// tested
+	inptSign bool
+
+	// the direction of the SWCHA bits for the port have been set to output.
+	// keypads require this in order to scan the key rows
+	ddrOutput bool
+
+	// the SWCHA bits for the port have been masked by the program in a way
+	// consistent with reading a driving controller
+	grayMask bool
+}
+
+// the number of instructions after a read of SWCHA in which we'll look for
+// the driving controller mask
+const swchaWindow = 3
+
+// controllerScan collects the evidence for DetectControllers()
+type controllerScan struct {
+	left, right portEvidence
+	dumpPorts   bool
+	swchaWrite  bool
+
+	// a gray code table has been read by an indexed instruction
+	grayTable bool
+
+	// the most recent immediate value loaded into each register. forgotten
+	// whenever the sequence of instructions is broken
+	imm map[string]int
+
+	// countdown after the most recent read of SWCHA
+	swchaRead int
+
+	// the port for which the paddle input was read by the previous
+	// instruction. nil if the previous instruction did not read a paddle input
+	inptRead *portEvidence
+}
+
+// forget the values carried from one instruction to the next. should be
+// called whenever the next instruction does not follow on from the previous
+// instruction
+func (sc *controllerScan) sequence() {
+	sc.imm = map[string]int{"A": -1, "X": -1, "Y": -1}
+	sc.swchaRead = 0
+	sc.inptRead = nil
+}
+
+// examine a single instruction. data is the content of the bank in which
+// the instruction was found
+func (sc *controllerScan) instruction(defn *instructions.Definition, operand uint16, data []uint8) {
+	if sc.swchaRead > 0 {
+		sc.swchaRead--
+	}
+
+	// the sign of the most recent paddle input read is tested by a branch
+	// instruction
+	if sc.inptRead != nil && (defn.Mnemonic == "BPL" || defn.Mnemonic == "BMI") {
+		sc.inptRead.inptSign = true
+	}
+	sc.inptRead = nil
+
+	if defn.AddressingMode == instructions.Immediate {
+		switch defn.Mnemonic {
+		case "LDA":
+			sc.imm["A"] = int(operand)
+		case "LDX":
+			sc.imm["X"] = int(operand)
+		case "LDY":
+			sc.imm["Y"] = int(operand)
+		case "AND":
+			if sc.swchaRead > 0 {
+				if operand&0xff == 0x30 {
+					sc.left.grayMask = true
+				} else if operand&0xff == 0x03 {
+					sc.right.grayMask = true
+				}
+			}
+		}
+		return
+	}
+
+	switch defn.AddressingMode {
+	case instructions.Absolute, instructions.ZeroPage,
+		instructions.AbsoluteIndexedX, instructions.AbsoluteIndexedY,
+		instructions.ZeroPageIndexedX, instructions.ZeroPageIndexedY:
+	default:
+		// loads into registers with any other addressing mode invalidate the
+		// immediate value
+		if len(defn.Mnemonic) == 3 && defn.Mnemonic[:2] == "LD" {
+			sc.imm[defn.Mnemonic[2:]] = -1
+		}
+		return
+	}
+
+	switch defn.Effect {
+	case instructions.Read, instructions.RMW:
+		switch readSymbol(operand) {
+		case "INPT0", "INPT1":
+			sc.left.inpt = true
+			sc.inptRead = &sc.left
+		case "INPT2", "INPT3":
+			sc.right.inpt = true
+			sc.inptRead = &sc.right
+		case "SWCHA":
+			sc.swchaRead = swchaWindow + 1
+		}
+
+		// indexed reads of cartridge data may be reads of the driving
+		// controller lookup table
+		if defn.AddressingMode == instructions.AbsoluteIndexedX || defn.AddressingMode == instructions.AbsoluteIndexedY {
+			if len(data) > 0 && operand&memorymap.OriginCart == memorymap.OriginCart {
+				if hasGrayCodeTable(data, int(operand&memorymap.CartridgeBits)%len(data)) {
+					sc.grayTable = true
+				}
+			}
+		}
+
+		if len(defn.Mnemonic) == 3 && defn.Mnemonic[:2] == "LD" {
+			sc.imm[defn.Mnemonic[2:]] = -1
+		}
+
+	case instructions.Write:
+		// the value being written, if it is known
+		v := -1
+		if len(defn.Mnemonic) == 3 && defn.Mnemonic[:2] == "ST" {
+			v = sc.imm[defn.Mnemonic[2:]]
+		}
+
+		switch writeSymbol(operand) {
+		case "VBLANK":
+			if v >= 0 && v&0x80 == 0x80 {
+				sc.dumpPorts = true
+			}
+		case "SWACNT":
+			if v >= 0 {
+				if v&0xf0 != 0 {
+					sc.left.ddrOutput = true
+				}
+				if v&0x0f != 0 {
+					sc.right.ddrOutput = true
+				}
+			}
+		case "SWCHA":
+			sc.swchaWrite = true
+		}
+	}
+}
+
+// decide on the controller type for the port
+func (sc *controllerScan) decide(p portEvidence) ControllerHint {
+	if p.ddrOutput && sc.swchaWrite && p.inpt {
+		return ControllerKeypad
+	}
+	if p.inptSign && sc.dumpPorts {
+		return ControllerPaddle
+	}
+	if p.grayMask && sc.grayTable {
+		return ControllerDriving
+	}
+	return ControllerUnknown
+}
+
+// DetectControllers examines the blessed entries in the disassembly for
+// evidence of non-joystick controllers:
+//
+//	paddles: dumping of the input ports to ground with VBLANK and reading of
+//	INPT0 to INPT3 followed immediately by a test of the sign bit (BPL or BMI)
+//
+//	keypads: writing to SWCHA with the data direction (SWACNT) set to output
+//	for the port and reading of the port's INPTx inputs
+//
+//	driving controllers: masking of the gray code bits of SWCHA and an
+//	indexed read of a gray code lookup table in the cartridge data
+//
+// The analysis is only as good as the disassembly. Code that is not blessed
+// is not considered. Evidence that depends on the order of instructions is
+// only collected from instructions that follow on from one another in the
+// disassembly.
+func (dsm *Disassembly) DetectControllers() Controllers {
+	dsm.crit.Lock()
+	defer dsm.crit.Unlock()
+
+	if dsm.cart == nil {
+		return Controllers{}
+	}
+
+	// the data for each bank. used to check for the gray code table
+	data := make([][]uint8, len(dsm.disasm))
+	bank, err := dsm.cart.IterateBanks(nil)
+	for bank != nil && err == nil {
+		if bank.Number < len(data) {
+			data[bank.Number] = bank.Data
+		}
+		bank, err = dsm.cart.IterateBanks(bank)
+	}
+
+	sc := &controllerScan{}
+
+	for b := range dsm.disasm {
+		sc.sequence()
+
+		// the address of the instruction that would follow the previous
+		// blessed instruction
+		next := -1
+
+		for a, e := range dsm.disasm[b] {
+			if e == nil || e.Level < EntryLevelBlessed || e.Result.Defn == nil {
+				continue
+			}
+
+			if a != next {
+				sc.sequence()
+			}
+			next = a + e.Result.ByteCount
+
+			sc.instruction(e.Result.Defn, e.Result.InstructionData, data[b])
+		}
+	}
+
+	return Controllers{
+		Left:  sc.decide(sc.left),
+		Right: sc.decide(sc.right),
+	}
+}
+
+// the TIA/RIOT read symbol for the address. returns the empty string if the
+// address is not a TIA or RIOT address
+func readSymbol(address uint16) string {
+	ma, area := memorymap.MapAddress(address, true)
+	if area == memorymap.Cartridge || int(ma) >= len(addresses.Read) {
+		return ""
+	}
+	return addresses.Read[ma]
+}
+
+// the TIA/RIOT write symbol for the address. returns the empty string if the
+// address is not a TIA or RIOT address
+func writeSymbol(address uint16) string {
+	ma, area := memorymap.MapAddress(address, false)
+	if area == memorymap.Cartridge || int(ma) >= len(addresses.Write) {
+		return ""
+	}
+	return addresses.Write[ma]
+}
+
+// the gray code sequence of the driving controller, as found in the lookup
+// tables of driving games. the sequence is for the right port and the left
+// port (upper nibble) in both directions
+var grayCodeTables = [][]byte{
+	{0x00, 0x01, 0x03, 0x02},
+	{0x00, 0x02, 0x03, 0x01},
+	{0x00, 0x10, 0x30, 0x20},
+	{0x00, 0x20, 0x30, 0x10},
+}
+
+// returns true if the data at the offset is a gray code table
+func hasGrayCodeTable(data []byte, offset int) bool {
+	for _, t := range grayCodeTables {
+		if bytes.HasPrefix(data[offset:], t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package disassembly_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/cartridgeloader"
+	"github.com/jetsetilly/gopher2600/disassembly"
+)
+
+// disassemble a 4k cartridge with the program at the start of the ROM. the
+// data argument is placed at the end of the ROM, before the vectors
+func newTestDisassembly(t *testing.T, program []byte, data []byte) *disassembly.Disassembly {
+	t.Helper()
+
+	rom := make([]byte, 4096)
+	copy(rom, program)
+	copy(rom[0x0ff0-len(data):], data)
+	rom[0x0ffc], rom[0x0ffd] = 0x00, 0xf0
+
+	dir, err := ioutil.TempDir("", "gopher2600_controllers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.bin")
+	err = ioutil.WriteFile(filename, rom, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsm, err := disassembly.FromCartridge(cartridgeloader.NewLoader(filename, "AUTO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dsm
+}
+
+func TestDetectControllers(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []byte
+		data    []byte
+		left    disassembly.ControllerHint
+		right   disassembly.ControllerHint
+	}{
+		{
+			name: "joystick",
+			program: []byte{
+				0xad, 0x80, 0x02, // LDA SWCHA
+				0x29, 0x80, // AND #$80
+				0xa5, 0x0c, // LDA INPT4
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			left:  disassembly.ControllerUnknown,
+			right: disassembly.ControllerUnknown,
+		},
+		{
+			name: "left paddle",
+			program: []byte{
+				0xa9, 0x82, // LDA #$82
+				0x85, 0x01, // STA VBLANK
+				0xa5, 0x08, // LDA INPT0
+				0x10, 0xfc, // BPL
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			left:  disassembly.ControllerPaddle,
+			right: disassembly.ControllerUnknown,
+		},
+		{
+			name: "right paddle",
+			program: []byte{
+				0xa2, 0x80, // LDX #$80
+				0x86, 0x01, // STX VBLANK
+				0x24, 0x0b, // BIT INPT3
+				0x30, 0xfc, // BMI
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			left:  disassembly.ControllerUnknown,
+			right: disassembly.ControllerPaddle,
+		},
+		{
+			// many joystick games write $82 to VBLANK. reading a paddle input
+			// without testing the sign bit is not enough evidence
+			name: "paddle without sign test",
+			program: []byte{
+				0xa9, 0x82, // LDA #$82
+				0x85, 0x01, // STA VBLANK
+				0xa5, 0x08, // LDA INPT0
+				0x29, 0x80, // AND #$80
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			left:  disassembly.ControllerUnknown,
+			right: disassembly.ControllerUnknown,
+		},
+		{
+			name: "left keypad",
+			program: []byte{
+				0xa9, 0xf0, // LDA #$F0
+				0x8d, 0x81, 0x02, // STA SWACNT
+				0xa9, 0xe0, // LDA #$E0
+				0x8d, 0x80, 0x02, // STA SWCHA
+				0xa5, 0x08, // LDA INPT0
+				0xa5, 0x09, // LDA INPT1
+				0xa5, 0x0c, // LDA INPT4
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			left:  disassembly.ControllerKeypad,
+			right: disassembly.ControllerUnknown,
+		},
+		{
+			name: "both keypads",
+			program: []byte{
+				0xa0, 0xff, // LDY #$FF
+				0x8c, 0x81, 0x02, // STY SWACNT
+				0xa9, 0xee, // LDA #$EE
+				0x8d, 0x80, 0x02, // STA SWCHA
+				0xa5, 0x08, // LDA INPT0
+				0xa5, 0x0a, // LDA INPT2
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			left:  disassembly.ControllerKeypad,
+			right: disassembly.ControllerKeypad,
+		},
+		{
+			name: "driving",
+			program: []byte{
+				0xad, 0x80, 0x02, // LDA SWCHA
+				0x29, 0x30, // AND #$30
+				0x4a,             // LSR
+				0x4a,             // LSR
+				0x4a,             // LSR
+				0x4a,             // LSR
+				0xa8,             // TAY
+				0xb9, 0xec, 0xff, // LDA $FFEC,Y
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			data:  []byte{0x00, 0x10, 0x30, 0x20},
+			left:  disassembly.ControllerDriving,
+			right: disassembly.ControllerUnknown,
+		},
+		{
+			// masking SWCHA without a gray code table is not enough evidence
+			name: "driving without table",
+			program: []byte{
+				0xad, 0x80, 0x02, // LDA SWCHA
+				0x29, 0x30, // AND #$30
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			left:  disassembly.ControllerUnknown,
+			right: disassembly.ControllerUnknown,
+		},
+		{
+			// a joystick game testing the up/down bits of SWCHA. the gray
+			// code sequence appears in the data but is never read as a table
+			name: "driving with unused table",
+			program: []byte{
+				0xad, 0x80, 0x02, // LDA SWCHA
+				0x29, 0x30, // AND #$30
+				0x4c, 0x00, 0xf0, // JMP $F000
+			},
+			data:  []byte{0x00, 0x10, 0x30, 0x20},
+			left:  disassembly.ControllerUnknown,
+			right: disassembly.ControllerUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		dsm := newTestDisassembly(t, tt.program, tt.data)
+
+		ctrls := dsm.DetectControllers()
+
+		if ctrls.Left != tt.left {
+			t.Errorf("%s: left controller is %s (expected %s)", tt.name, ctrls.Left, tt.left)
+		}
+		if ctrls.Right != tt.right {
+			t.Errorf("%s: right controller is %s (expected %s)", tt.name, ctrls.Right, tt.right)
+		}
+	}
+}
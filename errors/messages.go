@@ -40,6 +40,9 @@ const (
 	TerminalError   = "%v"
 	GUIEventError   = "%v"
 	BreakpointError = "breakpoint error: %v"
+	GDBRemote       = "gdb remote: %v"
+	DAP             = "dap: %v"
+	ExpressionError = "expression error: %v"
 
 	// commandline
 	ParserError     = "parser error: %v"
@@ -82,6 +85,13 @@ const (
 	// patch
 	PatchError = "patch error: %v"
 
+	// properties
+	PropertiesError     = "properties error: %v"
+	PropertiesReadError = "properties error: %v [line %d]"
+
+	// rewind
+	RewindError = "rewind error: %v"
+
 	// symbols
 	SymbolsFileError       = "symbols error: error processing symbols file: %v"
 	SymbolsFileUnavailable = "symbols error: no symbols file for %v"
@@ -92,6 +102,7 @@ const (
 
 	// vcs
 	PolycounterError = "polycounter error: %v"
+	SnapshotError    = "snapshot error: %v"
 
 	// cpu
 	InvalidResult          = "cpu error: %v"
@@ -113,11 +124,13 @@ const (
 	CartridgePatchOOB    = "cartrdige error: patch offset too high (%#04x)"
 	CartridgeStaticArea  = "cartridge error: static area: %v"
 	SuperchargerError    = "cartridge error: AR: %v"
+	ARMError             = "cartridge error: ARM7: %v"
 
 	// input
 	UnknownInputEvent     = "input error: %v: unsupported event (%v)"
 	BadInputEventType     = "input error: bad value type for event %v (expecting %s)"
 	UnknownControllerType = "input error: unknown controller type (%v)"
+	SaveKeyError          = "input error: savekey: %v"
 
 	// television
 	UnknownTVRequest = "television error: unsupported request (%v)"
@@ -130,6 +143,12 @@ const (
 	// audio2wav
 	WavWriter = "wav writer: %v"
 
+	// video capture
+	VideoCapture = "video capture: %v"
+
+	// screenshots
+	Screenshot = "screenshot: %v"
+
 	// gui
 	UnsupportedGUIRequest = "unsupported request (%v)"
 	SDLDebug              = "sdldebug: %v"
@@ -32,6 +32,7 @@ import (
 	"github.com/jetsetilly/gopher2600/gui"
 	"github.com/jetsetilly/gopher2600/gui/deprecated/sdldebug"
 	"github.com/jetsetilly/gopher2600/gui/sdlimgui"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge"
 	"github.com/jetsetilly/gopher2600/hiscore"
 	"github.com/jetsetilly/gopher2600/modalflag"
 	"github.com/jetsetilly/gopher2600/paths"
@@ -40,6 +41,7 @@ import (
 	"github.com/jetsetilly/gopher2600/recorder"
 	"github.com/jetsetilly/gopher2600/regression"
 	"github.com/jetsetilly/gopher2600/television"
+	"github.com/jetsetilly/gopher2600/videocapture"
 	"github.com/jetsetilly/gopher2600/wavwriter"
 )
 
@@ -180,14 +182,10 @@ func launch(sync *mainSync) {
 		sync.state <- reqQuit
 	}()
 
-	// we generate random numbers in some places. seed the generator with the
-	// current time
-	// rand.Seed(int64(time.Now().Second()))
-
 	md := &modalflag.Modes{Output: os.Stdout}
 	md.NewArgs(os.Args[1:])
 	md.NewMode()
-	md.AddSubModes("RUN", "PLAY", "DEBUG", "DISASM", "PERFORMANCE", "REGRESS", "HISCORE")
+	md.AddSubModes("RUN", "PLAY", "DEBUG", "DISASM", "DETECT", "PERFORMANCE", "REGRESS", "HISCORE")
 
 	p, err := md.Parse()
 	switch p {
@@ -211,6 +209,9 @@ func launch(sync *mainSync) {
 	case "DISASM":
 		err = disasm(md)
 
+	case "DETECT":
+		err = detect(md)
+
 	case "PERFORMANCE":
 		err = perform(md, sync)
 
@@ -231,12 +232,13 @@ func play(md *modalflag.Modes, sync *mainSync) error {
 	md.NewMode()
 
 	mapping := md.AddString("mapping", "AUTO", "force use of cartridge mapping")
-	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL")
+	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL, PAL-M, PAL60, SECAM")
 	scaling := md.AddFloat64("scale", 0.0, "television scaling")
 	crt := md.AddBool("crt", true, "apply CRT post-processing")
 	fpsCap := md.AddBool("fpscap", true, "cap fps to specification")
 	record := md.AddBool("record", false, "record user input to a file")
 	wav := md.AddString("wav", "", "record audio to wav file")
+	capture := md.AddString("capture", "", "capture video to file (.avi or .png)")
 	patchFile := md.AddString("patch", "", "patch file to apply (cartridge args only)")
 	hiscore := md.AddBool("hiscore", false, "contact hiscore server [EXPERIMENTAL]")
 
@@ -269,6 +271,15 @@ func play(md *modalflag.Modes, sync *mainSync) error {
 			tv.AddAudioMixer(aw)
 		}
 
+		// start video capture if capture argument has been specified. the
+		// capture will be concluded when the television ends
+		if *capture != "" {
+			err = videocapture.NewCapture(tv).Start(*capture)
+			if err != nil {
+				return errors.New(errors.PlayError, err)
+			}
+		}
+
 		// create gui
 		sync.creator <- func() (GuiCreator, error) {
 			return sdlimgui.NewSdlImgui(tv, true)
@@ -339,16 +350,24 @@ func debug(md *modalflag.Modes, sync *mainSync) error {
 	}
 
 	mapping := md.AddString("mapping", "AUTO", "force use of cartridge mapping")
-	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL")
+	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL, PAL-M, PAL60, SECAM")
 	termType := md.AddString("term", "IMGUI", "terminal type to use in debug mode: IMGUI, COLOR, PLAIN")
 	initScript := md.AddString("initscript", defInitScript, "script to run on debugger start")
 	profile := md.AddBool("profile", false, "run debugger through cpu profiler")
+	gdb := md.AddString("gdb", "", "accept GDB remote connections on address (eg. 2600 or localhost:2600)")
+	dap := md.AddString("dap", "", "accept Debug Adapter Protocol connections on address (eg. 2600 or localhost:2600)")
+	tracelog := md.AddString("tracelog", "", "write every executed instruction to file")
+	remote := md.AddBool("remote", false, "allow GDB remote and DAP connections from other machines")
 
 	p, err := md.Parse()
 	if err != nil || p != modalflag.ParseContinue {
 		return err
 	}
 
+	if *gdb != "" && *dap != "" {
+		return fmt.Errorf("-gdb and -dap cannot be used together")
+	}
+
 	tv, err := television.NewTelevision(*spec)
 	if err != nil {
 		return errors.New(errors.DebuggerError, err)
@@ -410,6 +429,27 @@ func debug(md *modalflag.Modes, sync *mainSync) error {
 		return err
 	}
 
+	// input will come from remote client rather than the terminal
+	if *gdb != "" {
+		err = dbg.ListenGDB(*gdb, *remote)
+		if err != nil {
+			return err
+		}
+	} else if *dap != "" {
+		err = dbg.ListenDAP(*dap, *remote)
+		if err != nil {
+			return err
+		}
+	}
+
+	// log every instruction from the very first
+	if *tracelog != "" {
+		err = dbg.StartTraceLog(*tracelog)
+		if err != nil {
+			return err
+		}
+	}
+
 	switch len(md.RemainingArgs()) {
 	case 0:
 		return fmt.Errorf("2600 cartridge required for %s mode", md)
@@ -509,11 +549,38 @@ func disasm(md *modalflag.Modes) error {
 	return nil
 }
 
+func detect(md *modalflag.Modes) error {
+	md.NewMode()
+
+	p, err := md.Parse()
+	if err != nil || p != modalflag.ParseContinue {
+		return err
+	}
+
+	switch len(md.RemainingArgs()) {
+	case 0:
+		return fmt.Errorf("2600 cartridge required for %s mode", md)
+	case 1:
+		cartload := cartridgeloader.NewLoader(md.GetArg(0), "AUTO")
+
+		data, err := cartload.Load()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprint(md.Output, cartridge.Detect(data))
+	default:
+		return fmt.Errorf("too many arguments for %s mode", md)
+	}
+
+	return nil
+}
+
 func perform(md *modalflag.Modes, sync *mainSync) error {
 	md.NewMode()
 
 	mapping := md.AddString("mapping", "AUTO", "force use of cartridge mapping")
-	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL")
+	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL, PAL-M, PAL60, SECAM")
 	display := md.AddBool("display", false, "display TV output")
 	scaling := md.AddFloat64("scale", 0.0, "display scaling (only valid if -display=true")
 	fpsCap := md.AddBool("fpscap", true, "cap FPS to specification (only valid if -display=true)")
@@ -597,20 +664,56 @@ func regress(md *modalflag.Modes) error {
 	case "RUN":
 		md.NewMode()
 
-		// no additional arguments
 		verbose := md.AddBool("verbose", false, "output more detail (eg. error messages)")
 		failOnError := md.AddBool("fail", false, "fail on error")
+		notes := md.AddString("notes", "", "run entries with notes matching regular expression")
+		tags := md.AddString("tags", "", "run entries with all of the comma separated tags")
+		parallel := md.AddInt("parallel", 1, "number of tests to run in parallel (0 for one per CPU)")
+		report := md.AddString("report", "", "write report to comma separated list of files (.xml for JUnit, .json for JSON)")
+
+		md.AdditionalHelp("Database keys can be specified to run only those entries.")
 
 		p, err := md.Parse()
 		if err != nil || p != modalflag.ParseContinue {
 			return err
 		}
 
-		err = regression.RegressRunTests(md.Output, *verbose, *failOnError, md.RemainingArgs())
+		flt := regression.Filter{
+			Keys:  md.RemainingArgs(),
+			Notes: *notes,
+			Tags:  regression.ParseTags(*tags),
+		}
+
+		// check report filenames before running the tests
+		var rep *regression.Report
+		var reportFiles []string
+		for _, fn := range strings.Split(*report, ",") {
+			fn = strings.TrimSpace(fn)
+			if fn == "" {
+				continue // for loop
+			}
+			err = regression.CheckReportFilename(fn)
+			if err != nil {
+				return err
+			}
+			reportFiles = append(reportFiles, fn)
+		}
+		if len(reportFiles) > 0 {
+			rep = &regression.Report{}
+		}
+
+		err = regression.RegressRunTests(md.Output, *verbose, *failOnError, *parallel, flt, rep)
 		if err != nil {
 			return err
 		}
 
+		for _, fn := range reportFiles {
+			err = rep.Write(fn)
+			if err != nil {
+				return err
+			}
+		}
+
 	case "LIST":
 		md.NewMode()
 
@@ -635,31 +738,37 @@ func regress(md *modalflag.Modes) error {
 		md.NewMode()
 
 		answerYes := md.AddBool("yes", false, "answer yes to confirmation")
+		notes := md.AddString("notes", "", "delete entries with notes matching regular expression")
+		tags := md.AddString("tags", "", "delete entries with all of the comma separated tags")
+
+		md.AdditionalHelp("Entries to delete are specified by database key and/or by the notes and tags flags.")
 
 		p, err := md.Parse()
 		if err != nil || p != modalflag.ParseContinue {
 			return err
 		}
 
-		switch len(md.RemainingArgs()) {
-		case 0:
-			return fmt.Errorf("database key required for %s mode", md)
-		case 1:
+		flt := regression.Filter{
+			Keys:  md.RemainingArgs(),
+			Notes: *notes,
+			Tags:  regression.ParseTags(*tags),
+		}
 
-			// use stdin for confirmation unless "yes" flag has been sent
-			var confirmation io.Reader
-			if *answerYes {
-				confirmation = &yesReader{}
-			} else {
-				confirmation = os.Stdin
-			}
+		if flt.IsEmpty() {
+			return fmt.Errorf("database key or filter required for %s mode", md)
+		}
 
-			err := regression.RegressDelete(md.Output, confirmation, md.GetArg(0))
-			if err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("only one entry can be deleted at at time when using %s mode", md)
+		// use stdin for confirmation unless "yes" flag has been sent
+		var confirmation io.Reader
+		if *answerYes {
+			confirmation = &yesReader{}
+		} else {
+			confirmation = os.Stdin
+		}
+
+		err = regression.RegressDelete(md.Output, confirmation, flt)
+		if err != nil {
+			return err
 		}
 
 	case "ADD":
@@ -673,11 +782,13 @@ func regressAdd(md *modalflag.Modes) error {
 	md.NewMode()
 
 	mapping := md.AddString("mapping", "AUTO", "force use of cartridge mapping")
-	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL [cartridge args only]")
+	spec := md.AddString("tv", "AUTO", "television specification: NTSC, PAL, PAL-M, PAL60, SECAM [cartridge args only]")
 	numframes := md.AddInt("frames", 10, "number of frames to run [cartridge args only]")
 	state := md.AddBool("state", false, "record TV state at every CPU step [cartrdige args only]")
-	mode := md.AddString("mode", "video", "type of digest to create [cartridge args only]")
+	mode := md.AddString("mode", "video", "type of digest to create: video, audio or both [cartridge args only]")
+	refFrames := md.AddString("pngframes", "", "comma separated list of frames to store as reference images [cartridge args only]")
 	notes := md.AddString("notes", "", "annotation for the database")
+	tags := md.AddString("tags", "", "comma separated list of tags for the database")
 
 	md.AdditionalHelp("The regression test to be added can be the path to a cartrige file or a previously recorded playback file. For playback files, the flags marked [cartridge args only] do not make sense and will be ignored.")
 
@@ -695,7 +806,7 @@ func regressAdd(md *modalflag.Modes) error {
 		if recorder.IsPlaybackFile(md.GetArg(0)) {
 			// check and warn if unneeded arguments have been specified
 			md.Visit(func(flg string) {
-				if flg == "frames" {
+				if flg == "frames" || flg == "pngframes" {
 					fmt.Printf("! ignored %s flag when adding playback entry\n", flg)
 				}
 			})
@@ -703,6 +814,7 @@ func regressAdd(md *modalflag.Modes) error {
 			rec = &regression.PlaybackRegression{
 				Script: md.GetArg(0),
 				Notes:  *notes,
+				Tags:   regression.ParseTags(*tags),
 			}
 		} else {
 			cartload := cartridgeloader.NewLoader(md.GetArg(0), *mapping)
@@ -713,6 +825,12 @@ func regressAdd(md *modalflag.Modes) error {
 				return fmt.Errorf("%v", err)
 			}
 
+			// parse list of reference frames
+			frames, err := regression.ParseFrameList(*refFrames)
+			if err != nil {
+				return fmt.Errorf("%v", err)
+			}
+
 			rec = &regression.DigestRegression{
 				Mode:      m,
 				CartLoad:  cartload,
@@ -720,6 +838,8 @@ func regressAdd(md *modalflag.Modes) error {
 				NumFrames: *numframes,
 				State:     *state,
 				Notes:     *notes,
+				Frames:    frames,
+				Tags:      regression.ParseTags(*tags),
 			}
 		}
 
@@ -179,7 +179,10 @@ func (scr *SdlDebug) Service() {
 				x := float32(mx) / float32(w)
 				y := float32(my) / float32(h)
 
-				scr.events <- gui.EventMouseMotion{X: x, Y: y}
+				dx := float32(mx-scr.mx) / float32(w)
+				dy := float32(my-scr.my) / float32(h)
+
+				scr.events <- gui.EventMouseMotion{X: x, Y: y, DX: dx, DY: dy}
 				scr.mx = mx
 				scr.my = my
 			}
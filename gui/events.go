@@ -47,6 +47,11 @@ type EventMouseMotion struct {
 	// as a fraction of the window's dimensions
 	X float32
 	Y float32
+
+	// movement since the previous event, also as a fraction of the window's
+	// dimensions
+	DX float32
+	DY float32
 }
 
 // MouseButton identifies the mouse button
@@ -70,6 +70,11 @@ const (
 	// triggered when cartridge is being change
 	ReqChangingCartridge FeatureReq = "ReqChangingCartridge" // bool
 
+	// save the current television image to a file. the image is captured as
+	// it is at the moment of the request, which may be part way through a
+	// frame
+	ReqScreenshot FeatureReq = "ReqScreenshot" // gui.Screenshot
+
 	// ------------------------------------------------------
 	// the following requests are deprecated
 	ReqSetVisibleOnStable FeatureReq = "ReqSetVisibleOnStable" // none
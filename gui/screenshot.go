@@ -0,0 +1,61 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Screenshot is the argument to the ReqScreenshot request. Screenshots are
+// saved as PNG files.
+type Screenshot struct {
+	Filename string
+
+	// use debug colors rather than the colors of the television image
+	DebugColors bool
+
+	// draw the current overlay on top of the image
+	Overlay bool
+
+	// capture the entire television image rather than just the visible area
+	Full bool
+}
+
+// ScreenshotFilename returns a unique filename for a screenshot, based on the
+// name of the cartridge and the current time. If more than one screenshot is
+// taken in the same second then a sequence number is added to the filename.
+func ScreenshotFilename(cartFilename string) string {
+	shortName := strings.TrimSuffix(path.Base(cartFilename), path.Ext(cartFilename))
+
+	n := time.Now()
+	fn := fmt.Sprintf("screenshot_%s_%s", shortName,
+		fmt.Sprintf("%04d%02d%02d_%02d%02d%02d",
+			n.Year(), n.Month(), n.Day(), n.Hour(), n.Minute(), n.Second()))
+
+	unique := fmt.Sprintf("%s.png", fn)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(unique); os.IsNotExist(err) {
+			break
+		}
+		unique = fmt.Sprintf("%s_%d.png", fn, i)
+	}
+
+	return unique
+}
@@ -0,0 +1,166 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/reflection"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// the width of each television pixel in the saved image. this is the same
+// value as used by the SDL GUI so screenshots look the same whichever way they
+// were taken.
+const screenshotPixelWidth = 2
+
+// ScreenshotRenderer implements the television.PixelRenderer interface and can
+// be used to save screenshots when the GUI does not support the ReqScreenshot
+// request. For example, when the debugger is running headless.
+//
+// ScreenshotRenderer also implements the reflection.Renderer interface. If it
+// is used as the renderer for a reflection.Monitor then screenshots can be
+// saved using debug colors.
+type ScreenshotRenderer struct {
+	// the entire television image and the debug color equivalent
+	pixels      *image.RGBA
+	debugPixels *image.RGBA
+
+	// the visible area of the television image
+	crop image.Rectangle
+
+	// the most recent pixel set by SetPixel(). used to associate reflection
+	// information with the pixel
+	lastX int
+	lastY int
+
+	// whether Reflect() has been called
+	reflecting bool
+}
+
+// NewScreenshotRenderer is the preferred method of initialisation for the
+// ScreenshotRenderer type. The new instance is added to the television.
+func NewScreenshotRenderer(tv television.Television) *ScreenshotRenderer {
+	shot := &ScreenshotRenderer{}
+
+	spec, _ := tv.GetSpec()
+	shot.allocate(spec, spec.ScanlineTop, spec.ScanlineBottom-spec.ScanlineTop)
+
+	tv.AddPixelRenderer(shot)
+
+	return shot
+}
+
+func (shot *ScreenshotRenderer) allocate(spec *television.Specification, topScanline int, visibleScanlines int) {
+	shot.pixels = image.NewRGBA(image.Rect(0, 0, television.HorizClksScanline, spec.ScanlinesTotal))
+	shot.debugPixels = image.NewRGBA(image.Rect(0, 0, television.HorizClksScanline, spec.ScanlinesTotal))
+	shot.crop = image.Rect(television.HorizClksHBlank, topScanline,
+		television.HorizClksHBlank+television.HorizClksVisible, topScanline+visibleScanlines)
+}
+
+// Resize implements the television.PixelRenderer interface
+func (shot *ScreenshotRenderer) Resize(spec *television.Specification, topScanline int, visibleScanlines int) error {
+	shot.allocate(spec, topScanline, visibleScanlines)
+	return nil
+}
+
+// NewFrame implements the television.PixelRenderer interface
+func (shot *ScreenshotRenderer) NewFrame(_ int, _ bool) error {
+	return nil
+}
+
+// NewScanline implements the television.PixelRenderer interface
+func (shot *ScreenshotRenderer) NewScanline(_ int) error {
+	return nil
+}
+
+// SetPixel implements the television.PixelRenderer interface
+func (shot *ScreenshotRenderer) SetPixel(x int, y int, red byte, green byte, blue byte, vblank bool) error {
+	if vblank {
+		red, green, blue = 0, 0, 0
+	}
+	shot.lastX = x
+	shot.lastY = y
+	shot.pixels.SetRGBA(x, y, color.RGBA{R: red, G: green, B: blue, A: 255})
+	return nil
+}
+
+// EndRendering implements the television.PixelRenderer interface
+func (shot *ScreenshotRenderer) EndRendering() error {
+	return nil
+}
+
+// Reflect implements the reflection.Renderer interface
+func (shot *ScreenshotRenderer) Reflect(ref reflection.LastResult) error {
+	shot.reflecting = true
+	shot.debugPixels.SetRGBA(shot.lastX, shot.lastY, reflection.PaletteElements[ref.VideoElement])
+	return nil
+}
+
+// Save the television image to a PNG file. As with the SDL GUI, the image is
+// saved exactly as it is at this moment in the emulation, which may be part
+// way through a frame.
+//
+// The Overlay option is not supported because the overlay is a property of the
+// GUI. Debug colors are only supported if the ScreenshotRenderer is being used
+// as a reflection.Renderer.
+func (shot *ScreenshotRenderer) Save(req Screenshot) error {
+	if req.Overlay {
+		return errors.New(errors.Screenshot, "overlay not available without a GUI")
+	}
+
+	src := shot.pixels
+	if req.DebugColors {
+		if !shot.reflecting {
+			return errors.New(errors.Screenshot, "debug colors not available")
+		}
+		src = shot.debugPixels
+	}
+
+	r := src.Bounds()
+	if !req.Full {
+		r = shot.crop
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, r.Dx()*screenshotPixelWidth, r.Dy()))
+
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			c := src.RGBAAt(r.Min.X+x, r.Min.Y+y)
+			c.A = 255
+			for i := 0; i < screenshotPixelWidth; i++ {
+				img.SetRGBA(x*screenshotPixelWidth+i, y, c)
+			}
+		}
+	}
+
+	f, err := os.Create(req.Filename)
+	if err != nil {
+		return errors.New(errors.Screenshot, err)
+	}
+	defer f.Close()
+
+	err = png.Encode(f, img)
+	if err != nil {
+		return errors.New(errors.Screenshot, err)
+	}
+
+	return nil
+}
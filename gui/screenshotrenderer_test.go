@@ -0,0 +1,117 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package gui_test
+
+import (
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/gui"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+func TestScreenshotRenderer(t *testing.T) {
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	dir, err := ioutil.TempDir("", "screenshot")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	shot := gui.NewScreenshotRenderer(tv)
+
+	// a single red pixel at the top-left of the visible area and a green pixel
+	// in the vblank area above it. the vblank pixel should be saved as black
+	err = shot.Resize(television.SpecNTSC, 40, 192)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_ = shot.SetPixel(television.HorizClksHBlank, 40, 255, 0, 0, false)
+	_ = shot.SetPixel(television.HorizClksHBlank, 39, 0, 255, 0, true)
+
+	// visible area only
+	fn := filepath.Join(dir, "visible.png")
+	err = shot.Save(gui.Screenshot{Filename: fn})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	img, err := png.Decode(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if img.Bounds().Dx() != television.HorizClksVisible*2 || img.Bounds().Dy() != 192 {
+		t.Errorf("unexpected image size (%v)", img.Bounds())
+	}
+
+	red := color.RGBA{R: 255, A: 255}
+	for x := 0; x < 2; x++ {
+		if c := color.RGBAModel.Convert(img.At(x, 0)); c != red {
+			t.Errorf("unexpected color at %d,0 (%v)", x, c)
+		}
+	}
+
+	// entire television image
+	fn = filepath.Join(dir, "full.png")
+	err = shot.Save(gui.Screenshot{Filename: fn, Full: true})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	f, err = os.Open(fn)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	img, err = png.Decode(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if img.Bounds().Dx() != television.HorizClksScanline*2 || img.Bounds().Dy() != television.SpecNTSC.ScanlinesTotal {
+		t.Errorf("unexpected image size (%v)", img.Bounds())
+	}
+
+	black := color.RGBA{A: 255}
+	if c := color.RGBAModel.Convert(img.At(television.HorizClksHBlank*2, 39)); c != black {
+		t.Errorf("vblank pixel should be black (%v)", c)
+	}
+
+	// debug colors and overlays are not available without reflection and a
+	// GUI respectively
+	err = shot.Save(gui.Screenshot{Filename: filepath.Join(dir, "debug.png"), DebugColors: true})
+	if err == nil {
+		t.Errorf("debug colors should not be available without reflection")
+	}
+	err = shot.Save(gui.Screenshot{Filename: filepath.Join(dir, "overlay.png"), Overlay: true})
+	if err == nil {
+		t.Errorf("overlay should not be available without a GUI")
+	}
+}
@@ -97,9 +97,10 @@ type imguiColors struct {
 	TermStyleError      imgui.Vec4
 	TermStyleLog        imgui.Vec4
 
-	packedPaletteNTSC packedPalette
-	packedPalettePAL  packedPalette
-	packedPaletteAlt  packedPalette
+	packedPaletteNTSC  packedPalette
+	packedPalettePAL   packedPalette
+	packedPaletteSECAM packedPalette
+	packedPaletteAlt   packedPalette
 }
 
 func newColors() *imguiColors {
@@ -210,6 +211,17 @@ func newColors() *imguiColors {
 		vec4PalettePAL = append(vec4PalettePAL, v)
 	}
 
+	vec4PaletteSECAM := make([]imgui.Vec4, 0, len(television.PaletteSECAM))
+	for _, c := range television.PaletteSECAM {
+		v := imgui.Vec4{
+			float32(c.R) / 255,
+			float32(c.G) / 255,
+			float32(c.B) / 255,
+			1.0,
+		}
+		vec4PaletteSECAM = append(vec4PaletteSECAM, v)
+	}
+
 	vec4PaletteAlt := make([]imgui.Vec4, 0, len(reflection.PaletteElements))
 	for _, c := range reflection.PaletteElements {
 		v := imgui.Vec4{
@@ -232,6 +244,11 @@ func newColors() *imguiColors {
 		cols.packedPalettePAL = append(cols.packedPalettePAL, imgui.PackedColorFromVec4(c))
 	}
 
+	cols.packedPaletteSECAM = make(packedPalette, 0, len(vec4PaletteSECAM))
+	for _, c := range vec4PaletteSECAM {
+		cols.packedPaletteSECAM = append(cols.packedPaletteSECAM, imgui.PackedColorFromVec4(c))
+	}
+
 	cols.packedPaletteAlt = make(packedPalette, 0, len(vec4PaletteAlt))
 	for _, c := range vec4PaletteAlt {
 		cols.packedPaletteAlt = append(cols.packedPaletteAlt, imgui.PackedColorFromVec4(c))
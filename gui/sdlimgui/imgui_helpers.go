@@ -144,8 +144,10 @@ func (img *SdlImgui) imguiWindowQuadrant(p imgui.Vec2) imgui.Vec2 {
 // use appropriate palette for television spec
 func (img *SdlImgui) imguiTVPalette() (string, packedPalette) {
 	switch img.lz.TV.Spec.ID {
-	case "PAL":
-		return "PAL", img.cols.packedPalettePAL
+	case "PAL", "PAL-M", "PAL60":
+		return img.lz.TV.Spec.ID, img.cols.packedPalettePAL
+	case "SECAM":
+		return "SECAM", img.cols.packedPaletteSECAM
 	case "NTSC":
 		return "NTSC", img.cols.packedPaletteNTSC
 	}
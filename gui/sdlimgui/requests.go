@@ -96,6 +96,9 @@ func (img *SdlImgui) serviceFeatureRequests(request featureRequest) {
 	case gui.ReqSavePrefs:
 		err = img.prefs.Save()
 
+	case gui.ReqScreenshot:
+		err = img.screen.screenshot(request.args[0].(gui.Screenshot))
+
 	case gui.ReqChangingCartridge:
 		// a new cartridge requires us to reset the lazy system (see the
 		// lazyvalues.Reset() function commentary for why)
@@ -0,0 +1,86 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package sdlimgui
+
+import (
+	"image"
+	"image/png"
+	"os"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/gui"
+)
+
+// screenshot saves the screen image to a PNG file. the image is taken from the
+// backing pixels (or debug pixels) so that the image is exactly as it is at
+// this moment in the emulation, which may be part way through a frame.
+func (scr *screen) screenshot(req gui.Screenshot) error {
+	scr.crit.section.Lock()
+
+	src := scr.crit.backingPixels[scr.crit.backingPixelsCurrent]
+	if req.DebugColors {
+		src = scr.crit.debugPixels
+	}
+
+	r := src.Bounds()
+	if !req.Full {
+		r = scr.crit.cropPixels.Bounds()
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, r.Dx()*pixelWidth, r.Dy()))
+
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			c := src.RGBAAt(r.Min.X+x, r.Min.Y+y)
+			c.A = 255
+
+			// overlay colors are not premultiplied so we can't use the
+			// image/draw package to blend them
+			if req.Overlay {
+				o := scr.crit.overlayPixels.RGBAAt(r.Min.X+x, r.Min.Y+y)
+				if o.A > 0 {
+					c.R = blend(c.R, o.R, o.A)
+					c.G = blend(c.G, o.G, o.A)
+					c.B = blend(c.B, o.B, o.A)
+				}
+			}
+
+			for i := 0; i < pixelWidth; i++ {
+				img.SetRGBA(x*pixelWidth+i, y, c)
+			}
+		}
+	}
+
+	scr.crit.section.Unlock()
+
+	f, err := os.Create(req.Filename)
+	if err != nil {
+		return errors.New(errors.Screenshot, err)
+	}
+	defer f.Close()
+
+	err = png.Encode(f, img)
+	if err != nil {
+		return errors.New(errors.Screenshot, err)
+	}
+
+	return nil
+}
+
+// blend color component b over a with an alpha value of alpha
+func blend(a uint8, b uint8, alpha uint8) uint8 {
+	return uint8((uint16(a)*uint16(255-alpha) + uint16(b)*uint16(alpha)) / 255)
+}
@@ -132,6 +132,9 @@ func (img *SdlImgui) Service() {
 							sdl.ShowCursor(sdl.ENABLE)
 						}
 					}
+
+				case sdl.BUTTON_MIDDLE:
+					button = gui.MouseButtonMiddle
 				}
 
 				if img.isCaptured() {
@@ -171,7 +174,10 @@ func (img *SdlImgui) Service() {
 				x := float32(mx) / float32(w)
 				y := float32(my) / float32(h)
 
-				img.events <- gui.EventMouseMotion{X: x, Y: y}
+				dx := float32(mx-img.mx) / float32(w)
+				dy := float32(my-img.my) / float32(h)
+
+				img.events <- gui.EventMouseMotion{X: x, Y: y, DX: dx, DY: dy}
 				img.mx = mx
 				img.my = my
 			}
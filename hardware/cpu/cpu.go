@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"time"
 
 	"github.com/jetsetilly/gopher2600/errors"
 	"github.com/jetsetilly/gopher2600/hardware/cpu/execution"
@@ -45,6 +46,9 @@ type CPU struct {
 	mem          bus.CPUBus
 	instructions []*instructions.Definition
 
+	// the source of random numbers when the CPU is reset to a random state
+	rand *rand.Rand
+
 	// cycleCallback is called by endCycle() for additional emulator
 	// functionality
 	cycleCallback func() error
@@ -78,7 +82,10 @@ type CPU struct {
 // NewCPU is the preferred method of initialisation for the CPU structure. Note
 // that the CPU will be initialised in a random state.
 func NewCPU(mem bus.CPUBus) (*CPU, error) {
-	mc := &CPU{mem: mem}
+	mc := &CPU{
+		mem:  mem,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
 
 	mc.PC = registers.NewProgramCounter(0)
 	mc.A = registers.NewRegister(0, "A")
@@ -100,6 +107,12 @@ func NewCPU(mem bus.CPUBus) (*CPU, error) {
 	return mc, mc.Reset(true)
 }
 
+// SetRandSource sets the source of random numbers used when the CPU is reset
+// to a random state. By default the source is seeded with the current time.
+func (mc *CPU) SetRandSource(rnd *rand.Rand) {
+	mc.rand = rnd
+}
+
 func (mc *CPU) String() string {
 	return fmt.Sprintf("%s=%s %s=%s %s=%s %s=%s %s=%s %s=%s",
 		mc.PC.Label(), mc.PC, mc.A.Label(), mc.A,
@@ -113,12 +126,12 @@ func (mc *CPU) Reset(randomState bool) error {
 	mc.LastResult.Final = true
 
 	if randomState {
-		mc.PC.Load(uint16(rand.Intn(0xffff)))
-		mc.A.Load(uint8(rand.Intn(0xff)))
-		mc.X.Load(uint8(rand.Intn(0xff)))
-		mc.Y.Load(uint8(rand.Intn(0xff)))
-		mc.SP.Load(uint8(rand.Intn(0xff)))
-		mc.Status.FromValue(uint8(rand.Intn(0xff)))
+		mc.PC.Load(uint16(mc.rand.Intn(0xffff)))
+		mc.A.Load(uint8(mc.rand.Intn(0xff)))
+		mc.X.Load(uint8(mc.rand.Intn(0xff)))
+		mc.Y.Load(uint8(mc.rand.Intn(0xff)))
+		mc.SP.Load(uint8(mc.rand.Intn(0xff)))
+		mc.Status.FromValue(uint8(mc.rand.Intn(0xff)))
 	} else {
 		mc.PC.Load(0)
 		mc.A.Load(0)
@@ -143,6 +156,162 @@ func (mc CPU) HasReset() bool {
 	return mc.LastResult.Address == 0 && mc.LastResult.Defn == nil
 }
 
+// Snapshot creates a copy of the CPU in its current state. The copy should not
+// be used for emulation. Use it as an argument to Restore() only.
+func (mc *CPU) Snapshot() *CPU {
+	n := *mc
+
+	pc := *mc.PC
+	a := *mc.A
+	x := *mc.X
+	y := *mc.Y
+	sp := *mc.SP
+	status := *mc.Status
+	acc8 := *mc.acc8
+	acc16 := *mc.acc16
+
+	n.PC = &pc
+	n.A = &a
+	n.X = &x
+	n.Y = &y
+	n.SP = &sp
+	n.Status = &status
+	n.acc8 = &acc8
+	n.acc16 = &acc16
+
+	return &n
+}
+
+// Restore the state of the CPU from a snapshot previously created with
+// Snapshot(). The registers are updated in place so that any existing
+// references to them remain valid.
+func (mc *CPU) Restore(s *CPU) {
+	*mc.PC = *s.PC
+	*mc.A = *s.A
+	*mc.X = *s.X
+	*mc.Y = *s.Y
+	*mc.SP = *s.SP
+	*mc.Status = *s.Status
+	*mc.acc8 = *s.acc8
+	*mc.acc16 = *s.acc16
+
+	mc.RdyFlg = s.RdyFlg
+	mc.LastResult = s.LastResult
+	mc.Interrupted = s.Interrupted
+
+	// not touching NoFlowControl or the cycleCallback
+}
+
+// the version of the SavedCPU type. increase this whenever the SavedCPU type
+// changes
+const savedCPUVersion = 1
+
+// SavedCPU is the state of the CPU as returned by SaveState()
+type SavedCPU struct {
+	Version     int
+	PC          uint16
+	A           uint8
+	X           uint8
+	Y           uint8
+	SP          uint8
+	Status      registers.StatusRegister
+	Acc8        uint8
+	Acc16       uint16
+	RdyFlg      bool
+	Interrupted bool
+
+	// the instruction definition in LastResult is a reference to an entry in
+	// the instruction table. the opcode is saved instead. a value of -1
+	// indicates that there is no definition
+	LastResultOpCode          int
+	LastResultByteCount       int
+	LastResultAddress         uint16
+	LastResultInstructionData uint16
+	LastResultActualCycles    int
+	LastResultPageFault       bool
+	LastResultCPUBug          string
+	LastResultError           string
+	LastResultBranchSuccess   bool
+	LastResultFinal           bool
+}
+
+// SaveState returns the current state of the CPU
+func (mc *CPU) SaveState() SavedCPU {
+	s := SavedCPU{
+		Version:     savedCPUVersion,
+		PC:          mc.PC.Value(),
+		A:           mc.A.Value(),
+		X:           mc.X.Value(),
+		Y:           mc.Y.Value(),
+		SP:          mc.SP.Value(),
+		Status:      *mc.Status,
+		Acc8:        mc.acc8.Value(),
+		Acc16:       mc.acc16.Value(),
+		RdyFlg:      mc.RdyFlg,
+		Interrupted: mc.Interrupted,
+
+		LastResultOpCode:          -1,
+		LastResultByteCount:       mc.LastResult.ByteCount,
+		LastResultAddress:         mc.LastResult.Address,
+		LastResultInstructionData: mc.LastResult.InstructionData,
+		LastResultActualCycles:    mc.LastResult.ActualCycles,
+		LastResultPageFault:       mc.LastResult.PageFault,
+		LastResultCPUBug:          mc.LastResult.CPUBug,
+		LastResultError:           mc.LastResult.Error,
+		LastResultBranchSuccess:   mc.LastResult.BranchSuccess,
+		LastResultFinal:           mc.LastResult.Final,
+	}
+
+	if mc.LastResult.Defn != nil {
+		s.LastResultOpCode = int(mc.LastResult.Defn.OpCode)
+	}
+
+	return s
+}
+
+// LoadState sets the CPU to a state previously returned by SaveState(). It
+// should only be called on a snapshot and never on the live emulation. Use
+// Restore() to apply the snapshot.
+func (mc *CPU) LoadState(s SavedCPU) error {
+	if s.Version != savedCPUVersion {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unsupported CPU state version (%d)", s.Version))
+	}
+
+	var defn *instructions.Definition
+	if s.LastResultOpCode != -1 {
+		if s.LastResultOpCode < 0 || s.LastResultOpCode >= len(mc.instructions) {
+			return errors.New(errors.SnapshotError, "invalid opcode in CPU state")
+		}
+		defn = mc.instructions[s.LastResultOpCode]
+	}
+
+	mc.PC.Load(s.PC)
+	mc.A.Load(s.A)
+	mc.X.Load(s.X)
+	mc.Y.Load(s.Y)
+	mc.SP.Load(s.SP)
+	*mc.Status = s.Status
+	mc.acc8.Load(s.Acc8)
+	mc.acc16.Load(s.Acc16)
+	mc.RdyFlg = s.RdyFlg
+	mc.Interrupted = s.Interrupted
+
+	mc.LastResult = execution.Result{
+		Defn:            defn,
+		ByteCount:       s.LastResultByteCount,
+		Address:         s.LastResultAddress,
+		InstructionData: s.LastResultInstructionData,
+		ActualCycles:    s.LastResultActualCycles,
+		PageFault:       s.LastResultPageFault,
+		CPUBug:          s.LastResultCPUBug,
+		Error:           s.LastResultError,
+		BranchSuccess:   s.LastResultBranchSuccess,
+		Final:           s.LastResultFinal,
+	}
+
+	return nil
+}
+
 // LoadPCIndirect loads the contents of indirectAddress into the PC
 func (mc *CPU) LoadPCIndirect(indirectAddress uint16) error {
 	if !mc.LastResult.Final && !mc.Interrupted {
@@ -16,6 +16,8 @@
 package cartridge
 
 import (
+	"bytes"
+	"encoding/gob"
 	"fmt"
 	"strings"
 
@@ -176,14 +178,47 @@ func (cart *Cartridge) Attach(cartload cartridgeloader.Loader) error {
 		cart.mapper, err = newParkerBros(data)
 	case "E7":
 		cart.mapper, err = newMnetwork(data)
+	case "E78K":
+		cart.mapper, err = newMnetwork8k(data)
 	case "3F":
 		cart.mapper, err = newTigervision(data)
+	case "3E":
+		cart.mapper, err = new3e(data)
+	case "4A50":
+		cart.mapper, err = new4a50(data)
 	case "AR":
 		cart.mapper, err = supercharger.NewSupercharger(data)
 	case "DPC":
 		cart.mapper, err = newDPC(data)
+	case "0840":
+		cart.mapper, err = newEconobanking(data)
+	case "UA":
+		cart.mapper, err = newUALimited(data)
+	case "SB":
+		cart.mapper, err = newSuperbank(data)
+	case "X07":
+		cart.mapper, err = newX07(data)
+	case "EF":
+		cart.mapper, err = newAtari64k(data)
+	case "EFSC":
+		cart.mapper, err = newAtari64k(data)
+		addSuperchip = true
+	case "DF":
+		cart.mapper, err = newAtari128k(data)
+	case "DFSC":
+		cart.mapper, err = newAtari128k(data)
+		addSuperchip = true
+	case "BF":
+		cart.mapper, err = newAtari256k(data)
+	case "BFSC":
+		cart.mapper, err = newAtari256k(data)
+		addSuperchip = true
 	case "DPC+":
 		cart.mapper, err = harmony.NewDPCplus(data)
+	case "CDF":
+		fallthrough
+	case "CDFJ":
+		cart.mapper, err = harmony.NewCDF(data)
 	}
 
 	if addSuperchip {
@@ -266,6 +301,103 @@ func (cart Cartridge) GetRAMbus() bus.CartRAMbus {
 	return nil
 }
 
+// Snapshot creates a copy of the cartridge in its current state, including
+// the state of the mapper
+func (cart *Cartridge) Snapshot() *Cartridge {
+	n := *cart
+	n.mapper = cart.mapper.Snapshot().(cartMapper)
+	return &n
+}
+
+// Restore the state of the cartridge from a snapshot previously created with
+// Snapshot(). The snapshot must have been taken from the same cartridge.
+func (cart *Cartridge) Restore(s *Cartridge) error {
+	if cart.Hash != s.Hash || cart.mapper.ID() != s.mapper.ID() {
+		return errors.New(errors.SnapshotError, "snapshot is of a different cartridge")
+	}
+	return cart.mapper.Restore(s.mapper)
+}
+
+// the version of the SavedCartridge type. increase this whenever the
+// SavedCartridge type, or the saved state of any mapper, changes
+const savedCartridgeVersion = 1
+
+// SavedCartridge is the state of the cartridge as returned by SaveState()
+type SavedCartridge struct {
+	Version int
+	Hash    string
+	Mapping string
+
+	// the state of the mapper, encoded with the encoding/gob package. nil if
+	// the mapper has no state
+	Mapper []byte
+}
+
+// SaveState returns the current state of the cartridge
+func (cart *Cartridge) SaveState() (SavedCartridge, error) {
+	s := SavedCartridge{
+		Version: savedCartridgeVersion,
+		Hash:    cart.Hash,
+		Mapping: cart.mapper.ID(),
+	}
+
+	if m := cart.mapper.SaveState(); m != nil {
+		var b bytes.Buffer
+		if err := gob.NewEncoder(&b).Encode(m); err != nil {
+			return SavedCartridge{}, errors.New(errors.SnapshotError, err)
+		}
+		s.Mapper = b.Bytes()
+	}
+
+	return s, nil
+}
+
+// LoadState sets the cartridge to a state previously returned by
+// SaveState(). The state must be of the cartridge currently attached. It
+// should only be called on a snapshot and never on the live emulation. Use
+// Restore() to apply the snapshot.
+func (cart *Cartridge) LoadState(s SavedCartridge) error {
+	if s.Version != savedCartridgeVersion {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unsupported cartridge state version (%d)", s.Version))
+	}
+
+	if s.Hash != cart.Hash {
+		return errors.New(errors.SnapshotError, "state is for a different cartridge")
+	}
+
+	if s.Mapping != cart.mapper.ID() {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("state is for a different mapper (%s)", s.Mapping))
+	}
+
+	dec := gob.NewDecoder(bytes.NewReader(s.Mapper))
+	err := cart.mapper.LoadState(func(v interface{}) error {
+		if err := dec.Decode(v); err != nil {
+			return errors.New(errors.SnapshotError, err)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// loadRAM copies saved RAM into the RAM of a mapper. the saved RAM must be the
+// same size as the RAM it is being copied into
+func loadRAM(ram []uint8, saved []uint8) error {
+	if len(ram) != len(saved) {
+		return errors.New(errors.SnapshotError, "cartridge RAM state is the wrong size")
+	}
+	copy(ram, saved)
+	return nil
+}
+
+// validBank returns an error if the bank number is not in the range 0 to n-1
+func validBank(bank int, n int) error {
+	if bank < 0 || bank >= n {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("invalid bank in cartridge state (%d)", bank))
+	}
+	return nil
+}
+
 // IterateBanks returns the sequence of banks in a cartridge. To return the
 // next bank in the sequence, call the function with the instance of
 // banks.Content returned from the previous call. The end of the sequence is
@@ -0,0 +1,196 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/harmony"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/supercharger"
+)
+
+// Candidate is a possible mapping for cartridge data, as reported by Detect().
+type Candidate struct {
+	// the mapping ID. the same as used in the Mapping field of
+	// cartridgeloader.Loader
+	Mapping string
+
+	// the score is in the range 0 to 100. a score of 100 means that the
+	// evidence meets the threshold used by automatic fingerprinting
+	Score int
+
+	// description of the evidence found in the cartridge data
+	Evidence []string
+}
+
+// Detection is the result of the Detect() function.
+type Detection struct {
+	// the size of the cartridge data
+	Size int
+
+	// the mapping chosen by automatic fingerprinting. if the mapper could not
+	// be created then Err will describe why
+	Chosen string
+	Err    error
+
+	// every candidate mapping, ordered by score. the highest scoring candidate
+	// is first
+	Candidates []Candidate
+}
+
+func (d Detection) String() string {
+	s := strings.Builder{}
+	s.WriteString(fmt.Sprintf("size: %d bytes\n", d.Size))
+	if d.Err != nil {
+		s.WriteString(fmt.Sprintf("chosen: none (%v)\n", d.Err))
+	} else {
+		s.WriteString(fmt.Sprintf("chosen: %s\n", d.Chosen))
+	}
+	for _, c := range d.Candidates {
+		s.WriteString(fmt.Sprintf("%-5s %3d%%\n", c.Mapping, c.Score))
+		for _, e := range c.Evidence {
+			s.WriteString(fmt.Sprintf("      %s\n", e))
+		}
+	}
+	return s.String()
+}
+
+// the score given to a candidate for which the only evidence is the size of
+// the data
+const sizeOnlyScore = 50
+
+// Detect examines the cartridge data and returns every candidate mapping,
+// along with the evidence that suggests the mapping. The mapping that would be
+// chosen by automatic fingerprinting is also returned.
+//
+// Candidates are only included if the size of the data is suitable for the
+// mapping. Candidates for which the size is the only evidence will have a
+// score of 50.
+//
+// Note that the FE mapping is not yet supported by the emulation so it is
+// never chosen even if it is the highest scoring candidate.
+func Detect(data []byte) Detection {
+	d := Detection{Size: len(data)}
+
+	add := func(mapping string, e evidence) {
+		if e.score == 0 {
+			return
+		}
+
+		// the same mapping may be suggested by more than one fingerprint. we
+		// keep the highest score and all the evidence
+		for i := range d.Candidates {
+			if d.Candidates[i].Mapping == mapping {
+				if e.score > d.Candidates[i].Score {
+					d.Candidates[i].Score = e.score
+				}
+				d.Candidates[i].Evidence = append(d.Candidates[i].Evidence, e.notes...)
+				return
+			}
+		}
+
+		d.Candidates = append(d.Candidates, Candidate{
+			Mapping:  mapping,
+			Score:    e.score,
+			Evidence: e.notes,
+		})
+	}
+
+	size := func(mapping string, sizes ...int) {
+		for _, s := range sizes {
+			if len(data) == s {
+				add(mapping, evidence{score: sizeOnlyScore, notes: []string{fmt.Sprintf("size is %d bytes", s)}})
+				return
+			}
+		}
+	}
+
+	// harmony based cartridges
+	if e := fingerprintHarmony(data); e.ok() {
+		if harmony.FingerprintCDF(data) {
+			e.notes = append(e.notes, "CDF signature in driver")
+			add("CDF", e)
+		} else {
+			add("DPC+", e)
+		}
+	}
+
+	// supercharger
+	add("AR", fingerprintSuperchargerFastLoad(data))
+	add("AR", evidenceBool(supercharger.FingerprintSoundLoad(data), "sound file"))
+
+	// formats identified by signatures. mappings with 2k or 1k banks can be
+	// any multiple of those sizes
+	if len(data)%2048 == 0 {
+		add("3F", fingerprintTigervision(data))
+		add("3E", fingerprint3e(data))
+	}
+	if len(data)%1024 == 0 {
+		add("3E+", fingerprint3ePlus(data))
+	}
+
+	switch len(data) {
+	case 8192:
+		add("E0", fingerprintParkerBros(data))
+		add("E78K", fingerprintMnetwork8k(data))
+		add("FE", fingerprintFE(data))
+		add("0840", fingerprintEconobanking(data))
+		add("UA", fingerprintUA(data))
+	case 16384:
+		add("E7", fingerprintMnetwork(data))
+	case 65536:
+		add("EF", fingerprintEF(data))
+		add("X07", fingerprintX07(data))
+	case 131072:
+		add("DF", fingerprintExtendedAtari(data, "DF"))
+		add("SB", fingerprintSuperbank(data))
+	case 262144:
+		add("BF", fingerprintExtendedAtari(data, "BF"))
+		add("SB", fingerprintSuperbank(data))
+	}
+
+	switch len(data) {
+	case 32768, 65536, 131072:
+		add("4A50", fingerprint4A50(data))
+	}
+
+	// formats identified by size alone
+	size("2k", 2048)
+	size("4k", 4096)
+	size("F8", 8192)
+	size("DPC", 10240, 10495)
+	size("FA", 12288)
+	size("F6", 16384)
+	size("F4", 32768)
+	size("EF", 65536)
+	size("DF", 131072)
+	size("BF", 262144)
+
+	sort.SliceStable(d.Candidates, func(i, j int) bool {
+		return d.Candidates[i].Score > d.Candidates[j].Score
+	})
+
+	// the mapping that would be chosen by automatic fingerprinting
+	cart := &Cartridge{}
+	d.Err = cart.fingerprint(data)
+	if d.Err == nil {
+		d.Chosen = cart.mapper.ID()
+	}
+
+	return d
+}
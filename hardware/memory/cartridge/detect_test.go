@@ -0,0 +1,236 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	// image returns cartridge data of the specified size. the data is filled
+	// with a value that doesn't occur in any signature so that the data
+	// doesn't trigger the superchip detection
+	image := func(size int, f func(b []byte)) []byte {
+		b := make([]byte, size)
+		for i := range b {
+			b[i] = byte(i) | 0x01
+		}
+		if f != nil {
+			f(b)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		top    string
+		score  int
+		chosen string
+	}{
+		{
+			name:   "2k",
+			data:   image(2048, nil),
+			top:    "2k",
+			score:  sizeOnlyScore,
+			chosen: "2k",
+		},
+		{
+			name:   "4k",
+			data:   image(4096, nil),
+			top:    "4k",
+			score:  sizeOnlyScore,
+			chosen: "4k",
+		},
+		{
+			name:   "F8",
+			data:   image(8192, nil),
+			top:    "F8",
+			score:  sizeOnlyScore,
+			chosen: "F8",
+		},
+		{
+			name: "3F",
+			data: image(8192, func(b []byte) {
+				scatter(b, []byte{0x85, 0x3f}, 8)
+			}),
+			top:    "3F",
+			score:  100,
+			chosen: "3F",
+		},
+		{
+			name: "3E",
+			data: image(8192, func(b []byte) {
+				scatter(b, []byte{0x85, 0x3e, 0xa9, 0x00, 0x85, 0x3f}, 2)
+			}),
+			top:    "3E",
+			score:  100,
+			chosen: "3E",
+		},
+		{
+			name: "3E+",
+			data: image(8192, func(b []byte) {
+				copy(b[0x200:], []byte("TJ3E"))
+			}),
+			top:    "3E+",
+			score:  100,
+			chosen: "3E+",
+		},
+		{
+			name: "E0",
+			data: image(8192, func(b []byte) {
+				scatter(b, []byte{0x8d, 0xe0, 0x1f}, 1)
+			}),
+			top:    "E0",
+			score:  100,
+			chosen: "E0",
+		},
+		{
+			name: "E78K",
+			data: image(8192, func(b []byte) {
+				scatter(b[:4096], []byte{0xad, 0xe4, 0x1f}, 2)
+				scatter(b[4096:], []byte{0xad, 0xe8, 0x1f}, 2)
+			}),
+			top:    "E78K",
+			score:  100,
+			chosen: "E78K",
+		},
+		{
+			// there is no FE mapper so automatic fingerprinting falls back to
+			// the standard 8k mapper
+			name: "FE",
+			data: image(8192, func(b []byte) {
+				scatter(b, []byte{0x20, 0x00, 0xd0, 0xc6, 0xc5}, 1)
+			}),
+			top:    "FE",
+			score:  100,
+			chosen: "F8",
+		},
+		{
+			name: "0840",
+			data: image(8192, func(b []byte) {
+				scatter(b, []byte{0xad, 0x40, 0x08}, 2)
+			}),
+			top:    "0840",
+			score:  100,
+			chosen: "0840",
+		},
+		{
+			name:   "DPC",
+			data:   image(10240, nil),
+			top:    "DPC",
+			score:  sizeOnlyScore,
+			chosen: "DPC",
+		},
+		{
+			name: "E7",
+			data: image(16384, func(b []byte) {
+				scatter(b, []byte{0x7e, 0x66, 0x66, 0x66}, 2)
+			}),
+			top:    "E7",
+			score:  100,
+			chosen: "E7",
+		},
+		{
+			name:   "F4",
+			data:   image(32768, nil),
+			top:    "F4",
+			score:  sizeOnlyScore,
+			chosen: "F4",
+		},
+		{
+			name: "4A50",
+			data: image(32768, func(b []byte) {
+				b[len(b)-6] = 0x50
+				b[len(b)-5] = 0x4a
+			}),
+			top:    "4A50",
+			score:  100,
+			chosen: "4A50",
+		},
+		{
+			name: "EF",
+			data: image(65536, func(b []byte) {
+				copy(b[len(b)-8:], []byte("EFEF"))
+			}),
+			top:    "EF",
+			score:  100,
+			chosen: "EF",
+		},
+		{
+			name: "X07",
+			data: image(65536, func(b []byte) {
+				scatter(b, []byte{0xad, 0x0d, 0x08}, 1)
+			}),
+			top:    "X07",
+			score:  100,
+			chosen: "X07",
+		},
+		{
+			name: "SB",
+			data: image(131072, func(b []byte) {
+				scatter(b, []byte{0xbd, 0x00, 0x08}, 1)
+			}),
+			top:    "SB",
+			score:  100,
+			chosen: "SB",
+		},
+		{
+			name: "BF",
+			data: image(262144, func(b []byte) {
+				copy(b[len(b)-8:], []byte("BFSC"))
+			}),
+			top:    "BF",
+			score:  100,
+			chosen: "BF",
+		},
+	}
+
+	for _, tt := range tests {
+		d := Detect(tt.data)
+
+		if len(d.Candidates) == 0 {
+			t.Errorf("%s: no candidates", tt.name)
+			continue
+		}
+
+		top := d.Candidates[0]
+		if top.Mapping != tt.top {
+			t.Errorf("%s: top candidate is %s (%d%%)", tt.name, top.Mapping, top.Score)
+		}
+		if top.Score != tt.score {
+			t.Errorf("%s: top candidate score is %d (expected %d)", tt.name, top.Score, tt.score)
+		}
+		if len(top.Evidence) == 0 {
+			t.Errorf("%s: top candidate has no evidence", tt.name)
+		}
+
+		if d.Err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, d.Err)
+		} else if d.Chosen != tt.chosen {
+			t.Errorf("%s: chosen mapping is %s (expected %s)", tt.name, d.Chosen, tt.chosen)
+		}
+	}
+
+	// unsupported sizes have no candidates and no chosen mapping
+	d := Detect(image(1000, nil))
+	if len(d.Candidates) != 0 {
+		t.Errorf("unexpected candidates for unsupported size")
+	}
+	if d.Err == nil {
+		t.Errorf("expected error for unsupported size")
+	}
+}
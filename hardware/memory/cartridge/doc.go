@@ -35,11 +35,21 @@
 //	Atari 16k		"F6"
 //	Atari 32k		"F4"
 //	CBS case		"FA"
-//	M-Network		"E7"
+//	M-Network		"E7" (or "E78K" for the 8k variant)
 //	Parker Bros		"E0"
 //	Tigervision		"3F"
+//	3E				"3E"
+//	Supercat		"4A50"
+//	Econobanking	"0840"
+//	UA Ltd			"UA"
+//	Superbank		"SB"
+//	AtariAge X07	"X07"
+//	64k				"EF" (or "EFSC" with superchip)
+//	128k			"DF" (or "DFSC" with superchip)
+//	256k			"BF" (or "BFSC" with superchip)
 //	DPC (Pitfall2)  "DPC"
 //	DPC+			"DPC+"
+//	CDF				"CDF" (and "CDFJ")
 //	3E+				"3E+"
 //	Supercharger	"AR"
 package cartridge
@@ -16,6 +16,7 @@
 package cartridge
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/jetsetilly/gopher2600/errors"
@@ -23,139 +24,477 @@ import (
 	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/supercharger"
 )
 
-func fingerprint3ePlus(b []byte) bool {
-	// 3e is similar to tigervision, a key difference being that it uses 0x3e
-	// to switch ram, in addition to 0x3f for switching banks.
-	//
-	// postulating that the fingerprint method can be the same except for the
-	// write address.
+// signature is a sequence of bytes that is indicative of a cartridge format
+type signature struct {
+	seq  []byte
+	desc string
+}
 
-	threshold3e := 5
-	threshold3f := 5
-	for i := range b {
-		if b[i] == 0x85 && b[i+1] == 0x3e {
-			threshold3e--
-		}
-		if b[i] == 0x85 && b[i+1] == 0x3f {
-			threshold3f--
-		}
-		if threshold3e <= 0 && threshold3f <= 0 {
-			return true
+// evidence is the result of a fingerprint check. the score is in the range 0
+// to 100, with 100 meaning that there is enough evidence for the fingerprint
+// to succeed. the notes field describes what was found.
+type evidence struct {
+	score int
+	notes []string
+}
+
+func (e evidence) ok() bool {
+	return e.score >= 100
+}
+
+// the evidence for a fingerprint that either succeeds or fails outright
+func evidenceBool(ok bool, note string) evidence {
+	if ok {
+		return evidence{score: 100, notes: []string{note}}
+	}
+	return evidence{}
+}
+
+// countSignature returns the number of times the byte sequence appears in the
+// data
+func countSignature(b []byte, sig []byte) int {
+	n := 0
+	for i := 0; i <= len(b)-len(sig); i++ {
+		if bytes.Equal(b[i:i+len(sig)], sig) {
+			n++
 		}
 	}
-	return false
+	return n
 }
 
-func fingerprintMnetwork(b []byte) bool {
-	threshold := 2
-	for i := 0; i < len(b)-3; i++ {
-		if b[i] == 0x7e && b[i+1] == 0x66 && b[i+2] == 0x66 && b[i+3] == 0x66 {
-			threshold--
+// counts each signature in the data. the score is the percentage of the
+// threshold reached by the most frequent signature. in other words, the
+// fingerprint succeeds if any one of the signatures appears at least
+// threshold times.
+func matchAny(b []byte, threshold int, sigs ...signature) evidence {
+	e := evidence{}
+	for _, s := range sigs {
+		n := countSignature(b, s.seq)
+		if n == 0 {
+			continue
 		}
-		if threshold == 0 {
-			return true
+		e.notes = append(e.notes, fmt.Sprintf("% x (%s) found %d times", s.seq, s.desc, n))
+		e.score = maxScore(e.score, n*100/threshold)
+	}
+	return e
+}
+
+// counts each signature in the data. the score is the percentage of the
+// threshold reached by the least frequent signature. in other words, the
+// fingerprint succeeds only if every signature appears at least threshold
+// times.
+func matchAll(b []byte, threshold int, sigs ...signature) evidence {
+	e := evidence{score: 100}
+	for _, s := range sigs {
+		n := countSignature(b, s.seq)
+		e.notes = append(e.notes, fmt.Sprintf("% x (%s) found %d times", s.seq, s.desc, n))
+		sc := n * 100 / threshold
+		if sc < e.score {
+			e.score = sc
 		}
 	}
+	if e.score == 0 {
+		e.notes = nil
+	}
+	return e
+}
+
+func maxScore(a int, b int) int {
+	if b > 100 {
+		b = 100
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func fingerprint3ePlus(b []byte) evidence {
+	// 3E+ cartridges are identified by the "TJ3E" signature somewhere in the
+	// data. fingerprint pattern taken from Stella CartDetector.cxx
+	e := matchAny(b, 1,
+		signature{seq: []byte("TJ3E"), desc: "3E+ signature"},
+	)
+	if e.ok() {
+		return e
+	}
 
-	return false
+	// older 3E+ cartridges may not have the signature. 3E+ is similar to
+	// tigervision, a key difference being that it uses 0x3e to switch ram, in
+	// addition to 0x3f for switching banks.
+	//
+	// postulating that the fingerprint method can be the same except for the
+	// write address.
+	return matchAll(b, 5,
+		signature{seq: []byte{0x85, 0x3e}, desc: "STA $3E"},
+		signature{seq: []byte{0x85, 0x3f}, desc: "STA $3F"},
+	)
 }
 
-func fingerprintParkerBros(b []byte) bool {
-	// fingerprint patterns taken from Stella CartDetector.cxx
+func fingerprint3e(b []byte) evidence {
+	// 3E+ cartridges also write to 0x3e and 0x3f. the 3E+ signature rules out
+	// the plain 3E format
+	if countSignature(b, []byte("TJ3E")) > 0 {
+		return evidence{}
+	}
+
+	// the write to 0x3e is commonly followed by an immediate LDA. as with
+	// tigervision, banks are switched by writing to 0x3f. there are at least
+	// two banks so we expect to see both sequences at least twice.
+	// fingerprint pattern taken from Stella CartDetector.cxx
+	return matchAll(b, 2,
+		signature{seq: []byte{0x85, 0x3e, 0xa9, 0x00}, desc: "STA $3E; LDA #$00"},
+		signature{seq: []byte{0x85, 0x3f}, desc: "STA $3F"},
+	)
+}
+
+// 3E and 3E+ cartridges would also pass the tigervision fingerprint so they
+// must be checked first. returns nil if the data is neither format
+func fingerprint3eFormats(data []byte) func([]byte) (cartMapper, error) {
+	if len(data)%2048 == 0 && fingerprint3e(data).ok() {
+		return new3e
+	}
+
+	if len(data)%1024 == 0 && fingerprint3ePlus(data).ok() {
+		return new3ePlus
+	}
+
+	return nil
+}
+
+func fingerprintMnetwork(b []byte) evidence {
+	return matchAny(b, 2,
+		signature{seq: []byte{0x7e, 0x66, 0x66, 0x66}, desc: "M-Network graphics"},
+	)
+}
+
+// countHotspots returns the number of absolute addressing LDA, STA, BIT and
+// NOP instructions that access an address in the cartridge space between from
+// and to inclusive. mirrors of the cartridge space are also counted.
+func countHotspots(b []byte, from uint16, to uint16) int {
+	n := 0
 	for i := 0; i <= len(b)-3; i++ {
-		if (b[i] == 0x8d && b[i+1] == 0xe0 && b[i+2] == 0x1f) ||
-			(b[i] == 0x8d && b[i+1] == 0xe0 && b[i+2] == 0x5f) ||
-			(b[i] == 0x8d && b[i+1] == 0xe9 && b[i+2] == 0xff) ||
-			(b[i] == 0x0c && b[i+1] == 0xe0 && b[i+2] == 0x1f) ||
-			(b[i] == 0xad && b[i+1] == 0xe0 && b[i+2] == 0x1f) ||
-			(b[i] == 0xad && b[i+1] == 0xe9 && b[i+2] == 0xff) ||
-			(b[i] == 0xad && b[i+1] == 0xed && b[i+2] == 0xff) ||
-			(b[i] == 0xad && b[i+1] == 0xf3 && b[i+2] == 0xbf) {
-			return true
+		switch b[i] {
+		case 0xad, 0x8d, 0x2c, 0x0c:
+		default:
+			continue
+		}
+
+		addr := uint16(b[i+1]) | uint16(b[i+2])<<8
+		if addr&0x1000 != 0x1000 {
+			continue
 		}
 
+		addr &= 0x0fff
+		if addr >= from && addr <= to {
+			n++
+		}
 	}
+	return n
+}
 
-	return false
+func fingerprintMnetwork8k(b []byte) evidence {
+	if len(b) != 8192 {
+		return evidence{}
+	}
+
+	// the 8k M-Network cartridge switches banks by accessing 0x0fe0 to 0x0fe7
+	// and switches the 256 byte RAM blocks by accessing 0x0fe8 to 0x0feb. we
+	// expect to see both types of access.
+	//
+	// the parker bros (E0) format also uses the hotspots from 0x0fe0 but
+	// continues to 0x0ff7. access to those addresses suggests that this is not
+	// an M-Network cartridge.
+	banks := countHotspots(b, 0x0fe0, 0x0fe7)
+	ram := countHotspots(b, 0x0fe8, 0x0feb)
+	other := countHotspots(b, 0x0fec, 0x0ff7)
+
+	if banks == 0 || ram == 0 || other > 0 {
+		return evidence{}
+	}
+
+	return evidence{
+		score: 100,
+		notes: []string{
+			fmt.Sprintf("bank hotspots (0x0fe0 to 0x0fe7) accessed %d times", banks),
+			fmt.Sprintf("RAM hotspots (0x0fe8 to 0x0feb) accessed %d times", ram),
+		},
+	}
 }
 
-func fingerprintHarmony(b []byte) bool {
-	return b[0x20] == 0x1e && b[0x21] == 0xab && b[0x22] == 0xad && b[0x23] == 0x10
+func fingerprintParkerBros(b []byte) evidence {
+	// fingerprint patterns taken from Stella CartDetector.cxx
+	return matchAny(b, 1,
+		signature{seq: []byte{0x8d, 0xe0, 0x1f}, desc: "STA $1FE0"},
+		signature{seq: []byte{0x8d, 0xe0, 0x5f}, desc: "STA $5FE0"},
+		signature{seq: []byte{0x8d, 0xe9, 0xff}, desc: "STA $FFE9"},
+		signature{seq: []byte{0x0c, 0xe0, 0x1f}, desc: "NOP $1FE0"},
+		signature{seq: []byte{0xad, 0xe0, 0x1f}, desc: "LDA $1FE0"},
+		signature{seq: []byte{0xad, 0xe9, 0xff}, desc: "LDA $FFE9"},
+		signature{seq: []byte{0xad, 0xed, 0xff}, desc: "LDA $FFED"},
+		signature{seq: []byte{0xad, 0xf3, 0xbf}, desc: "LDA $BFF3"},
+	)
 }
 
-func fingerprintSuperchargerFastLoad(b []byte) bool {
+func fingerprintFE(b []byte) evidence {
+	// activision (FE) cartridges switch banks by way of the stack. the JSR and
+	// RTS instructions in the cartridge are the only evidence. fingerprint
+	// patterns taken from Stella CartDetector.cxx
+	return matchAny(b, 1,
+		signature{seq: []byte{0x20, 0x00, 0xd0, 0xc6, 0xc5}, desc: "JSR $D000; DEC $C5"},
+		signature{seq: []byte{0x20, 0xc3, 0xf8, 0xa5, 0x82}, desc: "JSR $F8C3; LDA $82"},
+		signature{seq: []byte{0xd0, 0xfb, 0x20, 0x73, 0xfe}, desc: "BNE $FB; JSR $FE73"},
+		signature{seq: []byte{0x20, 0x00, 0xf0, 0x84, 0xd6}, desc: "JSR $F000; STY $D6"},
+		signature{seq: []byte{0x20, 0x28, 0xf8, 0xa5, 0x82}, desc: "JSR $F828; LDA $82"},
+	)
+}
+
+func fingerprintHarmony(b []byte) evidence {
+	return evidenceBool(len(b) > 0x23 && b[0x20] == 0x1e && b[0x21] == 0xab && b[0x22] == 0xad && b[0x23] == 0x10,
+		"harmony driver signature at 0x0020")
+}
+
+func fingerprintSuperchargerFastLoad(b []byte) evidence {
+	// fastload binaries are made up of one or more loads of 8448 bytes
 	l := len(b)
-	return l == 8448 || l == 25344 || l == 33792
+	return evidenceBool(l > 0 && l%8448 == 0, fmt.Sprintf("size is a multiple of 8448 (%d loads)", l/8448))
 }
 
-func fingerprintTigervision(b []byte) bool {
+func fingerprintTigervision(b []byte) evidence {
 	// tigervision cartridges change banks by writing to memory address 0x3f. we
 	// can hypothesize that these types of cartridges will have that instruction
 	// sequence "85 3f" many times in a ROM whereas other cartridge types will not
+	return matchAny(b, 5,
+		signature{seq: []byte{0x85, 0x3f}, desc: "STA $3F"},
+	)
+}
 
-	threshold := 5
-	for i := 0; i < len(b)-1; i++ {
-		if b[i] == 0x85 && b[i+1] == 0x3f {
-			threshold--
-		}
-		if threshold == 0 {
-			return true
-		}
+func fingerprint4A50(b []byte) evidence {
+	// 4A50 cartridges store the address 0x4a50 in the NMI vector, which is in
+	// the last 256 bytes of ROM
+	return evidenceBool(len(b) >= 6 && b[len(b)-6] == 0x50 && b[len(b)-5] == 0x4a,
+		"NMI vector points to 0x4a50")
+}
+
+func fingerprintEconobanking(b []byte) evidence {
+	// econobanking (0840) switches banks by accessing 0x0800 or 0x0840. we
+	// expect the access to happen at least twice. fingerprint patterns taken
+	// from Stella CartDetector.cxx
+	return matchAny(b, 2,
+		signature{seq: []byte{0xad, 0x00, 0x08}, desc: "LDA $0800"},
+		signature{seq: []byte{0xad, 0x40, 0x08}, desc: "LDA $0840"},
+		signature{seq: []byte{0x2c, 0x00, 0x08}, desc: "BIT $0800"},
+		signature{seq: []byte{0x0c, 0x00, 0x08, 0x4c}, desc: "NOP $0800; JMP"},
+		signature{seq: []byte{0x0c, 0xff, 0x0f, 0x4c}, desc: "NOP $0FFF; JMP"},
+	)
+}
+
+func fingerprintUA(b []byte) evidence {
+	// UA switches to bank 1 by accessing 0x0240. fingerprint patterns taken
+	// from Stella CartDetector.cxx
+	return matchAny(b, 1,
+		signature{seq: []byte{0x8d, 0x40, 0x02}, desc: "STA $240"},
+		signature{seq: []byte{0xad, 0x40, 0x02}, desc: "LDA $240"},
+		signature{seq: []byte{0xbd, 0x1f, 0x02}, desc: "LDA $21F,X"},
+	)
+}
+
+func fingerprintSuperbank(b []byte) evidence {
+	// superbank switches banks by accessing addresses from 0x0800. fingerprint
+	// patterns taken from Stella CartDetector.cxx
+	return matchAny(b, 1,
+		signature{seq: []byte{0xbd, 0x00, 0x08}, desc: "LDA $0800,X"},
+		signature{seq: []byte{0xad, 0x00, 0x08}, desc: "LDA $0800"},
+	)
+}
+
+func fingerprintX07(b []byte) evidence {
+	// X07 switches banks by accessing 0x080d and its mirrors. fingerprint
+	// patterns taken from Stella CartDetector.cxx
+	return matchAny(b, 1,
+		signature{seq: []byte{0xad, 0x0d, 0x08}, desc: "LDA $080D"},
+		signature{seq: []byte{0xad, 0x1d, 0x08}, desc: "LDA $081D"},
+		signature{seq: []byte{0xad, 0x2d, 0x08}, desc: "LDA $082D"},
+		signature{seq: []byte{0x0c, 0x0d, 0x08}, desc: "NOP $080D"},
+		signature{seq: []byte{0x0c, 0x1d, 0x08}, desc: "NOP $081D"},
+		signature{seq: []byte{0x0c, 0x2d, 0x08}, desc: "NOP $082D"},
+	)
+}
+
+// newer EF, DF and BF cartridges store a signature in the last eight bytes of
+// the file. for example, "EFEF" or "EFSC" for the EF format
+func fingerprintExtendedAtari(b []byte, id string) evidence {
+	if len(b) < 8 {
+		return evidence{}
+	}
+	tail := b[len(b)-8:]
+	if countSignature(tail, []byte(id+id)) > 0 {
+		return evidenceBool(true, fmt.Sprintf("%s%s signature in last eight bytes", id, id))
+	}
+	if countSignature(tail, []byte(id+"SC")) > 0 {
+		return evidenceBool(true, fmt.Sprintf("%sSC signature in last eight bytes", id))
 	}
-	return false
+	return evidence{}
 }
 
-func fingerprint8k(data []byte) func([]byte) (cartMapper, error) {
-	if fingerprintTigervision(data) {
-		return newTigervision
+func fingerprintEF(b []byte) evidence {
+	if e := fingerprintExtendedAtari(b, "EF"); e.ok() {
+		return e
 	}
 
-	if fingerprintParkerBros(data) {
+	// older EF cartridges can be identified by the access to the first
+	// hotspot. fingerprint patterns taken from Stella CartDetector.cxx
+	return matchAny(b, 1,
+		signature{seq: []byte{0x0c, 0xe0, 0xff}, desc: "NOP $FFE0"},
+		signature{seq: []byte{0xad, 0xe0, 0xff}, desc: "LDA $FFE0"},
+		signature{seq: []byte{0x0c, 0xe0, 0x1f}, desc: "NOP $1FE0"},
+		signature{seq: []byte{0xad, 0xe0, 0x1f}, desc: "LDA $1FE0"},
+	)
+}
+
+func fingerprint4k(data []byte) func([]byte) (cartMapper, error) {
+	if f := fingerprint3eFormats(data); f != nil {
+		return f
+	}
+
+	return newAtari4k
+}
+
+func fingerprint8k(data []byte) func([]byte) (cartMapper, error) {
+	if fingerprintParkerBros(data).ok() {
 		return newParkerBros
 	}
 
+	if fingerprintMnetwork8k(data).ok() {
+		return newMnetwork8k
+	}
+
+	if fingerprintEconobanking(data).ok() {
+		return newEconobanking
+	}
+
+	if fingerprintUA(data).ok() {
+		return newUALimited
+	}
+
+	if f := fingerprint3eFormats(data); f != nil {
+		return f
+	}
+
+	if fingerprintTigervision(data).ok() {
+		return newTigervision
+	}
+
 	return newAtari8k
 }
 
 func fingerprint16k(data []byte) func([]byte) (cartMapper, error) {
-	if fingerprintTigervision(data) {
-		return newTigervision
+	if fingerprintMnetwork(data).ok() {
+		return newMnetwork
 	}
 
-	if fingerprintMnetwork(data) {
-		return newMnetwork
+	if f := fingerprint3eFormats(data); f != nil {
+		return f
+	}
+
+	if fingerprintTigervision(data).ok() {
+		return newTigervision
 	}
 
 	return newAtari16k
 }
 
 func fingerprint32k(data []byte) func([]byte) (cartMapper, error) {
-	if fingerprintTigervision(data) {
+	if fingerprint4A50(data).ok() {
+		return new4a50
+	}
+
+	if f := fingerprint3eFormats(data); f != nil {
+		return f
+	}
+
+	if fingerprintTigervision(data).ok() {
 		return newTigervision
 	}
 
 	return newAtari32k
 }
 
+func fingerprint64k(data []byte) func([]byte) (cartMapper, error) {
+	if fingerprint4A50(data).ok() {
+		return new4a50
+	}
+
+	if fingerprintEF(data).ok() {
+		return newAtari64k
+	}
+
+	if fingerprintX07(data).ok() {
+		return newX07
+	}
+
+	if f := fingerprint3eFormats(data); f != nil {
+		return f
+	}
+
+	return newAtari64k
+}
+
+func fingerprint128k(data []byte) func([]byte) (cartMapper, error) {
+	if fingerprint4A50(data).ok() {
+		return new4a50
+	}
+
+	if fingerprintExtendedAtari(data, "DF").ok() {
+		return newAtari128k
+	}
+
+	if fingerprintSuperbank(data).ok() {
+		return newSuperbank
+	}
+
+	if f := fingerprint3eFormats(data); f != nil {
+		return f
+	}
+
+	return newAtari128k
+}
+
+func fingerprint256k(data []byte) func([]byte) (cartMapper, error) {
+	if fingerprintExtendedAtari(data, "BF").ok() {
+		return newAtari256k
+	}
+
+	if fingerprintSuperbank(data).ok() {
+		return newSuperbank
+	}
+
+	if f := fingerprint3eFormats(data); f != nil {
+		return f
+	}
+
+	return newAtari256k
+}
+
 func (cart *Cartridge) fingerprint(data []byte) error {
 	var err error
 
-	if fingerprintHarmony(data) {
-		// !!TODO: this might be a CFDJ cartridge. check for that.
+	if fingerprintHarmony(data).ok() {
+		// CDF cartridges also pass the harmony fingerprint so we check for
+		// that first
+		if harmony.FingerprintCDF(data) {
+			cart.mapper, err = harmony.NewCDF(data)
+			return err
+		}
 		cart.mapper, err = harmony.NewDPCplus(data)
 		return err
 	}
 
-	if fingerprintSuperchargerFastLoad(data) {
+	if fingerprintSuperchargerFastLoad(data).ok() || supercharger.FingerprintSoundLoad(data) {
 		cart.mapper, err = supercharger.NewSupercharger(data)
 		return err
 	}
 
-	if fingerprint3ePlus(data) {
-		cart.mapper, err = new3ePlus(data)
-		return err
-	}
-
 	switch len(data) {
 	case 2048:
 		cart.mapper, err = newAtari2k(data)
@@ -164,7 +503,7 @@ func (cart *Cartridge) fingerprint(data []byte) error {
 		}
 
 	case 4096:
-		cart.mapper, err = newAtari4k(data)
+		cart.mapper, err = fingerprint4k(data)(data)
 		if err != nil {
 			return err
 		}
@@ -203,10 +542,33 @@ func (cart *Cartridge) fingerprint(data []byte) error {
 		}
 
 	case 65536:
-		return errors.New(errors.CartridgeError, "65536 bytes not yet supported")
+		cart.mapper, err = fingerprint64k(data)(data)
+		if err != nil {
+			return err
+		}
+
+	case 131072:
+		cart.mapper, err = fingerprint128k(data)(data)
+		if err != nil {
+			return err
+		}
+
+	case 262144:
+		cart.mapper, err = fingerprint256k(data)(data)
+		if err != nil {
+			return err
+		}
 
 	default:
-		return errors.New(errors.CartridgeError, fmt.Sprintf("unrecognised cartridge size (%d bytes)", len(data)))
+		// 3E and 3E+ cartridges can be any multiple of the bank size
+		f := fingerprint3eFormats(data)
+		if f == nil {
+			return errors.New(errors.CartridgeError, fmt.Sprintf("unrecognised cartridge size (%d bytes)", len(data)))
+		}
+		cart.mapper, err = f(data)
+		if err != nil {
+			return err
+		}
 	}
 
 	// if cartridge mapper implements the optionalSuperChip interface then try
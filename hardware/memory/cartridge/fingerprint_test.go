@@ -0,0 +1,187 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"testing"
+)
+
+// place the instruction sequence in the data at regular intervals, the
+// specified number of times
+func scatter(data []byte, seq []byte, n int) {
+	step := len(data) / (n + 1)
+	for i := 1; i <= n; i++ {
+		copy(data[i*step:], seq)
+	}
+}
+
+func Test3eFingerprint(t *testing.T) {
+	// plain 3E
+	data := make([]byte, 8192)
+	scatter(data[:4096], []byte{0x85, 0x3e, 0xa9, 0x00}, 2)
+	scatter(data[4096:], []byte{0x85, 0x3f}, 2)
+	if !fingerprint3e(data).ok() {
+		t.Errorf("3E data not detected as 3E")
+	}
+	if fingerprint3ePlus(data).ok() {
+		t.Errorf("3E data detected as 3E+")
+	}
+
+	// a single write to 0x3e is not enough evidence
+	data = make([]byte, 8192)
+	scatter(data, []byte{0x85, 0x3e, 0xa9, 0x00}, 1)
+	if fingerprint3e(data).ok() {
+		t.Errorf("single STA $3E detected as 3E")
+	}
+
+	// nor is the write to 0x3e without any write to 0x3f
+	data = make([]byte, 8192)
+	scatter(data, []byte{0x85, 0x3e, 0xa9, 0x00}, 5)
+	if fingerprint3e(data).ok() {
+		t.Errorf("data without STA $3F detected as 3E")
+	}
+
+	// 3E+ by signature
+	data = make([]byte, 8192)
+	copy(data[0x100:], []byte("TJ3E"))
+	scatter(data[:4096], []byte{0x85, 0x3e, 0xa9, 0x00}, 5)
+	scatter(data[4096:], []byte{0x85, 0x3f}, 5)
+	if !fingerprint3ePlus(data).ok() {
+		t.Errorf("3E+ data not detected as 3E+")
+	}
+	if fingerprint3e(data).ok() {
+		t.Errorf("3E+ data detected as 3E")
+	}
+
+	// 3E+ without the signature
+	data = make([]byte, 8192)
+	scatter(data[:4096], []byte{0x85, 0x3e}, 5)
+	scatter(data[4096:], []byte{0x85, 0x3f}, 5)
+	if !fingerprint3ePlus(data).ok() {
+		t.Errorf("3E+ data without signature not detected as 3E+")
+	}
+	if fingerprint3e(data).ok() {
+		t.Errorf("3E+ data without signature detected as 3E")
+	}
+
+	// tigervision (3F) only writes to 0x3f
+	data = make([]byte, 8192)
+	scatter(data, []byte{0x85, 0x3f}, 10)
+	if fingerprint3e(data).ok() {
+		t.Errorf("3F data detected as 3E")
+	}
+	if fingerprint3ePlus(data).ok() {
+		t.Errorf("3F data detected as 3E+")
+	}
+}
+
+func Test3eFingerprintChosen(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     func(data []byte)
+		expected string
+	}{
+		{
+			name: "3E",
+			data: func(data []byte) {
+				scatter(data, []byte{0x85, 0x3e, 0xa9, 0x00, 0x85, 0x3f}, 5)
+			},
+			expected: "3E",
+		},
+		{
+			name: "3E+ without signature",
+			data: func(data []byte) {
+				scatter(data, []byte{0x85, 0x3e, 0x85, 0x3f}, 5)
+			},
+			expected: "3E+",
+		},
+		{
+			name: "3F",
+			data: func(data []byte) {
+				scatter(data, []byte{0x85, 0x3f}, 5)
+			},
+			expected: "3F",
+		},
+		{
+			name: "F8 with stray STA $3E",
+			data: func(data []byte) {
+				scatter(data, []byte{0x85, 0x3e, 0xa9, 0x00}, 1)
+			},
+			expected: "F8",
+		},
+	}
+
+	for _, tt := range tests {
+		data := make([]byte, 8192)
+		tt.data(data)
+		cart := &Cartridge{}
+		if err := cart.fingerprint(data); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if cart.mapper.ID() != tt.expected {
+			t.Errorf("%s: expected %s mapper, got %s", tt.name, tt.expected, cart.mapper.ID())
+		}
+	}
+}
+
+func TestMnetwork8kFingerprint(t *testing.T) {
+	// E78K uses hotspots 0x0fe0 to 0x0fe7 and 0x0fe8 to 0x0feb
+	data := make([]byte, 8192)
+	scatter(data[:4096], []byte{0xad, 0xe5, 0x1f}, 2) // LDA $1FE5
+	scatter(data[4096:], []byte{0xad, 0xe9, 0x3f}, 2) // LDA $3FE9
+	if !fingerprintMnetwork8k(data).ok() {
+		t.Errorf("E78K data not detected as E78K")
+	}
+
+	cart := &Cartridge{}
+	if err := cart.fingerprint(data); err != nil {
+		t.Fatal(err)
+	}
+	if cart.mapper.ID() != "E78K" {
+		t.Errorf("expected E78K mapper, got %s", cart.mapper.ID())
+	}
+
+	// E78K is only 8k
+	big := make([]byte, 16384)
+	copy(big, data)
+	if fingerprintMnetwork8k(big).ok() {
+		t.Errorf("16k data detected as E78K")
+	}
+
+	// both types of hotspot must be accessed
+	data = make([]byte, 8192)
+	scatter(data, []byte{0xad, 0xe5, 0x1f}, 4)
+	if fingerprintMnetwork8k(data).ok() {
+		t.Errorf("data without RAM hotspot access detected as E78K")
+	}
+
+	// parker bros cartridges also access 0x0ff0 to 0x0ff7
+	data = make([]byte, 8192)
+	scatter(data[:4096], []byte{0x8d, 0xe5, 0x1f}, 2) // STA $1FE5
+	scatter(data[4096:], []byte{0x8d, 0xe9, 0x1f}, 2) // STA $1FE9
+	copy(data[0x1000:], []byte{0x8d, 0xf2, 0x1f})     // STA $1FF2
+	if fingerprintMnetwork8k(data).ok() {
+		t.Errorf("E0 data detected as E78K")
+	}
+
+	// accesses outside of cartridge space are not counted
+	data = make([]byte, 8192)
+	scatter(data[:4096], []byte{0xad, 0xe5, 0x0f}, 2) // LDA $0FE5
+	scatter(data[4096:], []byte{0xad, 0xe9, 0x0f}, 2) // LDA $0FE9
+	if fingerprintMnetwork8k(data).ok() {
+		t.Errorf("access outside of cartridge space detected as E78K")
+	}
+}
@@ -0,0 +1,212 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package arm7tdmi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// register names
+const (
+	rSP = 13 + iota
+	rLR
+	rPC
+	rCount
+)
+
+// the maximum number of instructions that will be executed in a single call
+// to Run(). if the ARM program has not ended by then it is assumed that
+// something has gone wrong
+const maxInstructions = 10000000
+
+// ARM implements the ARM7TDMI-S LPC2103 processor. Only the Thumb instruction
+// set is supported.
+type ARM struct {
+	mem  SharedMemory
+	hook CartridgeHook
+
+	// the PC register points to the instruction *after* the instruction
+	// currently being executed. reading the PC register (with the
+	// readRegister() function) will return the pipelined value, ie. the
+	// address of the current instruction plus four
+	registers [rCount]uint32
+	status    status
+
+	// the address of the instruction currently being executed
+	executingPC uint32
+
+	// the value of the link register on reset. if the program counter ever
+	// reaches this address then the ARM program has finished
+	exitAddress uint32
+
+	// whether the program has been ended
+	continueExecution bool
+
+	// number of cycles consumed by the current call to Run()
+	cycles int
+
+	// peripherals
+	timer timer
+	mam   mam
+}
+
+// NewARM is the preferred method of initialisation for the ARM type
+func NewARM(mem SharedMemory, hook CartridgeHook) *ARM {
+	arm := &ARM{
+		mem:  mem,
+		hook: hook,
+	}
+	arm.reset()
+	return arm
+}
+
+func (arm ARM) String() string {
+	s := strings.Builder{}
+	for i, r := range arm.registers {
+		if i > 0 {
+			if i%4 == 0 {
+				s.WriteString("\n")
+			} else {
+				s.WriteString("\t\t")
+			}
+		}
+		s.WriteString(fmt.Sprintf("R%-2d: %08x", i, r))
+	}
+	s.WriteString(fmt.Sprintf("\nstatus: %s", arm.status))
+	return s.String()
+}
+
+func (arm *ARM) reset() {
+	for i := range arm.registers {
+		arm.registers[i] = 0
+	}
+	arm.status.reset()
+
+	sp, lr, pc := arm.mem.ResetVectors()
+	arm.registers[rSP] = sp
+	arm.registers[rLR] = lr
+	arm.registers[rPC] = pc &^ 0x01
+	arm.exitAddress = lr &^ 0x01
+}
+
+// readRegister returns the value of the register as seen by an executing
+// instruction
+func (arm *ARM) readRegister(r uint32) uint32 {
+	if r == rPC {
+		return arm.registers[rPC] + 2
+	}
+	return arm.registers[r]
+}
+
+// writeRegister sets the value of the register. writing to the PC register
+// causes a branch
+func (arm *ARM) writeRegister(r uint32, val uint32) {
+	if r == rPC {
+		arm.registers[rPC] = val &^ 0x01
+		arm.cycles += 2
+		return
+	}
+	arm.registers[r] = val
+}
+
+// Run will execute the ARM program from the reset vectors (see the
+// SharedMemory interface) until the program returns. Returns the number of
+// ARM cycles taken by the program.
+func (arm *ARM) Run() (int, error) {
+	arm.reset()
+	arm.cycles = 0
+	arm.continueExecution = true
+
+	var err error
+
+	for i := 0; arm.continueExecution; i++ {
+		if i >= maxInstructions {
+			return arm.cycles, errors.New(errors.ARMError, fmt.Sprintf("program did not end after %d instructions", i))
+		}
+
+		arm.executingPC = arm.registers[rPC]
+		opcode := arm.read16(arm.executingPC)
+		arm.registers[rPC] += 2
+
+		before := arm.cycles
+		arm.cycles++
+
+		err = arm.execute(opcode)
+		if err != nil {
+			return arm.cycles, err
+		}
+
+		arm.timer.step(arm.cycles - before)
+
+		if arm.registers[rPC] == arm.exitAddress {
+			arm.continueExecution = false
+		}
+	}
+
+	return arm.cycles, nil
+}
+
+func (arm *ARM) execute(opcode uint16) error {
+	// the order of the tests is important. some formats overlap with one
+	// another and the more specific format must be tested first
+	switch {
+	case opcode&0xf800 == 0x1800:
+		arm.executeAddSubtract(opcode)
+	case opcode&0xe000 == 0x0000:
+		arm.executeMoveShiftedRegister(opcode)
+	case opcode&0xe000 == 0x2000:
+		arm.executeMovCmpAddSubImm(opcode)
+	case opcode&0xfc00 == 0x4000:
+		arm.executeALUoperations(opcode)
+	case opcode&0xfc00 == 0x4400:
+		return arm.executeHiRegisterOps(opcode)
+	case opcode&0xf800 == 0x4800:
+		arm.executePCrelativeLoad(opcode)
+	case opcode&0xf200 == 0x5000:
+		arm.executeLoadStoreWithRegisterOffset(opcode)
+	case opcode&0xf200 == 0x5200:
+		arm.executeLoadStoreSignExtendedByteHalford(opcode)
+	case opcode&0xe000 == 0x6000:
+		arm.executeLoadStoreWithImmOffset(opcode)
+	case opcode&0xf000 == 0x8000:
+		arm.executeLoadStoreHalfword(opcode)
+	case opcode&0xf000 == 0x9000:
+		arm.executeSPRelativeLoadStore(opcode)
+	case opcode&0xf000 == 0xa000:
+		arm.executeLoadAddress(opcode)
+	case opcode&0xff00 == 0xb000:
+		arm.executeAddOffsetToSP(opcode)
+	case opcode&0xf600 == 0xb400:
+		arm.executePushPopRegisters(opcode)
+	case opcode&0xf000 == 0xc000:
+		arm.executeMultipleLoadStore(opcode)
+	case opcode&0xff00 == 0xdf00:
+		return arm.executeSoftwareInterrupt(opcode)
+	case opcode&0xf000 == 0xd000:
+		arm.executeConditionalBranch(opcode)
+	case opcode&0xf800 == 0xe000:
+		arm.executeUnconditionalBranch(opcode)
+	case opcode&0xf000 == 0xf000:
+		arm.executeLongBranchWithLink(opcode)
+	default:
+		return errors.New(errors.ARMError, fmt.Sprintf("undefined instruction (%04x) at (%08x)", opcode, arm.executingPC))
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package arm7tdmi_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/harmony/arm7tdmi"
+)
+
+type mockMem struct {
+	flash []byte
+	sram  []byte
+}
+
+func newMockMem() *mockMem {
+	return &mockMem{
+		flash: make([]byte, 0x1000),
+		sram:  make([]byte, 0x2000),
+	}
+}
+
+func (mem *mockMem) MapAddress(addr uint32, write bool) ([]byte, uint32) {
+	if addr >= 0x40000000 && addr < 0x40000000+uint32(len(mem.sram)) {
+		return mem.sram, 0x40000000
+	}
+	if !write && addr < uint32(len(mem.flash)) {
+		return mem.flash, 0x00000000
+	}
+	return nil, 0
+}
+
+func (mem *mockMem) ResetVectors() (uint32, uint32, uint32) {
+	return 0x40001fb4, 0x00000800, 0x00000808
+}
+
+func (mem *mockMem) putInstructions(origin uint32, opcodes ...uint16) {
+	for i, o := range opcodes {
+		binary.LittleEndian.PutUint16(mem.flash[origin+uint32(i*2):], o)
+	}
+}
+
+func (mem *mockMem) assert(t *testing.T, addr uint32, value uint32) {
+	t.Helper()
+	v := binary.LittleEndian.Uint32(mem.sram[addr-0x40000000:])
+	if v != value {
+		t.Errorf("memory assertion failed (%d - wanted %d at address %08x)", v, value, addr)
+	}
+}
+
+func TestProgram(t *testing.T) {
+	mem := newMockMem()
+
+	mem.putInstructions(0x808,
+		0xb500, // push {lr}
+		0x2005, // mov r0, #5
+		0x2107, // mov r1, #7
+		0x1842, // add r2, r0, r1
+		0x2301, // mov r3, #1
+		0x079b, // lsl r3, r3, #30
+		0x601a, // str r2, [r3, #0]
+		0x2400, // mov r4, #0
+		0x3403, // loop: add r4, #3
+		0x3801, // sub r0, #1
+		0xd1fc, // bne loop
+		0x605c, // str r4, [r3, #4]
+		0xf000, // bl sub
+		0xf802,
+		0x609d, // str r5, [r3, #8]
+		0xbd00, // pop {pc}
+		0x2509, // sub: mov r5, #9
+		0x4770, // bx lr
+	)
+
+	arm := arm7tdmi.NewARM(mem, nil)
+
+	cycles, err := arm.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if cycles == 0 {
+		t.Errorf("ARM program took no cycles")
+	}
+
+	mem.assert(t, 0x40000000, 12)
+	mem.assert(t, 0x40000004, 15)
+	mem.assert(t, 0x40000008, 9)
+}
+
+func TestRunaway(t *testing.T) {
+	mem := newMockMem()
+
+	mem.putInstructions(0x808,
+		0xe7fe, // b .
+	)
+
+	arm := arm7tdmi.NewARM(mem, nil)
+
+	_, err := arm.Run()
+	if err == nil {
+		t.Errorf("expected error from runaway ARM program")
+	}
+}
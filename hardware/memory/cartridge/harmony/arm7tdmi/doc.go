@@ -0,0 +1,41 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package arm7tdmi implements the ARM7TDMI instruction set as defined in the
+// ARM7TDMI Instruction Set Reference:
+//
+// http://www.ecs.csun.edu/~smirzaei/docs/ece425/arm7tdmi_instruction_set_reference.pdf
+//
+// For this project we only need to emulate the Thumb architecture. The ARM
+// architecture, with which the Thumb architecture is interlinked, is not
+// required because the ARM programs in cartridges such as the Harmony are
+// entirely Thumb code. Where the Thumb program branches to ARM code (with the
+// BX instruction) the emulation will either hand control to the cartridge
+// (see the CartridgeHook interface) or end the execution of the program.
+//
+// The package is intended to be used by cartridge mappers that contain an ARM
+// coprocessor. The cartridge mapper provides access to the memory that is
+// visible to the ARM (see the SharedMemory interface) and calls the Run()
+// function whenever the 6507 program requests that the ARM program be
+// executed.
+//
+// A small number of the peripherals found in the LPC2103 chip of the Harmony
+// cartridge are emulated: the timer (T1TCR and T1TC) and the memory
+// accelerator module (MAMCR and MAMTIM). The latter has no effect on the
+// emulation.
+//
+// Cycle counting is an approximation based on the cycle times given in the
+// ARM7TDMI Technical Reference Manual. Memory wait states are not considered.
+package arm7tdmi
@@ -0,0 +1,60 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package arm7tdmi
+
+// SharedMemory represents the memory that is shared between the ARM and the
+// host cartridge.
+type SharedMemory interface {
+	// MapAddress returns the block of memory containing the address and the
+	// address at which that block of memory begins. The write argument
+	// indicates whether the memory is to be written to.
+	//
+	// Should return nil if the address is not mapped or if it is not
+	// writable when the write argument is true.
+	MapAddress(addr uint32, write bool) ([]byte, uint32)
+
+	// ResetVectors returns the initial values for the stack pointer, link
+	// register and program counter. The link register value is also used to
+	// detect the end of the ARM program.
+	ResetVectors() (uint32, uint32, uint32)
+}
+
+// CartridgeHook allows the host cartridge to emulate the ARM (as opposed to
+// Thumb) functions in the cartridge's driver.
+type CartridgeHook interface {
+	// ARMinterrupt is called whenever the Thumb program branches to an ARM
+	// function. The addr argument is the address of the BX instruction that
+	// made the branch and the val1 and val2 arguments are the values of
+	// registers R2 and R3.
+	ARMinterrupt(addr uint32, val1 uint32, val2 uint32) (ARMinterruptReturn, error)
+}
+
+// ARMinterruptReturn is returned by the ARMinterrupt() function of the
+// CartridgeHook interface.
+type ARMinterruptReturn struct {
+	// whether the interrupt was serviced by the cartridge. if it was not then
+	// the ARM program will end
+	InterruptServiced bool
+
+	// if SaveResult is true then the value in SaveValue will be written to
+	// the register SaveRegister before the ARM program continues
+	SaveResult   bool
+	SaveRegister int
+	SaveValue    uint32
+
+	// the number of additional cycles taken by the emulated function
+	NumCycles int
+}
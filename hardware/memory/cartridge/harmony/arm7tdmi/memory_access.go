@@ -0,0 +1,128 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package arm7tdmi
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/logger"
+)
+
+// mapAddress returns the memory block and the index into the block of the
+// address. returns nil if the address cannot be mapped or if the number of
+// bytes starting at the address exceeds the memory block
+func (arm *ARM) mapAddress(addr uint32, write bool, n uint32) ([]byte, uint32) {
+	mem, origin := arm.mem.MapAddress(addr, write)
+	if mem == nil {
+		return nil, 0
+	}
+	idx := addr - origin
+	if idx+n > uint32(len(mem)) {
+		return nil, 0
+	}
+	return mem, idx
+}
+
+func (arm *ARM) read8(addr uint32) uint8 {
+	mem, idx := arm.mapAddress(addr, false, 1)
+	if mem == nil {
+		if v, ok := arm.readPeripheral(addr); ok {
+			return uint8(v)
+		}
+		logger.Log("ARM7", fmt.Sprintf("read8: unrecognised address %08x (PC: %08x)", addr, arm.executingPC))
+		return 0
+	}
+	return mem[idx]
+}
+
+func (arm *ARM) write8(addr uint32, val uint8) {
+	mem, idx := arm.mapAddress(addr, true, 1)
+	if mem == nil {
+		if ok := arm.writePeripheral(addr, uint32(val)); ok {
+			return
+		}
+		logger.Log("ARM7", fmt.Sprintf("write8: unrecognised address %08x (PC: %08x)", addr, arm.executingPC))
+		return
+	}
+	mem[idx] = val
+}
+
+func (arm *ARM) read16(addr uint32) uint16 {
+	// unaligned halfword accesses are unpredictable. we simply align the
+	// address
+	addr &= 0xfffffffe
+
+	mem, idx := arm.mapAddress(addr, false, 2)
+	if mem == nil {
+		if v, ok := arm.readPeripheral(addr); ok {
+			return uint16(v)
+		}
+		logger.Log("ARM7", fmt.Sprintf("read16: unrecognised address %08x (PC: %08x)", addr, arm.executingPC))
+		return 0
+	}
+	return uint16(mem[idx]) | uint16(mem[idx+1])<<8
+}
+
+func (arm *ARM) write16(addr uint32, val uint16) {
+	addr &= 0xfffffffe
+
+	mem, idx := arm.mapAddress(addr, true, 2)
+	if mem == nil {
+		if ok := arm.writePeripheral(addr, uint32(val)); ok {
+			return
+		}
+		logger.Log("ARM7", fmt.Sprintf("write16: unrecognised address %08x (PC: %08x)", addr, arm.executingPC))
+		return
+	}
+	mem[idx] = uint8(val)
+	mem[idx+1] = uint8(val >> 8)
+}
+
+func (arm *ARM) read32(addr uint32) uint32 {
+	// an unaligned word read returns the aligned word rotated so that the
+	// addressed byte is in the least significant position
+	rot := (addr & 0x03) << 3
+	addr &= 0xfffffffc
+
+	mem, idx := arm.mapAddress(addr, false, 4)
+	if mem == nil {
+		if v, ok := arm.readPeripheral(addr); ok {
+			return v
+		}
+		logger.Log("ARM7", fmt.Sprintf("read32: unrecognised address %08x (PC: %08x)", addr, arm.executingPC))
+		return 0
+	}
+
+	v := uint32(mem[idx]) | uint32(mem[idx+1])<<8 | uint32(mem[idx+2])<<16 | uint32(mem[idx+3])<<24
+	return v>>rot | v<<(32-rot)
+}
+
+func (arm *ARM) write32(addr uint32, val uint32) {
+	addr &= 0xfffffffc
+
+	mem, idx := arm.mapAddress(addr, true, 4)
+	if mem == nil {
+		if ok := arm.writePeripheral(addr, val); ok {
+			return
+		}
+		logger.Log("ARM7", fmt.Sprintf("write32: unrecognised address %08x (PC: %08x)", addr, arm.executingPC))
+		return
+	}
+	mem[idx] = uint8(val)
+	mem[idx+1] = uint8(val >> 8)
+	mem[idx+2] = uint8(val >> 16)
+	mem[idx+3] = uint8(val >> 24)
+}
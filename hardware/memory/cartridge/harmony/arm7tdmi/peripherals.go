@@ -0,0 +1,113 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package arm7tdmi
+
+// addresses of the LPC2103 peripherals that are emulated
+const (
+	addrT1TCR  = 0xe0008004
+	addrT1TC   = 0xe0008008
+	addrMAMCR  = 0xe01fc000
+	addrMAMTIM = 0xe01fc004
+)
+
+// timer is a minimal implementation of timer 1 of the LPC2103. the timer
+// counts ARM cycles, there is no prescaling.
+type timer struct {
+	// timer control register. bit 0 is counter enable and bit 1 is counter
+	// reset
+	control uint32
+
+	// timer counter
+	counter uint32
+}
+
+func (t *timer) step(cycles int) {
+	if t.control&0x01 == 0x01 && t.control&0x02 == 0x00 {
+		t.counter += uint32(cycles)
+	}
+}
+
+func (t *timer) write(addr uint32, val uint32) {
+	switch addr {
+	case addrT1TCR:
+		t.control = val
+		if t.control&0x02 == 0x02 {
+			t.counter = 0
+		}
+	case addrT1TC:
+		t.counter = val
+	}
+}
+
+func (t *timer) read(addr uint32) uint32 {
+	switch addr {
+	case addrT1TCR:
+		return t.control
+	case addrT1TC:
+		return t.counter
+	}
+	return 0
+}
+
+// mam is the memory accelerator module of the LPC2103. the values written to
+// the registers are stored but otherwise have no effect.
+type mam struct {
+	control uint32
+	timing  uint32
+}
+
+func (m *mam) write(addr uint32, val uint32) {
+	switch addr {
+	case addrMAMCR:
+		m.control = val
+	case addrMAMTIM:
+		m.timing = val
+	}
+}
+
+func (m *mam) read(addr uint32) uint32 {
+	switch addr {
+	case addrMAMCR:
+		return m.control
+	case addrMAMTIM:
+		return m.timing
+	}
+	return 0
+}
+
+// returns true if address was handled as a peripheral
+func (arm *ARM) writePeripheral(addr uint32, val uint32) bool {
+	switch addr {
+	case addrT1TCR, addrT1TC:
+		arm.timer.write(addr, val)
+	case addrMAMCR, addrMAMTIM:
+		arm.mam.write(addr, val)
+	default:
+		return false
+	}
+	return true
+}
+
+// returns value and true if address was handled as a peripheral
+func (arm *ARM) readPeripheral(addr uint32) (uint32, bool) {
+	switch addr {
+	case addrT1TCR, addrT1TC:
+		return arm.timer.read(addr), true
+	case addrMAMCR, addrMAMTIM:
+		return arm.mam.read(addr), true
+	}
+	return 0, false
+}
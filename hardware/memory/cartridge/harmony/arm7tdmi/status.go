@@ -0,0 +1,124 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package arm7tdmi
+
+import (
+	"strings"
+)
+
+// the condition flags of the program status register. the other bits in the
+// register are not required by the emulation
+type status struct {
+	negative bool
+	zero     bool
+	overflow bool
+	carry    bool
+}
+
+func (sr status) String() string {
+	s := strings.Builder{}
+	if sr.negative {
+		s.WriteRune('N')
+	} else {
+		s.WriteRune('n')
+	}
+	if sr.zero {
+		s.WriteRune('Z')
+	} else {
+		s.WriteRune('z')
+	}
+	if sr.overflow {
+		s.WriteRune('V')
+	} else {
+		s.WriteRune('v')
+	}
+	if sr.carry {
+		s.WriteRune('C')
+	} else {
+		s.WriteRune('c')
+	}
+	return s.String()
+}
+
+func (sr *status) reset() {
+	sr.negative = false
+	sr.zero = false
+	sr.overflow = false
+	sr.carry = false
+}
+
+func (sr *status) setNegative(a uint32) {
+	sr.negative = a&0x80000000 == 0x80000000
+}
+
+func (sr *status) setZero(a uint32) {
+	sr.zero = a == 0x00
+}
+
+// set carry flag for the addition a + b + c. subtraction can be performed by
+// inverting b and setting c to one
+func (sr *status) setCarry(a, b, c uint32) {
+	d := (a & 0x7fffffff) + (b & 0x7fffffff) + c
+	d = (d >> 31) + (a >> 31) + (b >> 31)
+	sr.carry = d&0x02 == 0x02
+}
+
+// set overflow flag for the addition a + b + c. subtraction can be performed
+// by inverting b and setting c to one
+func (sr *status) setOverflow(a, b, c uint32) {
+	d := (a & 0x7fffffff) + (b & 0x7fffffff) + c
+	d >>= 31
+	e := (d & 0x01) + (a >> 31) + (b >> 31)
+	e >>= 1
+	sr.overflow = (d^e)&0x01 == 0x01
+}
+
+// condition returns true if the condition code (as found in the conditional
+// branch instruction) is met
+func (sr status) condition(cond uint8) bool {
+	switch cond {
+	case 0b0000: // EQ
+		return sr.zero
+	case 0b0001: // NE
+		return !sr.zero
+	case 0b0010: // CS
+		return sr.carry
+	case 0b0011: // CC
+		return !sr.carry
+	case 0b0100: // MI
+		return sr.negative
+	case 0b0101: // PL
+		return !sr.negative
+	case 0b0110: // VS
+		return sr.overflow
+	case 0b0111: // VC
+		return !sr.overflow
+	case 0b1000: // HI
+		return sr.carry && !sr.zero
+	case 0b1001: // LS
+		return !sr.carry || sr.zero
+	case 0b1010: // GE
+		return sr.negative == sr.overflow
+	case 0b1011: // LT
+		return sr.negative != sr.overflow
+	case 0b1100: // GT
+		return !sr.zero && sr.negative == sr.overflow
+	case 0b1101: // LE
+		return sr.zero || sr.negative != sr.overflow
+	}
+
+	return false
+}
@@ -0,0 +1,647 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package arm7tdmi
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// the execute*() functions are named after the instruction formats in the
+// "ARM7TDMI Data Sheet", section 5 ("Thumb Instruction Set").
+
+// format 1
+func (arm *ARM) executeMoveShiftedRegister(opcode uint16) {
+	op := (opcode & 0x1800) >> 11
+	shift := uint32((opcode & 0x07c0) >> 6)
+	srcReg := uint32((opcode & 0x38) >> 3)
+	destReg := uint32(opcode & 0x07)
+
+	src := arm.registers[srcReg]
+	var res uint32
+
+	switch op {
+	case 0b00: // LSL
+		if shift == 0 {
+			res = src
+		} else {
+			arm.status.carry = (src>>(32-shift))&0x01 == 0x01
+			res = src << shift
+		}
+	case 0b01: // LSR
+		// a shift of zero is interpreted as a shift of 32
+		if shift == 0 {
+			arm.status.carry = src&0x80000000 == 0x80000000
+			res = 0
+		} else {
+			arm.status.carry = (src>>(shift-1))&0x01 == 0x01
+			res = src >> shift
+		}
+	case 0b10: // ASR
+		// a shift of zero is interpreted as a shift of 32
+		if shift == 0 {
+			arm.status.carry = src&0x80000000 == 0x80000000
+			if arm.status.carry {
+				res = 0xffffffff
+			} else {
+				res = 0
+			}
+		} else {
+			arm.status.carry = (src>>(shift-1))&0x01 == 0x01
+			res = uint32(int32(src) >> shift)
+		}
+	}
+
+	arm.registers[destReg] = res
+	arm.status.setNegative(res)
+	arm.status.setZero(res)
+}
+
+// format 2
+func (arm *ARM) executeAddSubtract(opcode uint16) {
+	immediate := opcode&0x0400 == 0x0400
+	subtract := opcode&0x0200 == 0x0200
+	imm := uint32((opcode & 0x01c0) >> 6)
+	srcReg := uint32((opcode & 0x38) >> 3)
+	destReg := uint32(opcode & 0x07)
+
+	// value to work with is either an immediate value or is in a register
+	val := imm
+	if !immediate {
+		val = arm.registers[imm]
+	}
+
+	a := arm.registers[srcReg]
+	var res uint32
+
+	if subtract {
+		arm.status.setCarry(a, ^val, 1)
+		arm.status.setOverflow(a, ^val, 1)
+		res = a - val
+	} else {
+		arm.status.setCarry(a, val, 0)
+		arm.status.setOverflow(a, val, 0)
+		res = a + val
+	}
+
+	arm.registers[destReg] = res
+	arm.status.setNegative(res)
+	arm.status.setZero(res)
+}
+
+// format 3
+func (arm *ARM) executeMovCmpAddSubImm(opcode uint16) {
+	op := (opcode & 0x1800) >> 11
+	destReg := uint32((opcode & 0x0700) >> 8)
+	imm := uint32(opcode & 0x00ff)
+
+	a := arm.registers[destReg]
+
+	switch op {
+	case 0b00: // MOV
+		arm.registers[destReg] = imm
+		arm.status.setNegative(imm)
+		arm.status.setZero(imm)
+	case 0b01: // CMP
+		arm.status.setCarry(a, ^imm, 1)
+		arm.status.setOverflow(a, ^imm, 1)
+		res := a - imm
+		arm.status.setNegative(res)
+		arm.status.setZero(res)
+	case 0b10: // ADD
+		arm.status.setCarry(a, imm, 0)
+		arm.status.setOverflow(a, imm, 0)
+		res := a + imm
+		arm.registers[destReg] = res
+		arm.status.setNegative(res)
+		arm.status.setZero(res)
+	case 0b11: // SUB
+		arm.status.setCarry(a, ^imm, 1)
+		arm.status.setOverflow(a, ^imm, 1)
+		res := a - imm
+		arm.registers[destReg] = res
+		arm.status.setNegative(res)
+		arm.status.setZero(res)
+	}
+}
+
+// format 4
+func (arm *ARM) executeALUoperations(opcode uint16) {
+	op := (opcode & 0x03c0) >> 6
+	srcReg := uint32((opcode & 0x38) >> 3)
+	destReg := uint32(opcode & 0x07)
+
+	a := arm.registers[destReg]
+	b := arm.registers[srcReg]
+	var res uint32
+
+	// whether the result should be written back to the destination register
+	write := true
+
+	switch op {
+	case 0b0000: // AND
+		res = a & b
+	case 0b0001: // EOR
+		res = a ^ b
+	case 0b0010: // LSL
+		shift := b & 0xff
+		switch {
+		case shift == 0:
+			res = a
+		case shift < 32:
+			arm.status.carry = (a>>(32-shift))&0x01 == 0x01
+			res = a << shift
+		case shift == 32:
+			arm.status.carry = a&0x01 == 0x01
+			res = 0
+		default:
+			arm.status.carry = false
+			res = 0
+		}
+		arm.cycles++
+	case 0b0011: // LSR
+		shift := b & 0xff
+		switch {
+		case shift == 0:
+			res = a
+		case shift < 32:
+			arm.status.carry = (a>>(shift-1))&0x01 == 0x01
+			res = a >> shift
+		case shift == 32:
+			arm.status.carry = a&0x80000000 == 0x80000000
+			res = 0
+		default:
+			arm.status.carry = false
+			res = 0
+		}
+		arm.cycles++
+	case 0b0100: // ASR
+		shift := b & 0xff
+		switch {
+		case shift == 0:
+			res = a
+		case shift < 32:
+			arm.status.carry = (a>>(shift-1))&0x01 == 0x01
+			res = uint32(int32(a) >> shift)
+		default:
+			arm.status.carry = a&0x80000000 == 0x80000000
+			if arm.status.carry {
+				res = 0xffffffff
+			} else {
+				res = 0
+			}
+		}
+		arm.cycles++
+	case 0b0101: // ADC
+		var c uint32
+		if arm.status.carry {
+			c = 1
+		}
+		arm.status.setCarry(a, b, c)
+		arm.status.setOverflow(a, b, c)
+		res = a + b + c
+	case 0b0110: // SBC
+		var c uint32
+		if arm.status.carry {
+			c = 1
+		}
+		arm.status.setCarry(a, ^b, c)
+		arm.status.setOverflow(a, ^b, c)
+		res = a + ^b + c
+	case 0b0111: // ROR
+		shift := b & 0xff
+		switch {
+		case shift == 0:
+			res = a
+		case shift&0x1f == 0:
+			arm.status.carry = a&0x80000000 == 0x80000000
+			res = a
+		default:
+			shift &= 0x1f
+			res = a>>shift | a<<(32-shift)
+			arm.status.carry = res&0x80000000 == 0x80000000
+		}
+		arm.cycles++
+	case 0b1000: // TST
+		res = a & b
+		write = false
+	case 0b1001: // NEG
+		arm.status.setCarry(0, ^b, 1)
+		arm.status.setOverflow(0, ^b, 1)
+		res = -b
+	case 0b1010: // CMP
+		arm.status.setCarry(a, ^b, 1)
+		arm.status.setOverflow(a, ^b, 1)
+		res = a - b
+		write = false
+	case 0b1011: // CMN
+		arm.status.setCarry(a, b, 0)
+		arm.status.setOverflow(a, b, 0)
+		res = a + b
+		write = false
+	case 0b1100: // ORR
+		res = a | b
+	case 0b1101: // MUL
+		res = a * b
+		arm.cycles += 2
+	case 0b1110: // BIC
+		res = a &^ b
+	case 0b1111: // MVN
+		res = ^b
+	}
+
+	if write {
+		arm.registers[destReg] = res
+	}
+	arm.status.setNegative(res)
+	arm.status.setZero(res)
+}
+
+// format 5
+func (arm *ARM) executeHiRegisterOps(opcode uint16) error {
+	op := (opcode & 0x300) >> 8
+	hi1 := opcode&0x80 == 0x80
+	hi2 := opcode&0x40 == 0x40
+	srcReg := uint32((opcode & 0x38) >> 3)
+	destReg := uint32(opcode & 0x07)
+
+	if hi1 {
+		destReg += 8
+	}
+	if hi2 {
+		srcReg += 8
+	}
+
+	switch op {
+	case 0b00: // ADD
+		arm.writeRegister(destReg, arm.readRegister(destReg)+arm.readRegister(srcReg))
+	case 0b01: // CMP
+		a := arm.readRegister(destReg)
+		b := arm.readRegister(srcReg)
+		arm.status.setCarry(a, ^b, 1)
+		arm.status.setOverflow(a, ^b, 1)
+		res := a - b
+		arm.status.setNegative(res)
+		arm.status.setZero(res)
+	case 0b10: // MOV
+		arm.writeRegister(destReg, arm.readRegister(srcReg))
+	case 0b11: // BX
+		return arm.branchExchange(arm.readRegister(srcReg))
+	}
+
+	return nil
+}
+
+// branchExchange implements the BX instruction. a branch to an address with
+// bit zero set is a branch to Thumb code. otherwise it is a branch to ARM code
+// and the cartridge is given the chance to emulate it
+func (arm *ARM) branchExchange(target uint32) error {
+	arm.cycles += 2
+
+	if target&0x01 == 0x01 {
+		arm.registers[rPC] = target &^ 0x01
+		return nil
+	}
+
+	if arm.hook == nil {
+		arm.continueExecution = false
+		return nil
+	}
+
+	res, err := arm.hook.ARMinterrupt(arm.executingPC, arm.registers[2], arm.registers[3])
+	if err != nil {
+		return errors.New(errors.ARMError, err)
+	}
+
+	if !res.InterruptServiced {
+		arm.continueExecution = false
+		return nil
+	}
+
+	if res.SaveResult {
+		arm.registers[res.SaveRegister] = res.SaveValue
+	}
+	arm.cycles += res.NumCycles
+
+	// return from the emulated function
+	arm.registers[rPC] = arm.registers[rLR] &^ 0x01
+
+	return nil
+}
+
+// format 6
+func (arm *ARM) executePCrelativeLoad(opcode uint16) {
+	destReg := uint32((opcode & 0x0700) >> 8)
+	imm := uint32(opcode&0x00ff) << 2
+
+	// bit 1 of the PC is forced to zero for the purposes of this instruction
+	addr := (arm.readRegister(rPC) &^ 0x02) + imm
+	arm.registers[destReg] = arm.read32(addr)
+	arm.cycles += 2
+}
+
+// format 7
+func (arm *ARM) executeLoadStoreWithRegisterOffset(opcode uint16) {
+	load := opcode&0x0800 == 0x0800
+	byteTransfer := opcode&0x0400 == 0x0400
+	offsetReg := uint32((opcode & 0x01c0) >> 6)
+	baseReg := uint32((opcode & 0x38) >> 3)
+	reg := uint32(opcode & 0x07)
+
+	addr := arm.registers[baseReg] + arm.registers[offsetReg]
+
+	if load {
+		if byteTransfer {
+			arm.registers[reg] = uint32(arm.read8(addr))
+		} else {
+			arm.registers[reg] = arm.read32(addr)
+		}
+		arm.cycles += 2
+		return
+	}
+
+	if byteTransfer {
+		arm.write8(addr, uint8(arm.registers[reg]))
+	} else {
+		arm.write32(addr, arm.registers[reg])
+	}
+	arm.cycles++
+}
+
+// format 8
+func (arm *ARM) executeLoadStoreSignExtendedByteHalford(opcode uint16) {
+	hi := opcode&0x0800 == 0x0800
+	sign := opcode&0x0400 == 0x0400
+	offsetReg := uint32((opcode & 0x01c0) >> 6)
+	baseReg := uint32((opcode & 0x38) >> 3)
+	reg := uint32(opcode & 0x07)
+
+	addr := arm.registers[baseReg] + arm.registers[offsetReg]
+
+	switch {
+	case !sign && !hi: // STRH
+		arm.write16(addr, uint16(arm.registers[reg]))
+		arm.cycles++
+		return
+	case !sign && hi: // LDRH
+		arm.registers[reg] = uint32(arm.read16(addr))
+	case sign && !hi: // LDSB
+		arm.registers[reg] = uint32(int32(int8(arm.read8(addr))))
+	case sign && hi: // LDSH
+		arm.registers[reg] = uint32(int32(int16(arm.read16(addr))))
+	}
+	arm.cycles += 2
+}
+
+// format 9
+func (arm *ARM) executeLoadStoreWithImmOffset(opcode uint16) {
+	byteTransfer := opcode&0x1000 == 0x1000
+	load := opcode&0x0800 == 0x0800
+	offset := uint32((opcode & 0x07c0) >> 6)
+	baseReg := uint32((opcode & 0x38) >> 3)
+	reg := uint32(opcode & 0x07)
+
+	// the offset is a word offset for word transfers
+	if !byteTransfer {
+		offset <<= 2
+	}
+
+	addr := arm.registers[baseReg] + offset
+
+	if load {
+		if byteTransfer {
+			arm.registers[reg] = uint32(arm.read8(addr))
+		} else {
+			arm.registers[reg] = arm.read32(addr)
+		}
+		arm.cycles += 2
+		return
+	}
+
+	if byteTransfer {
+		arm.write8(addr, uint8(arm.registers[reg]))
+	} else {
+		arm.write32(addr, arm.registers[reg])
+	}
+	arm.cycles++
+}
+
+// format 10
+func (arm *ARM) executeLoadStoreHalfword(opcode uint16) {
+	load := opcode&0x0800 == 0x0800
+	offset := uint32((opcode&0x07c0)>>6) << 1
+	baseReg := uint32((opcode & 0x38) >> 3)
+	reg := uint32(opcode & 0x07)
+
+	addr := arm.registers[baseReg] + offset
+
+	if load {
+		arm.registers[reg] = uint32(arm.read16(addr))
+		arm.cycles += 2
+		return
+	}
+
+	arm.write16(addr, uint16(arm.registers[reg]))
+	arm.cycles++
+}
+
+// format 11
+func (arm *ARM) executeSPRelativeLoadStore(opcode uint16) {
+	load := opcode&0x0800 == 0x0800
+	reg := uint32((opcode & 0x0700) >> 8)
+	offset := uint32(opcode&0x00ff) << 2
+
+	addr := arm.registers[rSP] + offset
+
+	if load {
+		arm.registers[reg] = arm.read32(addr)
+		arm.cycles += 2
+		return
+	}
+
+	arm.write32(addr, arm.registers[reg])
+	arm.cycles++
+}
+
+// format 12
+func (arm *ARM) executeLoadAddress(opcode uint16) {
+	sp := opcode&0x0800 == 0x0800
+	destReg := uint32((opcode & 0x0700) >> 8)
+	offset := uint32(opcode&0x00ff) << 2
+
+	if sp {
+		arm.registers[destReg] = arm.registers[rSP] + offset
+		return
+	}
+
+	// bit 1 of the PC is forced to zero for the purposes of this instruction
+	arm.registers[destReg] = (arm.readRegister(rPC) &^ 0x02) + offset
+}
+
+// format 13
+func (arm *ARM) executeAddOffsetToSP(opcode uint16) {
+	negative := opcode&0x80 == 0x80
+	offset := uint32(opcode&0x7f) << 2
+
+	if negative {
+		arm.registers[rSP] -= offset
+		return
+	}
+	arm.registers[rSP] += offset
+}
+
+// format 14
+func (arm *ARM) executePushPopRegisters(opcode uint16) {
+	load := opcode&0x0800 == 0x0800
+	pclr := opcode&0x0100 == 0x0100
+	regList := uint8(opcode & 0x00ff)
+
+	if load {
+		// POP
+		addr := arm.registers[rSP]
+		for i := uint32(0); i <= 7; i++ {
+			if regList&(1<<i) != 0 {
+				arm.registers[i] = arm.read32(addr)
+				addr += 4
+				arm.cycles++
+			}
+		}
+		if pclr {
+			arm.writeRegister(rPC, arm.read32(addr))
+			addr += 4
+			arm.cycles++
+		}
+		arm.registers[rSP] = addr
+		arm.cycles += 2
+		return
+	}
+
+	// PUSH
+	n := uint32(0)
+	for i := uint32(0); i <= 7; i++ {
+		if regList&(1<<i) != 0 {
+			n++
+		}
+	}
+	if pclr {
+		n++
+	}
+
+	addr := arm.registers[rSP] - (n * 4)
+	arm.registers[rSP] = addr
+
+	for i := uint32(0); i <= 7; i++ {
+		if regList&(1<<i) != 0 {
+			arm.write32(addr, arm.registers[i])
+			addr += 4
+			arm.cycles++
+		}
+	}
+	if pclr {
+		arm.write32(addr, arm.registers[rLR])
+		arm.cycles++
+	}
+}
+
+// format 15
+func (arm *ARM) executeMultipleLoadStore(opcode uint16) {
+	load := opcode&0x0800 == 0x0800
+	baseReg := uint32((opcode & 0x0700) >> 8)
+	regList := uint8(opcode & 0x00ff)
+
+	addr := arm.registers[baseReg]
+
+	// the base register is not written back if it has been loaded
+	writeback := true
+
+	for i := uint32(0); i <= 7; i++ {
+		if regList&(1<<i) != 0 {
+			if load {
+				arm.registers[i] = arm.read32(addr)
+				if i == baseReg {
+					writeback = false
+				}
+			} else {
+				arm.write32(addr, arm.registers[i])
+			}
+			addr += 4
+			arm.cycles++
+		}
+	}
+
+	if writeback {
+		arm.registers[baseReg] = addr
+	}
+
+	if load {
+		arm.cycles++
+	}
+}
+
+// format 16
+func (arm *ARM) executeConditionalBranch(opcode uint16) {
+	cond := uint8((opcode & 0x0f00) >> 8)
+	offset := uint32(int32(int8(opcode&0x00ff))) << 1
+
+	if arm.status.condition(cond) {
+		arm.registers[rPC] = arm.readRegister(rPC) + offset
+		arm.cycles += 2
+	}
+}
+
+// format 17
+func (arm *ARM) executeSoftwareInterrupt(opcode uint16) error {
+	return errors.New(errors.ARMError, fmt.Sprintf("SWI (%02x) at (%08x) not supported", opcode&0x00ff, arm.executingPC))
+}
+
+// format 18
+func (arm *ARM) executeUnconditionalBranch(opcode uint16) {
+	offset := uint32(opcode&0x07ff) << 1
+
+	// sign extend
+	if offset&0x800 == 0x800 {
+		offset |= 0xfffff000
+	}
+
+	arm.registers[rPC] = arm.readRegister(rPC) + offset
+	arm.cycles += 2
+}
+
+// format 19
+func (arm *ARM) executeLongBranchWithLink(opcode uint16) {
+	low := opcode&0x0800 == 0x0800
+	offset := uint32(opcode & 0x07ff)
+
+	if !low {
+		// first instruction of the pair. the offset is the high part of the
+		// branch offset
+		offset <<= 12
+		if offset&0x400000 == 0x400000 {
+			offset |= 0xff800000
+		}
+		arm.registers[rLR] = arm.readRegister(rPC) + offset
+		return
+	}
+
+	// second instruction of the pair. the address of the instruction
+	// following this one is saved in the link register (with bit zero set to
+	// indicate Thumb mode)
+	next := arm.registers[rPC]
+	arm.registers[rPC] = (arm.registers[rLR] + (offset << 1)) &^ 0x01
+	arm.registers[rLR] = next | 0x01
+	arm.cycles += 2
+}
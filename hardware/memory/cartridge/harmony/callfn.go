@@ -0,0 +1,94 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package harmony
+
+import (
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// the clock speeds of the ARM processor in the Harmony cartridge and of the
+// 6507 in the VCS (in MHz)
+const (
+	armClock = 70.0
+	vcsClock = 1.19
+)
+
+// the states of the callFunction type
+type callFunctionState int
+
+const (
+	callFunctionIdle callFunctionState = iota
+	callFunctionStart
+	callFunctionJMP
+	callFunctionJMPlo
+	callFunctionJMPhi
+)
+
+// callFunction stalls the 6507 while the ARM program is running. while the
+// ARM is running the 6507 is repeatedly fed a JMP instruction to the address
+// at which it was stalled. when the ARM program has completed, the 6507
+// continues from that address.
+//
+// the ARM program itself is run to completion immediately. the stall is to
+// ensure that the 6507 program, and by extension the TIA, experiences the
+// correct passage of time.
+type callFunction struct {
+	state callFunctionState
+
+	// the number of 6507 cycles remaining before the 6507 is released
+	remaining int
+
+	// the address of the first instruction to be executed by the 6507 after
+	// it has been released
+	resume uint16
+}
+
+// start stalling the 6507 for the number of ARM cycles
+func (cf *callFunction) start(armCycles int) {
+	cf.remaining = int(float64(armCycles) * vcsClock / armClock)
+	cf.state = callFunctionStart
+}
+
+// check should be called on every non-passive read of the cartridge. returns
+// the value to put on the data bus and true if the 6507 is being stalled
+func (cf *callFunction) check(addr uint16) (uint8, bool) {
+	switch cf.state {
+	case callFunctionStart:
+		// the first read after the ARM program has been started is the
+		// opcode of the next 6507 instruction
+		cf.resume = addr
+		cf.state = callFunctionJMP
+		fallthrough
+	case callFunctionJMP:
+		if cf.remaining <= 0 {
+			cf.state = callFunctionIdle
+			return 0, false
+		}
+
+		// JMP absolute takes three cycles
+		cf.remaining -= 3
+		cf.state = callFunctionJMPlo
+		return 0x4c, true
+	case callFunctionJMPlo:
+		cf.state = callFunctionJMPhi
+		return uint8(cf.resume), true
+	case callFunctionJMPhi:
+		cf.state = callFunctionJMP
+		return uint8((cf.resume | memorymap.OriginCart) >> 8), true
+	}
+
+	return 0, false
+}
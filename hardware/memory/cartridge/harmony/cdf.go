@@ -0,0 +1,554 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package harmony
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/banks"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/harmony/arm7tdmi"
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// cdf implements the cartMapper interface.
+//
+// https://atariage.com/forums/topic/262817-cdf-bankswitching/
+//
+// The CDFJ format is a variation of CDF and is supported by the same type.
+type cdf struct {
+	mappingID   string
+	description string
+
+	version cdfVersion
+
+	// the entire cartridge file. the ARM sees this as flash memory
+	image []byte
+
+	// banks and the currently selected bank
+	bankSize int
+	banks    [][]byte
+	bank     int
+
+	// the ARM sees this as SRAM. the driver is copied to the beginning of
+	// the RAM on initialisation. the registers of the cartridge (the
+	// datastream pointers etc.) are also in the RAM.
+	ram []byte
+
+	// the ARM coprocessor and the stalling of the 6507 while the ARM program
+	// is running
+	arm    *arm7tdmi.ARM
+	callfn callFunction
+
+	// the cartridge mode as set by the SETMODE register
+	mode uint8
+
+	// address of the operand of the most recent LDA <immediate> instruction
+	// in fast fetch mode. zero if there is no LDA operand pending
+	ldaOperand uint16
+
+	// address of the next operand byte of a JMP FASTJMP instruction. zero if
+	// there is no JMP operand pending
+	jmpOperand uint16
+
+	// the datastream to use for the JMP FASTJMP operand
+	jmpStream uint8
+
+	// music fetchers are clocked at a fixed (slower) rate than the reference
+	// to the VCS's clock. see Step() function.
+	beats int
+	music [3]cdfMusic
+}
+
+// the music state is not stored in RAM. the driver functions that access
+// this state are emulated (see ARMinterrupt() function)
+type cdfMusic struct {
+	Freq     uint32
+	Count    uint32
+	WaveSize uint8
+}
+
+// memory map of the cartridge as seen by the ARM
+const (
+	cdfFlashOrigin = 0x00000000
+	cdfRAMorigin   = 0x40000000
+
+	// the 6507 program banks begin after the driver and custom ARM code
+	cdfDriverSize     = 2048
+	cdfCustomSize     = 2048
+	cdfRAMsize        = 8192
+	cdfDisplayOrigin  = 0x0800
+	cdfVariableOrigin = 0x1800
+)
+
+// special datastreams
+const (
+	cdfCommStream     = 0x20
+	cdfJumpStreamBase = 0x21
+)
+
+// NewCDF is the preferred method of initialisation for the cdf type
+func NewCDF(data []byte) (*cdf, error) {
+	version, ok := cdfVersionByte(data)
+	if !ok {
+		return nil, errors.New(errors.CartridgeError, "CDF: no version string in driver")
+	}
+
+	cart := &cdf{
+		version:     newCDFversion(version),
+		description: "harmony",
+		image:       data,
+		bankSize:    4096,
+		ram:         make([]byte, cdfRAMsize),
+	}
+	cart.mappingID = cart.version.mappingID
+
+	bankLen := len(data) - cdfDriverSize - cdfCustomSize
+
+	// size check
+	if bankLen <= 0 || bankLen%cart.bankSize != 0 {
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in cartridge data", cart.mappingID))
+	}
+
+	// partition data into banks
+	cart.banks = make([][]uint8, bankLen/cart.bankSize)
+	for k := 0; k < cart.NumBanks(); k++ {
+		offset := cdfDriverSize + cdfCustomSize + k*cart.bankSize
+		cart.banks[k] = data[offset : offset+cart.bankSize]
+	}
+
+	cart.arm = arm7tdmi.NewARM(cart, cart)
+
+	// initialise cartridge before returning success
+	cart.Initialise()
+
+	return cart, nil
+}
+
+func (cart cdf) String() string {
+	return fmt.Sprintf("%s [%s] Bank: %d", cart.mappingID, cart.description, cart.bank)
+}
+
+func (cart cdf) ID() string {
+	return cart.mappingID
+}
+
+func (cart *cdf) Initialise() {
+	cart.bank = len(cart.banks) - 1
+	cart.mode = 0xff
+	cart.ldaOperand = 0
+	cart.jmpOperand = 0
+
+	// copy driver to RAM. the remainder of RAM is cleared
+	for i := range cart.ram {
+		cart.ram[i] = 0
+	}
+	copy(cart.ram, cart.image[:cdfDriverSize])
+
+	for i := range cart.music {
+		cart.music[i] = cdfMusic{WaveSize: 27}
+	}
+}
+
+// fast fetch mode is on when the lower nibble of the mode is zero
+func (cart *cdf) fastFetch() bool {
+	return cart.mode&0x0f == 0x00
+}
+
+// digital audio mode is on when the upper nibble of the mode is zero
+func (cart *cdf) digitalAudio() bool {
+	return cart.mode&0xf0 == 0x00
+}
+
+func (cart *cdf) Read(addr uint16, passive bool) (uint8, error) {
+	data := cart.banks[cart.bank][addr]
+
+	// leave early if this is a passive read. we don't want to disturb the
+	// state of the datastreams
+	if passive {
+		return data, nil
+	}
+
+	// the 6507 is stalled while the ARM program is running
+	if data, ok := cart.callfn.check(addr); ok {
+		return data, nil
+	}
+
+	// the operand of a JMP FASTJMP instruction is read from the jump
+	// datastream
+	if cart.jmpOperand != 0 {
+		if cart.jmpOperand == addr {
+			cart.jmpOperand++
+			return cart.readDatastream(cart.jmpStream), nil
+		}
+		cart.jmpOperand = 0
+	}
+
+	if cart.fastFetch() {
+		// JMP FASTJMP
+		if data == 0x4c && addr < 0x0ffe {
+			lo := cart.banks[cart.bank][addr+1]
+			hi := cart.banks[cart.bank][addr+2]
+			if lo&cart.version.fastJumpMask == 0 && hi == 0 {
+				cart.jmpOperand = addr + 1
+				cart.jmpStream = cdfJumpStreamBase + lo
+				return data, nil
+			}
+		}
+
+		// the operand of an LDA <immediate> instruction is interpreted as a
+		// datastream if it is in range
+		if cart.ldaOperand == addr && data <= cart.version.amplitudeStream {
+			cart.ldaOperand = 0
+			if data == cart.version.amplitudeStream {
+				return cart.readAmplitude(), nil
+			}
+			return cart.readDatastream(data), nil
+		}
+	}
+
+	cart.ldaOperand = 0
+
+	if cart.hotspot(addr) {
+		return data, nil
+	}
+
+	if cart.fastFetch() && data == 0xa9 {
+		cart.ldaOperand = addr + 1
+	}
+
+	return data, nil
+}
+
+func (cart *cdf) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if passive {
+		return nil
+	}
+
+	switch addr {
+	case 0x0ff0:
+		// DSWRITE
+		ptr := cart.streamPointer(cdfCommStream)
+		cart.ram[cdfDisplayOrigin+(ptr>>20)&0x0fff] = data
+		cart.setStreamPointer(cdfCommStream, ptr+0x00100000)
+		return nil
+
+	case 0x0ff1:
+		// DSPTR
+		ptr := cart.streamPointer(cdfCommStream)
+		ptr = (ptr<<8)&0xf0000000 | uint32(data)<<20
+		cart.setStreamPointer(cdfCommStream, ptr)
+		return nil
+
+	case 0x0ff2:
+		// SETMODE
+		cart.mode = data
+		return nil
+
+	case 0x0ff3:
+		// CALLFN
+		switch data {
+		case 0xfe:
+			// call with IRQ driven audio. no special handling required
+			fallthrough
+		case 0xff:
+			cycles, err := cart.arm.Run()
+			if err != nil {
+				return err
+			}
+			cart.callfn.start(cycles)
+		}
+		return nil
+	}
+
+	if cart.hotspot(addr) {
+		return nil
+	}
+
+	if poke {
+		cart.banks[cart.bank][addr] = data
+		return nil
+	}
+
+	return errors.New(errors.MemoryBusError, addr)
+}
+
+// bankswitch on hotspot access
+func (cart *cdf) hotspot(addr uint16) bool {
+	if addr >= 0x0ff5 && addr <= 0x0ffb {
+		cart.bank = int(addr - 0x0ff5)
+		return true
+	}
+	return false
+}
+
+// the value of the datastream pointer is stored in RAM
+func (cart *cdf) streamPointer(stream uint8) uint32 {
+	idx := cart.version.datastreamBase + uint16(stream)*4
+	return uint32(cart.ram[idx]) | uint32(cart.ram[idx+1])<<8 | uint32(cart.ram[idx+2])<<16 | uint32(cart.ram[idx+3])<<24
+}
+
+func (cart *cdf) setStreamPointer(stream uint8, ptr uint32) {
+	idx := cart.version.datastreamBase + uint16(stream)*4
+	cart.ram[idx] = uint8(ptr)
+	cart.ram[idx+1] = uint8(ptr >> 8)
+	cart.ram[idx+2] = uint8(ptr >> 16)
+	cart.ram[idx+3] = uint8(ptr >> 24)
+}
+
+// the datastream increment is stored in RAM. only the lower 16 bits are
+// meaningful
+func (cart *cdf) streamIncrement(stream uint8) uint32 {
+	idx := cart.version.incrementBase + uint16(stream)*4
+	return uint32(cart.ram[idx]) | uint32(cart.ram[idx+1])<<8
+}
+
+func (cart *cdf) setStreamIncrement(stream uint8, inc uint32) {
+	idx := cart.version.incrementBase + uint16(stream)*4
+	cart.ram[idx] = uint8(inc)
+	cart.ram[idx+1] = uint8(inc >> 8)
+}
+
+// the waveform pointer is stored in RAM as an ARM address. the value returned
+// by this function is an index into the display data
+func (cart *cdf) waveform(voice int) uint32 {
+	idx := cart.version.waveformBase + uint16(voice)*4
+	w := uint32(cart.ram[idx]) | uint32(cart.ram[idx+1])<<8 | uint32(cart.ram[idx+2])<<16 | uint32(cart.ram[idx+3])<<24
+	w -= cdfRAMorigin + cdfDisplayOrigin
+	return w & 0x0fff
+}
+
+// in digital audio mode the waveform pointer of the first voice is the
+// address of the sample data. the address is unchanged from how it is stored
+// in RAM
+func (cart *cdf) sample() uint32 {
+	idx := cart.version.waveformBase
+	return uint32(cart.ram[idx]) | uint32(cart.ram[idx+1])<<8 | uint32(cart.ram[idx+2])<<16 | uint32(cart.ram[idx+3])<<24
+}
+
+func (cart *cdf) readDatastream(stream uint8) uint8 {
+	ptr := cart.streamPointer(stream)
+	data := cart.ram[cdfDisplayOrigin+(ptr>>20)&0x0fff]
+	ptr += cart.streamIncrement(stream) << 12
+	cart.setStreamPointer(stream, ptr)
+	return data
+}
+
+func (cart *cdf) readAmplitude() uint8 {
+	if cart.digitalAudio() {
+		// samples are packed two to a byte
+		addr := cart.sample() + (cart.music[0].Count >> 21)
+
+		var data uint8
+		if addr < uint32(len(cart.image)) {
+			data = cart.image[addr]
+		} else if addr >= cdfRAMorigin && addr < cdfRAMorigin+cdfRAMsize {
+			data = cart.ram[addr-cdfRAMorigin]
+		}
+
+		// the sample is in the upper nibble if bit 20 of the counter is unset
+		if cart.music[0].Count&(1<<20) == 0 {
+			data >>= 4
+		}
+		return data & 0x0f
+	}
+
+	var data uint8
+	for v := range cart.music {
+		idx := cart.waveform(v) + (cart.music[v].Count >> cart.music[v].WaveSize)
+		data += cart.ram[cdfDisplayOrigin+idx&0x0fff]
+	}
+	return data
+}
+
+func (cart cdf) NumBanks() int {
+	return len(cart.banks)
+}
+
+func (cart cdf) GetBank(addr uint16) banks.Details {
+	return banks.Details{Number: cart.bank, IsRAM: false}
+}
+
+func (cart *cdf) Patch(offset int, data uint8) error {
+	if offset >= len(cart.image) {
+		return errors.New(errors.CartridgePatchOOB, offset)
+	}
+
+	// the banks are slices of the image so patching the image is sufficient
+	cart.image[offset] = data
+
+	return nil
+}
+
+func (cart *cdf) Listen(addr uint16, data uint8) {
+}
+
+func (cart *cdf) Step() {
+	// the music fetchers are clocked at 20KHz. see the Step() function in
+	// the dpcPlus type for commentary
+	cart.beats++
+	if cart.beats%59 == 0 {
+		cart.beats = 0
+		cart.music[0].Count += cart.music[0].Freq
+		cart.music[1].Count += cart.music[1].Freq
+		cart.music[2].Count += cart.music[2].Freq
+	}
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *cdf) Snapshot() interface{} {
+	n := *cart
+
+	// RAM contains the cartridge registers as well as the display data
+	n.ram = make([]byte, len(cart.ram))
+	copy(n.ram, cart.ram)
+
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *cdf) Restore(s interface{}) error {
+	if s, ok := s.(*cdf); ok {
+		*cart = *s.Snapshot().(*cdf)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the cdf type as returned by SaveState()
+type cdfState struct {
+	Bank            int
+	RAM             []byte
+	CallFnState     int
+	CallFnRemaining int
+	CallFnResume    uint16
+	Mode            uint8
+	LDAOperand      uint16
+	JMPOperand      uint16
+	JMPStream       uint8
+	Beats           int
+	Music           [3]cdfMusic
+}
+
+// SaveState implements the cartMapper interface
+func (cart *cdf) SaveState() interface{} {
+	return cdfState{
+		Bank:            cart.bank,
+		RAM:             cart.ram,
+		CallFnState:     int(cart.callfn.state),
+		CallFnRemaining: cart.callfn.remaining,
+		CallFnResume:    cart.callfn.resume,
+		Mode:            cart.mode,
+		LDAOperand:      cart.ldaOperand,
+		JMPOperand:      cart.jmpOperand,
+		JMPStream:       cart.jmpStream,
+		Beats:           cart.beats,
+		Music:           cart.music,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *cdf) LoadState(decode func(interface{}) error) error {
+	var s cdfState
+	if err := decode(&s); err != nil {
+		return err
+	}
+	if s.Bank < 0 || s.Bank >= len(cart.banks) {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("invalid bank in cartridge state (%d)", s.Bank))
+	}
+	if len(s.RAM) != len(cart.ram) {
+		return errors.New(errors.SnapshotError, "cartridge RAM state is the wrong size")
+	}
+
+	copy(cart.ram, s.RAM)
+	cart.bank = s.Bank
+	cart.callfn.state = callFunctionState(s.CallFnState)
+	cart.callfn.remaining = s.CallFnRemaining
+	cart.callfn.resume = s.CallFnResume
+	cart.mode = s.Mode
+	cart.ldaOperand = s.LDAOperand
+	cart.jmpOperand = s.JMPOperand
+	cart.jmpStream = s.JMPStream
+	cart.beats = s.Beats
+	cart.music = s.Music
+
+	return nil
+}
+
+// IterateBank implemnts the disassemble interface
+func (cart cdf) IterateBanks(prev *banks.Content) *banks.Content {
+	b := prev.Number + 1
+	if b < len(cart.banks) {
+		return &banks.Content{Number: b,
+			Data: cart.banks[b],
+			Origins: []uint16{
+				memorymap.OriginCart,
+			},
+		}
+	}
+	return nil
+}
+
+// MapAddress implements the arm7tdmi.SharedMemory interface
+func (cart *cdf) MapAddress(addr uint32, write bool) ([]byte, uint32) {
+	if addr >= cdfRAMorigin && addr < cdfRAMorigin+uint32(len(cart.ram)) {
+		return cart.ram, cdfRAMorigin
+	}
+
+	// flash memory is not writable
+	if !write && addr < cdfFlashOrigin+uint32(len(cart.image)) {
+		return cart.image, cdfFlashOrigin
+	}
+
+	return nil, 0
+}
+
+// ResetVectors implements the arm7tdmi.SharedMemory interface
+func (cart *cdf) ResetVectors() (uint32, uint32, uint32) {
+	return 0x40001fb4, 0x00000800, 0x00000808
+}
+
+// ARMinterrupt implements the arm7tdmi.CartridgeHook interface
+func (cart *cdf) ARMinterrupt(addr uint32, val1 uint32, val2 uint32) (arm7tdmi.ARMinterruptReturn, error) {
+	var r arm7tdmi.ARMinterruptReturn
+
+	// the voice number is always in the first value
+	if val1 >= uint32(len(cart.music)) {
+		if addr == cart.version.setNote || addr == cart.version.resetWave ||
+			addr == cart.version.getWavePtr || addr == cart.version.setWaveSize {
+			return r, errors.New(errors.CartridgeError, fmt.Sprintf("%s: music voice out of range (%d)", cart.mappingID, val1))
+		}
+	}
+
+	switch addr {
+	case cart.version.setNote:
+		cart.music[val1].Freq = val2
+		r.InterruptServiced = true
+	case cart.version.resetWave:
+		cart.music[val1].Count = 0
+		r.InterruptServiced = true
+	case cart.version.getWavePtr:
+		r.SaveValue = cart.music[val1].Count
+		r.SaveRegister = 2
+		r.SaveResult = true
+		r.InterruptServiced = true
+	case cart.version.setWaveSize:
+		cart.music[val1].WaveSize = uint8(val2)
+		r.InterruptServiced = true
+	}
+
+	return r, nil
+}
@@ -0,0 +1,147 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package harmony
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/hardware/memory/bus"
+)
+
+// CDFRegisters implements the bus.CartRegisters interface
+type CDFRegisters struct {
+	Datastream [35]cdfDatastream
+	Music      [3]cdfMusic
+
+	// the value of the SETMODE register
+	Mode uint8
+
+	FastFetch    bool
+	DigitalAudio bool
+}
+
+type cdfDatastream struct {
+	Pointer   uint32
+	Increment uint32
+}
+
+func (r CDFRegisters) String() string {
+	s := strings.Builder{}
+
+	s.WriteString(fmt.Sprintf("Mode: %#02x\n", r.Mode))
+	s.WriteString(fmt.Sprintf("Fast Fetch: %#v\n", r.FastFetch))
+	s.WriteString(fmt.Sprintf("Digital Audio: %#v\n", r.DigitalAudio))
+
+	s.WriteString("\nDatastreams\n")
+	s.WriteString("-----------\n")
+	for f := 0; f < len(r.Datastream); f++ {
+		s.WriteString(fmt.Sprintf("DS%02d: p:%#08x i:%#04x", f,
+			r.Datastream[f].Pointer,
+			r.Datastream[f].Increment,
+		))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\nMusic\n")
+	s.WriteString("-----\n")
+	for f := 0; f < len(r.Music); f++ {
+		s.WriteString(fmt.Sprintf("V%d: f:%#08x c:%#08x s:%d", f,
+			r.Music[f].Freq,
+			r.Music[f].Count,
+			r.Music[f].WaveSize,
+		))
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// GetRegisters implements the bus.CartDebugBus interface
+func (cart cdf) GetRegisters() bus.CartRegisters {
+	r := CDFRegisters{
+		Music:        cart.music,
+		Mode:         cart.mode,
+		FastFetch:    cart.fastFetch(),
+		DigitalAudio: cart.digitalAudio(),
+	}
+
+	// the number of datastreams available depends on the version
+	n := int(cart.version.amplitudeStream)
+	for f := 0; f < n && f < len(r.Datastream); f++ {
+		r.Datastream[f].Pointer = cart.streamPointer(uint8(f))
+		r.Datastream[f].Increment = cart.streamIncrement(uint8(f))
+	}
+
+	return r
+}
+
+// PutRegister implements the bus.CartDebugBus interface
+//
+// Register specification is divided with the "::" string. The following table
+// describes what the valid register strings and, after the = sign, the type to
+// which the data argument will be converted.
+//
+//	datastream::%int::pointer = uint32
+//	datastream::%int::increment = uint16
+//	music::%int::freq = uint32
+//	music::%int::count = uint32
+//	music::%int::wavesize = uint8
+//	mode = uint8
+//
+// note that PutRegister() will panic() if the register or data string is invalid.
+func (cart *cdf) PutRegister(register string, data string) {
+	// most data is expected to be a hexadecimal integer. if it doesn't
+	// convert then it doesn't matter
+	d, _ := strconv.ParseUint(data, 16, 32)
+
+	r := strings.Split(register, "::")
+	switch r[0] {
+	case "datastream":
+		f, err := strconv.Atoi(r[1])
+		if err != nil || f < 0 || f >= int(cart.version.amplitudeStream) {
+			panic(fmt.Sprintf("unrecognised datastream [%s]", register))
+		}
+		switch r[2] {
+		case "pointer":
+			cart.setStreamPointer(uint8(f), uint32(d))
+		case "increment":
+			cart.setStreamIncrement(uint8(f), uint32(d))
+		default:
+			panic(fmt.Sprintf("unrecognised variable [%s]", register))
+		}
+	case "music":
+		f, err := strconv.Atoi(r[1])
+		if err != nil || f < 0 || f >= len(cart.music) {
+			panic(fmt.Sprintf("unrecognised voice [%s]", register))
+		}
+		switch r[2] {
+		case "freq":
+			cart.music[f].Freq = uint32(d)
+		case "count":
+			cart.music[f].Count = uint32(d)
+		case "wavesize":
+			cart.music[f].WaveSize = uint8(d)
+		default:
+			panic(fmt.Sprintf("unrecognised variable [%s]", register))
+		}
+	case "mode":
+		cart.mode = uint8(d)
+	default:
+		panic(fmt.Sprintf("unrecognised variable [%s]", register))
+	}
+}
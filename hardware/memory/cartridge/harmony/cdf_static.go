@@ -0,0 +1,62 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package harmony
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/memory/bus"
+)
+
+// the areas of the CDF RAM as presented by the bus.CartStaticBus interface
+var cdfStaticAreas = []struct {
+	label  string
+	origin int
+	memtop int
+}{
+	{label: "Driver", origin: 0x0000, memtop: cdfDisplayOrigin},
+	{label: "Data", origin: cdfDisplayOrigin, memtop: cdfVariableOrigin},
+	{label: "Variables", origin: cdfVariableOrigin, memtop: cdfRAMsize},
+}
+
+// GetStatic implements the bus.CartDebugBus interface
+func (cart cdf) GetStatic() []bus.CartStatic {
+	s := make([]bus.CartStatic, len(cdfStaticAreas))
+
+	for i, a := range cdfStaticAreas {
+		s[i].Label = a.label
+		s[i].Data = make([]byte, a.memtop-a.origin)
+		copy(s[i].Data, cart.ram[a.origin:a.memtop])
+	}
+
+	return s
+}
+
+// StaticWrite implements the bus.CartDebugBus interface
+func (cart *cdf) PutStatic(label string, addr uint16, data uint8) error {
+	for _, a := range cdfStaticAreas {
+		if a.label == label {
+			if int(addr) >= a.memtop-a.origin {
+				return errors.New(errors.CartridgeStaticArea, fmt.Errorf("address too high (%#04x) for %s area", addr, label))
+			}
+			cart.ram[a.origin+int(addr)] = data
+			return nil
+		}
+	}
+
+	return errors.New(errors.CartridgeStaticArea, fmt.Errorf("unknown static area (%s)", label))
+}
@@ -0,0 +1,282 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package harmony
+
+import (
+	"testing"
+)
+
+// cdfImage returns a CDFJ cartridge image with seven 4k banks. the first
+// bytes of each bank are an LDA <immediate> instruction for the datastream
+// given by the operand, an LDA <immediate> for the amplitude stream and an
+// LDA <immediate> with an operand that is out of range for a datastream. the
+// byte at 0x0100 of each bank is the bank number.
+func cdfImage() []byte {
+	data := make([]byte, cdfDriverSize+cdfCustomSize+7*4096)
+
+	// version string, placed so that it doesn't overlap the registers
+	copy(data[0x0200:], "CDF\x4aCDF\x4aCDF\x4a")
+
+	for b := 0; b < 7; b++ {
+		bank := data[cdfDriverSize+cdfCustomSize+b*4096:]
+		copy(bank, []byte{0xa9, 0x05, 0xa9, 0x23, 0xa9, 0x30})
+		bank[0x0100] = byte(b)
+	}
+
+	return data
+}
+
+func newTestCDF(t *testing.T) *cdf {
+	t.Helper()
+
+	cart, err := NewCDF(cdfImage())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cart.ID() != "CDFJ" {
+		t.Fatalf("unexpected mapping ID: %s", cart.ID())
+	}
+	return cart
+}
+
+func readCDF(t *testing.T, cart *cdf, addr uint16) uint8 {
+	t.Helper()
+
+	d, err := cart.Read(addr, false)
+	if err != nil {
+		t.Fatalf("unexpected error reading %#04x: %v", addr, err)
+	}
+	return d
+}
+
+func TestCDFDatastream(t *testing.T) {
+	cart := newTestCDF(t)
+
+	// fast fetch on
+	if err := cart.Write(0x0ff2, 0x00, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	copy(cart.ram[cdfDisplayOrigin+0x10:], []byte{0x11, 0x22, 0x33})
+
+	// a whole increment advances the pointer by one byte on every read
+	cart.setStreamPointer(5, 0x10<<20)
+	cart.setStreamIncrement(5, 0x100)
+
+	for _, expected := range []uint8{0x11, 0x22, 0x33} {
+		if d := readCDF(t, cart, 0x0000); d != 0xa9 {
+			t.Fatalf("expected LDA opcode (0xa9) but got %#02x", d)
+		}
+		if d := readCDF(t, cart, 0x0001); d != expected {
+			t.Errorf("expected datastream value %#02x but got %#02x", expected, d)
+		}
+	}
+
+	if p := cart.streamPointer(5); p != 0x13<<20 {
+		t.Errorf("unexpected datastream pointer after reads: %#08x", p)
+	}
+
+	// a half increment advances the pointer by one byte every other read
+	cart.setStreamPointer(5, 0x10<<20)
+	cart.setStreamIncrement(5, 0x80)
+
+	for _, expected := range []uint8{0x11, 0x11, 0x22, 0x22} {
+		readCDF(t, cart, 0x0000)
+		if d := readCDF(t, cart, 0x0001); d != expected {
+			t.Errorf("expected datastream value %#02x but got %#02x", expected, d)
+		}
+	}
+
+	// passive reads do not disturb the datastream
+	cart.setStreamPointer(5, 0x10<<20)
+	cart.setStreamIncrement(5, 0x100)
+	if _, err := cart.Read(0x0000, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, _ := cart.Read(0x0001, true); d != 0x05 {
+		t.Errorf("expected passive read to return the operand (0x05) but got %#02x", d)
+	}
+	if p := cart.streamPointer(5); p != 0x10<<20 {
+		t.Errorf("passive read changed datastream pointer: %#08x", p)
+	}
+}
+
+func TestCDFFastFetch(t *testing.T) {
+	cart := newTestCDF(t)
+
+	cart.ram[cdfDisplayOrigin] = 0x99
+	cart.setStreamIncrement(5, 0x100)
+
+	// fast fetch is off after initialisation. the LDA operand is read from
+	// the cartridge as normal
+	readCDF(t, cart, 0x0000)
+	if d := readCDF(t, cart, 0x0001); d != 0x05 {
+		t.Errorf("expected operand (0x05) with fast fetch off but got %#02x", d)
+	}
+	if p := cart.streamPointer(5); p != 0 {
+		t.Errorf("datastream pointer changed with fast fetch off: %#08x", p)
+	}
+
+	// fast fetch on
+	if err := cart.Write(0x0ff2, 0x00, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cart.fastFetch() {
+		t.Fatalf("expected fast fetch to be on")
+	}
+
+	readCDF(t, cart, 0x0000)
+	if d := readCDF(t, cart, 0x0001); d != 0x99 {
+		t.Errorf("expected datastream value (0x99) with fast fetch on but got %#02x", d)
+	}
+
+	// an operand that isn't immediately preceded by the LDA opcode is not
+	// intercepted
+	cart.setStreamPointer(5, 0)
+	if d := readCDF(t, cart, 0x0001); d != 0x05 {
+		t.Errorf("expected operand (0x05) without LDA opcode but got %#02x", d)
+	}
+
+	// an operand greater than the amplitude stream is not intercepted
+	readCDF(t, cart, 0x0004)
+	if d := readCDF(t, cart, 0x0005); d != 0x30 {
+		t.Errorf("expected out of range operand (0x30) but got %#02x", d)
+	}
+
+	// fast fetch off again
+	if err := cart.Write(0x0ff2, 0xff, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	readCDF(t, cart, 0x0000)
+	if d := readCDF(t, cart, 0x0001); d != 0x05 {
+		t.Errorf("expected operand (0x05) with fast fetch off but got %#02x", d)
+	}
+}
+
+func TestCDFMusicFetchers(t *testing.T) {
+	cart := newTestCDF(t)
+
+	// fast fetch on and digital audio off
+	if err := cart.Write(0x0ff2, 0xf0, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// waveforms for each voice in the display data
+	for v, w := range []uint32{0x0100, 0x0200, 0x0300} {
+		idx := cart.version.waveformBase + uint16(v)*4
+		p := cdfRAMorigin + cdfDisplayOrigin + w
+		cart.ram[idx] = uint8(p)
+		cart.ram[idx+1] = uint8(p >> 8)
+		cart.ram[idx+2] = uint8(p >> 16)
+		cart.ram[idx+3] = uint8(p >> 24)
+	}
+	cart.ram[cdfDisplayOrigin+0x0100] = 1
+	cart.ram[cdfDisplayOrigin+0x0101] = 3
+	cart.ram[cdfDisplayOrigin+0x0200] = 4
+	cart.ram[cdfDisplayOrigin+0x0300] = 5
+
+	// set frequency of first voice with the emulated driver function. the
+	// default wave size of 27 means that the counter must reach 1<<27 before
+	// the next sample in the waveform is used
+	if _, err := cart.ARMinterrupt(cart.version.setNote, 0, 1<<27); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	readCDF(t, cart, 0x0002)
+	if d := readCDF(t, cart, 0x0003); d != 10 {
+		t.Errorf("expected amplitude of 10 but got %d", d)
+	}
+
+	// the music fetchers are clocked once every 59 steps
+	for i := 0; i < 58; i++ {
+		cart.Step()
+	}
+	if cart.music[0].Count != 0 {
+		t.Errorf("music counter advanced too early: %#08x", cart.music[0].Count)
+	}
+	cart.Step()
+	if cart.music[0].Count != 1<<27 {
+		t.Errorf("unexpected music counter: %#08x", cart.music[0].Count)
+	}
+
+	readCDF(t, cart, 0x0002)
+	if d := readCDF(t, cart, 0x0003); d != 12 {
+		t.Errorf("expected amplitude of 12 but got %d", d)
+	}
+
+	// the counter is returned by the emulated driver function
+	r, err := cart.ARMinterrupt(cart.version.getWavePtr, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !r.SaveResult || r.SaveValue != 1<<27 {
+		t.Errorf("unexpected wave pointer result: %#08x", r.SaveValue)
+	}
+
+	// and reset by another
+	if _, err := cart.ARMinterrupt(cart.version.resetWave, 0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cart.music[0].Count != 0 {
+		t.Errorf("music counter not reset: %#08x", cart.music[0].Count)
+	}
+
+	// voice number out of range
+	if _, err := cart.ARMinterrupt(cart.version.setNote, 3, 0); err == nil {
+		t.Errorf("expected error for out of range voice")
+	}
+}
+
+func TestCDFBankSwitching(t *testing.T) {
+	cart := newTestCDF(t)
+
+	if cart.bank != 6 {
+		t.Fatalf("expected last bank after initialisation but got %d", cart.bank)
+	}
+
+	for b := 0; b < 7; b++ {
+		readCDF(t, cart, 0x0ff5+uint16(b))
+		if cart.bank != b {
+			t.Errorf("expected bank %d after read of %#04x but got %d", b, 0x0ff5+b, cart.bank)
+		}
+		if d := readCDF(t, cart, 0x0100); d != uint8(b) {
+			t.Errorf("expected data from bank %d but got %d", b, d)
+		}
+	}
+
+	// writes to hotspots also switch banks
+	if err := cart.Write(0x0ff8, 0x00, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cart.bank != 3 {
+		t.Errorf("expected bank 3 after write but got %d", cart.bank)
+	}
+
+	// passive reads do not
+	if _, err := cart.Read(0x0ff5, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cart.bank != 3 {
+		t.Errorf("passive read switched bank to %d", cart.bank)
+	}
+
+	// addresses either side of the hotspot range do not switch banks
+	readCDF(t, cart, 0x0ff4)
+	readCDF(t, cart, 0x0ffc)
+	if cart.bank != 3 {
+		t.Errorf("non-hotspot read switched bank to %d", cart.bank)
+	}
+}
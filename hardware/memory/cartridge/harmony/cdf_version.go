@@ -0,0 +1,110 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package harmony
+
+// the different versions of the CDF format differ in the location of the
+// registers in RAM and in some details of the fast fetch mode.
+type cdfVersion struct {
+	mappingID string
+
+	// fast fetch LDA operands less than or equal to this value are
+	// interpreted as a datastream. a value equal to amplitudeStream returns the
+	// current music sample
+	amplitudeStream uint8
+
+	// location in RAM of the datastream pointers, the datastream increments
+	// and the waveform pointers
+	datastreamBase uint16
+	incrementBase  uint16
+	waveformBase   uint16
+
+	// the JMP FASTJMP instruction is detected by masking the operand of the
+	// JMP instruction with this value
+	fastJumpMask uint8
+
+	// addresses of the BX instructions in the driver that branch to the
+	// ARM functions emulated by the cartridge
+	setNote     uint32
+	resetWave   uint32
+	getWavePtr  uint32
+	setWaveSize uint32
+}
+
+func newCDFversion(version byte) cdfVersion {
+	switch version {
+	case 0x4a:
+		return cdfVersion{
+			mappingID:       "CDFJ",
+			amplitudeStream: 0x23,
+			datastreamBase:  0x0098,
+			incrementBase:   0x0124,
+			waveformBase:    0x01b0,
+			fastJumpMask:    0xfe,
+			setNote:         0x00000752,
+			resetWave:       0x00000756,
+			getWavePtr:      0x0000075a,
+			setWaveSize:     0x0000075e,
+		}
+	case 0x00:
+		return cdfVersion{
+			mappingID:       "CDF",
+			amplitudeStream: 0x22,
+			datastreamBase:  0x06e0,
+			incrementBase:   0x0768,
+			waveformBase:    0x07f0,
+			fastJumpMask:    0xff,
+			setNote:         0x000006e2,
+			resetWave:       0x000006e6,
+			getWavePtr:      0x000006ea,
+			setWaveSize:     0x000006ee,
+		}
+	}
+
+	return cdfVersion{
+		mappingID:       "CDF",
+		amplitudeStream: 0x22,
+		datastreamBase:  0x00a0,
+		incrementBase:   0x0128,
+		waveformBase:    0x01b0,
+		fastJumpMask:    0xff,
+		setNote:         0x00000752,
+		resetWave:       0x00000756,
+		getWavePtr:      0x0000075a,
+		setWaveSize:     0x0000075e,
+	}
+}
+
+// FingerprintCDF returns true if the data is a cartridge in one of the CDF
+// formats. Note that CDF cartridges will also pass a simpler test for the
+// Harmony cartridge so this function should be called first.
+func FingerprintCDF(data []byte) bool {
+	_, ok := cdfVersionByte(data)
+	return ok
+}
+
+// the CDF signature appears three times in the driver, each one aligned to a
+// 32bit word. the byte following each signature indicates the version
+func cdfVersionByte(data []byte) (byte, bool) {
+	const driverSize = 2048
+
+	for i := 0; i < driverSize-12 && i < len(data)-12; i += 4 {
+		if string(data[i:i+3]) == "CDF" && string(data[i+4:i+7]) == "CDF" && string(data[i+8:i+11]) == "CDF" {
+			return data[i+3], true
+		}
+	}
+
+	return 0, false
+}
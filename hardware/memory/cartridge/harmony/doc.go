@@ -13,6 +13,10 @@
 // You should have received a copy of the GNU General Public License
 // along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
 
-// Package harmony implements the Harmony cartridge. It is not yet fully
-// complete and in particular does not offer emulation of the ARM7 processor.
+// Package harmony implements the Harmony cartridge. Both the DPC+ and CDF
+// (including CDFJ) formats are supported.
+//
+// Both formats make use of the ARM7 processor in the Harmony. This is emulated
+// by the arm7tdmi package. The ARM program is run to completion as soon as it
+// is called but the 6507 is stalled for the equivalent amount of time.
 package harmony
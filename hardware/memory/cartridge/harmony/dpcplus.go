@@ -20,6 +20,7 @@ import (
 
 	"github.com/jetsetilly/gopher2600/errors"
 	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/banks"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/harmony/arm7tdmi"
 	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
 )
 
@@ -38,6 +39,23 @@ type dpcPlus struct {
 	registers DPCplusRegisters
 	static    DPCplusStatic
 
+	// the entire cartridge file. the ARM sees this as flash memory
+	image []byte
+
+	// the ARM sees this as SRAM. the static areas are slices of this array:
+	// the driver, the display data and the frequency table are copied here
+	// on initialisation
+	ram []byte
+
+	// the ARM coprocessor and the stalling of the 6507 while the ARM program
+	// is running
+	arm    *arm7tdmi.ARM
+	callfn callFunction
+
+	// parameters for the CALLFUNCTION register
+	parameters   [8]uint8
+	parameterIdx int
+
 	// was the last instruction read the opcode for "lda <immediate>"
 	lda bool
 
@@ -57,6 +75,12 @@ type dpcPlus struct {
 	fileSize    int
 }
 
+// memory map of the cartridge as seen by the ARM
+const (
+	dpcPlusFlashOrigin = 0x00000000
+	dpcPlusRAMorigin   = 0x40000000
+)
+
 // NewDPCplus is the preferred method of initialisation for the harmony type
 func NewDPCplus(data []byte) (*dpcPlus, error) {
 	const armSize = 3072
@@ -77,8 +101,11 @@ func NewDPCplus(data []byte) (*dpcPlus, error) {
 		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in cartridge data", cart.mappingID))
 	}
 
-	// partition
-	cart.static.Arm = data[:armSize]
+	// the static areas are copied into RAM below
+	cart.image = data
+	cart.ram = make([]byte, armSize+dataSize+freqSize)
+	cart.static.Arm = cart.ram[:armSize]
+	copy(cart.static.Arm, data[:armSize])
 
 	// allocate enough banks
 	cart.banks = make([][]uint8, bankLen/cart.bankSize)
@@ -93,8 +120,12 @@ func NewDPCplus(data []byte) (*dpcPlus, error) {
 
 	// gfx and frequency table at end of file
 	dataOffset := armSize + (cart.bankSize * cart.NumBanks())
-	cart.static.Data = data[dataOffset : dataOffset+dataSize]
-	cart.static.Freq = data[dataOffset+dataSize:]
+	cart.static.Data = cart.ram[armSize : armSize+dataSize]
+	cart.static.Freq = cart.ram[armSize+dataSize:]
+	copy(cart.static.Data, data[dataOffset:dataOffset+dataSize])
+	copy(cart.static.Freq, data[dataOffset+dataSize:])
+
+	cart.arm = arm7tdmi.NewARM(cart, nil)
 
 	// initialise cartridge before returning success
 	cart.Initialise()
@@ -121,6 +152,13 @@ func (cart *dpcPlus) Initialise() {
 }
 
 func (cart *dpcPlus) Read(addr uint16, passive bool) (uint8, error) {
+	// the 6507 is stalled while the ARM program is running
+	if !passive {
+		if data, ok := cart.callfn.check(addr); ok {
+			return data, nil
+		}
+	}
+
 	if cart.hotspot(addr, passive) {
 		return 0, nil
 	}
@@ -408,9 +446,17 @@ func (cart *dpcPlus) Write(addr uint16, data uint8, passive bool, poke bool) err
 
 	// function support - parameter
 	case 0x59:
+		if cart.parameterIdx < len(cart.parameters) {
+			cart.parameters[cart.parameterIdx] = data
+			cart.parameterIdx++
+		}
 
 	// function support - call function
 	case 0x5a:
+		err := cart.callFunction(data)
+		if err != nil {
+			return err
+		}
 
 	// reserved
 	case 0x5b:
@@ -554,6 +600,50 @@ func (cart *dpcPlus) Write(addr uint16, data uint8, passive bool, poke bool) err
 	return errors.New(errors.MemoryBusError, addr)
 }
 
+// callFunction implements the CALLFUNCTION register. the parameters for the
+// function have been previously written to the PARAMETER register
+func (cart *dpcPlus) callFunction(data uint8) error {
+	switch data {
+	case 0:
+		// reset parameter pointer
+		cart.parameterIdx = 0
+
+	case 1:
+		// copy ROM to fetcher. the ROM address is relative to the start of
+		// the 6507 program banks
+		romAddr := int(cart.parameters[1])<<8 | int(cart.parameters[0])
+		f := cart.parameters[2] & 0x07
+		dataAddr := int(cart.registers.Fetcher[f].Hi)<<8 | int(cart.registers.Fetcher[f].Low)
+		for i := 0; i < int(cart.parameters[3]); i++ {
+			cart.static.Data[(dataAddr+i)&0x0fff] = cart.image[(cart.banksOffset+romAddr+i)%len(cart.image)]
+		}
+		cart.parameterIdx = 0
+
+	case 2:
+		// copy value to fetcher
+		f := cart.parameters[2] & 0x07
+		dataAddr := int(cart.registers.Fetcher[f].Hi)<<8 | int(cart.registers.Fetcher[f].Low)
+		for i := 0; i < int(cart.parameters[3]); i++ {
+			cart.static.Data[(dataAddr+i)&0x0fff] = cart.parameters[0]
+		}
+		cart.parameterIdx = 0
+
+	case 254:
+		// call with IRQ driven audio. no special handling required
+		fallthrough
+
+	case 255:
+		// call user written ARM code
+		cycles, err := cart.arm.Run()
+		if err != nil {
+			return err
+		}
+		cart.callfn.start(cycles)
+	}
+
+	return nil
+}
+
 // bankswitch on hotspot access
 func (cart *dpcPlus) hotspot(addr uint16, passive bool) bool {
 	if addr >= 0x0ff6 && addr <= 0x0ffb {
@@ -591,16 +681,17 @@ func (cart *dpcPlus) Patch(offset int, data uint8) error {
 		return errors.New(errors.CartridgePatchOOB, offset)
 	}
 
+	// the banks are slices of the image so patching the image is sufficient
+	// for the banks. the static areas have been copied to RAM and must be
+	// patched separately
+	cart.image[offset] = data
+
 	if offset >= cart.freqOffset {
 		cart.static.Freq[offset-cart.freqOffset] = data
 	} else if offset >= cart.dataOffset {
 		cart.static.Data[offset-cart.dataOffset] = data
-	} else if offset >= cart.banksOffset {
-		bank := int(offset) / cart.bankSize
-		offset = offset % cart.bankSize
-		cart.banks[bank][offset] = data
-	} else {
-		cart.static.Arm[offset-cart.banksOffset] = data
+	} else if offset < cart.banksOffset {
+		cart.static.Arm[offset] = data
 	}
 
 	return nil
@@ -630,6 +721,93 @@ func (cart *dpcPlus) Step() {
 	}
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *dpcPlus) Snapshot() interface{} {
+	n := *cart
+
+	// the static areas can be written to by the running program and so must
+	// be copied. the static areas are slices of the RAM array
+	n.ram = make([]byte, len(cart.ram))
+	copy(n.ram, cart.ram)
+	armSize := len(cart.static.Arm)
+	dataSize := len(cart.static.Data)
+	n.static.Arm = n.ram[:armSize]
+	n.static.Data = n.ram[armSize : armSize+dataSize]
+	n.static.Freq = n.ram[armSize+dataSize:]
+
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *dpcPlus) Restore(s interface{}) error {
+	if s, ok := s.(*dpcPlus); ok {
+		*cart = *s.Snapshot().(*dpcPlus)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the dpcPlus type as returned by SaveState(). the static areas
+// are slices of RAM and are therefore included in the RAM field
+type dpcPlusState struct {
+	Bank            int
+	Registers       DPCplusRegisters
+	RAM             []byte
+	CallFnState     int
+	CallFnRemaining int
+	CallFnResume    uint16
+	Parameters      [8]uint8
+	ParameterIdx    int
+	LDA             bool
+	Beats           int
+}
+
+// SaveState implements the cartMapper interface
+func (cart *dpcPlus) SaveState() interface{} {
+	return dpcPlusState{
+		Bank:            cart.bank,
+		Registers:       cart.registers,
+		RAM:             cart.ram,
+		CallFnState:     int(cart.callfn.state),
+		CallFnRemaining: cart.callfn.remaining,
+		CallFnResume:    cart.callfn.resume,
+		Parameters:      cart.parameters,
+		ParameterIdx:    cart.parameterIdx,
+		LDA:             cart.lda,
+		Beats:           cart.beats,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *dpcPlus) LoadState(decode func(interface{}) error) error {
+	var s dpcPlusState
+	if err := decode(&s); err != nil {
+		return err
+	}
+	if s.Bank < 0 || s.Bank >= len(cart.banks) {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("invalid bank in cartridge state (%d)", s.Bank))
+	}
+	if len(s.RAM) != len(cart.ram) {
+		return errors.New(errors.SnapshotError, "cartridge RAM state is the wrong size")
+	}
+	if s.ParameterIdx < 0 || s.ParameterIdx > len(cart.parameters) {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("invalid parameter index in cartridge state (%d)", s.ParameterIdx))
+	}
+
+	copy(cart.ram, s.RAM)
+	cart.bank = s.Bank
+	cart.registers = s.Registers
+	cart.callfn.state = callFunctionState(s.CallFnState)
+	cart.callfn.remaining = s.CallFnRemaining
+	cart.callfn.resume = s.CallFnResume
+	cart.parameters = s.Parameters
+	cart.parameterIdx = s.ParameterIdx
+	cart.lda = s.LDA
+	cart.beats = s.Beats
+
+	return nil
+}
+
 // IterateBank implemnts the disassemble interface
 func (cart dpcPlus) IterateBanks(prev *banks.Content) *banks.Content {
 	b := prev.Number + 1
@@ -643,3 +821,22 @@ func (cart dpcPlus) IterateBanks(prev *banks.Content) *banks.Content {
 	}
 	return nil
 }
+
+// MapAddress implements the arm7tdmi.SharedMemory interface
+func (cart *dpcPlus) MapAddress(addr uint32, write bool) ([]byte, uint32) {
+	if addr >= dpcPlusRAMorigin && addr < dpcPlusRAMorigin+uint32(len(cart.ram)) {
+		return cart.ram, dpcPlusRAMorigin
+	}
+
+	// flash memory is not writable
+	if !write && addr < dpcPlusFlashOrigin+uint32(len(cart.image)) {
+		return cart.image, dpcPlusFlashOrigin
+	}
+
+	return nil, 0
+}
+
+// ResetVectors implements the arm7tdmi.SharedMemory interface
+func (cart *dpcPlus) ResetVectors() (uint32, uint32, uint32) {
+	return 0x40001fb4, 0x00000c00, 0x00000c08
+}
@@ -50,6 +50,28 @@ type cartMapper interface {
 	// return all the banks in the cartridge in sequence. see commentary for
 	// IterateBanks() function in the Cartridge type for details.
 	IterateBanks(prev *banks.Content) *banks.Content
+
+	// Snapshot returns a copy of the mapper in its current state. The
+	// returned value must itself satisfy the cartMapper interface. ROM data
+	// need not be copied.
+	Snapshot() interface{}
+
+	// Restore the state of the mapper from a value previously returned by
+	// Snapshot(). The mapper should be updated in place and the snapshot
+	// must remain usable for future calls to Restore().
+	Restore(interface{}) error
+
+	// SaveState returns the state of the mapper as a value that can be
+	// encoded with the encoding/gob package. The value is encoded
+	// immediately. ROM data need not be included. Mappers with no state
+	// should return nil.
+	SaveState() interface{}
+
+	// LoadState sets the state of the mapper to a state previously returned
+	// by SaveState(). The decode function decodes the saved state into a
+	// pointer to a value of the same type as that returned by SaveState().
+	// LoadState should only be called on a snapshot of the mapper.
+	LoadState(decode func(interface{}) error) error
 }
 
 // optionalSuperchip are implemented by cartMappers that have an optional
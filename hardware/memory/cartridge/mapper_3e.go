@@ -0,0 +1,295 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/memory/bus"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/banks"
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// the 3E format is an extension of the tigervision (3F) format. as with the
+// tigervision format, the cartridge is divided into two 2k segments, the last
+// segment always pointing to the last bank of ROM. a write to address 0x3f
+// selects the ROM bank for the first segment.
+//
+// in addition, a write to address 0x3e selects one of up to 32 1k banks of RAM
+// for the first segment. the RAM is read through 0x1000 to 0x13ff and written
+// through 0x1400 to 0x17ff.
+//
+// not to be confused with the 3E+ format (see mapper_3eplus.go)
+
+const num3eRAMbanks = 32
+
+type m3e struct {
+	mappingID   string
+	description string
+
+	// 3e cartridges have any number of 2k ROM banks
+	bankSize int
+	banks    [][]uint8
+
+	// 1k RAM banks
+	ram [num3eRAMbanks][]uint8
+
+	// the bank pointed to by the first segment. segmentIsRAM indicates
+	// whether the bank refers to a RAM bank or a ROM bank. the second segment
+	// always points to the last ROM bank
+	//
+	// hotspots are provided by the Listen() function
+	segment      int
+	segmentIsRAM bool
+}
+
+func new3e(data []byte) (cartMapper, error) {
+	cart := &m3e{
+		mappingID:   "3E",
+		description: "",
+		bankSize:    2048,
+	}
+
+	if len(data)%cart.bankSize != 0 {
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number bytes in the cartridge data", cart.mappingID))
+	}
+
+	numBanks := len(data) / cart.bankSize
+	cart.banks = make([][]uint8, numBanks)
+
+	for k := 0; k < numBanks; k++ {
+		cart.banks[k] = make([]uint8, cart.bankSize)
+		offset := k * cart.bankSize
+		copy(cart.banks[k], data[offset:offset+cart.bankSize])
+	}
+
+	for k := range cart.ram {
+		cart.ram[k] = make([]uint8, 1024)
+	}
+
+	cart.Initialise()
+
+	return cart, nil
+}
+
+func (cart m3e) String() string {
+	s := strings.Builder{}
+	s.WriteString(fmt.Sprintf("%s Banks: %d", cart.mappingID, cart.segment))
+	if cart.segmentIsRAM {
+		s.WriteString("R")
+	}
+	s.WriteString(fmt.Sprintf(", %d", len(cart.banks)-1))
+	return s.String()
+}
+
+// ID implements the cartMapper interface
+func (cart m3e) ID() string {
+	return cart.mappingID
+}
+
+// Initialise implements the cartMapper interface
+func (cart *m3e) Initialise() {
+	cart.segment = 0
+	cart.segmentIsRAM = false
+	for k := range cart.ram {
+		for i := range cart.ram[k] {
+			cart.ram[k][i] = 0x00
+		}
+	}
+}
+
+// Read implements the cartMapper interface
+func (cart *m3e) Read(addr uint16, _ bool) (uint8, error) {
+	if addr <= 0x07ff {
+		if cart.segmentIsRAM {
+			return cart.ram[cart.segment][addr&0x03ff], nil
+		}
+		return cart.banks[cart.segment][addr&0x07ff], nil
+	}
+	return cart.banks[len(cart.banks)-1][addr&0x07ff], nil
+}
+
+// Write implements the cartMapper interface
+func (cart *m3e) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if passive {
+		return nil
+	}
+
+	if addr >= 0x0400 && addr <= 0x07ff && cart.segmentIsRAM {
+		cart.ram[cart.segment][addr&0x03ff] = data
+		return nil
+	}
+
+	if poke {
+		if addr <= 0x07ff {
+			if !cart.segmentIsRAM {
+				cart.banks[cart.segment][addr&0x07ff] = data
+			}
+		} else {
+			cart.banks[len(cart.banks)-1][addr&0x07ff] = data
+		}
+		return nil
+	}
+
+	return errors.New(errors.MemoryBusError, addr)
+}
+
+// NumBanks implements the cartMapper interface
+func (cart m3e) NumBanks() int {
+	return len(cart.banks)
+}
+
+// GetBank implements the cartMapper interface
+func (cart *m3e) GetBank(addr uint16) banks.Details {
+	if addr <= 0x07ff {
+		return banks.Details{Number: cart.segment, IsRAM: cart.segmentIsRAM, Segment: 0}
+	}
+	return banks.Details{Number: len(cart.banks) - 1, Segment: 1}
+}
+
+// Patch implements the cartMapper interface
+func (cart *m3e) Patch(offset int, data uint8) error {
+	if offset >= cart.bankSize*len(cart.banks) {
+		return errors.New(errors.CartridgePatchOOB, offset)
+	}
+
+	bank := int(offset) / cart.bankSize
+	offset = offset % cart.bankSize
+	cart.banks[bank][offset] = data
+	return nil
+}
+
+// Listen implements the cartMapper interface
+func (cart *m3e) Listen(addr uint16, data uint8) {
+	// bankswitch on hotspot access
+	if addr == 0x3f {
+		cart.segment = int(data) % len(cart.banks)
+		cart.segmentIsRAM = false
+	} else if addr == 0x3e {
+		cart.segment = int(data) % num3eRAMbanks
+		cart.segmentIsRAM = true
+	}
+}
+
+// Step implements the cartMapper interface
+func (cart *m3e) Step() {
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *m3e) Snapshot() interface{} {
+	n := *cart
+	for i := range cart.ram {
+		n.ram[i] = make([]uint8, len(cart.ram[i]))
+		copy(n.ram[i], cart.ram[i])
+	}
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *m3e) Restore(s interface{}) error {
+	if s, ok := s.(*m3e); ok {
+		*cart = *s.Snapshot().(*m3e)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the m3e type as returned by SaveState()
+type m3eState struct {
+	RAM          [num3eRAMbanks][]uint8
+	Segment      int
+	SegmentIsRAM bool
+}
+
+// SaveState implements the cartMapper interface
+func (cart *m3e) SaveState() interface{} {
+	return m3eState{
+		RAM:          cart.ram,
+		Segment:      cart.segment,
+		SegmentIsRAM: cart.segmentIsRAM,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *m3e) LoadState(decode func(interface{}) error) error {
+	var s m3eState
+	if err := decode(&s); err != nil {
+		return err
+	}
+
+	n := len(cart.banks)
+	if s.SegmentIsRAM {
+		n = len(cart.ram)
+	}
+	if err := validBank(s.Segment, n); err != nil {
+		return err
+	}
+
+	for i := range cart.ram {
+		if err := loadRAM(cart.ram[i], s.RAM[i]); err != nil {
+			return err
+		}
+	}
+	cart.segment = s.Segment
+	cart.segmentIsRAM = s.SegmentIsRAM
+
+	return nil
+}
+
+// GetRAM implements the bus.CartRAMBus interface.
+func (cart m3e) GetRAM() []bus.CartRAM {
+	r := make([]bus.CartRAM, len(cart.ram))
+
+	for i := range cart.ram {
+		r[i] = bus.CartRAM{
+			Label:  fmt.Sprintf("%d", i),
+			Origin: 0x1000,
+			Data:   make([]uint8, len(cart.ram[i])),
+			Mapped: cart.segmentIsRAM && cart.segment == i,
+		}
+		copy(r[i].Data, cart.ram[i])
+	}
+
+	return r
+}
+
+// PutRAM implements the bus.CartRAMBus interface
+func (cart *m3e) PutRAM(bank int, idx int, data uint8) {
+	cart.ram[bank][idx] = data
+}
+
+// IterateBank implemnts the disassemble interface
+func (cart m3e) IterateBanks(prev *banks.Content) *banks.Content {
+	b := prev.Number + 1
+	if b < len(cart.banks)-1 {
+		return &banks.Content{Number: b,
+			Data: cart.banks[b],
+			Origins: []uint16{
+				memorymap.OriginCart,
+			},
+		}
+	} else if b == len(cart.banks)-1 {
+		return &banks.Content{Number: b,
+			Data: cart.banks[b],
+			Origins: []uint16{
+				memorymap.OriginCart + uint16(cart.bankSize),
+			},
+		}
+	}
+	return nil
+}
@@ -233,6 +233,69 @@ func (cart *m3ePlus) Listen(addr uint16, data uint8) {
 func (cart *m3ePlus) Step() {
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *m3ePlus) Snapshot() interface{} {
+	n := *cart
+	for i := range cart.ram {
+		n.ram[i] = make([]uint8, len(cart.ram[i]))
+		copy(n.ram[i], cart.ram[i])
+	}
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *m3ePlus) Restore(s interface{}) error {
+	if s, ok := s.(*m3ePlus); ok {
+		*cart = *s.Snapshot().(*m3ePlus)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the m3ePlus type as returned by SaveState()
+type m3ePlusState struct {
+	RAM          [64][]uint8
+	Segment      [4]int
+	SegmentIsRAM [4]bool
+}
+
+// SaveState implements the cartMapper interface
+func (cart *m3ePlus) SaveState() interface{} {
+	return m3ePlusState{
+		RAM:          cart.ram,
+		Segment:      cart.segment,
+		SegmentIsRAM: cart.segmentIsRam,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *m3ePlus) LoadState(decode func(interface{}) error) error {
+	var s m3ePlusState
+	if err := decode(&s); err != nil {
+		return err
+	}
+
+	for i := range s.Segment {
+		n := len(cart.banks)
+		if s.SegmentIsRAM[i] {
+			n = len(cart.ram)
+		}
+		if err := validBank(s.Segment[i], n); err != nil {
+			return err
+		}
+	}
+
+	for i := range cart.ram {
+		if err := loadRAM(cart.ram[i], s.RAM[i]); err != nil {
+			return err
+		}
+	}
+	cart.segment = s.Segment
+	cart.segmentIsRam = s.SegmentIsRAM
+
+	return nil
+}
+
 // GetRAM implements the bus.CartRAMBus interface.
 func (cart m3ePlus) GetRAM() []bus.CartRAM {
 	r := make([]bus.CartRAM, len(cart.ram))
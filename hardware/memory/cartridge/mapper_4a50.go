@@ -0,0 +1,463 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/memory/bus"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/banks"
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// the 4A50 format was designed by John Payson (Supercat). the cartridge
+// contains 128k of ROM and 32k of RAM. the 4k cartridge space is divided into
+// four segments:
+//
+//	0x1000 to 0x17ff	2k of RAM or any 2k of the first 64k of ROM
+//	0x1800 to 0x1dff	1.5k of RAM or 1.5k of the last 64k of ROM
+//	0x1e00 to 0x1eff	256 bytes of RAM or of the last 64k of ROM
+//	0x1f00 to 0x1fff	always the last 256 bytes of ROM
+//
+// RAM in the 4A50 format has no separate read and write addresses.
+//
+// bankswitching happens when the previous bus access was to the cartridge
+// space (or the bottom of the zero page) and when the data of that access was
+// in the range 0x60 to 0x7f (ie. a NOP, RTS, JMP, ADC or ROR instruction).
+// the address of the current access then decides what happens. there are two
+// sets of hotspots: those in the non-cartridge mirrors (0x0c00 to 0x0fff),
+// where the address determines the slice; and those in the upper half of the
+// zero page (0x74 to 0x7f), where the data written to the address determines
+// the slice.
+//
+// ROM images smaller than 128k are mirrored to fill the full ROM space.
+
+const (
+	size4a50ROM = 131072
+	size4a50RAM = 32768
+)
+
+type m4a50 struct {
+	mappingID   string
+	description string
+
+	// the cartridge is treated as a single 128k block of ROM. for the purposes
+	// of the NumBanks() and IterateBanks() functions it is divided into 2k
+	// banks
+	bankSize int
+	rom      []uint8
+	ram      []uint8
+
+	// offsets into the ROM or RAM for the first three segments
+	sliceLow    int
+	sliceMiddle int
+	sliceHigh   int
+
+	isRomLow    bool
+	isRomMiddle bool
+	isRomHigh   bool
+
+	// the previous access to the address and data bus
+	lastAddress uint16
+	lastData    uint8
+}
+
+func new4a50(data []byte) (cartMapper, error) {
+	cart := &m4a50{
+		mappingID:   "4A50",
+		description: "supercat",
+		bankSize:    2048,
+	}
+
+	switch len(data) {
+	case 32768, 65536, size4a50ROM:
+	default:
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in the cartridge data", cart.mappingID))
+	}
+
+	cart.rom = make([]uint8, size4a50ROM)
+	for i := 0; i < size4a50ROM; i += len(data) {
+		copy(cart.rom[i:], data)
+	}
+
+	cart.ram = make([]uint8, size4a50RAM)
+
+	cart.Initialise()
+
+	return cart, nil
+}
+
+func (cart m4a50) String() string {
+	s := strings.Builder{}
+	s.WriteString(fmt.Sprintf("%s [%s] ", cart.mappingID, cart.description))
+
+	segment := func(isRom bool, slice int) {
+		if isRom {
+			s.WriteString(fmt.Sprintf("%04x", slice))
+		} else {
+			s.WriteString(fmt.Sprintf("%04xR", slice))
+		}
+	}
+
+	s.WriteString("Slices: ")
+	segment(cart.isRomLow, cart.sliceLow)
+	s.WriteString(", ")
+	segment(cart.isRomMiddle, cart.sliceMiddle)
+	s.WriteString(", ")
+	segment(cart.isRomHigh, cart.sliceHigh)
+
+	return s.String()
+}
+
+// ID implements the cartMapper interface
+func (cart m4a50) ID() string {
+	return cart.mappingID
+}
+
+// Initialise implements the cartMapper interface
+func (cart *m4a50) Initialise() {
+	cart.sliceLow = 0
+	cart.sliceMiddle = 0
+	cart.sliceHigh = 0
+	cart.isRomLow = true
+	cart.isRomMiddle = true
+	cart.isRomHigh = true
+	cart.lastAddress = 0xffff
+	cart.lastData = 0xff
+
+	for i := range cart.ram {
+		cart.ram[i] = 0x00
+	}
+}
+
+// Read implements the cartMapper interface
+func (cart *m4a50) Read(addr uint16, _ bool) (uint8, error) {
+	if addr <= 0x07ff {
+		if cart.isRomLow {
+			return cart.rom[int(addr&0x07ff)+cart.sliceLow], nil
+		}
+		return cart.ram[int(addr&0x07ff)+cart.sliceLow], nil
+	}
+
+	if addr <= 0x0dff {
+		if cart.isRomMiddle {
+			return cart.rom[int(addr&0x07ff)+cart.sliceMiddle+0x10000], nil
+		}
+		return cart.ram[int(addr&0x07ff)+cart.sliceMiddle], nil
+	}
+
+	if addr <= 0x0eff {
+		if cart.isRomHigh {
+			return cart.rom[int(addr&0x00ff)+cart.sliceHigh+0x10000], nil
+		}
+		return cart.ram[int(addr&0x00ff)+cart.sliceHigh], nil
+	}
+
+	return cart.rom[int(addr&0x00ff)+size4a50ROM-0x100], nil
+}
+
+// Write implements the cartMapper interface
+func (cart *m4a50) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if passive {
+		return nil
+	}
+
+	if addr <= 0x07ff {
+		if !cart.isRomLow {
+			cart.ram[int(addr&0x07ff)+cart.sliceLow] = data
+			return nil
+		}
+		if poke {
+			cart.rom[int(addr&0x07ff)+cart.sliceLow] = data
+			return nil
+		}
+	} else if addr <= 0x0dff {
+		if !cart.isRomMiddle {
+			cart.ram[int(addr&0x07ff)+cart.sliceMiddle] = data
+			return nil
+		}
+		if poke {
+			cart.rom[int(addr&0x07ff)+cart.sliceMiddle+0x10000] = data
+			return nil
+		}
+	} else if addr <= 0x0eff {
+		if !cart.isRomHigh {
+			cart.ram[int(addr&0x00ff)+cart.sliceHigh] = data
+			return nil
+		}
+		if poke {
+			cart.rom[int(addr&0x00ff)+cart.sliceHigh+0x10000] = data
+			return nil
+		}
+	} else {
+		// writes to the last segment may cause a bankswitch, which is handled
+		// by the Listen() function
+		if poke {
+			cart.rom[int(addr&0x00ff)+size4a50ROM-0x100] = data
+		}
+		return nil
+	}
+
+	return errors.New(errors.MemoryBusError, addr)
+}
+
+// NumBanks implements the cartMapper interface
+func (cart m4a50) NumBanks() int {
+	return size4a50ROM / cart.bankSize
+}
+
+// GetBank implements the cartMapper interface
+func (cart *m4a50) GetBank(addr uint16) banks.Details {
+	if addr <= 0x07ff {
+		if cart.isRomLow {
+			return banks.Details{Number: cart.sliceLow / cart.bankSize, Segment: 0}
+		}
+		return banks.Details{Number: cart.sliceLow / cart.bankSize, IsRAM: true, Segment: 0}
+	}
+
+	if addr <= 0x0dff {
+		if cart.isRomMiddle {
+			return banks.Details{Number: (cart.sliceMiddle + 0x10000) / cart.bankSize, Segment: 1}
+		}
+		return banks.Details{Number: cart.sliceMiddle / cart.bankSize, IsRAM: true, Segment: 1}
+	}
+
+	if addr <= 0x0eff {
+		if cart.isRomHigh {
+			return banks.Details{Number: (cart.sliceHigh + 0x10000) / cart.bankSize, Segment: 2}
+		}
+		return banks.Details{Number: cart.sliceHigh / cart.bankSize, IsRAM: true, Segment: 2}
+	}
+
+	return banks.Details{Number: cart.NumBanks() - 1, Segment: 3}
+}
+
+// Patch implements the cartMapper interface
+func (cart *m4a50) Patch(offset int, data uint8) error {
+	if offset >= len(cart.rom) {
+		return errors.New(errors.CartridgePatchOOB, offset)
+	}
+
+	cart.rom[offset] = data
+	return nil
+}
+
+// Listen implements the cartMapper interface
+func (cart *m4a50) Listen(addr uint16, data uint8) {
+	if cart.bankswitchEnabled() {
+		if addr&memorymap.OriginCart == 0 {
+			cart.hotspot(addr, data)
+		} else if addr&0x1f00 == 0x1f00 {
+			// accessing the last segment changes the high slice
+			cart.sliceHigh = (cart.sliceHigh & 0xf0ff) | int(addr&0x08)<<8 | int(addr&0x70)<<4
+		}
+	}
+
+	cart.lastData = data
+	cart.lastAddress = addr & memorymap.Memtop
+}
+
+// bankswitching is only possible if the previous access was to the cartridge
+// space (or the bottom of the zero page) and the data was in the range 0x60
+// to 0x7f
+func (cart *m4a50) bankswitchEnabled() bool {
+	return cart.lastData&0xe0 == 0x60 && (cart.lastAddress >= 0x1000 || cart.lastAddress < 0x200)
+}
+
+func (cart *m4a50) hotspot(addr uint16, data uint8) {
+	if addr&0x0f00 == 0x0c00 {
+		// 256 bytes of ROM in the high segment
+		cart.isRomHigh = true
+		cart.sliceHigh = int(addr&0xff) << 8
+	} else if addr&0x0f00 == 0x0d00 {
+		// 256 bytes of RAM in the high segment
+		cart.isRomHigh = false
+		cart.sliceHigh = int(addr&0x7f) << 8
+	} else if addr&0x0f40 == 0x0e00 {
+		// 2k of ROM in the low segment
+		cart.isRomLow = true
+		cart.sliceLow = int(addr&0x1f) << 11
+	} else if addr&0x0f40 == 0x0e40 {
+		// 2k of RAM in the low segment
+		cart.isRomLow = false
+		cart.sliceLow = int(addr&0x0f) << 11
+	} else if addr&0x0f40 == 0x0f00 {
+		// 1.5k of ROM in the middle segment
+		cart.isRomMiddle = true
+		cart.sliceMiddle = int(addr&0x1f) << 11
+	} else if addr&0x0f50 == 0x0f40 {
+		// 1.5k of RAM in the middle segment
+		cart.isRomMiddle = false
+		cart.sliceMiddle = int(addr&0x0f) << 11
+	} else if addr&0x0f75 == 0x74 {
+		// zero page hotspots for the high segment. ROM selected with 0x74,
+		// 0x76, 0x7c and 0x7e
+		cart.isRomHigh = true
+		cart.sliceHigh = int(data) << 8
+	} else if addr&0x0f75 == 0x75 {
+		// RAM selected with 0x75, 0x77, 0x7d and 0x7f
+		cart.isRomHigh = false
+		cart.sliceHigh = int(data&0x7f) << 8
+	} else if addr&0x0f7c == 0x78 {
+		// zero page hotspots for the low and middle segments. the upper nibble
+		// of the data selects the segment and whether ROM or RAM is used
+		switch data & 0xf0 {
+		case 0x00:
+			cart.isRomLow = true
+			cart.sliceLow = int(data&0x0f) << 11
+		case 0x40:
+			cart.isRomLow = false
+			cart.sliceLow = int(data&0x0f) << 11
+		case 0x90:
+			cart.isRomMiddle = true
+			cart.sliceMiddle = int(data&0x0f|0x10) << 11
+		case 0xc0:
+			cart.isRomMiddle = false
+			cart.sliceMiddle = int(data&0x0f) << 11
+		}
+	}
+}
+
+// Step implements the cartMapper interface
+func (cart *m4a50) Step() {
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *m4a50) Snapshot() interface{} {
+	n := *cart
+	n.ram = make([]uint8, len(cart.ram))
+	copy(n.ram, cart.ram)
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *m4a50) Restore(s interface{}) error {
+	if s, ok := s.(*m4a50); ok {
+		*cart = *s.Snapshot().(*m4a50)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the m4a50 type as returned by SaveState()
+type m4a50State struct {
+	RAM         []uint8
+	SliceLow    int
+	SliceMiddle int
+	SliceHigh   int
+	IsRomLow    bool
+	IsRomMiddle bool
+	IsRomHigh   bool
+	LastAddress uint16
+	LastData    uint8
+}
+
+// SaveState implements the cartMapper interface
+func (cart *m4a50) SaveState() interface{} {
+	return m4a50State{
+		RAM:         cart.ram,
+		SliceLow:    cart.sliceLow,
+		SliceMiddle: cart.sliceMiddle,
+		SliceHigh:   cart.sliceHigh,
+		IsRomLow:    cart.isRomLow,
+		IsRomMiddle: cart.isRomMiddle,
+		IsRomHigh:   cart.isRomHigh,
+		LastAddress: cart.lastAddress,
+		LastData:    cart.lastData,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *m4a50) LoadState(decode func(interface{}) error) error {
+	var s m4a50State
+	if err := decode(&s); err != nil {
+		return err
+	}
+	if err := loadRAM(cart.ram, s.RAM); err != nil {
+		return err
+	}
+	cart.sliceLow = s.SliceLow
+	cart.sliceMiddle = s.SliceMiddle
+	cart.sliceHigh = s.SliceHigh
+	cart.isRomLow = s.IsRomLow
+	cart.isRomMiddle = s.IsRomMiddle
+	cart.isRomHigh = s.IsRomHigh
+	cart.lastAddress = s.LastAddress
+	cart.lastData = s.LastData
+	return nil
+}
+
+// GetRAM implements the bus.CartRAMBus interface. the 32k of RAM is presented
+// as sixteen 2k banks
+func (cart m4a50) GetRAM() []bus.CartRAM {
+	r := make([]bus.CartRAM, size4a50RAM/cart.bankSize)
+
+	for i := range r {
+		offset := i * cart.bankSize
+
+		r[i] = bus.CartRAM{
+			Label:  fmt.Sprintf("%d", i),
+			Origin: 0x1000,
+			Data:   make([]uint8, cart.bankSize),
+		}
+		copy(r[i].Data, cart.ram[offset:offset+cart.bankSize])
+
+		// the segment mapped to the RAM bank. the low segment takes priority
+		// if more than one segment points to the same bank
+		if !cart.isRomLow && cart.sliceLow == offset {
+			r[i].Mapped = true
+		} else if !cart.isRomMiddle && cart.sliceMiddle == offset {
+			r[i].Origin = 0x1800
+			r[i].Mapped = true
+		} else if !cart.isRomHigh && cart.sliceHigh&^0x7ff == offset {
+			r[i].Origin = 0x1e00 - uint16(cart.sliceHigh&0x7ff)
+			r[i].Mapped = true
+		}
+	}
+
+	return r
+}
+
+// PutRAM implements the bus.CartRAMBus interface
+func (cart *m4a50) PutRAM(bank int, idx int, data uint8) {
+	cart.ram[bank*cart.bankSize+idx] = data
+}
+
+// IterateBank implemnts the disassemble interface
+func (cart m4a50) IterateBanks(prev *banks.Content) *banks.Content {
+	b := prev.Number + 1
+	if b < 0 || b >= cart.NumBanks() {
+		return nil
+	}
+
+	offset := b * cart.bankSize
+	c := &banks.Content{Number: b,
+		Data: cart.rom[offset : offset+cart.bankSize],
+	}
+
+	// banks in the first 64k can only be mapped into the low segment. banks
+	// in the last 64k can be mapped into the middle segment or (in 256 byte
+	// slices) the high segment
+	if offset < 0x10000 {
+		c.Origins = []uint16{memorymap.OriginCart}
+	} else {
+		c.Origins = []uint16{memorymap.OriginCart + uint16(cart.bankSize)}
+	}
+
+	return c
+}
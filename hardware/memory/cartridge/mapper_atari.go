@@ -197,6 +197,41 @@ func (cart *atari) Listen(_ uint16, _ uint8) {
 func (cart *atari) Step() {
 }
 
+// snapshot returns a copy of the atari type with its own copy of any
+// superchip RAM. the ROM banks are not copied
+func (cart atari) snapshot() atari {
+	if cart.ram != nil {
+		ram := make([]uint8, len(cart.ram))
+		copy(ram, cart.ram)
+		cart.ram = ram
+	}
+	return cart
+}
+
+// the state of the atari type as returned by SaveState()
+type atariState struct {
+	Bank int
+	RAM  []uint8
+}
+
+// SaveState implements the cartMapper interface
+func (cart *atari) SaveState() interface{} {
+	return atariState{Bank: cart.bank, RAM: cart.ram}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *atari) LoadState(decode func(interface{}) error) error {
+	var s atariState
+	if err := decode(&s); err != nil {
+		return err
+	}
+	if err := validBank(s.Bank, len(cart.banks)); err != nil {
+		return err
+	}
+	cart.bank = s.Bank
+	return loadRAM(cart.ram, s.RAM)
+}
+
 // GetRAM implements the bus.CartRAMBus interface
 func (cart atari) GetRAM() []bus.CartRAM {
 	if cart.ram == nil {
@@ -272,6 +307,20 @@ func (cart atari4k) NumBanks() int {
 	return 1
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *atari4k) Snapshot() interface{} {
+	return &atari4k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari4k) Restore(s interface{}) error {
+	if s, ok := s.(*atari4k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
 // Read implements the cartMapper interface
 func (cart *atari4k) Read(addr uint16, passive bool) (uint8, error) {
 	if data, ok := cart.atari.Read(addr, passive); ok {
@@ -322,6 +371,20 @@ func (cart atari2k) NumBanks() int {
 	return 1
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *atari2k) Snapshot() interface{} {
+	return &atari2k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari2k) Restore(s interface{}) error {
+	if s, ok := s.(*atari2k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
 // Read implements the cartMapper interface
 func (cart *atari2k) Read(addr uint16, passive bool) (uint8, error) {
 	if data, ok := cart.atari.Read(addr, passive); ok {
@@ -373,6 +436,20 @@ func (cart atari8k) NumBanks() int {
 	return 2
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *atari8k) Snapshot() interface{} {
+	return &atari8k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari8k) Restore(s interface{}) error {
+	if s, ok := s.(*atari8k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
 // Read implements the cartMapper interface
 func (cart *atari8k) Read(addr uint16, passive bool) (uint8, error) {
 	if cart.hotspot(addr, passive) {
@@ -453,6 +530,20 @@ func (cart atari16k) NumBanks() int {
 	return 4
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *atari16k) Snapshot() interface{} {
+	return &atari16k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari16k) Restore(s interface{}) error {
+	if s, ok := s.(*atari16k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
 // Read implements the cartMapper interface
 func (cart *atari16k) Read(addr uint16, passive bool) (uint8, error) {
 	if cart.hotspot(addr, passive) {
@@ -537,6 +628,20 @@ func (cart atari32k) NumBanks() int {
 	return 8
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *atari32k) Snapshot() interface{} {
+	return &atari32k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari32k) Restore(s interface{}) error {
+	if s, ok := s.(*atari32k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
 // Read implements the cartMapper interface
 func (cart *atari32k) Read(addr uint16, passive bool) (uint8, error) {
 	if cart.hotspot(addr, passive) {
@@ -0,0 +1,294 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// the EF, DF and BF formats are extensions of the standard atari formats. they
+// are not atari formats in the sense that they were ever used by Atari but
+// they work in exactly the same way, only with more banks. as with the
+// standard atari formats, a superchip can be added to the cartridge (the SC
+// variants of the format).
+//
+// -EF: 64K in 16 banks. banks are selected by accessing 1FE0 to 1FEF
+//
+// -DF: 128K in 32 banks. banks are selected by accessing 1FC0 to 1FDF
+//
+// -BF: 256K in 64 banks. banks are selected by accessing 1F80 to 1FBF
+
+// atari64k (EF)
+//	o Homestar Runner RPG
+//	o etc.
+type atari64k struct {
+	atari
+}
+
+func newAtari64k(data []byte) (cartMapper, error) {
+	cart := &atari64k{}
+	cart.bankSize = 4096
+	cart.mappingID = "EF"
+	cart.description = "64k"
+	cart.banks = make([][]uint8, cart.NumBanks())
+
+	if len(data) != cart.bankSize*cart.NumBanks() {
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in the cartridge file", cart.mappingID))
+	}
+
+	for k := 0; k < cart.NumBanks(); k++ {
+		cart.banks[k] = make([]uint8, cart.bankSize)
+		offset := k * cart.bankSize
+		copy(cart.banks[k], data[offset:offset+cart.bankSize])
+	}
+
+	cart.Initialise()
+
+	return cart, nil
+}
+
+// NumBanks implements the cartMapper interface
+func (cart atari64k) NumBanks() int {
+	return 16
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *atari64k) Snapshot() interface{} {
+	return &atari64k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari64k) Restore(s interface{}) error {
+	if s, ok := s.(*atari64k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// Read implements the cartMapper interface
+func (cart *atari64k) Read(addr uint16, passive bool) (uint8, error) {
+	if cart.hotspot(addr, passive) {
+		return 0, nil
+	}
+
+	if data, ok := cart.atari.Read(addr, passive); ok {
+		return data, nil
+	}
+
+	data := cart.banks[cart.bank][addr]
+
+	return data, nil
+}
+
+// Write implements the cartMapper interface
+func (cart *atari64k) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if passive {
+		return nil
+	}
+
+	if cart.hotspot(addr, passive) {
+		return nil
+	}
+
+	return cart.atari.Write(addr, data, passive, poke)
+}
+
+// bankswitch on hotspot access
+func (cart *atari64k) hotspot(addr uint16, passive bool) bool {
+	if addr >= 0x0fe0 && addr <= 0x0fef {
+		if passive {
+			return true
+		}
+		cart.bank = int(addr - 0x0fe0)
+		return true
+	}
+	return false
+}
+
+// atari128k (DF)
+type atari128k struct {
+	atari
+}
+
+func newAtari128k(data []byte) (cartMapper, error) {
+	cart := &atari128k{}
+	cart.bankSize = 4096
+	cart.mappingID = "DF"
+	cart.description = "128k"
+	cart.banks = make([][]uint8, cart.NumBanks())
+
+	if len(data) != cart.bankSize*cart.NumBanks() {
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in the cartridge file", cart.mappingID))
+	}
+
+	for k := 0; k < cart.NumBanks(); k++ {
+		cart.banks[k] = make([]uint8, cart.bankSize)
+		offset := k * cart.bankSize
+		copy(cart.banks[k], data[offset:offset+cart.bankSize])
+	}
+
+	cart.Initialise()
+
+	return cart, nil
+}
+
+// NumBanks implements the cartMapper interface
+func (cart atari128k) NumBanks() int {
+	return 32
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *atari128k) Snapshot() interface{} {
+	return &atari128k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari128k) Restore(s interface{}) error {
+	if s, ok := s.(*atari128k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// Read implements the cartMapper interface
+func (cart *atari128k) Read(addr uint16, passive bool) (uint8, error) {
+	if cart.hotspot(addr, passive) {
+		return 0, nil
+	}
+
+	if data, ok := cart.atari.Read(addr, passive); ok {
+		return data, nil
+	}
+
+	data := cart.banks[cart.bank][addr]
+
+	return data, nil
+}
+
+// Write implements the cartMapper interface
+func (cart *atari128k) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if passive {
+		return nil
+	}
+
+	if cart.hotspot(addr, passive) {
+		return nil
+	}
+
+	return cart.atari.Write(addr, data, passive, poke)
+}
+
+// bankswitch on hotspot access
+func (cart *atari128k) hotspot(addr uint16, passive bool) bool {
+	if addr >= 0x0fc0 && addr <= 0x0fdf {
+		if passive {
+			return true
+		}
+		cart.bank = int(addr - 0x0fc0)
+		return true
+	}
+	return false
+}
+
+// atari256k (BF)
+type atari256k struct {
+	atari
+}
+
+func newAtari256k(data []byte) (cartMapper, error) {
+	cart := &atari256k{}
+	cart.bankSize = 4096
+	cart.mappingID = "BF"
+	cart.description = "256k"
+	cart.banks = make([][]uint8, cart.NumBanks())
+
+	if len(data) != cart.bankSize*cart.NumBanks() {
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in the cartridge file", cart.mappingID))
+	}
+
+	for k := 0; k < cart.NumBanks(); k++ {
+		cart.banks[k] = make([]uint8, cart.bankSize)
+		offset := k * cart.bankSize
+		copy(cart.banks[k], data[offset:offset+cart.bankSize])
+	}
+
+	cart.Initialise()
+
+	return cart, nil
+}
+
+// NumBanks implements the cartMapper interface
+func (cart atari256k) NumBanks() int {
+	return 64
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *atari256k) Snapshot() interface{} {
+	return &atari256k{atari: cart.atari.snapshot()}
+}
+
+// Restore implements the cartMapper interface
+func (cart *atari256k) Restore(s interface{}) error {
+	if s, ok := s.(*atari256k); ok {
+		cart.atari = s.atari.snapshot()
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// Read implements the cartMapper interface
+func (cart *atari256k) Read(addr uint16, passive bool) (uint8, error) {
+	if cart.hotspot(addr, passive) {
+		return 0, nil
+	}
+
+	if data, ok := cart.atari.Read(addr, passive); ok {
+		return data, nil
+	}
+
+	data := cart.banks[cart.bank][addr]
+
+	return data, nil
+}
+
+// Write implements the cartMapper interface
+func (cart *atari256k) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if passive {
+		return nil
+	}
+
+	if cart.hotspot(addr, passive) {
+		return nil
+	}
+
+	return cart.atari.Write(addr, data, passive, poke)
+}
+
+// bankswitch on hotspot access
+func (cart *atari256k) hotspot(addr uint16, passive bool) bool {
+	if addr >= 0x0f80 && addr <= 0x0fbf {
+		if passive {
+			return true
+		}
+		cart.bank = int(addr - 0x0f80)
+		return true
+	}
+	return false
+}
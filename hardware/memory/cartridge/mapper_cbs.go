@@ -165,6 +165,47 @@ func (cart *cbs) Listen(_ uint16, _ uint8) {
 func (cart *cbs) Step() {
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *cbs) Snapshot() interface{} {
+	n := *cart
+	n.ram = make([]uint8, len(cart.ram))
+	copy(n.ram, cart.ram)
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *cbs) Restore(s interface{}) error {
+	if s, ok := s.(*cbs); ok {
+		*cart = *s.Snapshot().(*cbs)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the cbs type as returned by SaveState()
+type cbsState struct {
+	Bank int
+	RAM  []uint8
+}
+
+// SaveState implements the cartMapper interface
+func (cart *cbs) SaveState() interface{} {
+	return cbsState{Bank: cart.bank, RAM: cart.ram}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *cbs) LoadState(decode func(interface{}) error) error {
+	var s cbsState
+	if err := decode(&s); err != nil {
+		return err
+	}
+	if err := validBank(s.Bank, len(cart.banks)); err != nil {
+		return err
+	}
+	cart.bank = s.Bank
+	return loadRAM(cart.ram, s.RAM)
+}
+
 // GetRAM implements the bus.CartRAMBus interface
 func (cart cbs) GetRAM() []bus.CartRAM {
 	r := make([]bus.CartRAM, 1)
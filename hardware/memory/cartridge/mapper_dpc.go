@@ -420,6 +420,52 @@ func (cart *dpc) Step() {
 	}
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *dpc) Snapshot() interface{} {
+	n := *cart
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *dpc) Restore(s interface{}) error {
+	if s, ok := s.(*dpc); ok {
+		*cart = *s.Snapshot().(*dpc)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the dpc type as returned by SaveState()
+type dpcState struct {
+	Bank      int
+	Registers DPCregisters
+	Beats     int
+}
+
+// SaveState implements the cartMapper interface
+func (cart *dpc) SaveState() interface{} {
+	return dpcState{
+		Bank:      cart.bank,
+		Registers: cart.registers,
+		Beats:     cart.beats,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *dpc) LoadState(decode func(interface{}) error) error {
+	var s dpcState
+	if err := decode(&s); err != nil {
+		return err
+	}
+	if err := validBank(s.Bank, len(cart.banks)); err != nil {
+		return err
+	}
+	cart.bank = s.Bank
+	cart.registers = s.Registers
+	cart.beats = s.Beats
+	return nil
+}
+
 // GetRegisters implements the bus.CartDebugBus interface
 func (cart dpc) GetRegisters() bus.CartRegisters {
 	return bus.CartRegisters(cart.registers)
@@ -0,0 +1,180 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/banks"
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// econobanking (0840) is an 8k format with two 4k banks. like the tigervision
+// format, banks are switched by accessing an address outside of cartridge
+// space. from the Stella source (Cart0840.hxx):
+//
+// "Bankswitching method used by several Atari homebrews: accessing $800
+// selects the first bank and accessing $840 selects the second. The
+// hotspots are mirrored throughout the $800-$FFF range."
+type econobanking struct {
+	mappingID   string
+	description string
+
+	bankSize int
+	banks    [][]uint8
+
+	// identifies the currently selected bank
+	bank int
+}
+
+func newEconobanking(data []byte) (cartMapper, error) {
+	cart := &econobanking{
+		mappingID:   "0840",
+		description: "econobanking",
+		bankSize:    4096,
+	}
+
+	if len(data) != cart.bankSize*2 {
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in the cartridge data", cart.mappingID))
+	}
+
+	cart.banks = make([][]uint8, len(data)/cart.bankSize)
+
+	for k := 0; k < cart.NumBanks(); k++ {
+		cart.banks[k] = make([]uint8, cart.bankSize)
+		offset := k * cart.bankSize
+		copy(cart.banks[k], data[offset:offset+cart.bankSize])
+	}
+
+	cart.Initialise()
+
+	return cart, nil
+}
+
+func (cart econobanking) String() string {
+	return fmt.Sprintf("%s [%s] Bank: %d", cart.mappingID, cart.description, cart.bank)
+}
+
+// ID implements the cartMapper interface
+func (cart econobanking) ID() string {
+	return cart.mappingID
+}
+
+// Initialise implements the cartMapper interface
+func (cart *econobanking) Initialise() {
+	cart.bank = 0
+}
+
+// Read implements the cartMapper interface
+func (cart *econobanking) Read(addr uint16, passive bool) (uint8, error) {
+	return cart.banks[cart.bank][addr], nil
+}
+
+// Write implements the cartMapper interface
+func (cart *econobanking) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if poke {
+		cart.banks[cart.bank][addr] = data
+		return nil
+	}
+
+	return errors.New(errors.MemoryBusError, addr)
+}
+
+// NumBanks implements the cartMapper interface
+func (cart econobanking) NumBanks() int {
+	return len(cart.banks)
+}
+
+// GetBank implements the cartMapper interface
+func (cart econobanking) GetBank(addr uint16) banks.Details {
+	return banks.Details{Number: cart.bank, IsRAM: false}
+}
+
+// Patch implements the cartMapper interface
+func (cart *econobanking) Patch(offset int, data uint8) error {
+	if offset >= cart.bankSize*len(cart.banks) {
+		return errors.New(errors.CartridgePatchOOB, offset)
+	}
+
+	bank := int(offset) / cart.bankSize
+	offset = offset % cart.bankSize
+	cart.banks[bank][offset] = data
+	return nil
+}
+
+// Listen implements the cartMapper interface
+func (cart *econobanking) Listen(addr uint16, _ uint8) {
+	// bankswitch on hotspot access. A12 must be low and A11 high
+	switch addr & 0x1840 {
+	case 0x0800:
+		cart.bank = 0
+	case 0x0840:
+		cart.bank = 1
+	}
+}
+
+// Step implements the cartMapper interface
+func (cart *econobanking) Step() {
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *econobanking) Snapshot() interface{} {
+	n := *cart
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *econobanking) Restore(s interface{}) error {
+	if s, ok := s.(*econobanking); ok {
+		*cart = *s
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// SaveState implements the cartMapper interface. The only state is the
+// current bank
+func (cart *econobanking) SaveState() interface{} {
+	return cart.bank
+}
+
+// LoadState implements the cartMapper interface
+func (cart *econobanking) LoadState(decode func(interface{}) error) error {
+	var bank int
+	if err := decode(&bank); err != nil {
+		return err
+	}
+	if err := validBank(bank, len(cart.banks)); err != nil {
+		return err
+	}
+	cart.bank = bank
+	return nil
+}
+
+// IterateBank implemnts the disassemble interface
+func (cart econobanking) IterateBanks(prev *banks.Content) *banks.Content {
+	b := prev.Number + 1
+	if b < len(cart.banks) {
+		return &banks.Content{Number: b,
+			Data: cart.banks[b],
+			Origins: []uint16{
+				memorymap.OriginCart,
+			},
+		}
+	}
+	return nil
+}
@@ -84,6 +84,31 @@ func (cart *ejected) Listen(_ uint16, _ uint8) {
 func (cart *ejected) Step() {
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *ejected) Snapshot() interface{} {
+	n := *cart
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *ejected) Restore(s interface{}) error {
+	if s, ok := s.(*ejected); ok {
+		*cart = *s.Snapshot().(*ejected)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// SaveState implements the cartMapper interface
+func (cart *ejected) SaveState() interface{} {
+	return nil
+}
+
+// LoadState implements the cartMapper interface
+func (cart *ejected) LoadState(_ func(interface{}) error) error {
+	return nil
+}
+
 // IterateBank implemnts the disassemble interface
 func (cart ejected) IterateBanks(prev *banks.Content) *banks.Content {
 	return nil
@@ -47,6 +47,9 @@ import (
 // write port while 1900-19FF is the read port.  You select which 256 byte
 // block appears here by accessing 1FF8 to 1FFB.
 //
+// (the 1FF8 to 1FFB range in the paragraph above is a mistake in the document.
+// the paragraph below has the correct range of 1FE8 to 1FEB)
+//
 //
 // from the same document, more detail about M-Network RAM:
 //
@@ -70,7 +73,8 @@ type mnetwork struct {
 	mappingID   string
 	description string
 
-	// mnetwork cartridges have 8 banks of 2048 bytes
+	// mnetwork cartridges have 8 banks of 2048 bytes (4 banks in the case of
+	// the 8k variant)
 	bankSize int
 	banks    [][]uint8
 
@@ -103,12 +107,30 @@ func newMnetwork(data []byte) (cartMapper, error) {
 		bankSize:    2048,
 	}
 
-	cart.banks = make([][]uint8, cart.NumBanks())
+	return cart.setup(data, 8)
+}
+
+// newMnetwork8k creates the 8k variant of the M-Network format. the format
+// only differs in the number of ROM banks. there are four 2k banks in all,
+// selected with hotspots 0x1fe4 to 0x1fe6, with the last bank fixed as normal.
+// hotspot 0x1fe7 still selects the 1k RAM.
+func newMnetwork8k(data []byte) (cartMapper, error) {
+	cart := &mnetwork{
+		description: "mnetwork (8k)",
+		mappingID:   "E78K",
+		bankSize:    2048,
+	}
+
+	return cart.setup(data, 4)
+}
 
-	if len(data) != cart.bankSize*cart.NumBanks() {
+func (cart *mnetwork) setup(data []byte, numBanks int) (cartMapper, error) {
+	if len(data) != cart.bankSize*numBanks {
 		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in the cartridge data", cart.mappingID))
 	}
 
+	cart.banks = make([][]uint8, numBanks)
+
 	for k := 0; k < cart.NumBanks(); k++ {
 		cart.banks[k] = make([]uint8, cart.bankSize)
 		offset := k * cart.bankSize
@@ -215,33 +237,22 @@ func (cart *mnetwork) Write(addr uint16, data uint8, passive bool, poke bool) er
 
 // bankswitch on hotspot access
 func (cart *mnetwork) hotspot(addr uint16, passive bool) bool {
-	if (addr >= 0xfe0 && addr <= 0xfe7) || (addr >= 0xff8 && addr <= 0x0ffb) {
+	if addr >= 0x0fe0 && addr <= 0x0feb {
 		if passive {
 			return true
 		}
 
 		switch addr {
-		case 0x0fe0:
-			cart.bank = 0
-			cart.use1kRAM = false
-		case 0x0fe1:
-			cart.bank = 1
-			cart.use1kRAM = false
-		case 0x0fe2:
-			cart.bank = 2
-			cart.use1kRAM = false
-		case 0x0fe3:
-			cart.bank = 3
-			cart.use1kRAM = false
-		case 0x0fe4:
-			cart.bank = 4
-			cart.use1kRAM = false
-		case 0x0fe5:
-			cart.bank = 5
-			cart.use1kRAM = false
-		case 0x0fe6:
-			cart.bank = 6
-			cart.use1kRAM = false
+		case 0x0fe0, 0x0fe1, 0x0fe2, 0x0fe3, 0x0fe4, 0x0fe5, 0x0fe6:
+			// the hotspots for the switchable banks are the ones immediately
+			// preceding 0x0fe7. for the standard 16k cartridge that's all
+			// hotspots from 0x0fe0 to 0x0fe6. for the 8k variant the hotspots
+			// are 0x0fe4 to 0x0fe6 and the others have no effect.
+			base := 0x0fe7 - uint16(cart.NumBanks()-1)
+			if addr >= base {
+				cart.bank = int(addr - base)
+				cart.use1kRAM = false
+			}
 
 			// from bankswitch_sizes.txt: "Note that you cannot select the last 2K
 			// of the ROM image into the lower 2K of the cart!  Accessing 1FE7
@@ -251,18 +262,19 @@ func (cart *mnetwork) hotspot(addr uint16, passive bool) bool {
 		case 0x0fe7:
 			cart.use1kRAM = true
 
-			// from bankswitch_sizes.txt: "You select which 256 byte block appears
-			// here by accessing 1FF8 to 1FFB."
+			// from bankswitch_sizes.txt: "You select which part to use by
+			// issuing a fake read to 1FE8-1FEB. The RAM is then available for
+			// use by all banks at 1800-19FF."
 			//
-			// "here" refers to the read range 0x0900 to 0x09ff and the write range
-			// 0x0800 to 0x08ff
-		case 0x0ff8:
+			// ie. the read range 0x0900 to 0x09ff and the write range 0x0800
+			// to 0x08ff
+		case 0x0fe8:
 			cart.ram256byteIdx = 0
-		case 0x0ff9:
+		case 0x0fe9:
 			cart.ram256byteIdx = 1
-		case 0x0ffa:
+		case 0x0fea:
 			cart.ram256byteIdx = 2
-		case 0x0ffb:
+		case 0x0feb:
 			cart.ram256byteIdx = 3
 		}
 
@@ -274,7 +286,7 @@ func (cart *mnetwork) hotspot(addr uint16, passive bool) bool {
 
 // NumBanks implements the cartMapper interface
 func (cart mnetwork) NumBanks() int {
-	return 8 // eight banks of 2k
+	return len(cart.banks)
 }
 
 // GetBank implements the cartMapper interface
@@ -290,7 +302,7 @@ func (cart *mnetwork) GetBank(addr uint16) banks.Details {
 		return banks.Details{Number: cart.ram256byteIdx, IsRAM: true, Segment: 1}
 	}
 
-	return banks.Details{Number: 7, IsRAM: false, Segment: 1}
+	return banks.Details{Number: cart.NumBanks() - 1, IsRAM: false, Segment: 1}
 }
 
 // Patch implements the cartMapper interface
@@ -313,6 +325,73 @@ func (cart *mnetwork) Listen(_ uint16, _ uint8) {
 func (cart *mnetwork) Step() {
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *mnetwork) Snapshot() interface{} {
+	n := *cart
+	for i := range cart.ram256byte {
+		n.ram256byte[i] = make([]uint8, len(cart.ram256byte[i]))
+		copy(n.ram256byte[i], cart.ram256byte[i])
+	}
+	n.ram1k = make([]uint8, len(cart.ram1k))
+	copy(n.ram1k, cart.ram1k)
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *mnetwork) Restore(s interface{}) error {
+	if s, ok := s.(*mnetwork); ok {
+		*cart = *s.Snapshot().(*mnetwork)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the mnetwork type as returned by SaveState()
+type mnetworkState struct {
+	Bank          int
+	RAM256byte    [num256ByteRAMbanks][]uint8
+	RAM256byteIdx int
+	RAM1k         []uint8
+	Use1kRAM      bool
+}
+
+// SaveState implements the cartMapper interface
+func (cart *mnetwork) SaveState() interface{} {
+	return mnetworkState{
+		Bank:          cart.bank,
+		RAM256byte:    cart.ram256byte,
+		RAM256byteIdx: cart.ram256byteIdx,
+		RAM1k:         cart.ram1k,
+		Use1kRAM:      cart.use1kRAM,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *mnetwork) LoadState(decode func(interface{}) error) error {
+	var s mnetworkState
+	if err := decode(&s); err != nil {
+		return err
+	}
+	if err := validBank(s.Bank, len(cart.banks)); err != nil {
+		return err
+	}
+	if err := validBank(s.RAM256byteIdx, len(cart.ram256byte)); err != nil {
+		return err
+	}
+	for i := range cart.ram256byte {
+		if err := loadRAM(cart.ram256byte[i], s.RAM256byte[i]); err != nil {
+			return err
+		}
+	}
+	if err := loadRAM(cart.ram1k, s.RAM1k); err != nil {
+		return err
+	}
+	cart.bank = s.Bank
+	cart.ram256byteIdx = s.RAM256byteIdx
+	cart.use1kRAM = s.Use1kRAM
+	return nil
+}
+
 // GetRAM implements the bus.CartRAMBus interface
 func (cart mnetwork) GetRAM() []bus.CartRAM {
 	r := make([]bus.CartRAM, num256ByteRAMbanks+1)
@@ -350,7 +429,7 @@ func (cart *mnetwork) PutRAM(bank int, idx int, data uint8) {
 // IterateBank implemnts the disassemble interface
 func (cart mnetwork) IterateBanks(prev *banks.Content) *banks.Content {
 	b := prev.Number + 1
-	if b >= 0 && b <= 6 {
+	if b >= 0 && b < cart.NumBanks()-1 {
 		// includes 1k ram section
 		return &banks.Content{Number: b,
 			Data: cart.banks[b],
@@ -358,7 +437,7 @@ func (cart mnetwork) IterateBanks(prev *banks.Content) *banks.Content {
 				memorymap.OriginCart,
 			},
 		}
-	} else if b == 7 {
+	} else if b == cart.NumBanks()-1 {
 		// includes 256B ram section
 		return &banks.Content{Number: b,
 			Data: cart.banks[b],
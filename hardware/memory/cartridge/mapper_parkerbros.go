@@ -243,6 +243,42 @@ func (cart *parkerBros) Listen(_ uint16, _ uint8) {
 func (cart *parkerBros) Step() {
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *parkerBros) Snapshot() interface{} {
+	n := *cart
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *parkerBros) Restore(s interface{}) error {
+	if s, ok := s.(*parkerBros); ok {
+		*cart = *s.Snapshot().(*parkerBros)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// SaveState implements the cartMapper interface. The only state is the bank
+// in each segment
+func (cart *parkerBros) SaveState() interface{} {
+	return cart.segment
+}
+
+// LoadState implements the cartMapper interface
+func (cart *parkerBros) LoadState(decode func(interface{}) error) error {
+	var segment [4]int
+	if err := decode(&segment); err != nil {
+		return err
+	}
+	for _, b := range segment {
+		if err := validBank(b, len(cart.banks)); err != nil {
+			return err
+		}
+	}
+	cart.segment = segment
+	return nil
+}
+
 // IterateBank implemnts the disassemble interface
 func (cart parkerBros) IterateBanks(prev *banks.Content) *banks.Content {
 	b := prev.Number + 1
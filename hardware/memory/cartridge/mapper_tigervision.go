@@ -178,6 +178,42 @@ func (cart *tigervision) Listen(addr uint16, data uint8) {
 func (cart *tigervision) Step() {
 }
 
+// Snapshot implements the cartMapper interface
+func (cart *tigervision) Snapshot() interface{} {
+	n := *cart
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *tigervision) Restore(s interface{}) error {
+	if s, ok := s.(*tigervision); ok {
+		*cart = *s.Snapshot().(*tigervision)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// SaveState implements the cartMapper interface. The only state is the bank
+// in each segment
+func (cart *tigervision) SaveState() interface{} {
+	return cart.segment
+}
+
+// LoadState implements the cartMapper interface
+func (cart *tigervision) LoadState(decode func(interface{}) error) error {
+	var segment [2]int
+	if err := decode(&segment); err != nil {
+		return err
+	}
+	for _, b := range segment {
+		if err := validBank(b, len(cart.banks)); err != nil {
+			return err
+		}
+	}
+	cart.segment = segment
+	return nil
+}
+
 // IterateBank implemnts the disassemble interface
 func (cart tigervision) IterateBanks(prev *banks.Content) *banks.Content {
 	b := prev.Number + 1
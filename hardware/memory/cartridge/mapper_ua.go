@@ -0,0 +1,179 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package cartridge
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/memory/cartridge/banks"
+	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
+)
+
+// uaLimited (UA) is an 8k format with two 4k banks, used by UA Ltd. banks are
+// switched by accessing an address outside of cartridge space: 0x0220 selects
+// the first bank and 0x0240 selects the second.
+//
+//	o Funky Fish
+//	o Pleiades
+type uaLimited struct {
+	mappingID   string
+	description string
+
+	bankSize int
+	banks    [][]uint8
+
+	// identifies the currently selected bank
+	bank int
+}
+
+func newUALimited(data []byte) (cartMapper, error) {
+	cart := &uaLimited{
+		mappingID:   "UA",
+		description: "UA Ltd",
+		bankSize:    4096,
+	}
+
+	if len(data) != cart.bankSize*2 {
+		return nil, errors.New(errors.CartridgeError, fmt.Sprintf("%s: wrong number of bytes in the cartridge data", cart.mappingID))
+	}
+
+	cart.banks = make([][]uint8, len(data)/cart.bankSize)
+
+	for k := 0; k < cart.NumBanks(); k++ {
+		cart.banks[k] = make([]uint8, cart.bankSize)
+		offset := k * cart.bankSize
+		copy(cart.banks[k], data[offset:offset+cart.bankSize])
+	}
+
+	cart.Initialise()
+
+	return cart, nil
+}
+
+func (cart uaLimited) String() string {
+	return fmt.Sprintf("%s [%s] Bank: %d", cart.mappingID, cart.description, cart.bank)
+}
+
+// ID implements the cartMapper interface
+func (cart uaLimited) ID() string {
+	return cart.mappingID
+}
+
+// Initialise implements the cartMapper interface
+func (cart *uaLimited) Initialise() {
+	cart.bank = 0
+}
+
+// Read implements the cartMapper interface
+func (cart *uaLimited) Read(addr uint16, passive bool) (uint8, error) {
+	return cart.banks[cart.bank][addr], nil
+}
+
+// Write implements the cartMapper interface
+func (cart *uaLimited) Write(addr uint16, data uint8, passive bool, poke bool) error {
+	if poke {
+		cart.banks[cart.bank][addr] = data
+		return nil
+	}
+
+	return errors.New(errors.MemoryBusError, addr)
+}
+
+// NumBanks implements the cartMapper interface
+func (cart uaLimited) NumBanks() int {
+	return len(cart.banks)
+}
+
+// GetBank implements the cartMapper interface
+func (cart uaLimited) GetBank(addr uint16) banks.Details {
+	return banks.Details{Number: cart.bank, IsRAM: false}
+}
+
+// Patch implements the cartMapper interface
+func (cart *uaLimited) Patch(offset int, data uint8) error {
+	if offset >= cart.bankSize*len(cart.banks) {
+		return errors.New(errors.CartridgePatchOOB, offset)
+	}
+
+	bank := int(offset) / cart.bankSize
+	offset = offset % cart.bankSize
+	cart.banks[bank][offset] = data
+	return nil
+}
+
+// Listen implements the cartMapper interface
+func (cart *uaLimited) Listen(addr uint16, _ uint8) {
+	// bankswitch on hotspot access
+	switch addr & 0x1260 {
+	case 0x0220:
+		cart.bank = 0
+	case 0x0240:
+		cart.bank = 1
+	}
+}
+
+// Step implements the cartMapper interface
+func (cart *uaLimited) Step() {
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *uaLimited) Snapshot() interface{} {
+	n := *cart
+	return &n
+}
+
+// Restore implements the cartMapper interface
+func (cart *uaLimited) Restore(s interface{}) error {
+	if s, ok := s.(*uaLimited); ok {
+		*cart = *s
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// SaveState implements the cartMapper interface. The only state is the
+// current bank
+func (cart *uaLimited) SaveState() interface{} {
+	return cart.bank
+}
+
+// LoadState implements the cartMapper interface
+func (cart *uaLimited) LoadState(decode func(interface{}) error) error {
+	var bank int
+	if err := decode(&bank); err != nil {
+		return err
+	}
+	if err := validBank(bank, len(cart.banks)); err != nil {
+		return err
+	}
+	cart.bank = bank
+	return nil
+}
+
+// IterateBank implemnts the disassemble interface
+func (cart uaLimited) IterateBanks(prev *banks.Content) *banks.Content {
+	b := prev.Number + 1
+	if b < len(cart.banks) {
+		return &banks.Content{Number: b,
+			Data: cart.banks[b],
+			Origins: []uint16{
+				memorymap.OriginCart,
+			},
+		}
+	}
+	return nil
+}
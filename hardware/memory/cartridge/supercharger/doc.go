@@ -32,4 +32,15 @@
 //
 // Wherever it is handled, the error should be caught and interpreted as a
 // function and called, with a reference to the emulator's CPU, RAM and Timer.
+//
+// Fast-load binaries can contain more than one load. The first load is used
+// when the tape is first started, subsequent loads are made when the BIOS is
+// called by the game (a multi-load).
+//
+// Alternatively, the tape can be loaded from a WAV recording of a real
+// Supercharger tape. In this case, the audio input of the Supercharger is
+// emulated and the BIOS loads the data as it would on real hardware. No
+// special handling is required by the emulator but note that the real
+// Supercharger BIOS is required. MP3 recordings are not supported and should
+// be converted to WAV before use.
 package supercharger
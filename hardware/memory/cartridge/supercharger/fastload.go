@@ -31,6 +31,11 @@ import (
 // On success it returns the FastLoaded error. This must be interpreted by the
 // emulator driver and called with the arguments listed in the error type.
 //
+// The binary file can contain more than one load. Each load is 8448 bytes
+// long. The first load is used when the tape is first loaded. Subsequent
+// loads are requested by the BIOS with the multiload number stored at VCS RAM
+// address 0xfa.
+//
 // Format information for fast-loca binary rom mailing list post:
 //
 // Subject: Re: [stella] Supercharger BIN format
@@ -39,8 +44,21 @@ import (
 type FastLoad struct {
 	cart *Supercharger
 	data []byte
+
+	// the number of loads in the data
+	numLoads int
+
+	// whether the first load has happened. once the first load has happened
+	// the load to use is taken from VCS RAM
+	loaded bool
 }
 
+// the length of each load in a fastload binary
+const fastLoadBlockLen = 8448
+
+// the VCS RAM address containing the number of the requested multiload
+const multiloadAddress = 0xfa
+
 // FastLoaded error is returned on success of FastLoad.Load(). It must be
 // honoured (ie. caught and the function called) by the driving emulator for
 // the fastload process to complete.
@@ -61,72 +79,78 @@ func (er FastLoaded) Error() string {
 }
 
 // NewFastLoad is the preferred method of initialisation for the FastLoad type
-func NewFastLoad(cart *Supercharger, data interface{}) (Tape, error) {
+func NewFastLoad(cart *Supercharger, data []byte) (Tape, error) {
 	tap := &FastLoad{
 		cart: cart,
-		data: data.([]byte),
+		data: data,
 	}
 
-	l := len(tap.data)
-	if l != 8448 && l != 25344 && l != 33792 {
+	if len(tap.data) == 0 || len(tap.data)%fastLoadBlockLen != 0 {
 		return nil, errors.New(errors.SuperchargerError, "wrong number of bytes in cartridge data")
 	}
 
+	tap.numLoads = len(tap.data) / fastLoadBlockLen
+
 	return tap, nil
 }
 
 // Load implements the Tape interface
-func (tap *FastLoad) Load() error {
-	gameData := tap.data[0:0x1eff]
+func (tap *FastLoad) Load() (uint8, error) {
+	// the actual loading is deferred until the FastLoaded function is called
+	// because we need access to VCS RAM to decide which load to use
+	return 0, FastLoaded(func(mc *cpu.CPU, ram *vcs.RAM, tmr *timer.Timer) error {
+		block, err := tap.findLoad(ram)
+		if err != nil {
+			return err
+		}
 
-	// only 8448 .bin format is supported currently
-	gameHeader := tap.data[0x2000:0x2008]
+		tap.loaded = true
 
-	// PC address to jump to once loading has finished
-	startAddress := (uint16(gameHeader[1]) << 8) | uint16(gameHeader[0])
+		gameData := block[0:0x2000]
+		gameHeader := block[0x2000:0x2008]
 
-	// RAM config to be set adter tape load
-	configByte := gameHeader[2]
+		// PC address to jump to once loading has finished
+		startAddress := (uint16(gameHeader[1]) << 8) | uint16(gameHeader[0])
 
-	// number of pages to load
-	numPages := int(gameHeader[3])
+		// RAM config to be set adter tape load
+		configByte := gameHeader[2]
 
-	// not using the following in any meaningful way
-	checksum := gameHeader[4]
-	multiload := gameHeader[5]
-	progressCounter := (uint16(gameHeader[7]) << 8) | uint16(gameHeader[6])
+		// number of pages to load
+		numPages := int(gameHeader[3])
 
-	logger.Log("supercharger", fmt.Sprintf("start address: %#04x", startAddress))
-	logger.Log("supercharger", fmt.Sprintf("config byte: %#08b", configByte))
-	logger.Log("supercharger", fmt.Sprintf("num pages: %d", numPages))
-	logger.Log("supercharger", fmt.Sprintf("checksum: %#02x", checksum))
-	logger.Log("supercharger", fmt.Sprintf("multi load: %#02x", multiload))
-	logger.Log("supercharger", fmt.Sprintf("progress counter: %#02x", progressCounter))
+		// not using the following in any meaningful way
+		checksum := gameHeader[4]
+		multiload := gameHeader[5]
+		progressCounter := (uint16(gameHeader[7]) << 8) | uint16(gameHeader[6])
 
-	// data is loaded accoring to page table
-	pageTable := tap.data[0x2010:0x2028]
-	logger.Log("supercharger", fmt.Sprintf("page-table: %v", pageTable))
+		logger.Log("supercharger", fmt.Sprintf("start address: %#04x", startAddress))
+		logger.Log("supercharger", fmt.Sprintf("config byte: %#08b", configByte))
+		logger.Log("supercharger", fmt.Sprintf("num pages: %d", numPages))
+		logger.Log("supercharger", fmt.Sprintf("checksum: %#02x", checksum))
+		logger.Log("supercharger", fmt.Sprintf("multi load: %#02x", multiload))
+		logger.Log("supercharger", fmt.Sprintf("progress counter: %#02x", progressCounter))
 
-	// copy data to RAM banks
-	for i := 0; i < numPages; i++ {
-		bank := pageTable[i] & 0x3
-		page := pageTable[i] >> 2
-		bankOffset := int(page) * 0x100
-		binOffset := i * 0x100
+		// data is loaded accoring to page table
+		pageTable := block[0x2010:0x2028]
+		logger.Log("supercharger", fmt.Sprintf("page-table: %v", pageTable))
 
-		data := gameData[binOffset : binOffset+0x100]
-		copy(tap.cart.ram[bank][bankOffset:bankOffset+0x100], data)
+		// copy data to RAM banks
+		for i := 0; i < numPages; i++ {
+			bank := pageTable[i] & 0x3
+			page := pageTable[i] >> 2
+			bankOffset := int(page) * 0x100
+			binOffset := i * 0x100
 
-		logger.Log("supercharger", fmt.Sprintf("copying %#04x:%#04x to bank %d page %d, offset %#04x", binOffset, binOffset+0x100, bank, page, bankOffset))
-	}
+			data := gameData[binOffset : binOffset+0x100]
+			copy(tap.cart.ram[bank][bankOffset:bankOffset+0x100], data)
 
-	// setup cartridge according to tape instructions. we do this by returning
-	// a function disguised as an error type. The VCS knows how to interpret
-	// this error and will call the function
-	return FastLoaded(func(mc *cpu.CPU, ram *vcs.RAM, tmr *timer.Timer) error {
+			logger.Log("supercharger", fmt.Sprintf("copying %#04x:%#04x to bank %d page %d, offset %#04x", binOffset, binOffset+0x100, bank, page, bankOffset))
+		}
+
+		// setup cartridge according to tape instructions
 		tap.cart.registers.setConfigByte(configByte)
 
-		err := mc.LoadPC(startAddress)
+		err = mc.LoadPC(startAddress)
 		if err != nil {
 			return errors.New(errors.SuperchargerError, err)
 		}
@@ -164,3 +188,36 @@ func (tap *FastLoad) Load() error {
 		return nil
 	})
 }
+
+// findLoad returns the block of data for the next load. the first load is
+// always the first block in the data. after that, the block is chosen by
+// matching the multiload byte in the block header with the value in VCS RAM.
+func (tap *FastLoad) findLoad(ram *vcs.RAM) ([]byte, error) {
+	if !tap.loaded {
+		return tap.data[:fastLoadBlockLen], nil
+	}
+
+	m, err := ram.Peek(multiloadAddress)
+	if err != nil {
+		return nil, errors.New(errors.SuperchargerError, err)
+	}
+
+	for i := 0; i < tap.numLoads; i++ {
+		block := tap.data[i*fastLoadBlockLen : (i+1)*fastLoadBlockLen]
+		if block[0x2005] == m {
+			logger.Log("supercharger", fmt.Sprintf("multiload %d found in block %d", m, i))
+			return block, nil
+		}
+	}
+
+	return nil, errors.New(errors.SuperchargerError, fmt.Sprintf("multiload %d not found on tape", m))
+}
+
+// Step implements the Tape interface
+func (tap *FastLoad) Step() {
+}
+
+// Rewind implements the Tape interface
+func (tap *FastLoad) Rewind() {
+	tap.loaded = false
+}
@@ -0,0 +1,187 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package supercharger
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-audio/wav"
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/logger"
+)
+
+// SoundLoad implements the Tape interface. It loads data from a sampled
+// recording of a Supercharger tape by emulating the audio input of the
+// Supercharger. The BIOS reads the audio input through address 0x1ff9 and
+// decodes the data in exactly the same way as it would on real hardware.
+//
+// This means that the real Supercharger BIOS is required. It also means that
+// loading takes as long as it would on the real hardware.
+//
+// Only WAV files are currently supported. MP3 files are recognised but are
+// rejected by NewSoundLoad() with an error suggesting that the recording is
+// converted to WAV.
+type SoundLoad struct {
+	cart *Supercharger
+
+	// the samples in the recording mixed down to a single channel
+	samples []float64
+
+	// the number of samples to advance per CPU cycle
+	samplesPerCycle float64
+
+	// samples above the threshold are interpreted as a set audio bit. the
+	// threshold is the average of all samples, which compensates for any DC
+	// offset in the recording
+	threshold float64
+
+	// the position of the tape. idx is the current sample and frac is the
+	// fractional progress towards the next sample
+	idx  int
+	frac float64
+
+	// the tape starts playing on the first access of the audio input
+	playing bool
+}
+
+// the VCS CPU runs at this rate. the difference between NTSC and PAL
+// machines is not significant for the purposes of tape loading
+const cpuClock = 1193182.0
+
+// FingerprintSoundLoad returns true if data looks like a sound file that is
+// intended for the SoundLoad type. Note that this includes MP3 files, which
+// are not supported by NewSoundLoad(). This is so that the user is told why
+// the file cannot be loaded.
+func FingerprintSoundLoad(data []byte) bool {
+	return fingerprintWAV(data) || fingerprintMP3(data)
+}
+
+func fingerprintWAV(data []byte) bool {
+	return len(data) > 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE"
+}
+
+// MP3 files either begin with an ID3 tag or with the sync word of the first
+// MPEG audio frame. the sync word check also requires that the frame is a
+// layer III frame
+func fingerprintMP3(data []byte) bool {
+	if len(data) < 3 {
+		return false
+	}
+	if string(data[0:3]) == "ID3" {
+		return true
+	}
+	return data[0] == 0xff && data[1]&0xe6 == 0xe2
+}
+
+// NewSoundLoad is the preferred method of initialisation for the SoundLoad
+// type
+func NewSoundLoad(cart *Supercharger, data []byte) (Tape, error) {
+	tap := &SoundLoad{
+		cart: cart,
+	}
+
+	if fingerprintMP3(data) {
+		return nil, errors.New(errors.SuperchargerError, "MP3 tapes are not supported (convert the recording to WAV)")
+	}
+
+	dec := wav.NewDecoder(bytes.NewReader(data))
+	if !dec.IsValidFile() {
+		return nil, errors.New(errors.SuperchargerError, "not a valid wav file")
+	}
+
+	buf, err := dec.FullPCMBuffer()
+	if err != nil {
+		return nil, errors.New(errors.SuperchargerError, err)
+	}
+
+	numChans := buf.Format.NumChannels
+	if numChans < 1 {
+		return nil, errors.New(errors.SuperchargerError, "wav file has no audio channels")
+	}
+
+	if buf.Format.SampleRate <= 0 {
+		return nil, errors.New(errors.SuperchargerError, "wav file has an invalid sample rate")
+	}
+
+	// mix channels
+	tap.samples = make([]float64, len(buf.Data)/numChans)
+	var total float64
+	for i := range tap.samples {
+		var v float64
+		for c := 0; c < numChans; c++ {
+			v += float64(buf.Data[i*numChans+c])
+		}
+		v /= float64(numChans)
+		tap.samples[i] = v
+		total += v
+	}
+
+	if len(tap.samples) == 0 {
+		return nil, errors.New(errors.SuperchargerError, "wav file contains no audio")
+	}
+
+	tap.threshold = total / float64(len(tap.samples))
+	tap.samplesPerCycle = float64(buf.Format.SampleRate) / cpuClock
+
+	logger.Log("supercharger", fmt.Sprintf("wav: %d samples at %dHz", len(tap.samples), buf.Format.SampleRate))
+
+	return tap, nil
+}
+
+// Load implements the Tape interface. It returns the state of the audio input
+// in bit 0.
+func (tap *SoundLoad) Load() (uint8, error) {
+	if !tap.playing && tap.idx == 0 {
+		tap.playing = true
+		logger.Log("supercharger", "tape playing")
+	}
+
+	if tap.idx >= len(tap.samples) {
+		return 0, nil
+	}
+
+	if tap.samples[tap.idx] > tap.threshold {
+		return 0x01, nil
+	}
+
+	return 0x00, nil
+}
+
+// Step implements the Tape interface
+func (tap *SoundLoad) Step() {
+	if !tap.playing {
+		return
+	}
+
+	tap.frac += tap.samplesPerCycle
+	for tap.frac >= 1.0 {
+		tap.idx++
+		tap.frac--
+	}
+
+	if tap.idx >= len(tap.samples) {
+		tap.playing = false
+		logger.Log("supercharger", "tape finished")
+	}
+}
+
+// Rewind implements the Tape interface
+func (tap *SoundLoad) Rewind() {
+	tap.idx = 0
+	tap.frac = 0
+	tap.playing = false
+}
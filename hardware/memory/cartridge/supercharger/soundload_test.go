@@ -0,0 +1,170 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package supercharger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+const testSampleRate = 44100
+
+// create a WAV file from the list of samples. each sample is written to every
+// channel. the contents of the file are returned
+func testWAV(t *testing.T, samples []int, numChans int) []byte {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "supercharger")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "tape.wav")
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	buf := &audio.IntBuffer{
+		Format:         &audio.Format{NumChannels: numChans, SampleRate: testSampleRate},
+		SourceBitDepth: 16,
+	}
+	for _, s := range samples {
+		for c := 0; c < numChans; c++ {
+			buf.Data = append(buf.Data, s)
+		}
+	}
+
+	enc := wav.NewEncoder(f, testSampleRate, 16, numChans, 1)
+	err = enc.Write(buf)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = enc.Close()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	f.Close()
+
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return data
+}
+
+// the expected audio bit after the tape has been stepped the number of cycles
+func expectedBit(samples []int, cycles int) uint8 {
+	idx := int(float64(cycles) * testSampleRate / cpuClock)
+	if idx >= len(samples) || samples[idx] <= 0 {
+		return 0x00
+	}
+	return 0x01
+}
+
+func testDecode(t *testing.T, numChans int) {
+	t.Helper()
+
+	// a square wave with an uneven duty cycle. the average value of the
+	// samples is not zero, which means the threshold is not zero either
+	samples := make([]int, 0, 1000)
+	for len(samples) < 1000 {
+		for i := 0; i < 10; i++ {
+			samples = append(samples, 10000)
+		}
+		for i := 0; i < 5; i++ {
+			samples = append(samples, -8000)
+		}
+	}
+
+	data := testWAV(t, samples, numChans)
+	if !FingerprintSoundLoad(data) {
+		t.Fatalf("WAV file not fingerprinted")
+	}
+
+	tape, err := NewSoundLoad(nil, data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	tap := tape.(*SoundLoad)
+
+	if len(tap.samples) != len(samples) {
+		t.Fatalf("unexpected number of samples (%d) should be (%d)", len(tap.samples), len(samples))
+	}
+
+	// the tape should not move until it has been accessed
+	tap.Step()
+	if tap.idx != 0 || tap.playing {
+		t.Fatalf("tape should not be playing before the first access")
+	}
+
+	// play the tape past the end of the recording
+	cycles := int(float64(len(samples)+10) * cpuClock / testSampleRate)
+	for c := 0; c < cycles; c++ {
+		b, err := tap.Load()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if b != expectedBit(samples, c) {
+			t.Fatalf("unexpected audio bit at cycle %d (%d) should be (%d)", c, b, expectedBit(samples, c))
+		}
+		tap.Step()
+	}
+
+	if tap.playing {
+		t.Errorf("tape should have finished")
+	}
+
+	// rewinding returns the tape to the start
+	tap.Rewind()
+	b, _ := tap.Load()
+	if b != 0x01 || !tap.playing {
+		t.Errorf("tape did not rewind correctly")
+	}
+}
+
+func TestSoundLoad(t *testing.T) {
+	testDecode(t, 1)
+	testDecode(t, 2)
+}
+
+func TestSoundLoadMP3(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("ID3\x03\x00\x00\x00\x00\x00\x00"),
+		[]byte{0xff, 0xfb, 0x90, 0x64, 0x00},
+	} {
+		if !FingerprintSoundLoad(data) {
+			t.Errorf("MP3 file not fingerprinted")
+		}
+
+		_, err := NewSoundLoad(nil, data)
+		if err == nil {
+			t.Errorf("MP3 file should be rejected")
+		}
+	}
+
+	// cartridge data padded with 0xff should not look like an MP3 file
+	if FingerprintSoundLoad([]byte{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("padded cartridge data fingerprinted as MP3 file")
+	}
+}
@@ -35,7 +35,15 @@ const bankSize = 2048
 // interface, the Supercharger implementation supports both fast-loading
 // from a Stella bin file, and "slow" loading from a sound file.
 type Tape interface {
-	Load() error
+	// Load is called whenever address 0x1ff9 is read. the returned value
+	// will be the value on the data bus
+	Load() (uint8, error)
+
+	// Step is called every CPU cycle
+	Step()
+
+	// Rewind returns the tape to the beginning
+	Rewind()
 }
 
 // Supercharger represents a supercharger cartridge
@@ -52,7 +60,8 @@ type Supercharger struct {
 }
 
 // NewSupercharger is the preferred method of initialisation for the
-// Supercharger type
+// Supercharger type. The data can be either a fast-load binary file or a WAV
+// recording of a Supercharger tape.
 func NewSupercharger(data []byte) (*Supercharger, error) {
 	cart := &Supercharger{
 		mappingID:   MappingID,
@@ -62,8 +71,13 @@ func NewSupercharger(data []byte) (*Supercharger, error) {
 
 	var err error
 
-	// set up tape
-	cart.tape, err = NewFastLoad(cart, data)
+	// set up tape. sound files are loaded via the BIOS, while binary files
+	// are fast-loaded
+	if FingerprintSoundLoad(data) {
+		cart.tape, err = NewSoundLoad(cart, data)
+	} else {
+		cart.tape, err = NewFastLoad(cart, data)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +116,7 @@ func (cart *Supercharger) Initialise() {
 	cart.registers.BankingMode = 0
 	cart.registers.ROMpower = true
 	cart.registers.RAMwrite = true
+	cart.tape.Rewind()
 }
 
 // Read implements the cartMapper interface
@@ -119,7 +134,7 @@ func (cart *Supercharger) Read(fullAddr uint16, passive bool) (uint8, error) {
 			if !cart.registers.RAMwrite {
 				return 0, nil
 			}
-			return 0, cart.tape.Load()
+			return cart.tape.Load()
 		}
 
 		// note address to be used as the next value in the control register
@@ -248,6 +263,66 @@ func (cart *Supercharger) Listen(addr uint16, _ uint8) {
 
 // Step implements the cartMapper interface
 func (cart *Supercharger) Step() {
+	cart.tape.Step()
+}
+
+// Snapshot implements the cartMapper interface
+func (cart *Supercharger) Snapshot() interface{} {
+	n := *cart
+	for i := range cart.ram {
+		n.ram[i] = make([]uint8, len(cart.ram[i]))
+		copy(n.ram[i], cart.ram[i])
+	}
+	return &n
+}
+
+// Restore implements the cartMapper interface. Note that the tape is not
+// affected.
+func (cart *Supercharger) Restore(s interface{}) error {
+	if s, ok := s.(*Supercharger); ok {
+		*cart = *s.Snapshot().(*Supercharger)
+		return nil
+	}
+	return errors.New(errors.SnapshotError, "cartridge mapper mismatch")
+}
+
+// the state of the Supercharger type as returned by SaveState()
+type superchargerState struct {
+	Registers         Registers
+	TransitionAddress uint16
+	RAM               [3][]uint8
+}
+
+// SaveState implements the cartMapper interface. As with Restore(), the
+// state of the tape is not included.
+func (cart *Supercharger) SaveState() interface{} {
+	return superchargerState{
+		Registers:         cart.registers,
+		TransitionAddress: cart.registers.transitionAddress,
+		RAM:               cart.ram,
+	}
+}
+
+// LoadState implements the cartMapper interface
+func (cart *Supercharger) LoadState(decode func(interface{}) error) error {
+	var s superchargerState
+	if err := decode(&s); err != nil {
+		return err
+	}
+
+	for i := range cart.ram {
+		if len(cart.ram[i]) != len(s.RAM[i]) {
+			return errors.New(errors.SnapshotError, "cartridge RAM state is the wrong size")
+		}
+	}
+	for i := range cart.ram {
+		copy(cart.ram[i], s.RAM[i])
+	}
+
+	cart.registers = s.Registers
+	cart.registers.transitionAddress = s.TransitionAddress
+
+	return nil
 }
 
 // IterateBank implemnts the disassemble interface
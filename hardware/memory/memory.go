@@ -16,7 +16,9 @@
 package memory
 
 import (
+	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/jetsetilly/gopher2600/errors"
 	"github.com/jetsetilly/gopher2600/hardware/memory/addresses"
@@ -70,11 +72,16 @@ type VCSMemory struct {
 	// pins are randomised. this is the equivalent of the Stella option "drive
 	// unused pins randomly on a read/peek"
 	RandomPins prefs.Bool
+
+	// the source of random numbers when RandomPins is true
+	rand *rand.Rand
 }
 
 // NewVCSMemory is the preferred method of initialisation for VCSMemory
 func NewVCSMemory() (*VCSMemory, error) {
-	mem := &VCSMemory{}
+	mem := &VCSMemory{
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
 
 	mem.Memmap = make([]bus.DebugBus, memorymap.Memtop+1)
 
@@ -104,6 +111,12 @@ func NewVCSMemory() (*VCSMemory, error) {
 	return mem, nil
 }
 
+// SetRandSource sets the source of random numbers used when RandomPins is
+// true. By default the source is seeded with the current time.
+func (mem *VCSMemory) SetRandSource(rnd *rand.Rand) {
+	mem.rand = rnd
+}
+
 // GetArea returns the actual memory of the specified area type
 func (mem *VCSMemory) GetArea(area memorymap.Area) bus.DebugBus {
 	switch area {
@@ -149,14 +162,14 @@ func (mem *VCSMemory) read(address uint16, zeroPage bool) (uint8, error) {
 		if !zeroPage {
 			data &= addresses.DataMasks[ma]
 			if mem.RandomPins.Get().(bool) {
-				data |= uint8(rand.Int()) & (addresses.DataMasks[ma] ^ 0xff)
+				data |= uint8(mem.rand.Int()) & (addresses.DataMasks[ma] ^ 0xff)
 			} else {
 				data |= uint8((address>>8)&0xff) & (addresses.DataMasks[ma] ^ 0xff)
 			}
 		} else {
 			data &= addresses.DataMasks[ma]
 			if mem.RandomPins.Get().(bool) {
-				data |= uint8(rand.Int()) & (addresses.DataMasks[ma] ^ 0xff)
+				data |= uint8(mem.rand.Int()) & (addresses.DataMasks[ma] ^ 0xff)
 			} else {
 				data |= uint8(address&0x00ff) & (addresses.DataMasks[ma] ^ 0xff)
 			}
@@ -228,3 +241,113 @@ func (mem VCSMemory) Poke(address uint16, data uint8) error {
 	}
 	return errors.New(errors.UnpokeableAddress, address)
 }
+
+// Snapshot creates a copy of the VCS memory, including the state of the
+// attached cartridge
+func (mem *VCSMemory) Snapshot() *VCSMemory {
+	n := *mem
+	n.RIOT = mem.RIOT.Snapshot()
+	n.TIA = mem.TIA.Snapshot()
+	n.RAM = mem.RAM.Snapshot()
+	n.Cart = mem.Cart.Snapshot()
+
+	// the memory map of the snapshot is meaningless
+	n.Memmap = nil
+
+	return &n
+}
+
+// Restore the state of the VCS memory from a snapshot previously created with
+// Snapshot(). The memory areas are updated in place.
+func (mem *VCSMemory) Restore(s *VCSMemory) error {
+	err := mem.Cart.Restore(s.Cart)
+	if err != nil {
+		return err
+	}
+
+	mem.RIOT.Restore(s.RIOT)
+	mem.TIA.Restore(s.TIA)
+	mem.RAM.Restore(s.RAM)
+
+	mem.LastAccessAddress = s.LastAccessAddress
+	mem.LastAccessAddressMapped = s.LastAccessAddressMapped
+	mem.LastAccessValue = s.LastAccessValue
+	mem.LastAccessWrite = s.LastAccessWrite
+	mem.LastAccessID = s.LastAccessID
+	mem.accessCount = s.accessCount
+
+	return nil
+}
+
+// the version of the SavedMemory type. increase this whenever the SavedMemory
+// type, or any of the types it contains, changes. the cartridge has its own
+// version number
+const savedMemoryVersion = 1
+
+// SavedMemory is the state of the VCS memory as returned by SaveState()
+type SavedMemory struct {
+	Version                 int
+	RIOT                    vcs.SavedChipMemory
+	TIA                     vcs.SavedChipMemory
+	RAM                     vcs.SavedRAM
+	Cart                    cartridge.SavedCartridge
+	LastAccessAddress       uint16
+	LastAccessAddressMapped uint16
+	LastAccessValue         uint8
+	LastAccessWrite         bool
+	LastAccessID            int
+	AccessCount             int
+}
+
+// SaveState returns the current state of the VCS memory, including the state
+// of the attached cartridge
+func (mem *VCSMemory) SaveState() (SavedMemory, error) {
+	cart, err := mem.Cart.SaveState()
+	if err != nil {
+		return SavedMemory{}, err
+	}
+
+	return SavedMemory{
+		Version:                 savedMemoryVersion,
+		RIOT:                    mem.RIOT.SaveState(),
+		TIA:                     mem.TIA.SaveState(),
+		RAM:                     mem.RAM.SaveState(),
+		Cart:                    cart,
+		LastAccessAddress:       mem.LastAccessAddress,
+		LastAccessAddressMapped: mem.LastAccessAddressMapped,
+		LastAccessValue:         mem.LastAccessValue,
+		LastAccessWrite:         mem.LastAccessWrite,
+		LastAccessID:            mem.LastAccessID,
+		AccessCount:             mem.accessCount,
+	}, nil
+}
+
+// LoadState sets the VCS memory to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+func (mem *VCSMemory) LoadState(s SavedMemory) error {
+	if s.Version != savedMemoryVersion {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unsupported memory state version (%d)", s.Version))
+	}
+	if err := mem.RIOT.LoadState(s.RIOT); err != nil {
+		return err
+	}
+	if err := mem.TIA.LoadState(s.TIA); err != nil {
+		return err
+	}
+	if err := mem.RAM.LoadState(s.RAM); err != nil {
+		return err
+	}
+	if err := mem.Cart.LoadState(s.Cart); err != nil {
+		return err
+	}
+
+	mem.LastAccessAddress = s.LastAccessAddress
+	mem.LastAccessAddressMapped = s.LastAccessAddressMapped
+	mem.LastAccessValue = s.LastAccessValue
+	mem.LastAccessWrite = s.LastAccessWrite
+	mem.LastAccessID = s.LastAccessID
+	mem.accessCount = s.AccessCount
+
+	return nil
+}
@@ -127,3 +127,58 @@ func (area *ChipMemory) Write(address uint16, data uint8) error {
 
 	return nil
 }
+
+// Snapshot creates a copy of the ChipMemory in its current state
+func (area *ChipMemory) Snapshot() *ChipMemory {
+	n := *area
+	n.memory = make([]uint8, len(area.memory))
+	copy(n.memory, area.memory)
+	return &n
+}
+
+// Restore the state of the ChipMemory from a snapshot previously created
+// with Snapshot()
+func (area *ChipMemory) Restore(s *ChipMemory) {
+	copy(area.memory, s.memory)
+	area.writeAddress = s.writeAddress
+	area.writeData = s.writeData
+	area.writeSignal = s.writeSignal
+	area.readRegister = s.readRegister
+}
+
+// SavedChipMemory is the state of the ChipMemory as returned by SaveState()
+type SavedChipMemory struct {
+	Memory       []uint8
+	WriteAddress uint16
+	WriteData    uint8
+	WriteSignal  bool
+	ReadRegister string
+}
+
+// SaveState returns the current state of the ChipMemory
+func (area *ChipMemory) SaveState() SavedChipMemory {
+	s := SavedChipMemory{
+		Memory:       make([]uint8, len(area.memory)),
+		WriteAddress: area.writeAddress,
+		WriteData:    area.writeData,
+		WriteSignal:  area.writeSignal,
+		ReadRegister: area.readRegister,
+	}
+	copy(s.Memory, area.memory)
+	return s
+}
+
+// LoadState sets the ChipMemory to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+func (area *ChipMemory) LoadState(s SavedChipMemory) error {
+	if len(s.Memory) != len(area.memory) {
+		return errors.New(errors.SnapshotError, "chip memory state is the wrong size")
+	}
+	copy(area.memory, s.Memory)
+	area.writeAddress = s.WriteAddress
+	area.writeData = s.WriteData
+	area.writeSignal = s.WriteSignal
+	area.readRegister = s.ReadRegister
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jetsetilly/gopher2600/errors"
 	"github.com/jetsetilly/gopher2600/hardware/memory/bus"
 	"github.com/jetsetilly/gopher2600/hardware/memory/memorymap"
 )
@@ -77,3 +78,40 @@ func (ram *RAM) Write(address uint16, data uint8) error {
 	ram.RAM[address^memorymap.OriginRAM] = data
 	return nil
 }
+
+// Snapshot creates a copy of RAM in its current state
+func (ram *RAM) Snapshot() *RAM {
+	n := *ram
+	n.RAM = make([]uint8, len(ram.RAM))
+	copy(n.RAM, ram.RAM)
+	return &n
+}
+
+// Restore the contents of RAM from a snapshot previously created with
+// Snapshot()
+func (ram *RAM) Restore(s *RAM) {
+	copy(ram.RAM, s.RAM)
+}
+
+// SavedRAM is the state of RAM as returned by SaveState()
+type SavedRAM struct {
+	RAM []uint8
+}
+
+// SaveState returns the current contents of RAM
+func (ram *RAM) SaveState() SavedRAM {
+	s := SavedRAM{RAM: make([]uint8, len(ram.RAM))}
+	copy(s.RAM, ram.RAM)
+	return s
+}
+
+// LoadState sets the contents of RAM to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+func (ram *RAM) LoadState(s SavedRAM) error {
+	if len(s.RAM) != len(ram.RAM) {
+		return errors.New(errors.SnapshotError, "RAM state is the wrong size")
+	}
+	copy(ram.RAM, s.RAM)
+	return nil
+}
@@ -53,6 +53,27 @@ const (
 	KeypadDown Event = "KeypadDown" // rune
 	KeypadUp   Event = "KeypadUp"   // nil
 
+	// driving controller. the value is the number of gray code steps to turn
+	// the controller by. positive values turn clockwise, negative values
+	// turn anti-clockwise. fractional steps accumulate until there is a whole
+	// step. the fire button is the joystick Fire event
+	DrivingTurn Event = "DrivingTurn" // float32
+
+	// booster grip. the stick and fire button are the joystick events
+	BoosterGripTrigger Event = "BoosterGripTrigger" // bool
+	BoosterGripBooster Event = "BoosterGripBooster" // bool
+
+	// trackball. the value is the amount of movement in that axis. positive
+	// values are to the right and downwards. as with the driving controller,
+	// fractional movement accumulates. the fire button is the joystick Fire
+	// event
+	TrackballHorizontal Event = "TrackballHorizontal" // float32
+	TrackballVertical   Event = "TrackballVertical"   // float32
+
+	// mindlink. the value is the position of the mindlink between 0.0 and
+	// 1.0. the joystick Fire event is used to start the game
+	MindlinkSet Event = "MindlinkSet" // float32
+
 	PanelPowerOff Event = "PanelPowerOff" // nil
 )
 
@@ -31,6 +31,11 @@ import (
 // if a paddle/keypad ROM requires paddle/keypad probing from the instant
 // the machine starts (are there any examples of this?) then we will need to
 // initialise the hand controller accordingly, using the setup system.
+//
+// the driving, booster grip, trackball and mindlink controllers are never
+// smart-selected. they must be chosen explicitly with SwitchType(). the same
+// is true of the SaveKey and AtariVox, which aren't really controllers but
+// are attached to the controller port.
 type ControllerType int
 
 // List of allowed ControllerTypes
@@ -38,10 +43,16 @@ const (
 	JoystickType ControllerType = iota
 	PaddleType
 	KeypadType
+	DrivingType
+	BoosterGripType
+	TrackballType
+	MindlinkType
+	SaveKeyType
+	AtariVoxType
 )
 
 // ControllerTypeList is a list of all possible string representations of the Interval type
-var ControllerTypeList = []string{"Joystick", "Paddle", "Keypad"}
+var ControllerTypeList = []string{"Joystick", "Paddle", "Keypad", "Driving", "BoosterGrip", "Trackball", "Mindlink", "SaveKey", "AtariVox"}
 
 func (c ControllerType) String() string {
 	switch c {
@@ -51,6 +62,18 @@ func (c ControllerType) String() string {
 		return "Paddle"
 	case KeypadType:
 		return "Keypad"
+	case DrivingType:
+		return "Driving"
+	case BoosterGripType:
+		return "BoosterGrip"
+	case TrackballType:
+		return "Trackball"
+	case MindlinkType:
+		return "Mindlink"
+	case SaveKeyType:
+		return "SaveKey"
+	case AtariVoxType:
+		return "AtariVox"
 	}
 	panic("unknown controller type")
 }
@@ -72,9 +95,17 @@ type HandController struct {
 	AutoControllerType bool
 
 	// controller types
-	stick  stick
-	paddle paddle
-	keypad keypad
+	stick     stick
+	paddle    paddle
+	keypad    keypad
+	driving   driving
+	booster   booster
+	trackball trackball
+	mindlink  mindlink
+	savekey   savekey
+
+	// the most recent value written to SWCHA by the CPU. not normalised
+	swcha uint8
 
 	// data direction register. for simplicity, the bits should be normalised
 	// such that only the upper nibble is used. in reality, player 0
@@ -168,6 +199,101 @@ type keypad struct {
 // the value of keypad.key when nothing is being pressed
 const noKey = ' '
 
+// the sequence of values produced by the driving controller as it is turned
+// clockwise. the values are written to the lower two bits of the (normalised)
+// SWCHA nibble. the upper two bits are unused and are always set.
+var drivingGrayCode = [4]uint8{0x03, 0x01, 0x00, 0x02}
+
+// the driving type implements the "driving" or "indy" controller. the fire
+// button is the same as the joystick fire button
+type driving struct {
+	// position in the drivingGrayCode sequence
+	count int
+
+	// fractional part of turn events that have yet to move the controller
+	// to the next position in the sequence
+	turn float32
+}
+
+// value of the booster grip buttons when pressed and when released. these
+// are written to the dumped input ports, unlike the fire button which is
+// written to the same latched input port as the joystick.
+const boosterButtonOn = uint8(0x80)
+const boosterButtonOff = uint8(0x00)
+
+// the booster type implements the booster grip. the booster grip is a
+// joystick with two additional buttons that are read through the paddle
+// inputs
+type booster struct {
+	triggerReg addresses.ChipRegister
+	boosterReg addresses.ChipRegister
+
+	trigger uint8
+	booster uint8
+}
+
+// the number of video cycles between each movement being presented to the
+// VCS. movement events from the user can be large but the program will only
+// see the changes it is quick enough to sample. one movement every scanline
+// seems to be slow enough for all trackball games.
+const trackballRate = 228
+
+// the trackball type implements the CX-22 and CX-80 trackballs when they are
+// in trackball mode. (in joystick mode they are no different to a joystick.)
+//
+// movement in each axis is indicated by toggling one bit (the count bit) and
+// the direction of movement by another. movement is written to SWCHA:
+//
+//	bit 7: horizontal count
+//	bit 6: horizontal direction (set for right)
+//	bit 5: vertical count
+//	bit 4: vertical direction (set for down)
+//
+// the fire button is the same as the joystick fire button
+type trackball struct {
+	// movement that has yet to be presented to the VCS
+	pendingH int
+	pendingV int
+
+	// fractional movement that is not yet large enough to be added to the
+	// pending movement
+	fracH float32
+	fracV float32
+
+	// direction of most recent movement
+	right bool
+	down  bool
+
+	// count bits. toggled for every unit of movement
+	countH bool
+	countV bool
+
+	// number of cycles until next movement
+	ticks int
+}
+
+// the mindlink is calibrated to produce values within this range
+const mindlinkMin = 0x2800
+const mindlinkRange = 0x1000
+
+// setting this bit in the mindlink position starts the game
+const mindlinkStart = 0x4000
+
+// the number of bits sent by the mindlink before the sequence begins again
+const mindlinkBits = 16
+
+// the mindlink type implements the Atari Mindlink. the position of the
+// mindlink is sent one bit at a time. the VCS program clocks the next bit by
+// writing to the first bit of the SWCHA nibble and then reads the data bit
+// from the fourth bit of the SWCHA nibble.
+type mindlink struct {
+	position uint16
+	start    bool
+
+	// the bit of the position currently being sent
+	shift int
+}
+
 // NewHandController0 is the preferred method of creating a new instance of
 // HandController for representing hand controller zero
 func NewHandController0(mem *inputMemory, control *VBlankBits) *HandController {
@@ -191,6 +317,15 @@ func NewHandController0(mem *inputMemory, control *VBlankBits) *HandController {
 			column: [3]addresses.ChipRegister{addresses.INPT0, addresses.INPT1, addresses.INPT4},
 			key:    noKey,
 		},
+		booster: booster{
+			triggerReg: addresses.INPT0,
+			boosterReg: addresses.INPT1,
+			trigger:    boosterButtonOff,
+			booster:    boosterButtonOff,
+		},
+		mindlink: mindlink{
+			position: mindlinkMin,
+		},
 		normaliseOnRead:  func(n uint8) uint8 { return n & 0xf0 },
 		normaliseOnWrite: func(n uint8) uint8 { return n },
 		writeMask:        0x0f,
@@ -232,6 +367,15 @@ func NewHandController1(mem *inputMemory, control *VBlankBits) *HandController {
 			column: [3]addresses.ChipRegister{addresses.INPT2, addresses.INPT3, addresses.INPT5},
 			key:    noKey,
 		},
+		booster: booster{
+			triggerReg: addresses.INPT2,
+			boosterReg: addresses.INPT3,
+			trigger:    boosterButtonOff,
+			booster:    boosterButtonOff,
+		},
+		mindlink: mindlink{
+			position: mindlinkMin,
+		},
 		normaliseOnRead:  func(n uint8) uint8 { return (n & 0x0f) << 4 },
 		normaliseOnWrite: func(n uint8) uint8 { return n >> 4 },
 		writeMask:        0xf0,
@@ -255,6 +399,162 @@ func (hc *HandController) String() string {
 	return "nothing yet"
 }
 
+// Snapshot creates a copy of the HandController in its current state
+func (hc *HandController) Snapshot() *HandController {
+	n := *hc
+	return &n
+}
+
+// Restore the HandController from a snapshot. Attached playback and recording
+// devices are not affected
+func (hc *HandController) Restore(s *HandController) {
+	p := hc.port
+	*hc = *s
+	hc.port = p
+}
+
+// SavedHandController is the state of a HandController as returned by
+// SaveState()
+type SavedHandController struct {
+	ControllerType     ControllerType
+	AutoControllerType bool
+	SWCHA              uint8
+	DDR                uint8
+
+	StickAxis   uint8
+	StickButton uint8
+
+	PaddleCharge        uint8
+	PaddleResistance    float32
+	PaddleSensitivity   float32
+	PaddleTicks         float32
+	PaddleTouchLeft     int
+	PaddleTouchRight    int
+	PaddleTouchingLeft  bool
+	PaddleTouchingRight bool
+
+	KeypadKey rune
+
+	DrivingCount int
+	DrivingTurn  float32
+
+	BoosterTrigger uint8
+	BoosterBooster uint8
+
+	TrackballPendingH int
+	TrackballPendingV int
+	TrackballFracH    float32
+	TrackballFracV    float32
+	TrackballRight    bool
+	TrackballDown     bool
+	TrackballCountH   bool
+	TrackballCountV   bool
+	TrackballTicks    int
+
+	MindlinkPosition uint16
+	MindlinkStart    bool
+	MindlinkShift    int
+
+	SaveKey SavedSaveKey
+}
+
+// SaveState returns the current state of the HandController
+func (hc *HandController) SaveState() SavedHandController {
+	return SavedHandController{
+		ControllerType:      hc.ControllerType,
+		AutoControllerType:  hc.AutoControllerType,
+		SWCHA:               hc.swcha,
+		DDR:                 hc.ddr,
+		StickAxis:           hc.stick.axis,
+		StickButton:         hc.stick.button,
+		PaddleCharge:        hc.paddle.charge,
+		PaddleResistance:    hc.paddle.resistance,
+		PaddleSensitivity:   hc.paddle.sensitivity,
+		PaddleTicks:         hc.paddle.ticks,
+		PaddleTouchLeft:     hc.paddle.touchLeft,
+		PaddleTouchRight:    hc.paddle.touchRight,
+		PaddleTouchingLeft:  hc.paddle.touchingLeft,
+		PaddleTouchingRight: hc.paddle.touchingRight,
+		KeypadKey:           hc.keypad.key,
+		DrivingCount:        hc.driving.count,
+		DrivingTurn:         hc.driving.turn,
+		BoosterTrigger:      hc.booster.trigger,
+		BoosterBooster:      hc.booster.booster,
+		TrackballPendingH:   hc.trackball.pendingH,
+		TrackballPendingV:   hc.trackball.pendingV,
+		TrackballFracH:      hc.trackball.fracH,
+		TrackballFracV:      hc.trackball.fracV,
+		TrackballRight:      hc.trackball.right,
+		TrackballDown:       hc.trackball.down,
+		TrackballCountH:     hc.trackball.countH,
+		TrackballCountV:     hc.trackball.countV,
+		TrackballTicks:      hc.trackball.ticks,
+		MindlinkPosition:    hc.mindlink.position,
+		MindlinkStart:       hc.mindlink.start,
+		MindlinkShift:       hc.mindlink.shift,
+		SaveKey:             hc.savekey.saveState(),
+	}
+}
+
+// LoadState sets the HandController to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+//
+// If the saved controller is a SaveKey or AtariVox then the EEPROM data is
+// loaded from disk, if it has not been loaded already.
+func (hc *HandController) LoadState(s SavedHandController) error {
+	switch s.ControllerType {
+	case JoystickType, PaddleType, KeypadType, DrivingType, BoosterGripType, TrackballType, MindlinkType:
+	case SaveKeyType, AtariVoxType:
+		filename := saveKeyFile
+		if s.ControllerType == AtariVoxType {
+			filename = atariVoxFile
+		}
+		if hc.savekey.data == nil || hc.savekey.filename != filename {
+			if err := hc.savekey.load(filename); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New(errors.UnknownControllerType, s.ControllerType)
+	}
+
+	hc.ControllerType = s.ControllerType
+	hc.AutoControllerType = s.AutoControllerType
+	hc.swcha = s.SWCHA
+	hc.ddr = s.DDR
+	hc.stick.axis = s.StickAxis
+	hc.stick.button = s.StickButton
+	hc.paddle.charge = s.PaddleCharge
+	hc.paddle.resistance = s.PaddleResistance
+	hc.paddle.sensitivity = s.PaddleSensitivity
+	hc.paddle.ticks = s.PaddleTicks
+	hc.paddle.touchLeft = s.PaddleTouchLeft
+	hc.paddle.touchRight = s.PaddleTouchRight
+	hc.paddle.touchingLeft = s.PaddleTouchingLeft
+	hc.paddle.touchingRight = s.PaddleTouchingRight
+	hc.keypad.key = s.KeypadKey
+	hc.driving.count = s.DrivingCount
+	hc.driving.turn = s.DrivingTurn
+	hc.booster.trigger = s.BoosterTrigger
+	hc.booster.booster = s.BoosterBooster
+	hc.trackball.pendingH = s.TrackballPendingH
+	hc.trackball.pendingV = s.TrackballPendingV
+	hc.trackball.fracH = s.TrackballFracH
+	hc.trackball.fracV = s.TrackballFracV
+	hc.trackball.right = s.TrackballRight
+	hc.trackball.down = s.TrackballDown
+	hc.trackball.countH = s.TrackballCountH
+	hc.trackball.countV = s.TrackballCountV
+	hc.trackball.ticks = s.TrackballTicks
+	hc.mindlink.position = s.MindlinkPosition
+	hc.mindlink.start = s.MindlinkStart
+	hc.mindlink.shift = s.MindlinkShift
+	hc.savekey.loadState(s.SaveKey)
+
+	return nil
+}
+
 // Reset DDR of hand controller port
 func (hc *HandController) Reset() {
 	hc.setDDR(0x00)
@@ -289,6 +589,40 @@ func (hc *HandController) SwitchType(newType ControllerType) error {
 		hc.writeSWCHA(paddleFire, hc.writeMask)
 	case KeypadType:
 		hc.ControllerType = KeypadType
+	case DrivingType:
+		hc.ControllerType = DrivingType
+		hc.writeSWCHA(hc.drivingAxis(), hc.writeMask)
+		hc.mem.tia.InputDeviceWrite(hc.stick.buttonReg, hc.stick.button, 0x00)
+	case BoosterGripType:
+		hc.ControllerType = BoosterGripType
+		hc.writeSWCHA(hc.stick.axis, hc.writeMask)
+		hc.mem.tia.InputDeviceWrite(hc.stick.buttonReg, hc.stick.button, 0x00)
+		hc.mem.tia.InputDeviceWrite(hc.booster.triggerReg, hc.booster.trigger, 0x00)
+		hc.mem.tia.InputDeviceWrite(hc.booster.boosterReg, hc.booster.booster, 0x00)
+	case TrackballType:
+		hc.ControllerType = TrackballType
+		hc.writeSWCHA(hc.trackballAxis(), hc.writeMask)
+		hc.mem.tia.InputDeviceWrite(hc.stick.buttonReg, hc.stick.button, 0x00)
+	case MindlinkType:
+		hc.ControllerType = MindlinkType
+		hc.mindlink.shift = 0
+		hc.writeSWCHA(0xf0, hc.writeMask)
+	case SaveKeyType, AtariVoxType:
+		filename := saveKeyFile
+		if newType == AtariVoxType {
+			filename = atariVoxFile
+		}
+
+		// load EEPROM data if it's not already been loaded
+		if hc.savekey.data == nil || hc.savekey.filename != filename {
+			if err := hc.savekey.load(filename); err != nil {
+				return err
+			}
+		}
+
+		hc.ControllerType = newType
+		hc.savekey.speech.enabled = newType == AtariVoxType
+		hc.writeSWCHA(0xf0, hc.writeMask)
 
 	default:
 		return errors.New(errors.UnknownControllerType, newType)
@@ -297,6 +631,22 @@ func (hc *HandController) SwitchType(newType ControllerType) error {
 	return nil
 }
 
+// usesStickAxis returns true if the current controller type uses the
+// joystick directions
+func (hc *HandController) usesStickAxis() bool {
+	return hc.ControllerType == JoystickType || hc.ControllerType == BoosterGripType
+}
+
+// usesStickButton returns true if the current controller type has a fire
+// button that behaves like the joystick fire button
+func (hc *HandController) usesStickButton() bool {
+	switch hc.ControllerType {
+	case JoystickType, BoosterGripType, DrivingType, TrackballType:
+		return true
+	}
+	return false
+}
+
 // Handle implements Port interface
 func (hc *HandController) Handle(event Event, value EventData) error {
 	switch event {
@@ -312,7 +662,7 @@ func (hc *HandController) Handle(event Event, value EventData) error {
 		}
 
 		// smart switch to joystick type
-		if hc.ControllerType != JoystickType {
+		if !hc.usesStickAxis() {
 			if hc.AutoControllerType {
 				if err := hc.SwitchType(JoystickType); err != nil {
 					return err
@@ -336,7 +686,7 @@ func (hc *HandController) Handle(event Event, value EventData) error {
 		}
 
 		// smart switch to joystick type
-		if hc.ControllerType != JoystickType {
+		if !hc.usesStickAxis() {
 			if hc.AutoControllerType {
 				if err := hc.SwitchType(JoystickType); err != nil {
 					return err
@@ -360,7 +710,7 @@ func (hc *HandController) Handle(event Event, value EventData) error {
 		}
 
 		// smart switch to joystick type
-		if hc.ControllerType != JoystickType {
+		if !hc.usesStickAxis() {
 			if hc.AutoControllerType {
 				if err := hc.SwitchType(JoystickType); err != nil {
 					return err
@@ -384,7 +734,7 @@ func (hc *HandController) Handle(event Event, value EventData) error {
 		}
 
 		// smart switch to joystick type
-		if hc.ControllerType != JoystickType {
+		if !hc.usesStickAxis() {
 			if hc.AutoControllerType {
 				if err := hc.SwitchType(JoystickType); err != nil {
 					return err
@@ -407,8 +757,15 @@ func (hc *HandController) Handle(event Event, value EventData) error {
 			return errors.New(errors.BadInputEventType, event, "bool")
 		}
 
+		// the mindlink has no fire button as such but the event is used to
+		// start the game
+		if hc.ControllerType == MindlinkType {
+			hc.mindlink.start = b
+			break
+		}
+
 		// smart switch to joystick type
-		if hc.ControllerType != JoystickType {
+		if !hc.usesStickButton() {
 			if hc.AutoControllerType {
 				if err := hc.SwitchType(JoystickType); err != nil {
 					return err
@@ -525,6 +882,116 @@ func (hc *HandController) Handle(event Event, value EventData) error {
 
 		hc.keypad.key = noKey
 
+	case DrivingTurn:
+		f, ok := value.(float32)
+		if !ok {
+			return errors.New(errors.BadInputEventType, event, "float32")
+		}
+
+		// no smart switch for driving controller
+		if hc.ControllerType != DrivingType {
+			return nil
+		}
+
+		// accumulate fractional turns until there is at least one whole step
+		hc.driving.turn += f
+		steps := int(hc.driving.turn)
+		hc.driving.turn -= float32(steps)
+
+		hc.driving.count = (hc.driving.count + steps) % len(drivingGrayCode)
+		if hc.driving.count < 0 {
+			hc.driving.count += len(drivingGrayCode)
+		}
+		hc.writeSWCHA(hc.drivingAxis(), hc.writeMask)
+
+	case BoosterGripTrigger:
+		b, ok := value.(bool)
+		if !ok {
+			return errors.New(errors.BadInputEventType, event, "bool")
+		}
+
+		// no smart switch for booster grip
+		if hc.ControllerType != BoosterGripType {
+			return nil
+		}
+
+		if b {
+			hc.booster.trigger = boosterButtonOn
+		} else {
+			hc.booster.trigger = boosterButtonOff
+		}
+		hc.mem.tia.InputDeviceWrite(hc.booster.triggerReg, hc.booster.trigger, 0x00)
+
+	case BoosterGripBooster:
+		b, ok := value.(bool)
+		if !ok {
+			return errors.New(errors.BadInputEventType, event, "bool")
+		}
+
+		// no smart switch for booster grip
+		if hc.ControllerType != BoosterGripType {
+			return nil
+		}
+
+		if b {
+			hc.booster.booster = boosterButtonOn
+		} else {
+			hc.booster.booster = boosterButtonOff
+		}
+		hc.mem.tia.InputDeviceWrite(hc.booster.boosterReg, hc.booster.booster, 0x00)
+
+	case TrackballHorizontal:
+		f, ok := value.(float32)
+		if !ok {
+			return errors.New(errors.BadInputEventType, event, "float32")
+		}
+
+		// no smart switch for trackball
+		if hc.ControllerType != TrackballType {
+			return nil
+		}
+
+		// movement is presented to the VCS by rollTrackball()
+		hc.trackball.fracH += f
+		n := int(hc.trackball.fracH)
+		hc.trackball.fracH -= float32(n)
+		hc.trackball.pendingH += n
+
+	case TrackballVertical:
+		f, ok := value.(float32)
+		if !ok {
+			return errors.New(errors.BadInputEventType, event, "float32")
+		}
+
+		// no smart switch for trackball
+		if hc.ControllerType != TrackballType {
+			return nil
+		}
+
+		// movement is presented to the VCS by rollTrackball()
+		hc.trackball.fracV += f
+		n := int(hc.trackball.fracV)
+		hc.trackball.fracV -= float32(n)
+		hc.trackball.pendingV += n
+
+	case MindlinkSet:
+		f, ok := value.(float32)
+		if !ok {
+			return errors.New(errors.BadInputEventType, event, "float32")
+		}
+
+		// no smart switch for mindlink
+		if hc.ControllerType != MindlinkType {
+			return nil
+		}
+
+		if f < 0.0 {
+			f = 0.0
+		} else if f > 1.0 {
+			f = 1.0
+		}
+		hc.mindlink.position = mindlinkMin + uint16(f*mindlinkRange)
+
 	case Unplug:
 		return errors.New(errors.InputDeviceUnplugged, hc.id)
 
@@ -546,6 +1013,22 @@ func (hc *HandController) Handle(event Event, value EventData) error {
 func (hc *HandController) setDDR(data uint8) {
 	hc.ddr = hc.normaliseOnRead(data)
 
+	// the additional controller types are never smart-selected so we
+	// shouldn't smart-switch away from them either
+	switch hc.ControllerType {
+	case DrivingType, BoosterGripType, TrackballType:
+		return
+	case MindlinkType:
+		// the program is (re)starting the mindlink sequence
+		hc.mindlink.shift = 0
+		return
+	case SaveKeyType, AtariVoxType:
+		// changing the direction of the I2C pins can change the state of the
+		// I2C lines
+		hc.clockSaveKey(hc.swcha)
+		return
+	}
+
 	// if the ddr value is being such so that SWCHA is input rather than output
 	// the the expected controller is most probably a keypad. not sure what
 	// we can say if ddr is only partially set to input.
@@ -660,7 +1143,7 @@ func (hc *HandController) readKeypad(data uint8) {
 // VBLANK bit 6 has been set. joystick button will latch, meaning that
 // releasing the fire button has no immediate effect
 func (hc *HandController) unlatch() {
-	if hc.ControllerType != JoystickType {
+	if !hc.usesStickButton() {
 		return
 	}
 
@@ -687,7 +1170,19 @@ func (hc *HandController) ground() {
 	hc.mem.riot.InputDeviceWrite(hc.paddle.puckReg, hc.paddle.charge, 0x00)
 }
 
-// recharge() is called every video step via Input.Step()
+// step() is called every video step via Input.Step()
+func (hc *HandController) step() {
+	switch hc.ControllerType {
+	case PaddleType:
+		hc.recharge()
+	case TrackballType:
+		hc.rollTrackball()
+	case AtariVoxType:
+		hc.savekey.speech.step()
+	}
+}
+
+// recharge() is called every video step via step()
 func (hc *HandController) recharge() {
 	// as in the case of ground() I'm not sure if restricting recharge() events
 	// to the paddle type is strictly necessary.
@@ -724,3 +1219,135 @@ func (hc *HandController) writeSWCHA(data uint8, mask uint8) {
 	data = hc.normaliseOnWrite(data & (hc.ddr ^ 0xff))
 	hc.mem.riot.InputDeviceWrite(addresses.SWCHA, data, mask)
 }
+
+// the value to write to SWCHA for the current driving controller position
+func (hc *HandController) drivingAxis() uint8 {
+	return 0xc0 | (drivingGrayCode[hc.driving.count] << 4)
+}
+
+// the value to write to SWCHA for the current trackball state
+func (hc *HandController) trackballAxis() uint8 {
+	var v uint8
+	if hc.trackball.countH {
+		v |= 0x80
+	}
+	if hc.trackball.right {
+		v |= 0x40
+	}
+	if hc.trackball.countV {
+		v |= 0x20
+	}
+	if hc.trackball.down {
+		v |= 0x10
+	}
+	return v
+}
+
+// rollTrackball() is called every video step via step(). one unit of pending
+// movement in each axis is presented to the VCS every trackballRate cycles.
+func (hc *HandController) rollTrackball() {
+	if hc.trackball.ticks > 0 {
+		hc.trackball.ticks--
+		return
+	}
+
+	if hc.trackball.pendingH == 0 && hc.trackball.pendingV == 0 {
+		return
+	}
+
+	hc.trackball.ticks = trackballRate
+
+	if hc.trackball.pendingH > 0 {
+		hc.trackball.right = true
+		hc.trackball.countH = !hc.trackball.countH
+		hc.trackball.pendingH--
+	} else if hc.trackball.pendingH < 0 {
+		hc.trackball.right = false
+		hc.trackball.countH = !hc.trackball.countH
+		hc.trackball.pendingH++
+	}
+
+	if hc.trackball.pendingV > 0 {
+		hc.trackball.down = true
+		hc.trackball.countV = !hc.trackball.countV
+		hc.trackball.pendingV--
+	} else if hc.trackball.pendingV < 0 {
+		hc.trackball.down = false
+		hc.trackball.countV = !hc.trackball.countV
+		hc.trackball.pendingV++
+	}
+
+	hc.writeSWCHA(hc.trackballAxis(), hc.writeMask)
+}
+
+// respondSWCHA() is called whenever SWCHA is written to by the CPU, after the
+// value has been written to memory. peripherals that communicate with the VCS
+// through SWCHA respond here.
+func (hc *HandController) respondSWCHA(data uint8) {
+	hc.swcha = data
+
+	switch hc.ControllerType {
+	case MindlinkType:
+		hc.clockMindlink(data)
+	case AtariVoxType:
+		if hc.ddr&speakjetData == speakjetData {
+			hc.savekey.speech.write(hc.normaliseOnRead(data)&speakjetData == speakjetData)
+		}
+		hc.clockSaveKey(data)
+	case SaveKeyType:
+		hc.clockSaveKey(data)
+	}
+}
+
+// clockMindlink() is called by respondSWCHA(). if the
+// first bit of the (normalised) nibble is set then the next bit of the
+// mindlink position is sent to the fourth bit of the nibble.
+func (hc *HandController) clockMindlink(data uint8) {
+	if hc.ControllerType != MindlinkType {
+		return
+	}
+
+	data = hc.normaliseOnRead(data)
+	if data&hc.ddr&0x10 != 0x10 {
+		return
+	}
+
+	position := hc.mindlink.position
+	if hc.mindlink.start {
+		position |= mindlinkStart
+	}
+
+	v := uint8(0x30)
+	if position&(1<<uint(hc.mindlink.shift)) != 0 {
+		v |= 0x80
+	}
+
+	hc.mindlink.shift++
+	if hc.mindlink.shift >= mindlinkBits {
+		hc.mindlink.shift = 0
+	}
+
+	// the data bits are input bits so we can't use writeSWCHA(), which
+	// filters by the DDR
+	hc.mem.riot.InputDeviceWrite(addresses.SWCHA, hc.normaliseOnWrite(v), hc.writeMask)
+}
+
+// clockSaveKey() is called by respondSWCHA() and setDDR(). pins that are not
+// set as outputs by the DDR are pulled high. the response of the EEPROM on the
+// SDA line is written back to SWCHA if the SDA pin is an input.
+func (hc *HandController) clockSaveKey(data uint8) {
+	if hc.ControllerType != SaveKeyType && hc.ControllerType != AtariVoxType {
+		return
+	}
+
+	lines := hc.normaliseOnRead(data) | (hc.ddr ^ 0xf0)
+	hc.savekey.update(lines&i2cSCL == i2cSCL, lines&i2cSDA == i2cSDA)
+
+	if hc.ddr&i2cSDA == 0x00 {
+		v := i2cSDA
+		if !hc.savekey.sdaOut {
+			v = 0x00
+		}
+		hc.mem.riot.InputDeviceWrite(addresses.SWCHA, hc.normaliseOnWrite(v), ^hc.normaliseOnWrite(i2cSDA))
+	}
+}
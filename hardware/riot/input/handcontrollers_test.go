@@ -0,0 +1,200 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package input
+
+import (
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/hardware/memory/addresses"
+)
+
+// mockBus implements the bus.InputDeviceBus interface in the same way as the
+// VCS chip memory
+type mockBus struct {
+	memory map[addresses.ChipRegister]uint8
+}
+
+func (b *mockBus) InputDeviceWrite(reg addresses.ChipRegister, data uint8, preserveBits uint8) {
+	b.memory[reg] = data | (b.memory[reg] & preserveBits)
+}
+
+func newTestHandControllers() (*HandController, *HandController, *mockBus, *mockBus) {
+	mem := &inputMemory{
+		riot: &mockBus{memory: make(map[addresses.ChipRegister]uint8)},
+		tia:  &mockBus{memory: make(map[addresses.ChipRegister]uint8)},
+	}
+	control := &VBlankBits{}
+	hc0 := NewHandController0(mem, control)
+	hc1 := NewHandController1(mem, control)
+	return hc0, hc1, mem.riot.(*mockBus), mem.tia.(*mockBus)
+}
+
+func TestDrivingGrayCode(t *testing.T) {
+	hc0, hc1, riot, _ := newTestHandControllers()
+
+	err := hc0.SwitchType(DrivingType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hc1.SwitchType(DrivingType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the sequence of SWCHA values as the left controller is turned
+	// clockwise. the upper two bits of the nibble are always set
+	clockwise := []uint8{0xf0, 0xd0, 0xc0, 0xe0, 0xf0, 0xd0}
+
+	if v := riot.memory[addresses.SWCHA] & 0xf0; v != clockwise[0] {
+		t.Fatalf("unexpected initial value: %02x", v)
+	}
+
+	for i := 1; i < len(clockwise); i++ {
+		if err := hc0.Handle(DrivingTurn, float32(1.0)); err != nil {
+			t.Fatal(err)
+		}
+		if v := riot.memory[addresses.SWCHA] & 0xf0; v != clockwise[i] {
+			t.Errorf("clockwise step %d: expected %02x got %02x", i, clockwise[i], v)
+		}
+	}
+
+	// and back again
+	for i := len(clockwise) - 2; i >= 0; i-- {
+		if err := hc0.Handle(DrivingTurn, float32(-1.0)); err != nil {
+			t.Fatal(err)
+		}
+		if v := riot.memory[addresses.SWCHA] & 0xf0; v != clockwise[i] {
+			t.Errorf("anti-clockwise step %d: expected %02x got %02x", i, clockwise[i], v)
+		}
+	}
+
+	// fractional turns accumulate
+	if err := hc0.Handle(DrivingTurn, float32(0.5)); err != nil {
+		t.Fatal(err)
+	}
+	if v := riot.memory[addresses.SWCHA] & 0xf0; v != clockwise[0] {
+		t.Errorf("half step should not move the controller: got %02x", v)
+	}
+	if err := hc0.Handle(DrivingTurn, float32(0.5)); err != nil {
+		t.Fatal(err)
+	}
+	if v := riot.memory[addresses.SWCHA] & 0xf0; v != clockwise[1] {
+		t.Errorf("two half steps should move the controller: expected %02x got %02x", clockwise[1], v)
+	}
+
+	// turning more than a full sequence at once
+	if err := hc0.Handle(DrivingTurn, float32(-6.0)); err != nil {
+		t.Fatal(err)
+	}
+	if v := riot.memory[addresses.SWCHA] & 0xf0; v != clockwise[3] {
+		t.Errorf("multiple steps: expected %02x got %02x", clockwise[3], v)
+	}
+
+	// the right controller uses the lower nibble and leaves the upper
+	// nibble alone
+	if err := hc1.Handle(DrivingTurn, float32(2.0)); err != nil {
+		t.Fatal(err)
+	}
+	if v := riot.memory[addresses.SWCHA]; v != clockwise[3]|clockwise[2]>>4 {
+		t.Errorf("right controller: expected %02x got %02x", clockwise[3]|clockwise[2]>>4, v)
+	}
+}
+
+func TestBoosterGrip(t *testing.T) {
+	hc0, hc1, riot, tia := newTestHandControllers()
+
+	err := hc0.SwitchType(BoosterGripType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hc1.SwitchType(BoosterGripType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(reg addresses.ChipRegister) uint8 {
+		return tia.memory[reg] & 0x80
+	}
+
+	if read(addresses.INPT0) != boosterButtonOff || read(addresses.INPT1) != boosterButtonOff {
+		t.Fatalf("booster grip buttons should be released initially")
+	}
+
+	// trigger is read through INPT0
+	if err := hc0.Handle(BoosterGripTrigger, true); err != nil {
+		t.Fatal(err)
+	}
+	if read(addresses.INPT0) != boosterButtonOn {
+		t.Errorf("trigger should be pressed on INPT0")
+	}
+	if read(addresses.INPT1) != boosterButtonOff {
+		t.Errorf("trigger should not affect INPT1")
+	}
+
+	// booster is read through INPT1
+	if err := hc0.Handle(BoosterGripBooster, true); err != nil {
+		t.Fatal(err)
+	}
+	if read(addresses.INPT1) != boosterButtonOn {
+		t.Errorf("booster should be pressed on INPT1")
+	}
+
+	if err := hc0.Handle(BoosterGripTrigger, false); err != nil {
+		t.Fatal(err)
+	}
+	if read(addresses.INPT0) != boosterButtonOff {
+		t.Errorf("trigger should be released on INPT0")
+	}
+	if read(addresses.INPT1) != boosterButtonOn {
+		t.Errorf("releasing trigger should not affect INPT1")
+	}
+
+	// the right booster grip uses INPT2 and INPT3
+	if err := hc1.Handle(BoosterGripTrigger, true); err != nil {
+		t.Fatal(err)
+	}
+	if read(addresses.INPT2) != boosterButtonOn || read(addresses.INPT0) != boosterButtonOff {
+		t.Errorf("right trigger should be pressed on INPT2 only")
+	}
+
+	// the stick and fire button work as normal
+	if err := hc0.Handle(Left, true); err != nil {
+		t.Fatal(err)
+	}
+	if hc0.ControllerType != BoosterGripType {
+		t.Errorf("stick event should not switch controller type")
+	}
+	if v := riot.memory[addresses.SWCHA] & 0xf0; v != 0xb0 {
+		t.Errorf("unexpected SWCHA value for left: %02x", v)
+	}
+	if err := hc0.Handle(Fire, true); err != nil {
+		t.Fatal(err)
+	}
+	if read(addresses.INPT4) != stickButtonOn {
+		t.Errorf("fire button should be pressed on INPT4")
+	}
+
+	// booster grip events are ignored by other controller types
+	if err := hc0.SwitchType(JoystickType); err != nil {
+		t.Fatal(err)
+	}
+	if err := hc0.Handle(BoosterGripBooster, false); err != nil {
+		t.Fatal(err)
+	}
+	if read(addresses.INPT1) != boosterButtonOn {
+		t.Errorf("booster event should be ignored by joystick")
+	}
+}
@@ -105,6 +105,60 @@ func NewInput(riotMem bus.ChipBus, tiaMem bus.ChipBus) (*Input, error) {
 	return inp, nil
 }
 
+// Snapshot creates a copy of the RIOT Input in its current state
+func (inp *Input) Snapshot() *Input {
+	n := *inp
+	n.Panel = inp.Panel.Snapshot()
+	n.HandController0 = inp.HandController0.Snapshot()
+	n.HandController1 = inp.HandController1.Snapshot()
+	return &n
+}
+
+// Restore the RIOT Input from a snapshot
+func (inp *Input) Restore(s *Input) {
+	inp.VBlankBits.groundPaddles = s.VBlankBits.groundPaddles
+	inp.VBlankBits.latchFireButton = s.VBlankBits.latchFireButton
+	inp.Panel.Restore(s.Panel)
+	inp.HandController0.Restore(s.HandController0)
+	inp.HandController1.Restore(s.HandController1)
+}
+
+// SavedInput is the state of the RIOT Input as returned by SaveState()
+type SavedInput struct {
+	GroundPaddles   bool
+	LatchFireButton bool
+	Panel           SavedPanel
+	HandController0 SavedHandController
+	HandController1 SavedHandController
+}
+
+// SaveState returns the current state of the RIOT Input
+func (inp *Input) SaveState() SavedInput {
+	return SavedInput{
+		GroundPaddles:   inp.VBlankBits.groundPaddles,
+		LatchFireButton: inp.VBlankBits.latchFireButton,
+		Panel:           inp.Panel.SaveState(),
+		HandController0: inp.HandController0.SaveState(),
+		HandController1: inp.HandController1.SaveState(),
+	}
+}
+
+// LoadState sets the RIOT Input to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+func (inp *Input) LoadState(s SavedInput) error {
+	if err := inp.HandController0.LoadState(s.HandController0); err != nil {
+		return err
+	}
+	if err := inp.HandController1.LoadState(s.HandController1); err != nil {
+		return err
+	}
+	inp.Panel.LoadState(s.Panel)
+	inp.VBlankBits.groundPaddles = s.GroundPaddles
+	inp.VBlankBits.latchFireButton = s.LatchFireButton
+	return nil
+}
+
 // Update checks to see if ChipData applies to the Input type and updates the
 // internal controller/panel states accordingly.
 //
@@ -118,6 +172,10 @@ func (inp *Input) Update(data bus.ChipData) bool {
 		// write data back to memory
 		inp.mem.riot.InputDeviceWrite(addresses.SWCHA, data.Value, 0x00)
 
+		// some peripherals respond to the write
+		inp.HandController0.respondSWCHA(data.Value)
+		inp.HandController1.respondSWCHA(data.Value)
+
 	case "SWACNT":
 		inp.HandController0.setDDR(data.Value)
 		inp.HandController1.setDDR(data.Value)
@@ -146,8 +204,8 @@ func (inp *Input) Update(data bus.ChipData) bool {
 // Step input state forward one cycle
 func (inp *Input) Step() {
 	// not much to do here because most input operations happen on demand.
-	// recharging of the paddle capacitors however happens (a little bit) every
-	// step.
-	inp.HandController0.recharge()
-	inp.HandController1.recharge()
+	// recharging of the paddle capacitors and the movement of the trackball
+	// however happens (a little bit) every step.
+	inp.HandController0.step()
+	inp.HandController1.step()
 }
@@ -55,6 +55,54 @@ func NewPanel(mem *inputMemory) *Panel {
 	return pan
 }
 
+// Snapshot creates a copy of the Panel in its current state
+func (pan *Panel) Snapshot() *Panel {
+	n := *pan
+	return &n
+}
+
+// Restore the Panel from a snapshot. Attached playback and recording devices
+// are not affected
+func (pan *Panel) Restore(s *Panel) {
+	p := pan.port
+	*pan = *s
+	pan.port = p
+}
+
+// SavedPanel is the state of the Panel as returned by SaveState()
+type SavedPanel struct {
+	P0Pro         bool
+	P1Pro         bool
+	Color         bool
+	SelectPressed bool
+	ResetPressed  bool
+	DDR           uint8
+}
+
+// SaveState returns the current state of the Panel
+func (pan *Panel) SaveState() SavedPanel {
+	return SavedPanel{
+		P0Pro:         pan.p0pro,
+		P1Pro:         pan.p1pro,
+		Color:         pan.color,
+		SelectPressed: pan.selectPressed,
+		ResetPressed:  pan.resetPressed,
+		DDR:           pan.ddr,
+	}
+}
+
+// LoadState sets the Panel to a state previously returned by SaveState(). It
+// should only be called on a snapshot and never on the live emulation. Use
+// Restore() to apply the snapshot.
+func (pan *Panel) LoadState(s SavedPanel) {
+	pan.p0pro = s.P0Pro
+	pan.p1pro = s.P1Pro
+	pan.color = s.Color
+	pan.selectPressed = s.SelectPressed
+	pan.resetPressed = s.ResetPressed
+	pan.ddr = s.DDR
+}
+
 // String implements the Port interface
 func (pan *Panel) String() string {
 	s := strings.Builder{}
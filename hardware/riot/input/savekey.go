@@ -0,0 +1,404 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package input
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/logger"
+	"github.com/jetsetilly/gopher2600/paths"
+)
+
+// the SaveKey and AtariVox are connected to the VCS through SWCHA. the
+// EEPROM in both devices is accessed with the I2C protocol, bit-banged by the
+// VCS program on two of the four pins. the AtariVox also uses the remaining
+// two pins to communicate with its speech chip.
+//
+// the pins are given here as bits of the normalised SWCHA nibble
+const (
+	speakjetData  = uint8(0x10)
+	speakjetReady = uint8(0x20)
+	i2cSDA        = uint8(0x40)
+	i2cSCL        = uint8(0x80)
+)
+
+// the size of the 24LC256 EEPROM and the size of each of its pages. writes to
+// the EEPROM wrap around the page boundary.
+const eepromSize = 0x8000
+const eepromPageSize = 0x40
+
+// the upper nibble of the I2C control byte that addresses the EEPROM
+const eepromDeviceID = 0xa0
+
+// the name of the files used to persist the contents of the EEPROM
+const saveKeyFile = "savekey"
+const atariVoxFile = "atarivox"
+
+// the states of the I2C conversation with the EEPROM
+type i2cState int
+
+const (
+	i2cIdle i2cState = iota
+	i2cControl
+	i2cAddressHi
+	i2cAddressLo
+	i2cWrite
+	i2cReadBegin
+	i2cRead
+)
+
+// the savekey type implements the 24LC256 I2C EEPROM found in both the
+// SaveKey and AtariVox. the AtariVox speech chip is also implemented by this
+// type.
+type savekey struct {
+	// the file the EEPROM is persisted to
+	filename string
+
+	// the contents of the EEPROM. the slice is deliberately shared between
+	// snapshots of the hand controller. the EEPROM is external storage and
+	// rewinding the emulation should not undo the saving of data.
+	data []uint8
+
+	// whether the EEPROM has been written to since it was last persisted
+	dirty bool
+
+	// state of the I2C lines as driven by the VCS
+	scl bool
+	sda bool
+
+	// the value of the SDA line as driven by the EEPROM. true if the line
+	// has been released (and is therefore pulled high)
+	sdaOut bool
+
+	state   i2cState
+	bit     int
+	shift   uint8
+	address uint16
+
+	// the speech chip. only enabled for the AtariVox
+	speech speakjet
+}
+
+// SavedSaveKey is the state of the SaveKey (or AtariVox) as returned by
+// saveState(). The contents of the EEPROM are not part of the saved state. The
+// EEPROM is external storage and loading a state should not undo the saving of
+// data.
+type SavedSaveKey struct {
+	SCL     bool
+	SDA     bool
+	SDAOut  bool
+	State   int
+	Bit     int
+	Shift   uint8
+	Address uint16
+
+	SpeechEnabled bool
+	SpeechShift   uint16
+	SpeechCount   int
+	SpeechCycles  int
+}
+
+func (sk *savekey) saveState() SavedSaveKey {
+	return SavedSaveKey{
+		SCL:           sk.scl,
+		SDA:           sk.sda,
+		SDAOut:        sk.sdaOut,
+		State:         int(sk.state),
+		Bit:           sk.bit,
+		Shift:         sk.shift,
+		Address:       sk.address,
+		SpeechEnabled: sk.speech.enabled,
+		SpeechShift:   sk.speech.shift,
+		SpeechCount:   sk.speech.count,
+		SpeechCycles:  sk.speech.cycles,
+	}
+}
+
+func (sk *savekey) loadState(s SavedSaveKey) {
+	sk.scl = s.SCL
+	sk.sda = s.SDA
+	sk.sdaOut = s.SDAOut
+	sk.state = i2cState(s.State)
+	sk.bit = s.Bit
+	sk.shift = s.Shift
+	sk.address = s.Address % eepromSize
+	sk.speech.enabled = s.SpeechEnabled
+	sk.speech.shift = s.SpeechShift
+	sk.speech.count = s.SpeechCount
+	sk.speech.cycles = s.SpeechCycles
+}
+
+// the number of video cycles between each bit sent to the speech chip
+// (62 CPU cycles) and the number of video cycles after which an incomplete
+// byte is discarded (1000 CPU cycles)
+const speakjetBitRate = 186
+const speakjetTimeout = 3000
+
+// the speakjet type receives the serial data sent to the AtariVox speech
+// chip. there is no speech synthesis. the received bytes are logged.
+type speakjet struct {
+	enabled bool
+
+	// serial data as it is received. the first bit is the start bit and the
+	// last bit the stop bit
+	shift uint16
+	count int
+
+	// number of video cycles since the last bit was received
+	cycles int
+}
+
+// load the contents of the EEPROM from file. if the file does not exist the
+// EEPROM is initialised as if it had never been written to.
+func (sk *savekey) load(filename string) error {
+	sk.filename = filename
+	sk.data = make([]uint8, eepromSize)
+	sk.dirty = false
+	sk.reset()
+
+	for i := range sk.data {
+		sk.data[i] = 0xff
+	}
+
+	pth, err := paths.ResourcePath("", sk.filename)
+	if err != nil {
+		return errors.New(errors.SaveKeyError, err)
+	}
+
+	d, err := ioutil.ReadFile(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.New(errors.SaveKeyError, err)
+	}
+
+	copy(sk.data, d)
+
+	return nil
+}
+
+// save the contents of the EEPROM to file, if it has been changed
+func (sk *savekey) save() error {
+	if !sk.dirty {
+		return nil
+	}
+
+	pth, err := paths.ResourcePath("", sk.filename)
+	if err != nil {
+		return errors.New(errors.SaveKeyError, err)
+	}
+
+	err = ioutil.WriteFile(pth, sk.data, 0600)
+	if err != nil {
+		return errors.New(errors.SaveKeyError, err)
+	}
+
+	sk.dirty = false
+
+	return nil
+}
+
+// reset the I2C conversation. the lines are released.
+func (sk *savekey) reset() {
+	sk.scl = true
+	sk.sda = true
+	sk.sdaOut = true
+	sk.state = i2cIdle
+	sk.bit = 0
+	sk.speech.count = 0
+}
+
+// update the I2C lines. the state machine is advanced on the edges of the
+// clock line, or on changes to the data line while the clock is high (the
+// start and stop conditions).
+func (sk *savekey) update(scl bool, sda bool) {
+	prevSCL := sk.scl
+	prevSDA := sk.sda
+	sk.scl = scl
+	sk.sda = sda
+
+	if scl && prevSCL {
+		if prevSDA && !sda {
+			// start condition
+			sk.state = i2cControl
+			sk.bit = 0
+			sk.sdaOut = true
+		} else if !prevSDA && sda {
+			// stop condition
+			if sk.state == i2cWrite {
+				if err := sk.save(); err != nil {
+					logger.Log("savekey", err.Error())
+				}
+			}
+			sk.state = i2cIdle
+			sk.sdaOut = true
+		}
+		return
+	}
+
+	if sk.state == i2cIdle {
+		return
+	}
+
+	if !prevSCL && scl {
+		sk.risingEdge()
+	} else if prevSCL && !scl {
+		sk.fallingEdge()
+	}
+}
+
+// bits are counted on the rising edge of the clock. the ninth clock of each
+// byte is the acknowledgement clock.
+func (sk *savekey) risingEdge() {
+	sk.bit++
+
+	if sk.state == i2cRead {
+		// the VCS acknowledges each byte it reads. if it doesn't then it
+		// has finished reading
+		if sk.bit == 9 && sk.sda {
+			sk.state = i2cIdle
+			sk.sdaOut = true
+		}
+		return
+	}
+
+	if sk.bit <= 8 {
+		sk.shift <<= 1
+		if sk.sda {
+			sk.shift |= 0x01
+		}
+	}
+}
+
+// the EEPROM changes the SDA line on the falling edge of the clock
+func (sk *savekey) fallingEdge() {
+	if sk.state == i2cRead {
+		switch sk.bit {
+		case 0:
+		case 8:
+			// release line for acknowledgement from the VCS
+			sk.sdaOut = true
+			sk.address = (sk.address + 1) % eepromSize
+		case 9:
+			sk.bit = 0
+			sk.sdaOut = sk.data[sk.address]&0x80 == 0x80
+		default:
+			sk.sdaOut = sk.data[sk.address]<<uint(sk.bit)&0x80 == 0x80
+		}
+		return
+	}
+
+	switch sk.bit {
+	case 8:
+		// byte has been received. the EEPROM acknowledges by pulling SDA low
+		sk.sdaOut = false
+		sk.receive()
+	case 9:
+		sk.bit = 0
+		sk.sdaOut = true
+
+		// begin sending first byte if necessary
+		if sk.state == i2cReadBegin {
+			sk.state = i2cRead
+			sk.sdaOut = sk.data[sk.address]&0x80 == 0x80
+		}
+	}
+}
+
+// receive is called when a complete byte has been received from the VCS
+func (sk *savekey) receive() {
+	switch sk.state {
+	case i2cControl:
+		if sk.shift&0xf0 != eepromDeviceID {
+			// the control byte is not for us. do not acknowledge
+			sk.sdaOut = true
+			sk.state = i2cIdle
+			return
+		}
+		if sk.shift&0x01 == 0x01 {
+			sk.state = i2cReadBegin
+		} else {
+			sk.state = i2cAddressHi
+		}
+
+	case i2cAddressHi:
+		sk.address = uint16(sk.shift&0x7f) << 8
+		sk.state = i2cAddressLo
+
+	case i2cAddressLo:
+		sk.address |= uint16(sk.shift)
+		sk.state = i2cWrite
+
+	case i2cWrite:
+		sk.data[sk.address] = sk.shift
+		sk.dirty = true
+
+		// address wraps around the page boundary
+		page := sk.address &^ (eepromPageSize - 1)
+		sk.address = page | ((sk.address + 1) & (eepromPageSize - 1))
+	}
+}
+
+// step the speech chip forward one video cycle
+func (sj *speakjet) step() {
+	if sj.cycles < speakjetTimeout {
+		sj.cycles++
+	}
+}
+
+// the data line to the speech chip has been written to. a bit is received
+// if enough time has passed since the previous write.
+func (sj *speakjet) write(bit bool) {
+	if !sj.enabled {
+		return
+	}
+
+	// the line is idle when high. wait for the start bit
+	if bit && sj.count == 0 {
+		return
+	}
+
+	// discard incomplete byte if the line hasn't been written to for a long
+	// time
+	if sj.cycles >= speakjetTimeout {
+		sj.shift = 0
+		sj.count = 0
+	}
+
+	if sj.count == 0 || sj.cycles >= speakjetBitRate {
+		if bit {
+			sj.shift |= 1 << uint(sj.count)
+		}
+		sj.count++
+
+		// 1 start bit, 8 data bits, 1 stop bit
+		if sj.count == 10 {
+			if sj.shift&(1<<9) == 0 {
+				logger.Log("atarivox", "bad stop bit")
+			} else {
+				logger.Log("atarivox", fmt.Sprintf("speakjet: %#02x", uint8(sj.shift>>1)))
+			}
+			sj.shift = 0
+			sj.count = 0
+		}
+	}
+
+	sj.cycles = 0
+}
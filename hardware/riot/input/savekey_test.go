@@ -0,0 +1,369 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package input
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestSaveKey changes the working directory to a temporary directory so
+// that the EEPROM file is created there. the returned function restores the
+// working directory and removes the temporary directory.
+func newTestSaveKey(t *testing.T) (*savekey, func()) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "savekey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	cleanup := func() {
+		_ = os.Chdir(wd)
+		_ = os.RemoveAll(dir)
+	}
+
+	sk := &savekey{}
+	if err := sk.load(saveKeyFile); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return sk, cleanup
+}
+
+// the following functions drive the I2C lines in the same way as a VCS
+// program. the SDA line is only changed while the clock is low, except for
+// the start and stop conditions.
+
+func (sk *savekey) testStart() {
+	sk.update(false, true)
+	sk.update(true, true)
+	sk.update(true, false)
+}
+
+func (sk *savekey) testStop() {
+	sk.update(false, false)
+	sk.update(true, false)
+	sk.update(true, true)
+}
+
+// returns true if the byte was acknowledged by the EEPROM
+func (sk *savekey) testWriteByte(b uint8) bool {
+	for i := 0; i < 8; i++ {
+		bit := b<<uint(i)&0x80 == 0x80
+		sk.update(false, bit)
+		sk.update(true, bit)
+		sk.update(false, bit)
+	}
+
+	// release SDA for the acknowledgement
+	sk.update(false, true)
+	sk.update(true, true)
+	ack := !sk.sdaOut
+	sk.update(false, true)
+
+	return ack
+}
+
+// if ack is false then the VCS signals that it has finished reading
+func (sk *savekey) testReadByte(ack bool) uint8 {
+	var b uint8
+
+	for i := 0; i < 8; i++ {
+		sk.update(true, true)
+		b <<= 1
+		if sk.sdaOut {
+			b |= 0x01
+		}
+		sk.update(false, true)
+	}
+
+	sk.update(false, !ack)
+	sk.update(true, !ack)
+	sk.update(false, !ack)
+
+	return b
+}
+
+// set address of the next read or write
+func (sk *savekey) testAddress(t *testing.T, address uint16) {
+	t.Helper()
+
+	sk.testStart()
+	if !sk.testWriteByte(eepromDeviceID) {
+		t.Fatalf("control byte not acknowledged")
+	}
+	if !sk.testWriteByte(uint8(address >> 8)) {
+		t.Fatalf("address hi byte not acknowledged")
+	}
+	if !sk.testWriteByte(uint8(address)) {
+		t.Fatalf("address lo byte not acknowledged")
+	}
+}
+
+func TestSaveKeyStartStop(t *testing.T) {
+	sk, cleanup := newTestSaveKey(t)
+	defer cleanup()
+
+	if sk.state != i2cIdle {
+		t.Fatalf("expected idle state after load")
+	}
+
+	// changing SDA while the clock is low is not a start condition
+	sk.update(false, true)
+	sk.update(false, false)
+	sk.update(false, true)
+	if sk.state != i2cIdle {
+		t.Errorf("SDA change while SCL low treated as start condition")
+	}
+
+	sk.testStart()
+	if sk.state != i2cControl {
+		t.Errorf("expected control state after start condition")
+	}
+
+	sk.testStop()
+	if sk.state != i2cIdle {
+		t.Errorf("expected idle state after stop condition")
+	}
+
+	// a control byte for another device is not acknowledged
+	sk.testStart()
+	if sk.testWriteByte(0x50) {
+		t.Errorf("control byte for another device was acknowledged")
+	}
+	if sk.state != i2cIdle {
+		t.Errorf("expected idle state after control byte for another device")
+	}
+	sk.testStop()
+
+	// stop condition in the middle of a byte abandons the conversation
+	sk.testStart()
+	sk.update(false, true)
+	sk.update(true, true)
+	sk.update(false, true)
+	sk.testStop()
+	if sk.state != i2cIdle {
+		t.Errorf("expected idle state after stop condition mid-byte")
+	}
+}
+
+func TestSaveKeyWrite(t *testing.T) {
+	sk, cleanup := newTestSaveKey(t)
+	defer cleanup()
+
+	sk.testAddress(t, 0x1234)
+	if sk.address != 0x1234 {
+		t.Errorf("expected address 0x1234 got %#04x", sk.address)
+	}
+	if sk.state != i2cWrite {
+		t.Errorf("expected write state after address bytes")
+	}
+
+	// the most significant bit of the address is ignored
+	sk.testStop()
+	sk.testAddress(t, 0x9234)
+	if sk.address != 0x1234 {
+		t.Errorf("expected address 0x1234 got %#04x", sk.address)
+	}
+
+	for _, b := range []uint8{0x01, 0x02, 0x03} {
+		if !sk.testWriteByte(b) {
+			t.Fatalf("data byte not acknowledged")
+		}
+	}
+	sk.testStop()
+
+	for i, b := range []uint8{0x01, 0x02, 0x03} {
+		if sk.data[0x1234+i] != b {
+			t.Errorf("expected %#02x at %#04x got %#02x", b, 0x1234+i, sk.data[0x1234+i])
+		}
+	}
+
+	// writes wrap around the page boundary
+	sk.testAddress(t, 0x007e)
+	for _, b := range []uint8{0x11, 0x22, 0x33} {
+		if !sk.testWriteByte(b) {
+			t.Fatalf("data byte not acknowledged")
+		}
+	}
+	sk.testStop()
+
+	if sk.data[0x007e] != 0x11 || sk.data[0x007f] != 0x22 || sk.data[0x0040] != 0x33 {
+		t.Errorf("write did not wrap around page boundary")
+	}
+	if sk.data[0x0080] != 0xff {
+		t.Errorf("write crossed page boundary")
+	}
+}
+
+func TestSaveKeySequentialRead(t *testing.T) {
+	sk, cleanup := newTestSaveKey(t)
+	defer cleanup()
+
+	expected := []uint8{0xaa, 0x55, 0x81, 0x7e, 0x00}
+	copy(sk.data[0x013e:], expected)
+
+	// set the address with a dummy write and then read from the same address
+	// after a repeated start condition
+	sk.testAddress(t, 0x013e)
+	sk.testStart()
+	if !sk.testWriteByte(eepromDeviceID | 0x01) {
+		t.Fatalf("control byte not acknowledged")
+	}
+	if sk.state != i2cRead {
+		t.Fatalf("expected read state after control byte")
+	}
+
+	// sequential reads are not limited to the page
+	for i, b := range expected {
+		v := sk.testReadByte(i < len(expected)-1)
+		if v != b {
+			t.Errorf("read %d: expected %#02x got %#02x", i, b, v)
+		}
+	}
+
+	if sk.state != i2cIdle {
+		t.Errorf("expected idle state after final read is not acknowledged")
+	}
+	sk.testStop()
+
+	if sk.dirty {
+		t.Errorf("reading marked EEPROM as dirty")
+	}
+}
+
+func TestSaveKeyPersistence(t *testing.T) {
+	sk, cleanup := newTestSaveKey(t)
+	defer cleanup()
+
+	sk.testAddress(t, 0x0100)
+	for _, b := range []uint8{0xde, 0xad, 0xbe, 0xef} {
+		if !sk.testWriteByte(b) {
+			t.Fatalf("data byte not acknowledged")
+		}
+	}
+
+	// the data is not saved until the stop condition
+	pth := filepath.Join(".gopher2600", saveKeyFile)
+	if _, err := os.Stat(pth); !os.IsNotExist(err) {
+		t.Fatalf("EEPROM file written before stop condition")
+	}
+
+	sk.testStop()
+
+	d, err := ioutil.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != eepromSize {
+		t.Fatalf("expected EEPROM file of %d bytes got %d", eepromSize, len(d))
+	}
+	if sk.dirty {
+		t.Errorf("EEPROM still dirty after save")
+	}
+
+	// a new EEPROM loads the saved data
+	sk2 := &savekey{}
+	if err := sk2.load(saveKeyFile); err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range []uint8{0xde, 0xad, 0xbe, 0xef} {
+		if sk2.data[0x0100+i] != b {
+			t.Errorf("expected %#02x at %#04x got %#02x", b, 0x0100+i, sk2.data[0x0100+i])
+		}
+	}
+	if sk2.data[0x0000] != 0xff || sk2.data[0x0104] != 0xff {
+		t.Errorf("unwritten EEPROM data not preserved")
+	}
+}
+
+// the contents of the EEPROM are not part of the saved state of the hand
+// controller. data written after the state was saved must survive the
+// loading of that state
+func TestSaveKeyLoadState(t *testing.T) {
+	_, cleanup := newTestSaveKey(t)
+	defer cleanup()
+
+	hc, _, _, _ := newTestHandControllers()
+	if err := hc.SwitchType(SaveKeyType); err != nil {
+		t.Fatal(err)
+	}
+
+	// save state in the same way as the STATE SAVE command
+	var state bytes.Buffer
+	if err := gob.NewEncoder(&state).Encode(hc.Snapshot().SaveState()); err != nil {
+		t.Fatal(err)
+	}
+
+	written := []uint8{0xde, 0xad, 0xbe, 0xef}
+	hc.savekey.testAddress(t, 0x0200)
+	for _, b := range written {
+		if !hc.savekey.testWriteByte(b) {
+			t.Fatalf("data byte not acknowledged")
+		}
+	}
+	hc.savekey.testStop()
+
+	// load state in the same way as the STATE LOAD command
+	var saved SavedHandController
+	if err := gob.NewDecoder(&state).Decode(&saved); err != nil {
+		t.Fatal(err)
+	}
+	s := hc.Snapshot()
+	if err := s.LoadState(saved); err != nil {
+		t.Fatal(err)
+	}
+	hc.Restore(s)
+
+	for i, b := range written {
+		if hc.savekey.data[0x0200+i] != b {
+			t.Errorf("expected %#02x at %#04x got %#02x", b, 0x0200+i, hc.savekey.data[0x0200+i])
+		}
+	}
+
+	// a hand controller with no EEPROM loads it from disk when the state is
+	// loaded
+	other, _, _, _ := newTestHandControllers()
+	s = other.Snapshot()
+	if err := s.LoadState(saved); err != nil {
+		t.Fatal(err)
+	}
+	other.Restore(s)
+
+	if other.ControllerType != SaveKeyType {
+		t.Fatalf("expected SaveKey controller after loading state")
+	}
+	for i, b := range written {
+		if other.savekey.data[0x0200+i] != b {
+			t.Errorf("expected %#02x at %#04x got %#02x", b, 0x0200+i, other.savekey.data[0x0200+i])
+		}
+	}
+}
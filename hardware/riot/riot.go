@@ -16,8 +16,10 @@
 package riot
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/jetsetilly/gopher2600/errors"
 	"github.com/jetsetilly/gopher2600/hardware/memory/bus"
 	"github.com/jetsetilly/gopher2600/hardware/riot/input"
 	"github.com/jetsetilly/gopher2600/hardware/riot/timer"
@@ -51,6 +53,53 @@ func (riot RIOT) String() string {
 	return s.String()
 }
 
+// Snapshot creates a copy of the RIOT in its current state
+func (riot *RIOT) Snapshot() *RIOT {
+	n := *riot
+	n.Timer = riot.Timer.Snapshot()
+	n.Input = riot.Input.Snapshot()
+	return &n
+}
+
+// Restore the RIOT from a snapshot
+func (riot *RIOT) Restore(s *RIOT) {
+	riot.Timer.Restore(s.Timer)
+	riot.Input.Restore(s.Input)
+}
+
+// the version of the SavedRIOT type. increase this whenever the SavedRIOT
+// type, or any of the types it contains, changes
+const savedRIOTVersion = 1
+
+// SavedRIOT is the state of the RIOT as returned by SaveState()
+type SavedRIOT struct {
+	Version int
+	Timer   timer.SavedTimer
+	Input   input.SavedInput
+}
+
+// SaveState returns the current state of the RIOT
+func (riot *RIOT) SaveState() SavedRIOT {
+	return SavedRIOT{
+		Version: savedRIOTVersion,
+		Timer:   riot.Timer.SaveState(),
+		Input:   riot.Input.SaveState(),
+	}
+}
+
+// LoadState sets the RIOT to a state previously returned by SaveState(). It
+// should only be called on a snapshot and never on the live emulation. Use
+// Restore() to apply the snapshot.
+func (riot *RIOT) LoadState(s SavedRIOT) error {
+	if s.Version != savedRIOTVersion {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unsupported RIOT state version (%d)", s.Version))
+	}
+	if err := riot.Timer.LoadState(s.Timer); err != nil {
+		return err
+	}
+	return riot.Input.LoadState(s.Input)
+}
+
 // Update checks for the most recent write by the CPU to the RIOT memory
 // registers
 func (riot *RIOT) Update() {
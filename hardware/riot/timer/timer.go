@@ -18,6 +18,7 @@ package timer
 import (
 	"fmt"
 
+	"github.com/jetsetilly/gopher2600/errors"
 	"github.com/jetsetilly/gopher2600/hardware/memory/addresses"
 	"github.com/jetsetilly/gopher2600/hardware/memory/bus"
 )
@@ -99,6 +100,56 @@ func NewTimer(mem bus.ChipBus) *Timer {
 	return tmr
 }
 
+// Snapshot creates a copy of the RIOT Timer in its current state
+func (tmr *Timer) Snapshot() *Timer {
+	n := *tmr
+	return &n
+}
+
+// Restore the RIOT Timer from a snapshot
+func (tmr *Timer) Restore(s *Timer) {
+	*tmr = *s
+}
+
+// SavedTimer is the state of the RIOT Timer as returned by SaveState()
+type SavedTimer struct {
+	Divider        Interval
+	INTIMvalue     uint8
+	Expired        bool
+	PA7            bool
+	TicksRemaining int
+}
+
+// SaveState returns the current state of the RIOT Timer
+func (tmr *Timer) SaveState() SavedTimer {
+	return SavedTimer{
+		Divider:        tmr.Divider,
+		INTIMvalue:     tmr.INTIMvalue,
+		Expired:        tmr.expired,
+		PA7:            tmr.pa7,
+		TicksRemaining: tmr.TicksRemaining,
+	}
+}
+
+// LoadState sets the RIOT Timer to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+func (tmr *Timer) LoadState(s SavedTimer) error {
+	switch s.Divider {
+	case TIM1T, TIM8T, TIM64T, T1024T:
+	default:
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unknown timer interval (%d)", s.Divider))
+	}
+
+	tmr.Divider = s.Divider
+	tmr.INTIMvalue = s.INTIMvalue
+	tmr.expired = s.Expired
+	tmr.pa7 = s.PA7
+	tmr.TicksRemaining = s.TicksRemaining
+
+	return nil
+}
+
 func (tmr Timer) String() string {
 	return fmt.Sprintf("INTIM=%#02x remn=%#02x intv=%s TIMINT=%v",
 		tmr.INTIMvalue,
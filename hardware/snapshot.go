@@ -0,0 +1,182 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package hardware
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/cpu"
+	"github.com/jetsetilly/gopher2600/hardware/memory"
+	"github.com/jetsetilly/gopher2600/hardware/riot"
+	"github.com/jetsetilly/gopher2600/hardware/tia"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// State is a snapshot of the entire VCS, including the television and the
+// attached cartridge.
+//
+// Some parts of the emulation refer to the live emulation and cannot be
+// detached from it. A State is therefore only meaningful to the VCS instance
+// that created it. However, a State can be written to disk with Save() and
+// read back with LoadState(), by any VCS instance with the same cartridge
+// attached. Only plain data, as returned by the SaveState() function of each
+// component, is written to disk.
+type State struct {
+	CPU  *cpu.CPU
+	Mem  *memory.VCSMemory
+	TIA  *tia.TIA
+	RIOT *riot.RIOT
+	TV   *television.State
+}
+
+// Snapshot creates a copy of the VCS in its current state
+func (vcs *VCS) Snapshot() *State {
+	return &State{
+		CPU:  vcs.CPU.Snapshot(),
+		Mem:  vcs.Mem.Snapshot(),
+		TIA:  vcs.TIA.Snapshot(),
+		RIOT: vcs.RIOT.Snapshot(),
+		TV:   vcs.TV.Snapshot(),
+	}
+}
+
+// Restore the VCS to the state contained in the snapshot. The State must have
+// been created by the same instance of VCS and the same cartridge must still
+// be attached.
+//
+// The snapshot is not altered by Restore() and can be used again.
+func (vcs *VCS) Restore(s *State) error {
+	err := vcs.Mem.Restore(s.Mem)
+	if err != nil {
+		return err
+	}
+
+	vcs.CPU.Restore(s.CPU)
+	vcs.TIA.Restore(s.TIA)
+	vcs.RIOT.Restore(s.RIOT)
+
+	return vcs.TV.Restore(s.TV)
+}
+
+// the state file begins with a fixed header. the version number should be
+// increased whenever the savedState type changes. each component of the
+// savedState has its own version number
+const (
+	stateFileID      = "gopher2600state"
+	stateFileVersion = 2
+)
+
+// the header of a state file
+type stateHeader struct {
+	ID      string
+	Version int
+}
+
+// the content of a state file. all fields are plain data and contain no
+// references to the emulation
+type savedState struct {
+	CPU  cpu.SavedCPU
+	Mem  memory.SavedMemory
+	TIA  tia.SavedTIA
+	RIOT riot.SavedRIOT
+	TV   television.SavedTelevision
+}
+
+// Save writes the State to w. The State can be read again with LoadState().
+func (s *State) Save(w io.Writer) error {
+	mem, err := s.Mem.SaveState()
+	if err != nil {
+		return err
+	}
+
+	saved := savedState{
+		CPU:  s.CPU.SaveState(),
+		Mem:  mem,
+		TIA:  s.TIA.SaveState(),
+		RIOT: s.RIOT.SaveState(),
+		TV:   s.TV.SaveState(),
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := gob.NewEncoder(bw)
+
+	err = enc.Encode(stateHeader{ID: stateFileID, Version: stateFileVersion})
+	if err != nil {
+		return errors.New(errors.SnapshotError, err)
+	}
+
+	err = enc.Encode(saved)
+	if err != nil {
+		return errors.New(errors.SnapshotError, err)
+	}
+
+	err = bw.Flush()
+	if err != nil {
+		return errors.New(errors.SnapshotError, err)
+	}
+
+	return nil
+}
+
+// LoadState reads a State previously written by Save() and restores the VCS
+// to that state. The cartridge currently attached to the VCS must be the
+// same cartridge that was attached when the State was saved.
+//
+// The VCS is unchanged if an error is returned.
+func (vcs *VCS) LoadState(r io.Reader) error {
+	dec := gob.NewDecoder(bufio.NewReader(r))
+
+	var hdr stateHeader
+	if err := dec.Decode(&hdr); err != nil || hdr.ID != stateFileID {
+		return errors.New(errors.SnapshotError, "not a state file")
+	}
+
+	if hdr.Version != stateFileVersion {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unsupported state file version (%d)", hdr.Version))
+	}
+
+	var saved savedState
+	if err := dec.Decode(&saved); err != nil {
+		return errors.New(errors.SnapshotError, err)
+	}
+
+	// the saved state is applied to a new snapshot of the VCS. the live
+	// emulation is only changed by the call to Restore(), once the entire
+	// state has been loaded successfully
+	s := vcs.Snapshot()
+
+	if err := s.Mem.LoadState(saved.Mem); err != nil {
+		return err
+	}
+	if err := s.CPU.LoadState(saved.CPU); err != nil {
+		return err
+	}
+	if err := s.TIA.LoadState(saved.TIA); err != nil {
+		return err
+	}
+	if err := s.RIOT.LoadState(saved.RIOT); err != nil {
+		return err
+	}
+	if err := s.TV.LoadState(saved.TV); err != nil {
+		return err
+	}
+
+	return vcs.Restore(s)
+}
@@ -0,0 +1,259 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package hardware_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/cartridgeloader"
+	"github.com/jetsetilly/gopher2600/hardware"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// the program is repeated in both banks of an 8k (F8) cartridge. every frame
+// it increments the value in $80 and switches bank depending on whether
+// the value is odd or even
+var snapshotProgram = []uint8{
+	0x78,       // SEI
+	0xd8,       // CLD
+	0xa2, 0xff, // LDX #$ff
+	0x9a,       // TXS
+	0xa9, 0x02, // LDA #$02 (frame)
+	0x85, 0x00, // STA VSYNC
+	0x85, 0x02, // STA WSYNC
+	0x85, 0x02, // STA WSYNC
+	0x85, 0x02, // STA WSYNC
+	0xa9, 0x00, // LDA #$00
+	0x85, 0x00, // STA VSYNC
+	0xe6, 0x80, // INC $80
+	0xa5, 0x80, // LDA $80
+	0x85, 0x09, // STA COLUBK
+	0x4a,       // LSR A
+	0xb0, 0x05, // BCS odd
+	0xad, 0xf8, 0x1f, // LDA $1ff8
+	0x90, 0x03, // BCC lines
+	0xad, 0xf9, 0x1f, // LDA $1ff9 (odd)
+	0xa0, 0xff, // LDY #$ff (lines)
+	0x85, 0x02, // STA WSYNC
+	0x88,       // DEY
+	0xd0, 0xfb, // BNE
+	0xa0, 0x04, // LDY #$04
+	0x85, 0x02, // STA WSYNC
+	0x88,       // DEY
+	0xd0, 0xfb, // BNE
+	0x4c, 0x05, 0xf0, // JMP frame
+}
+
+func newSnapshotVCS(t *testing.T, filename string, mapping string) *hardware.VCS {
+	t.Helper()
+
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vcs, err := hardware.NewVCS(tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = vcs.AttachCartridge(cartridgeloader.NewLoader(filename, mapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return vcs
+}
+
+func runFrames(t *testing.T, vcs *hardware.VCS, frames int) {
+	t.Helper()
+
+	end, _ := vcs.TV.GetState(television.ReqFramenum)
+	end += frames
+
+	for {
+		fn, _ := vcs.TV.GetState(television.ReqFramenum)
+		if fn >= end {
+			break
+		}
+		if err := vcs.Step(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+type snapshotSummary struct {
+	ram     []uint8
+	pc      uint16
+	a, x, y uint8
+	sp      uint8
+	status  uint8
+	bank    int
+	frame   int
+}
+
+func summarise(vcs *hardware.VCS) snapshotSummary {
+	s := snapshotSummary{
+		ram:    make([]uint8, len(vcs.Mem.RAM.RAM)),
+		pc:     vcs.CPU.PC.Value(),
+		a:      vcs.CPU.A.Value(),
+		x:      vcs.CPU.X.Value(),
+		y:      vcs.CPU.Y.Value(),
+		sp:     vcs.CPU.SP.Value(),
+		status: vcs.CPU.Status.Value(),
+		bank:   vcs.Mem.Cart.GetBank(vcs.CPU.PC.Address()).Number,
+	}
+	copy(s.ram, vcs.Mem.RAM.RAM)
+	s.frame, _ = vcs.TV.GetState(television.ReqFramenum)
+	return s
+}
+
+func compareSummary(t *testing.T, got, want snapshotSummary) {
+	t.Helper()
+
+	if !bytes.Equal(got.ram, want.ram) {
+		t.Errorf("RAM does not match")
+	}
+	if got.pc != want.pc || got.a != want.a || got.x != want.x || got.y != want.y ||
+		got.sp != want.sp || got.status != want.status {
+		t.Errorf("CPU registers do not match")
+	}
+	if got.bank != want.bank {
+		t.Errorf("cartridge bank does not match (%d instead of %d)", got.bank, want.bank)
+	}
+	if got.frame != want.frame {
+		t.Errorf("television frame does not match (%d instead of %d)", got.frame, want.frame)
+	}
+}
+
+func TestSnapshotSaveLoad(t *testing.T) {
+	rom := make([]uint8, 8192)
+	copy(rom, snapshotProgram)
+	copy(rom[4096:], snapshotProgram)
+	rom[0x0ffc], rom[0x0ffd] = 0x00, 0xf0
+	rom[0x1ffc], rom[0x1ffd] = 0x00, 0xf0
+
+	dir, err := ioutil.TempDir("", "gopher2600_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.bin")
+	err = ioutil.WriteFile(filename, rom, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vcs := newSnapshotVCS(t, filename, "F8")
+
+	// run to the middle of a frame so that the state is not trivial
+	runFrames(t, vcs, 5)
+	for i := 0; i < 1000; i++ {
+		if err := vcs.Step(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := summarise(vcs)
+
+	var state bytes.Buffer
+	err = vcs.Snapshot().Save(&state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the state should be restored after the emulation has moved on
+	runFrames(t, vcs, 3)
+	later := summarise(vcs)
+
+	err = vcs.LoadState(bytes.NewReader(state.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareSummary(t, summarise(vcs), saved)
+
+	// and the emulation should continue exactly as it did before
+	runFrames(t, vcs, 3)
+	compareSummary(t, summarise(vcs), later)
+
+	// the state can be loaded into another instance of the VCS
+	other := newSnapshotVCS(t, filename, "F8")
+	err = other.LoadState(bytes.NewReader(state.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareSummary(t, summarise(other), saved)
+
+	// saving the state again should produce exactly the same data
+	var again bytes.Buffer
+	err = other.Snapshot().Save(&again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(state.Bytes(), again.Bytes()) {
+		t.Errorf("state is different after being loaded and saved again")
+	}
+
+	runFrames(t, other, 3)
+	compareSummary(t, summarise(other), later)
+}
+
+func TestSnapshotLoadErrors(t *testing.T) {
+	rom := make([]uint8, 4096)
+	copy(rom, snapshotProgram)
+	rom[0x0ffc], rom[0x0ffd] = 0x00, 0xf0
+
+	dir, err := ioutil.TempDir("", "gopher2600_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.bin")
+	err = ioutil.WriteFile(filename, rom, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vcs := newSnapshotVCS(t, filename, "AUTO")
+	runFrames(t, vcs, 2)
+
+	var state bytes.Buffer
+	err = vcs.Snapshot().Save(&state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := summarise(vcs)
+
+	// truncated data is an error and should leave the VCS unchanged
+	err = vcs.LoadState(bytes.NewReader(state.Bytes()[:state.Len()/2]))
+	if err == nil {
+		t.Errorf("expected error for truncated state")
+	}
+	compareSummary(t, summarise(vcs), before)
+
+	// as is data that isn't a state file at all
+	err = vcs.LoadState(bytes.NewReader([]byte("not a state file")))
+	if err == nil {
+		t.Errorf("expected error for invalid state")
+	}
+}
@@ -65,8 +65,9 @@ func (au *Audio) String() string {
 	return s.String()
 }
 
-// NewAudio is the preferred method of initialisation for the Video structure
-func NewAudio() *Audio {
+// NewAudio is the preferred method of initialisation for the Video structure.
+// The 9bit polynomial is initialised with numbers from the supplied source.
+func NewAudio(rnd *rand.Rand) *Audio {
 	au := &Audio{}
 	au.channel0.au = au
 	au.channel1.au = au
@@ -86,7 +87,7 @@ func NewAudio() *Audio {
 	// "Rather than have a table with 511 entries, I use a random number
 	// generator."
 	for i := 0; i < len(au.poly9bit); i++ {
-		au.poly9bit[i] = uint16(rand.Int() & 0x01)
+		au.poly9bit[i] = uint16(rnd.Int() & 0x01)
 	}
 
 	// from TIASound.c:
@@ -101,6 +102,49 @@ func NewAudio() *Audio {
 	return au
 }
 
+// Snapshot creates a copy of the audio sub-system in its current state
+func (au *Audio) Snapshot() *Audio {
+	n := *au
+	return &n
+}
+
+// Restore the audio sub-system from a snapshot
+func (au *Audio) Restore(s *Audio) {
+	*au = *s
+
+	// make sure channels are referring to the live instance of Audio
+	au.channel0.au = au
+	au.channel1.au = au
+}
+
+// SavedAudio is the state of the audio sub-system as returned by SaveState()
+type SavedAudio struct {
+	Clock114 int
+	Poly9bit [511]uint16
+	Channel0 SavedChannel
+	Channel1 SavedChannel
+}
+
+// SaveState returns the current state of the audio sub-system
+func (au *Audio) SaveState() SavedAudio {
+	return SavedAudio{
+		Clock114: au.clock114,
+		Poly9bit: au.poly9bit,
+		Channel0: au.channel0.saveState(),
+		Channel1: au.channel1.saveState(),
+	}
+}
+
+// LoadState sets the audio sub-system to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+func (au *Audio) LoadState(s SavedAudio) {
+	au.clock114 = s.Clock114
+	au.poly9bit = s.Poly9bit
+	au.channel0.loadState(s.Channel0)
+	au.channel1.loadState(s.Channel1)
+}
+
 // Mix the two VCS audio channels, returning a boolean indicating whether the
 // sound has been updated and a single value representing the mixed volume
 func (au *Audio) Mix() (bool, uint8) {
@@ -58,6 +58,48 @@ type channel struct {
 	actualVol uint8
 }
 
+// SavedChannel is the state of an audio channel as returned by saveState()
+type SavedChannel struct {
+	RegControl uint8
+	RegFreq    uint8
+	RegVolume  uint8
+	Poly4ct    int
+	Poly5ct    int
+	Poly9ct    int
+	FreqClk    uint8
+	Div3ct     uint8
+	AdjFreq    uint8
+	ActualVol  uint8
+}
+
+func (ch *channel) saveState() SavedChannel {
+	return SavedChannel{
+		RegControl: ch.regControl,
+		RegFreq:    ch.regFreq,
+		RegVolume:  ch.regVolume,
+		Poly4ct:    ch.poly4ct,
+		Poly5ct:    ch.poly5ct,
+		Poly9ct:    ch.poly9ct,
+		FreqClk:    ch.freqClk,
+		Div3ct:     ch.div3ct,
+		AdjFreq:    ch.adjFreq,
+		ActualVol:  ch.actualVol,
+	}
+}
+
+func (ch *channel) loadState(s SavedChannel) {
+	ch.regControl = s.RegControl
+	ch.regFreq = s.RegFreq
+	ch.regVolume = s.RegVolume
+	ch.poly4ct = s.Poly4ct
+	ch.poly5ct = s.Poly5ct
+	ch.poly9ct = s.Poly9ct
+	ch.freqClk = s.FreqClk
+	ch.div3ct = s.Div3ct
+	ch.adjFreq = s.AdjFreq
+	ch.actualVol = s.ActualVol
+}
+
 func (ch *channel) String() string {
 	s := strings.Builder{}
 	s.WriteString(fmt.Sprintf("%04b @ %05b ^ %04b", ch.regControl, ch.regFreq, ch.regVolume))
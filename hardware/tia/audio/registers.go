@@ -78,3 +78,13 @@ func (ch *channel) reactAUDCx() {
 		// ...otherwide let it complete the previous
 	}
 }
+
+// Registers returns the current values of the AUDCx, AUDFx and AUDVx
+// registers for the channel. Channel should be 0 or 1.
+func (au *Audio) Registers(channel int) (control uint8, freq uint8, volume uint8) {
+	ch := &au.channel0
+	if channel == 1 {
+		ch = &au.channel1
+	}
+	return ch.regControl, ch.regFreq, ch.regVolume
+}
@@ -15,6 +15,10 @@
 
 package delay
 
+import (
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
 // Event represents an event that will occur in the future
 type Event struct {
 	initial   int
@@ -25,14 +29,22 @@ type Event struct {
 	arg       interface{}
 }
 
+// Bind the payload function to the event. The function will run when the
+// event concludes. Bind should be called once, when the Event is created. The
+// payload does not change after that and is not part of the saved state of
+// the Event.
+func (e *Event) Bind(payload func(interface{})) {
+	e.payload = payload
+}
+
 // Schedule an event to occur in the future. The payload function will run
-// after delay number of cycles.
-func (e *Event) Schedule(delay int, payload func(interface{}), arg interface{}) {
+// after delay number of cycles, with arg as its argument. The argument must be
+// nil, an int or an uint8 if the Event is to be saved with SaveState().
+func (e *Event) Schedule(delay int, arg interface{}) {
 	e.initial = delay + 1
 	e.remaining = delay + 1
 	e.paused = false
 	e.pushed = false
-	e.payload = payload
 	e.arg = arg
 }
 
@@ -98,3 +110,68 @@ func (e *Event) AboutToEnd() bool {
 func (e *Event) IsActive() bool {
 	return e.remaining > 0
 }
+
+// the type of argument stored in SavedEvent
+const (
+	argNil int = iota
+	argInt
+	argUint8
+)
+
+// SavedEvent is the state of an Event as returned by SaveState(). The payload
+// function is not part of the saved state.
+type SavedEvent struct {
+	Initial   int
+	Remaining int
+	Paused    bool
+	Pushed    bool
+	ArgType   int
+	Arg       int
+}
+
+// SaveState returns the current state of the Event
+func (e *Event) SaveState() SavedEvent {
+	s := SavedEvent{
+		Initial:   e.initial,
+		Remaining: e.remaining,
+		Paused:    e.paused,
+		Pushed:    e.pushed,
+	}
+
+	switch arg := e.arg.(type) {
+	case nil:
+		s.ArgType = argNil
+	case int:
+		s.ArgType = argInt
+		s.Arg = arg
+	case uint8:
+		s.ArgType = argUint8
+		s.Arg = int(arg)
+	default:
+		panic("unsupported argument type for saved event")
+	}
+
+	return s
+}
+
+// LoadState sets the state of the Event to a state previously returned by
+// SaveState(). The bound payload is not changed.
+func (e *Event) LoadState(s SavedEvent) error {
+	switch s.ArgType {
+	case argNil:
+		e.arg = nil
+	case argInt:
+		e.arg = s.Arg
+	case argUint8:
+		e.arg = uint8(s.Arg)
+	default:
+		return errors.New(errors.SnapshotError, "unknown argument type for delayed event")
+	}
+
+	e.initial = s.Initial
+	e.remaining = s.Remaining
+	e.paused = s.Paused
+	e.pushed = s.Pushed
+
+	return nil
+}
@@ -92,6 +92,16 @@ func (pcnt *Polycounter) Count() int {
 	return pcnt.count
 }
 
+// SetCount sets the polycounter to the value returned by a previous call to
+// Count()
+func (pcnt *Polycounter) SetCount(count int) error {
+	if count < 0 || count >= len(pcnt.table) {
+		return errors.New(errors.PolycounterError, fmt.Sprintf("count out of range (%d)", count))
+	}
+	pcnt.count = count
+	return nil
+}
+
 // ToBinary returns the bit pattern of the current polycounter value
 func (pcnt *Polycounter) ToBinary() string {
 	return pcnt.table[pcnt.count]
@@ -17,6 +17,7 @@ package tia
 
 import (
 	"fmt"
+	"math/rand"
 	"strings"
 
 	"github.com/jetsetilly/gopher2600/errors"
@@ -110,7 +111,7 @@ func (tia TIA) String() string {
 }
 
 // NewTIA creates a TIA, to be used in a VCS emulation
-func NewTIA(tv television.Television, mem bus.ChipBus, vblankBits *input.VBlankBits) (*TIA, error) {
+func NewTIA(tv television.Television, mem bus.ChipBus, vblankBits *input.VBlankBits, rnd *rand.Rand) (*TIA, error) {
 	tia := TIA{
 		tv:         tv,
 		mem:        mem,
@@ -127,12 +128,19 @@ func NewTIA(tv television.Television, mem bus.ChipBus, vblankBits *input.VBlankB
 	tia.pclk.Reset()
 	tia.HmoveCt = 0xff
 
+	tia.futureVblank.Bind(tia._futureVblank)
+	tia.futureRsyncAlign.Bind(tia._futureRsyncAlign)
+	tia.futureRsyncReset.Bind(tia._futureRsyncReset)
+	tia.futureHmoveLatch.Bind(tia._futureHmoveLatch)
+	tia.FutureHmove.Bind(tia._futureHmove)
+	tia.futureHsync.Bind(tia._futureHsync)
+
 	tia.Video, err = video.NewVideo(mem, &tia.pclk, tia.hsync, tv, &tia.Hblank, &tia.HmoveLatch)
 	if err != nil {
 		return nil, err
 	}
 
-	tia.Audio = audio.NewAudio()
+	tia.Audio = audio.NewAudio(rnd)
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +148,132 @@ func NewTIA(tv television.Television, mem bus.ChipBus, vblankBits *input.VBlankB
 	return &tia, nil
 }
 
+// Snapshot creates a copy of the TIA in its current state. Pending events are
+// included in the snapshot. They remain bound to the live TIA so the snapshot
+// is only meaningful to the TIA instance that created it.
+func (tia *TIA) Snapshot() *TIA {
+	n := *tia
+	h := *tia.hsync
+	n.hsync = &h
+	n.Video = tia.Video.Snapshot()
+	n.Audio = tia.Audio.Snapshot()
+	return &n
+}
+
+// Restore the TIA from a snapshot created by Snapshot()
+func (tia *TIA) Restore(s *TIA) {
+	*tia.hsync = *s.hsync
+	tia.Video.Restore(s.Video)
+	tia.Audio.Restore(s.Audio)
+
+	tia.videoCycles = s.videoCycles
+	tia.sig = s.sig
+	tia.Hblank = s.Hblank
+	tia.wsync = s.wsync
+	tia.HmoveLatch = s.HmoveLatch
+	tia.HmoveCt = s.HmoveCt
+	tia.pclk = s.pclk
+	tia.futureVblank = s.futureVblank
+	tia.futureRsyncAlign = s.futureRsyncAlign
+	tia.futureRsyncReset = s.futureRsyncReset
+	tia.futureHmoveLatch = s.futureHmoveLatch
+	tia.FutureHmove = s.FutureHmove
+	tia.futureHsync = s.futureHsync
+}
+
+// the version of the SavedTIA type. increase this whenever the SavedTIA type,
+// or any of the types it contains, changes
+const savedTIAVersion = 1
+
+// SavedTIA is the state of the TIA as returned by SaveState()
+type SavedTIA struct {
+	Version          int
+	VideoCycles      int
+	Sig              television.SignalAttributes
+	Hblank           bool
+	Wsync            bool
+	HmoveLatch       bool
+	HmoveCt          uint8
+	Hsync            int
+	Pclk             phaseclock.PhaseClock
+	FutureVblank     delay.SavedEvent
+	FutureRsyncAlign delay.SavedEvent
+	FutureRsyncReset delay.SavedEvent
+	FutureHmoveLatch delay.SavedEvent
+	FutureHmove      delay.SavedEvent
+	FutureHsync      delay.SavedEvent
+	Video            video.SavedVideo
+	Audio            audio.SavedAudio
+}
+
+// SaveState returns the current state of the TIA
+func (tia *TIA) SaveState() SavedTIA {
+	return SavedTIA{
+		Version:          savedTIAVersion,
+		VideoCycles:      tia.videoCycles,
+		Sig:              tia.sig,
+		Hblank:           tia.Hblank,
+		Wsync:            tia.wsync,
+		HmoveLatch:       tia.HmoveLatch,
+		HmoveCt:          tia.HmoveCt,
+		Hsync:            tia.hsync.Count(),
+		Pclk:             tia.pclk,
+		FutureVblank:     tia.futureVblank.SaveState(),
+		FutureRsyncAlign: tia.futureRsyncAlign.SaveState(),
+		FutureRsyncReset: tia.futureRsyncReset.SaveState(),
+		FutureHmoveLatch: tia.futureHmoveLatch.SaveState(),
+		FutureHmove:      tia.FutureHmove.SaveState(),
+		FutureHsync:      tia.futureHsync.SaveState(),
+		Video:            tia.Video.SaveState(),
+		Audio:            tia.Audio.SaveState(),
+	}
+}
+
+// LoadState sets the TIA to a state previously returned by SaveState(). It
+// should only be called on a snapshot and never on the live emulation. Use
+// Restore() to apply the snapshot.
+func (tia *TIA) LoadState(s SavedTIA) error {
+	if s.Version != savedTIAVersion {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unsupported TIA state version (%d)", s.Version))
+	}
+
+	if err := tia.hsync.SetCount(s.Hsync); err != nil {
+		return err
+	}
+
+	events := []struct {
+		e *delay.Event
+		s delay.SavedEvent
+	}{
+		{e: &tia.futureVblank, s: s.FutureVblank},
+		{e: &tia.futureRsyncAlign, s: s.FutureRsyncAlign},
+		{e: &tia.futureRsyncReset, s: s.FutureRsyncReset},
+		{e: &tia.futureHmoveLatch, s: s.FutureHmoveLatch},
+		{e: &tia.FutureHmove, s: s.FutureHmove},
+		{e: &tia.futureHsync, s: s.FutureHsync},
+	}
+	for _, ev := range events {
+		if err := ev.e.LoadState(ev.s); err != nil {
+			return err
+		}
+	}
+
+	if err := tia.Video.LoadState(s.Video); err != nil {
+		return err
+	}
+	tia.Audio.LoadState(s.Audio)
+
+	tia.videoCycles = s.VideoCycles
+	tia.sig = s.Sig
+	tia.Hblank = s.Hblank
+	tia.wsync = s.Wsync
+	tia.HmoveLatch = s.HmoveLatch
+	tia.HmoveCt = s.HmoveCt
+	tia.pclk = s.Pclk
+
+	return nil
+}
+
 // UpdateTIA checks for side effects in the TIA sub-system.
 //
 // Returns true if ChipData has *not* been serviced.
@@ -152,16 +286,7 @@ func (tia *TIA) UpdateTIA(data bus.ChipData) bool {
 	case "VBLANK":
 		// homebrew Donkey Kong shows the need for a delay of at least one
 		// cycle for VBLANK. see area just before score box on play screen
-		tia.futureVblank.Schedule(1, func(v interface{}) {
-			// actual vblank signal
-			tia.sig.VBlank = v.(uint8)&0x02 == 0x02
-
-			// dump paddle capacitors to ground
-			tia.vblankBits.SetGroundPaddles(v.(uint8)&0x80 == 0x80)
-
-			// joystick fire button latches
-			tia.vblankBits.SetLatchFireButton(v.(uint8)&0x40 == 0x40)
-		}, data.Value)
+		tia.futureVblank.Schedule(1, data.Value)
 
 		return false
 
@@ -196,23 +321,8 @@ func (tia *TIA) UpdateTIA(data bus.ChipData) bool {
 		//
 		// * Test RSYNC - test rom by Omegamatrix
 
-		tia.futureRsyncAlign.Schedule(3, func(_ interface{}) {
-			tia.newScanline(nil)
-
-			// adjust video elements by the number of visible pixels that have
-			// been consumed. adding one to the value because the tv pixel we
-			// want to hit has not been reached just yet
-			adj, _ := tia.tv.GetState(television.ReqHorizPos)
-			adj++
-			if adj > 0 {
-				tia.Video.RSYNC(adj)
-			}
-		}, nil)
-
-		tia.futureRsyncReset.Schedule(7, func(_ interface{}) {
-			tia.hsync.Reset()
-			tia.pclk.Reset()
-		}, nil)
+		tia.futureRsyncAlign.Schedule(3, nil)
+		tia.futureRsyncReset.Schedule(7, nil)
 
 		// I've not test what happens if we reach hsync naturally while the
 		// above RSYNC delay is active.
@@ -244,14 +354,8 @@ func (tia *TIA) UpdateTIA(data bus.ChipData) bool {
 			delay = 2
 		}
 
-		tia.futureHmoveLatch.Schedule(delay, func(_ interface{}) {
-			tia.HmoveLatch = true
-		}, nil)
-
-		tia.FutureHmove.Schedule(delay+3, func(_ interface{}) {
-			tia.Video.PrepareSpritesForHMOVE()
-			tia.HmoveCt = 15
-		}, nil)
+		tia.futureHmoveLatch.Schedule(delay, nil)
+		tia.FutureHmove.Schedule(delay+3, nil)
 
 		// from TIA_HW_Notes:
 		//
@@ -274,7 +378,74 @@ func (tia *TIA) UpdateTIA(data bus.ChipData) bool {
 	return true
 }
 
-func (tia *TIA) newScanline(_ interface{}) {
+// payload for the futureVblank event
+func (tia *TIA) _futureVblank(v interface{}) {
+	// actual vblank signal
+	tia.sig.VBlank = v.(uint8)&0x02 == 0x02
+
+	// dump paddle capacitors to ground
+	tia.vblankBits.SetGroundPaddles(v.(uint8)&0x80 == 0x80)
+
+	// joystick fire button latches
+	tia.vblankBits.SetLatchFireButton(v.(uint8)&0x40 == 0x40)
+}
+
+// payload for the futureRsyncAlign event
+func (tia *TIA) _futureRsyncAlign(_ interface{}) {
+	tia.newScanline()
+
+	// adjust video elements by the number of visible pixels that have
+	// been consumed. adding one to the value because the tv pixel we
+	// want to hit has not been reached just yet
+	adj, _ := tia.tv.GetState(television.ReqHorizPos)
+	adj++
+	if adj > 0 {
+		tia.Video.RSYNC(adj)
+	}
+}
+
+// payload for the futureRsyncReset event
+func (tia *TIA) _futureRsyncReset(_ interface{}) {
+	tia.hsync.Reset()
+	tia.pclk.Reset()
+}
+
+// payload for the futureHmoveLatch event
+func (tia *TIA) _futureHmoveLatch(_ interface{}) {
+	tia.HmoveLatch = true
+}
+
+// payload for the FutureHmove event
+func (tia *TIA) _futureHmove(_ interface{}) {
+	tia.Video.PrepareSpritesForHMOVE()
+	tia.HmoveCt = 15
+}
+
+// the futureHsync event is used for several things. the argument to
+// Schedule() says which
+const (
+	hsyncNewScanline int = iota
+	hsyncResetHSync
+	hsyncResetCBurst
+	hsyncResetHBlank
+)
+
+// payload for the futureHsync event
+func (tia *TIA) _futureHsync(v interface{}) {
+	switch v.(int) {
+	case hsyncNewScanline:
+		tia.newScanline()
+	case hsyncResetHSync:
+		tia.sig.HSync = false
+		tia.sig.CBurst = true
+	case hsyncResetCBurst:
+		tia.sig.CBurst = false
+	case hsyncResetHBlank:
+		tia.Hblank = false
+	}
+}
+
+func (tia *TIA) newScanline() {
 	// the CPU's WSYNC concludes at the beginning of a scanline
 	// from the TIA_1A document:
 	//
@@ -371,7 +542,7 @@ func (tia *TIA) Step(readMemory bool) (bool, error) {
 			// allow a new scanline event to occur naturally only when an RSYNC
 			// has not been scheduled
 			if !tia.futureRsyncAlign.IsActive() {
-				tia.futureHsync.Schedule(hsyncDelay, tia.newScanline, nil)
+				tia.futureHsync.Schedule(hsyncDelay, hsyncNewScanline)
 			}
 
 		case 4: // [SHS]
@@ -384,16 +555,11 @@ func (tia *TIA) Step(readMemory bool) (bool, error) {
 
 		case 8: // [RHS]
 			// reset HSYNC
-			tia.futureHsync.Schedule(hsyncDelay, func(_ interface{}) {
-				tia.sig.HSync = false
-				tia.sig.CBurst = true
-			}, nil)
+			tia.futureHsync.Schedule(hsyncDelay, hsyncResetHSync)
 
 		case 12: // [RCB]
 			// reset color burst
-			tia.futureHsync.Schedule(hsyncDelay, func(_ interface{}) {
-				tia.sig.CBurst = false
-			}, nil)
+			tia.futureHsync.Schedule(hsyncDelay, hsyncResetCBurst)
 
 		// the two cases below handle the turning off of the hblank flag. from
 		// TIA_HW_Notes.txt:
@@ -415,9 +581,7 @@ func (tia *TIA) Step(readMemory bool) (bool, error) {
 		case 16: // [RHB]
 			// early HBLANK off if hmoveLatch is false
 			if !tia.HmoveLatch {
-				tia.futureHsync.Schedule(hsyncDelay, func(_ interface{}) {
-					tia.Hblank = false
-				}, nil)
+				tia.futureHsync.Schedule(hsyncDelay, hsyncResetHBlank)
 			}
 
 		// ... and "two counts of the HSync Counter" later ...
@@ -425,9 +589,7 @@ func (tia *TIA) Step(readMemory bool) (bool, error) {
 		case 18:
 			// late HBLANK off if hmoveLatch is true
 			if tia.HmoveLatch {
-				tia.futureHsync.Schedule(hsyncDelay, func(_ interface{}) {
-					tia.Hblank = false
-				}, nil)
+				tia.futureHsync.Schedule(hsyncDelay, hsyncResetHBlank)
 			}
 		}
 	}
@@ -110,6 +110,9 @@ func newBallSprite(label string, tv television.Television, hblank, hmoveLatch *b
 	bs.Enclockifier.size = &bs.Size
 	bs.position.Reset()
 
+	bs.futureReset.Bind(bs._futureResetPosition)
+	bs.futureStart.Bind(bs._futureStartDrawingEvent)
+
 	return &bs, nil
 }
 
@@ -181,6 +184,95 @@ func (bs ballSprite) String() string {
 	return s.String()
 }
 
+// snapshot creates a copy of the sprite. the position polycounter is copied
+// but all other pointers continue to point to the live emulation
+func (bs *ballSprite) snapshot() *ballSprite {
+	n := *bs
+	p := *bs.position
+	n.position = &p
+	return &n
+}
+
+// restore sprite from a snapshot. the position polycounter is updated in place
+func (bs *ballSprite) restore(s *ballSprite) {
+	p := bs.position
+	*bs = *s
+	*p = *s.position
+	bs.position = p
+}
+
+// SavedBall is the state of the ball sprite as returned by saveState()
+type SavedBall struct {
+	Position          int
+	Pclk              phaseclock.PhaseClock
+	MoreHMOVE         bool
+	Hmove             uint8
+	LastHmoveCt       uint8
+	ResetPixel        int
+	HmovedPixel       int
+	LastTickFromHmove bool
+	Color             uint8
+	Ctrlpf            uint8
+	Size              uint8
+	VerticalDelay     bool
+	Enabled           bool
+	EnabledDelay      bool
+	FutureReset       delay.SavedEvent
+	FutureStart       delay.SavedEvent
+	Enclockifier      SavedEnclockifier
+}
+
+func (bs *ballSprite) saveState() SavedBall {
+	return SavedBall{
+		Position:          bs.position.Count(),
+		Pclk:              bs.pclk,
+		MoreHMOVE:         bs.MoreHMOVE,
+		Hmove:             bs.Hmove,
+		LastHmoveCt:       bs.lastHmoveCt,
+		ResetPixel:        bs.ResetPixel,
+		HmovedPixel:       bs.HmovedPixel,
+		LastTickFromHmove: bs.lastTickFromHmove,
+		Color:             bs.Color,
+		Ctrlpf:            bs.Ctrlpf,
+		Size:              bs.Size,
+		VerticalDelay:     bs.VerticalDelay,
+		Enabled:           bs.Enabled,
+		EnabledDelay:      bs.EnabledDelay,
+		FutureReset:       bs.futureReset.SaveState(),
+		FutureStart:       bs.futureStart.SaveState(),
+		Enclockifier:      bs.Enclockifier.saveState(),
+	}
+}
+
+func (bs *ballSprite) loadState(s SavedBall) error {
+	if err := bs.position.SetCount(s.Position); err != nil {
+		return err
+	}
+	if err := bs.futureReset.LoadState(s.FutureReset); err != nil {
+		return err
+	}
+	if err := bs.futureStart.LoadState(s.FutureStart); err != nil {
+		return err
+	}
+
+	bs.pclk = s.Pclk
+	bs.MoreHMOVE = s.MoreHMOVE
+	bs.Hmove = s.Hmove
+	bs.lastHmoveCt = s.LastHmoveCt
+	bs.ResetPixel = s.ResetPixel
+	bs.HmovedPixel = s.HmovedPixel
+	bs.lastTickFromHmove = s.LastTickFromHmove
+	bs.Color = s.Color
+	bs.Ctrlpf = s.Ctrlpf
+	bs.Size = s.Size
+	bs.VerticalDelay = s.VerticalDelay
+	bs.Enabled = s.Enabled
+	bs.EnabledDelay = s.EnabledDelay
+	bs.Enclockifier.loadState(s.Enclockifier)
+
+	return nil
+}
+
 func (bs *ballSprite) rsync(adjustment int) {
 	bs.ResetPixel -= adjustment
 	bs.HmovedPixel -= adjustment
@@ -226,7 +318,7 @@ func (bs *ballSprite) tick(visible, isHmove bool, hmoveCt uint8) bool {
 
 		switch bs.position.Count() {
 		case 39:
-			bs.futureStart.Schedule(4, bs._futureStartDrawingEvent, nil)
+			bs.futureStart.Schedule(4, nil)
 		case 40:
 			bs.position.Reset()
 		}
@@ -288,7 +380,7 @@ func (bs *ballSprite) resetPosition() {
 		return
 	}
 
-	bs.futureReset.Schedule(delay, bs._futureResetPosition, nil)
+	bs.futureReset.Schedule(delay, nil)
 }
 
 func (bs *ballSprite) _futureResetPosition(_ interface{}) {
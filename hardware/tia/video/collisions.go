@@ -46,6 +46,56 @@ type Collisions struct {
 	Activity strings.Builder
 }
 
+// restore collision registers from another instance of Collisions. the
+// Activity field is not copied
+func (col *Collisions) restore(s *Collisions) {
+	col.CXM0P = s.CXM0P
+	col.CXM1P = s.CXM1P
+	col.CXP0FB = s.CXP0FB
+	col.CXP1FB = s.CXP1FB
+	col.CXM0FB = s.CXM0FB
+	col.CXM1FB = s.CXM1FB
+	col.CXBLPF = s.CXBLPF
+	col.CXPPMM = s.CXPPMM
+}
+
+// SavedCollisions is the state of the collision registers as returned by
+// saveState()
+type SavedCollisions struct {
+	CXM0P  uint8
+	CXM1P  uint8
+	CXP0FB uint8
+	CXP1FB uint8
+	CXM0FB uint8
+	CXM1FB uint8
+	CXBLPF uint8
+	CXPPMM uint8
+}
+
+func (col *Collisions) saveState() SavedCollisions {
+	return SavedCollisions{
+		CXM0P:  col.CXM0P,
+		CXM1P:  col.CXM1P,
+		CXP0FB: col.CXP0FB,
+		CXP1FB: col.CXP1FB,
+		CXM0FB: col.CXM0FB,
+		CXM1FB: col.CXM1FB,
+		CXBLPF: col.CXBLPF,
+		CXPPMM: col.CXPPMM,
+	}
+}
+
+func (col *Collisions) loadState(s SavedCollisions) {
+	col.CXM0P = s.CXM0P
+	col.CXM1P = s.CXM1P
+	col.CXP0FB = s.CXP0FB
+	col.CXP1FB = s.CXP1FB
+	col.CXM0FB = s.CXM0FB
+	col.CXM1FB = s.CXM1FB
+	col.CXBLPF = s.CXBLPF
+	col.CXPPMM = s.CXPPMM
+}
+
 func newCollisions(mem bus.ChipBus) *Collisions {
 	col := &Collisions{mem: mem}
 	col.Clear()
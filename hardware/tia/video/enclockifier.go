@@ -47,6 +47,34 @@ type enclockifier struct {
 	size *uint8
 }
 
+// SavedEnclockifier is the state of an enclockifier as returned by
+// saveState()
+type SavedEnclockifier struct {
+	Active     bool
+	SecondHalf bool
+	Ticks      int
+	Paused     bool
+	Cpy        int
+}
+
+func (en *enclockifier) saveState() SavedEnclockifier {
+	return SavedEnclockifier{
+		Active:     en.Active,
+		SecondHalf: en.SecondHalf,
+		Ticks:      en.Ticks,
+		Paused:     en.Paused,
+		Cpy:        en.Cpy,
+	}
+}
+
+func (en *enclockifier) loadState(s SavedEnclockifier) {
+	en.Active = s.Active
+	en.SecondHalf = s.SecondHalf
+	en.Ticks = s.Ticks
+	en.Paused = s.Paused
+	en.Cpy = s.Cpy
+}
+
 func (en *enclockifier) String() string {
 	s := strings.Builder{}
 	if en.Active {
@@ -133,6 +133,9 @@ func newMissileSprite(label string, tv television.Television, hblank, hmoveLatch
 	ms.Enclockifier.size = &ms.Size
 	ms.position.Reset()
 
+	ms.futureReset.Bind(ms._futureResetPosition)
+	ms.futureStart.Bind(ms._futureStartDrawingEvent)
+
 	return &ms, nil
 
 }
@@ -231,6 +234,95 @@ func (ms missileSprite) String() string {
 	return s.String()
 }
 
+// snapshot creates a copy of the sprite. the position polycounter is copied
+// but all other pointers continue to point to the live emulation
+func (ms *missileSprite) snapshot() *missileSprite {
+	n := *ms
+	p := *ms.position
+	n.position = &p
+	return &n
+}
+
+// restore sprite from a snapshot. the position polycounter is updated in place
+func (ms *missileSprite) restore(s *missileSprite) {
+	p := ms.position
+	*ms = *s
+	*p = *s.position
+	ms.position = p
+}
+
+// SavedMissile is the state of a missile sprite as returned by saveState()
+type SavedMissile struct {
+	Position          int
+	Pclk              phaseclock.PhaseClock
+	MoreHMOVE         bool
+	Hmove             uint8
+	LastHmoveCt       uint8
+	ResetPixel        int
+	HmovedPixel       int
+	LastTickFromHmove bool
+	Color             uint8
+	Enabled           bool
+	ResetToPlayer     bool
+	Nusiz             uint8
+	Size              uint8
+	Copies            uint8
+	FutureReset       delay.SavedEvent
+	FutureStart       delay.SavedEvent
+	Enclockifier      SavedEnclockifier
+}
+
+func (ms *missileSprite) saveState() SavedMissile {
+	return SavedMissile{
+		Position:          ms.position.Count(),
+		Pclk:              ms.pclk,
+		MoreHMOVE:         ms.MoreHMOVE,
+		Hmove:             ms.Hmove,
+		LastHmoveCt:       ms.lastHmoveCt,
+		ResetPixel:        ms.ResetPixel,
+		HmovedPixel:       ms.HmovedPixel,
+		LastTickFromHmove: ms.lastTickFromHmove,
+		Color:             ms.Color,
+		Enabled:           ms.Enabled,
+		ResetToPlayer:     ms.ResetToPlayer,
+		Nusiz:             ms.Nusiz,
+		Size:              ms.Size,
+		Copies:            ms.Copies,
+		FutureReset:       ms.futureReset.SaveState(),
+		FutureStart:       ms.futureStart.SaveState(),
+		Enclockifier:      ms.Enclockifier.saveState(),
+	}
+}
+
+func (ms *missileSprite) loadState(s SavedMissile) error {
+	if err := ms.position.SetCount(s.Position); err != nil {
+		return err
+	}
+	if err := ms.futureReset.LoadState(s.FutureReset); err != nil {
+		return err
+	}
+	if err := ms.futureStart.LoadState(s.FutureStart); err != nil {
+		return err
+	}
+
+	ms.pclk = s.Pclk
+	ms.MoreHMOVE = s.MoreHMOVE
+	ms.Hmove = s.Hmove
+	ms.lastHmoveCt = s.LastHmoveCt
+	ms.ResetPixel = s.ResetPixel
+	ms.HmovedPixel = s.HmovedPixel
+	ms.lastTickFromHmove = s.LastTickFromHmove
+	ms.Color = s.Color
+	ms.Enabled = s.Enabled
+	ms.ResetToPlayer = s.ResetToPlayer
+	ms.Nusiz = s.Nusiz
+	ms.Size = s.Size
+	ms.Copies = s.Copies
+	ms.Enclockifier.loadState(s.Enclockifier)
+
+	return nil
+}
+
 func (ms *missileSprite) rsync(adjustment int) {
 	ms.ResetPixel -= adjustment
 	ms.HmovedPixel -= adjustment
@@ -308,7 +400,7 @@ func (ms *missileSprite) tick(visible, isHmove bool, hmoveCt uint8) bool {
 			switch ms.position.Count() {
 			case 3:
 				if ms.Copies == 0x01 || ms.Copies == 0x03 {
-					ms.futureStart.Schedule(4, ms._futureStartDrawingEvent, 1)
+					ms.futureStart.Schedule(4, 1)
 				}
 			case 7:
 				if ms.Copies == 0x03 || ms.Copies == 0x02 || ms.Copies == 0x06 {
@@ -316,7 +408,7 @@ func (ms *missileSprite) tick(visible, isHmove bool, hmoveCt uint8) bool {
 					if ms.Copies == 0x03 {
 						cpy = 2
 					}
-					ms.futureStart.Schedule(4, ms._futureStartDrawingEvent, cpy)
+					ms.futureStart.Schedule(4, cpy)
 				}
 			case 15:
 				if ms.Copies == 0x04 || ms.Copies == 0x06 {
@@ -324,10 +416,10 @@ func (ms *missileSprite) tick(visible, isHmove bool, hmoveCt uint8) bool {
 					if ms.Copies == 0x06 {
 						cpy = 2
 					}
-					ms.futureStart.Schedule(4, ms._futureStartDrawingEvent, cpy)
+					ms.futureStart.Schedule(4, cpy)
 				}
 			case 39:
-				ms.futureStart.Schedule(4, ms._futureStartDrawingEvent, 0)
+				ms.futureStart.Schedule(4, 0)
 			case 40:
 				ms.position.Reset()
 			}
@@ -401,7 +493,7 @@ func (ms *missileSprite) resetPosition() {
 		return
 	}
 
-	ms.futureReset.Schedule(delay, ms._futureResetPosition, nil)
+	ms.futureReset.Schedule(delay, nil)
 }
 
 func (ms *missileSprite) _futureResetPosition(_ interface{}) {
@@ -179,6 +179,10 @@ func newPlayerSprite(label string, tv television.Television, hblank, hmoveLatch
 	// initialise gfxData pointer
 	ps.gfxData = &ps.GfxDataNew
 
+	ps.futureReset.Bind(ps._futureResetPosition)
+	ps.futureStart.Bind(ps._futureStartDrawingEvent)
+	ps.futureSetNUSIZ.Bind(ps._futureSetNUSIZ)
+
 	return &ps, nil
 }
 
@@ -290,6 +294,114 @@ func (ps playerSprite) String() string {
 	return s.String()
 }
 
+// snapshot creates a copy of the sprite. the position polycounter is copied
+// but all other pointers continue to point to the live emulation
+func (ps *playerSprite) snapshot() *playerSprite {
+	n := *ps
+	p := *ps.position
+	n.position = &p
+	return &n
+}
+
+// restore sprite from a snapshot. the position polycounter is updated in place
+func (ps *playerSprite) restore(s *playerSprite) {
+	p := ps.position
+	*ps = *s
+	*p = *s.position
+	ps.position = p
+
+	// the gfxData pointer in the snapshot refers to a field in the snapshot
+	// and not the live sprite
+	if ps.VerticalDelay {
+		ps.gfxData = &ps.GfxDataOld
+	} else {
+		ps.gfxData = &ps.GfxDataNew
+	}
+}
+
+// SavedPlayer is the state of a player sprite as returned by saveState()
+type SavedPlayer struct {
+	Position       int
+	Pclk           phaseclock.PhaseClock
+	MoreHMOVE      bool
+	Hmove          uint8
+	LastHmoveCt    uint8
+	ResetPixel     int
+	HmovedPixel    int
+	Color          uint8
+	Reflected      bool
+	VerticalDelay  bool
+	GfxDataNew     uint8
+	GfxDataOld     uint8
+	Nusiz          uint8
+	SizeAndCopies  uint8
+	FutureReset    delay.SavedEvent
+	FutureStart    delay.SavedEvent
+	FutureSetNUSIZ delay.SavedEvent
+	ScanCounter    SavedScanCounter
+}
+
+func (ps *playerSprite) saveState() SavedPlayer {
+	return SavedPlayer{
+		Position:       ps.position.Count(),
+		Pclk:           ps.pclk,
+		MoreHMOVE:      ps.MoreHMOVE,
+		Hmove:          ps.Hmove,
+		LastHmoveCt:    ps.lastHmoveCt,
+		ResetPixel:     ps.ResetPixel,
+		HmovedPixel:    ps.HmovedPixel,
+		Color:          ps.Color,
+		Reflected:      ps.Reflected,
+		VerticalDelay:  ps.VerticalDelay,
+		GfxDataNew:     ps.GfxDataNew,
+		GfxDataOld:     ps.GfxDataOld,
+		Nusiz:          ps.Nusiz,
+		SizeAndCopies:  ps.SizeAndCopies,
+		FutureReset:    ps.futureReset.SaveState(),
+		FutureStart:    ps.futureStart.SaveState(),
+		FutureSetNUSIZ: ps.futureSetNUSIZ.SaveState(),
+		ScanCounter:    ps.ScanCounter.saveState(),
+	}
+}
+
+func (ps *playerSprite) loadState(s SavedPlayer) error {
+	if err := ps.position.SetCount(s.Position); err != nil {
+		return err
+	}
+	if err := ps.futureReset.LoadState(s.FutureReset); err != nil {
+		return err
+	}
+	if err := ps.futureStart.LoadState(s.FutureStart); err != nil {
+		return err
+	}
+	if err := ps.futureSetNUSIZ.LoadState(s.FutureSetNUSIZ); err != nil {
+		return err
+	}
+
+	ps.pclk = s.Pclk
+	ps.MoreHMOVE = s.MoreHMOVE
+	ps.Hmove = s.Hmove
+	ps.lastHmoveCt = s.LastHmoveCt
+	ps.ResetPixel = s.ResetPixel
+	ps.HmovedPixel = s.HmovedPixel
+	ps.Color = s.Color
+	ps.Reflected = s.Reflected
+	ps.VerticalDelay = s.VerticalDelay
+	ps.GfxDataNew = s.GfxDataNew
+	ps.GfxDataOld = s.GfxDataOld
+	ps.Nusiz = s.Nusiz
+	ps.SizeAndCopies = s.SizeAndCopies
+	ps.ScanCounter.loadState(s.ScanCounter)
+
+	if ps.VerticalDelay {
+		ps.gfxData = &ps.GfxDataOld
+	} else {
+		ps.gfxData = &ps.GfxDataNew
+	}
+
+	return nil
+}
+
 func (ps *playerSprite) rsync(adjustment int) {
 	ps.ResetPixel -= adjustment
 	ps.HmovedPixel -= adjustment
@@ -375,7 +487,7 @@ func (ps *playerSprite) tick(visible, isHmove bool, hmoveCt uint8) bool {
 			switch ps.position.Count() {
 			case 3:
 				if ps.SizeAndCopies == 0x01 || ps.SizeAndCopies == 0x03 {
-					ps.futureStart.Schedule(4, ps._futureStartDrawingEvent, 1)
+					ps.futureStart.Schedule(4, 1)
 				}
 			case 7:
 				if ps.SizeAndCopies == 0x03 || ps.SizeAndCopies == 0x02 || ps.SizeAndCopies == 0x06 {
@@ -383,7 +495,7 @@ func (ps *playerSprite) tick(visible, isHmove bool, hmoveCt uint8) bool {
 					if ps.SizeAndCopies == 0x03 {
 						cpy = 2
 					}
-					ps.futureStart.Schedule(4, ps._futureStartDrawingEvent, cpy)
+					ps.futureStart.Schedule(4, cpy)
 				}
 			case 15:
 				if ps.SizeAndCopies == 0x04 || ps.SizeAndCopies == 0x06 {
@@ -391,10 +503,10 @@ func (ps *playerSprite) tick(visible, isHmove bool, hmoveCt uint8) bool {
 					if ps.SizeAndCopies == 0x06 {
 						cpy = 2
 					}
-					ps.futureStart.Schedule(4, ps._futureStartDrawingEvent, cpy)
+					ps.futureStart.Schedule(4, cpy)
 				}
 			case 39:
-				ps.futureStart.Schedule(4, ps._futureStartDrawingEvent, 0)
+				ps.futureStart.Schedule(4, 0)
 
 			case 40:
 				ps.position.Reset()
@@ -490,7 +602,7 @@ func (ps *playerSprite) resetPosition() {
 		return
 	}
 
-	ps.futureReset.Schedule(delay, ps._futureResetPosition, nil)
+	ps.futureReset.Schedule(delay, nil)
 }
 
 func (ps *playerSprite) _futureResetPosition(_ interface{}) {
@@ -687,7 +799,7 @@ func (ps *playerSprite) setNUSIZ(value uint8) {
 	}
 
 	if delay >= 0 {
-		ps.futureSetNUSIZ.Schedule(delay, ps._futureSetNUSIZ, value)
+		ps.futureSetNUSIZ.Schedule(delay, value)
 	} else {
 		ps.SetNUSIZ(value)
 	}
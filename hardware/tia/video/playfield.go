@@ -77,6 +77,57 @@ func newPlayfield(pclk *phaseclock.PhaseClock, hsync *polycounter.Polycounter) *
 	return &pf
 }
 
+// SavedPlayfield is the state of the playfield as returned by saveState()
+type SavedPlayfield struct {
+	ForegroundColor  uint8
+	BackgroundColor  uint8
+	Data             [20]bool
+	PF0              uint8
+	PF1              uint8
+	PF2              uint8
+	Ctrlpf           uint8
+	Reflected        bool
+	Priority         bool
+	Scoremode        bool
+	Region           ScreenRegion
+	Idx              int
+	CurrentPixelIsOn bool
+}
+
+func (pf *playfield) saveState() SavedPlayfield {
+	return SavedPlayfield{
+		ForegroundColor:  pf.ForegroundColor,
+		BackgroundColor:  pf.BackgroundColor,
+		Data:             pf.Data,
+		PF0:              pf.PF0,
+		PF1:              pf.PF1,
+		PF2:              pf.PF2,
+		Ctrlpf:           pf.Ctrlpf,
+		Reflected:        pf.Reflected,
+		Priority:         pf.Priority,
+		Scoremode:        pf.Scoremode,
+		Region:           pf.Region,
+		Idx:              pf.Idx,
+		CurrentPixelIsOn: pf.currentPixelIsOn,
+	}
+}
+
+func (pf *playfield) loadState(s SavedPlayfield) {
+	pf.ForegroundColor = s.ForegroundColor
+	pf.BackgroundColor = s.BackgroundColor
+	pf.Data = s.Data
+	pf.PF0 = s.PF0
+	pf.PF1 = s.PF1
+	pf.PF2 = s.PF2
+	pf.Ctrlpf = s.Ctrlpf
+	pf.Reflected = s.Reflected
+	pf.Priority = s.Priority
+	pf.Scoremode = s.Scoremode
+	pf.Region = s.Region
+	pf.Idx = s.Idx
+	pf.currentPixelIsOn = s.CurrentPixelIsOn
+}
+
 func (pf playfield) Label() string {
 	return "Playfield"
 }
@@ -54,6 +54,33 @@ type scanCounter struct {
 	Cpy int
 }
 
+// SavedScanCounter is the state of a scanCounter as returned by saveState()
+type SavedScanCounter struct {
+	LatchedSizeAndCopies uint8
+	Latch                int
+	Pixel                int
+	Count                int
+	Cpy                  int
+}
+
+func (sc *scanCounter) saveState() SavedScanCounter {
+	return SavedScanCounter{
+		LatchedSizeAndCopies: sc.LatchedSizeAndCopies,
+		Latch:                sc.latch,
+		Pixel:                sc.Pixel,
+		Count:                sc.count,
+		Cpy:                  sc.Cpy,
+	}
+}
+
+func (sc *scanCounter) loadState(s SavedScanCounter) {
+	sc.LatchedSizeAndCopies = s.LatchedSizeAndCopies
+	sc.latch = s.Latch
+	sc.Pixel = s.Pixel
+	sc.count = s.Count
+	sc.Cpy = s.Cpy
+}
+
 func (sc *scanCounter) start() {
 	if sc.LatchedSizeAndCopies == 0x05 || sc.LatchedSizeAndCopies == 0x07 {
 		sc.latch = 2
@@ -148,9 +148,165 @@ func NewVideo(mem bus.ChipBus,
 	vd.Missile0.parentPlayer = vd.Player0
 	vd.Missile1.parentPlayer = vd.Player1
 
+	vd.writing.Bind(vd._writing)
+
 	return vd, nil
 }
 
+// the writing event is used for several registers. the argument to
+// Schedule() is the register being written to, ORed with the value being
+// written
+const (
+	writePF0 int = (iota + 1) << 8
+	writePF1
+	writePF2
+	writeHMP0
+	writeHMP1
+	writeHMM0
+	writeHMM1
+	writeHMBL
+	writeHMCLR
+)
+
+// payload for the writing event
+func (vd *Video) _writing(v interface{}) {
+	reg := v.(int) &^ 0xff
+	value := uint8(v.(int))
+
+	switch reg {
+	case writePF0:
+		vd.Playfield.setPF0(value)
+	case writePF1:
+		vd.Playfield.setPF1(value)
+	case writePF2:
+		vd.Playfield.setPF2(value)
+	case writeHMP0:
+		vd.Player0.setHmoveValue(value)
+	case writeHMP1:
+		vd.Player1.setHmoveValue(value)
+	case writeHMM0:
+		vd.Missile0.setHmoveValue(value)
+	case writeHMM1:
+		vd.Missile1.setHmoveValue(value)
+	case writeHMBL:
+		vd.Ball.setHmoveValue(value)
+	case writeHMCLR:
+		vd.Player0.clearHmoveValue()
+		vd.Player1.clearHmoveValue()
+		vd.Missile0.clearHmoveValue()
+		vd.Missile1.clearHmoveValue()
+		vd.Ball.clearHmoveValue()
+	}
+}
+
+// Snapshot creates a copy of the video sub-system in its current state
+func (vd *Video) Snapshot() *Video {
+	n := *vd
+
+	n.Collisions = &Collisions{}
+	n.Collisions.restore(vd.Collisions)
+
+	pf := *vd.Playfield
+	n.Playfield = &pf
+
+	n.Player0 = vd.Player0.snapshot()
+	n.Player1 = vd.Player1.snapshot()
+	n.Missile0 = vd.Missile0.snapshot()
+	n.Missile1 = vd.Missile1.snapshot()
+	n.Ball = vd.Ball.snapshot()
+
+	return &n
+}
+
+// Restore the video sub-system from a snapshot. Components are updated in
+// place so that references held elsewhere remain valid
+func (vd *Video) Restore(s *Video) {
+	vd.Collisions.restore(s.Collisions)
+	*vd.Playfield = *s.Playfield
+	vd.Player0.restore(s.Player0)
+	vd.Player1.restore(s.Player1)
+	vd.Missile0.restore(s.Missile0)
+	vd.Missile1.restore(s.Missile1)
+	vd.Ball.restore(s.Ball)
+
+	vd.LastElement = s.LastElement
+	vd.spriteHasChanged = s.spriteHasChanged
+	vd.lastPlayfieldActive = s.lastPlayfieldActive
+	vd.lastPixelColor = s.lastPixelColor
+	vd.Unchanged = s.Unchanged
+	vd.writing = s.writing
+}
+
+// SavedVideo is the state of the video sub-system as returned by SaveState()
+type SavedVideo struct {
+	Collisions          SavedCollisions
+	Playfield           SavedPlayfield
+	Player0             SavedPlayer
+	Player1             SavedPlayer
+	Missile0            SavedMissile
+	Missile1            SavedMissile
+	Ball                SavedBall
+	LastElement         Element
+	SpriteHasChanged    bool
+	LastPlayfieldActive bool
+	LastPixelColor      uint8
+	Unchanged           bool
+	Writing             delay.SavedEvent
+}
+
+// SaveState returns the current state of the video sub-system
+func (vd *Video) SaveState() SavedVideo {
+	return SavedVideo{
+		Collisions:          vd.Collisions.saveState(),
+		Playfield:           vd.Playfield.saveState(),
+		Player0:             vd.Player0.saveState(),
+		Player1:             vd.Player1.saveState(),
+		Missile0:            vd.Missile0.saveState(),
+		Missile1:            vd.Missile1.saveState(),
+		Ball:                vd.Ball.saveState(),
+		LastElement:         vd.LastElement,
+		SpriteHasChanged:    vd.spriteHasChanged,
+		LastPlayfieldActive: vd.lastPlayfieldActive,
+		LastPixelColor:      vd.lastPixelColor,
+		Unchanged:           vd.Unchanged,
+		Writing:             vd.writing.SaveState(),
+	}
+}
+
+// LoadState sets the video sub-system to a state previously returned by
+// SaveState(). It should only be called on a snapshot and never on the live
+// emulation. Use Restore() to apply the snapshot.
+func (vd *Video) LoadState(s SavedVideo) error {
+	if err := vd.Player0.loadState(s.Player0); err != nil {
+		return err
+	}
+	if err := vd.Player1.loadState(s.Player1); err != nil {
+		return err
+	}
+	if err := vd.Missile0.loadState(s.Missile0); err != nil {
+		return err
+	}
+	if err := vd.Missile1.loadState(s.Missile1); err != nil {
+		return err
+	}
+	if err := vd.Ball.loadState(s.Ball); err != nil {
+		return err
+	}
+	if err := vd.writing.LoadState(s.Writing); err != nil {
+		return err
+	}
+
+	vd.Collisions.loadState(s.Collisions)
+	vd.Playfield.loadState(s.Playfield)
+	vd.LastElement = s.LastElement
+	vd.spriteHasChanged = s.SpriteHasChanged
+	vd.lastPlayfieldActive = s.LastPlayfieldActive
+	vd.lastPixelColor = s.LastPixelColor
+	vd.Unchanged = s.Unchanged
+
+	return nil
+}
+
 // RSYNC adjusts the debugging information of the sprites when an RSYNC is
 // triggered
 func (vd *Video) RSYNC(adjustment int) {
@@ -330,11 +486,11 @@ func (vd *Video) UpdatePlayfield(data bus.ChipData) bool {
 	// to write new playfield data
 	switch data.Name {
 	case "PF0":
-		vd.writing.Schedule(2, vd.Playfield.setPF0, data.Value)
+		vd.writing.Schedule(2, writePF0|int(data.Value))
 	case "PF1":
-		vd.writing.Schedule(2, vd.Playfield.setPF1, data.Value)
+		vd.writing.Schedule(2, writePF1|int(data.Value))
 	case "PF2":
-		vd.writing.Schedule(2, vd.Playfield.setPF2, data.Value)
+		vd.writing.Schedule(2, writePF2|int(data.Value))
 	case "VDELBL":
 		vd.spriteHasChanged = true
 		vd.Ball.setVerticalDelay(data.Value&0x01 == 0x01)
@@ -382,23 +538,17 @@ func (vd *Video) UpdateSpriteHMOVE(data bus.ChipData) bool {
 	// the only common value that satisfies all test cases is 1, which equates
 	// to a delay of two cycles
 	case "HMP0":
-		vd.writing.Schedule(1, vd.Player0.setHmoveValue, data.Value&0xf0)
+		vd.writing.Schedule(1, writeHMP0|int(data.Value&0xf0))
 	case "HMP1":
-		vd.writing.Schedule(1, vd.Player1.setHmoveValue, data.Value&0xf0)
+		vd.writing.Schedule(1, writeHMP1|int(data.Value&0xf0))
 	case "HMM0":
-		vd.writing.Schedule(1, vd.Missile0.setHmoveValue, data.Value&0xf0)
+		vd.writing.Schedule(1, writeHMM0|int(data.Value&0xf0))
 	case "HMM1":
-		vd.writing.Schedule(1, vd.Missile1.setHmoveValue, data.Value&0xf0)
+		vd.writing.Schedule(1, writeHMM1|int(data.Value&0xf0))
 	case "HMBL":
-		vd.writing.Schedule(1, vd.Ball.setHmoveValue, data.Value&0xf0)
+		vd.writing.Schedule(1, writeHMBL|int(data.Value&0xf0))
 	case "HMCLR":
-		vd.writing.Schedule(1, func(_ interface{}) {
-			vd.Player0.clearHmoveValue()
-			vd.Player1.clearHmoveValue()
-			vd.Missile0.clearHmoveValue()
-			vd.Missile1.clearHmoveValue()
-			vd.Ball.clearHmoveValue()
-		}, nil)
+		vd.writing.Schedule(1, writeHMCLR)
 
 	default:
 		return true
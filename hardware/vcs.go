@@ -16,6 +16,9 @@
 package hardware
 
 import (
+	"math/rand"
+	"time"
+
 	"github.com/jetsetilly/gopher2600/cartridgeloader"
 	"github.com/jetsetilly/gopher2600/hardware/cpu"
 	"github.com/jetsetilly/gopher2600/hardware/memory"
@@ -48,26 +51,45 @@ type VCS struct {
 // used for all aspects of emulation: debugging sessions, and regular play
 // !!TODO: option for random state on VCS creation
 func NewVCS(tv television.Television) (*VCS, error) {
+	return NewVCSWithSeed(tv, time.Now().UnixNano())
+}
+
+// NewVCSWithSeed is the same as NewVCS() except that the random number source
+// used by the VCS is seeded with the specified value. Each VCS has its own
+// source so the sequence of random numbers is not affected by any other VCS,
+// making emulation determinate for a given seed. Useful for regression tests.
+func NewVCSWithSeed(tv television.Television, seed int64) (*VCS, error) {
 	var err error
 
 	vcs := &VCS{TV: tv}
 
+	rnd := rand.New(rand.NewSource(seed))
+
 	vcs.Mem, err = memory.NewVCSMemory()
 	if err != nil {
 		return nil, err
 	}
+	vcs.Mem.SetRandSource(rnd)
 
 	vcs.CPU, err = cpu.NewCPU(vcs.Mem)
 	if err != nil {
 		return nil, err
 	}
 
+	// the CPU is initialised in a random state by NewCPU(). reset it again so
+	// that the state is taken from the random source of the VCS
+	vcs.CPU.SetRandSource(rnd)
+	err = vcs.CPU.Reset(true)
+	if err != nil {
+		return nil, err
+	}
+
 	vcs.RIOT, err = riot.NewRIOT(vcs.Mem.RIOT, vcs.Mem.TIA)
 	if err != nil {
 		return nil, err
 	}
 
-	vcs.TIA, err = tia.NewTIA(vcs.TV, vcs.Mem.TIA, &vcs.RIOT.Input.VBlankBits)
+	vcs.TIA, err = tia.NewTIA(vcs.TV, vcs.Mem.TIA, &vcs.RIOT.Input.VBlankBits, rnd)
 	if err != nil {
 		return nil, err
 	}
@@ -18,6 +18,7 @@ package logger
 import (
 	"fmt"
 	"io"
+	"sync"
 )
 
 type Entry struct {
@@ -26,6 +27,9 @@ type Entry struct {
 }
 
 type logger struct {
+	// the log may be added to from more than one goroutine. for example,
+	// when running regression tests in parallel
+	crit    sync.Mutex
 	entries []Entry
 }
 
@@ -45,16 +49,22 @@ func init() {
 
 // Log adds an entry to the central logger
 func Log(tag, detail string) {
+	central.crit.Lock()
+	defer central.crit.Unlock()
 	central.entries = append(central.entries, Entry{tag: tag, detail: detail})
 }
 
 // Clear all entries from central logger
 func Clear() {
+	central.crit.Lock()
+	defer central.crit.Unlock()
 	central.entries = central.entries[:0]
 }
 
 // Write contents of central logger to io.Writer
 func Write(output io.Writer) bool {
+	central.crit.Lock()
+	defer central.crit.Unlock()
 	if len(central.entries) == 0 {
 		return false
 	}
@@ -67,6 +77,9 @@ func Write(output io.Writer) bool {
 
 // Write the last N entries to io.Writer
 func Tail(output io.Writer, number int) {
+	central.crit.Lock()
+	defer central.crit.Unlock()
+
 	// cap number to the number of entries
 	if number > len(central.entries) {
 		number = len(central.entries)
@@ -19,29 +19,72 @@ import (
 	"github.com/jetsetilly/gopher2600/gui"
 	"github.com/jetsetilly/gopher2600/hardware"
 	"github.com/jetsetilly/gopher2600/hardware/riot/input"
+	"github.com/jetsetilly/gopher2600/logger"
 )
 
+// the number of driving controller steps for a mouse movement the width of
+// the window
+const drivingMouseSensitivity = 64.0
+
+// the number of units of trackball movement for a mouse movement the width or
+// height of the window
+const trackballMouseSensitivity = 160.0
+
 // MouseMotionEventHandler handles mouse events sent from a GUI. Returns true if key
 // has been handled, false otherwise.
+//
+// What the mouse emulates depends on the controller type of the first hand
+// controller. The paddle is the default.
 func MouseMotionEventHandler(ev gui.EventMouseMotion, vcs *hardware.VCS) (bool, error) {
-	return true, vcs.HandController0.Handle(input.PaddleSet, ev.X)
+	hc := vcs.HandController0
+
+	switch hc.ControllerType {
+	case input.DrivingType:
+		return true, hc.Handle(input.DrivingTurn, ev.DX*drivingMouseSensitivity)
+
+	case input.TrackballType:
+		err := hc.Handle(input.TrackballHorizontal, ev.DX*trackballMouseSensitivity)
+		if err != nil {
+			return true, err
+		}
+		return true, hc.Handle(input.TrackballVertical, ev.DY*trackballMouseSensitivity)
+
+	case input.MindlinkType:
+		return true, hc.Handle(input.MindlinkSet, ev.X)
+	}
+
+	return true, hc.Handle(input.PaddleSet, ev.X)
 }
 
 // MouseButtonEventHandler handles mouse events sent from a GUI. Returns true if key
 // has been handled, false otherwise.
+//
+// As with mouse motion, what the buttons emulate depends on the controller
+// type of the first hand controller.
 func MouseButtonEventHandler(ev gui.EventMouseButton, vcs *hardware.VCS, scr gui.GUI) (bool, error) {
 	var handled bool
 	var err error
 
+	hc := vcs.HandController0
+
 	switch ev.Button {
 	case gui.MouseButtonLeft:
-		if ev.Down {
-			err = vcs.HandController0.Handle(input.PaddleFire, true)
-		} else {
-			err = vcs.HandController0.Handle(input.PaddleFire, false)
+		switch hc.ControllerType {
+		case input.DrivingType, input.TrackballType, input.MindlinkType:
+			err = hc.Handle(input.Fire, ev.Down)
+		case input.BoosterGripType:
+			err = hc.Handle(input.BoosterGripTrigger, ev.Down)
+		default:
+			err = hc.Handle(input.PaddleFire, ev.Down)
 		}
 
 		handled = true
+
+	case gui.MouseButtonMiddle:
+		if hc.ControllerType == input.BoosterGripType {
+			err = hc.Handle(input.BoosterGripBooster, ev.Down)
+			handled = true
+		}
 	}
 
 	return handled, err
@@ -74,12 +117,20 @@ func KeyboardEventHandler(ev gui.EventKeyboard, vcs *hardware.VCS) (bool, error)
 			err = vcs.Panel.Handle(input.PanelTogglePlayer1Pro, nil)
 			handled = true
 
-		// joystick
+		// joystick. the driving controller is turned one step at a time
 		case "Left":
-			err = vcs.HandController0.Handle(input.Left, true)
+			if vcs.HandController0.ControllerType == input.DrivingType {
+				err = vcs.HandController0.Handle(input.DrivingTurn, float32(-1.0))
+			} else {
+				err = vcs.HandController0.Handle(input.Left, true)
+			}
 			handled = true
 		case "Right":
-			err = vcs.HandController0.Handle(input.Right, true)
+			if vcs.HandController0.ControllerType == input.DrivingType {
+				err = vcs.HandController0.Handle(input.DrivingTurn, float32(1.0))
+			} else {
+				err = vcs.HandController0.Handle(input.Right, true)
+			}
 			handled = true
 		case "Up":
 			err = vcs.HandController0.Handle(input.Up, true)
@@ -173,10 +224,14 @@ func KeyboardEventHandler(ev gui.EventKeyboard, vcs *hardware.VCS) (bool, error)
 
 		// josytick
 		case "Left":
-			err = vcs.HandController0.Handle(input.Left, false)
+			if vcs.HandController0.ControllerType != input.DrivingType {
+				err = vcs.HandController0.Handle(input.Left, false)
+			}
 			handled = true
 		case "Right":
-			err = vcs.HandController0.Handle(input.Right, false)
+			if vcs.HandController0.ControllerType != input.DrivingType {
+				err = vcs.HandController0.Handle(input.Right, false)
+			}
 			handled = true
 		case "Up":
 			err = vcs.HandController0.Handle(input.Up, false)
@@ -208,6 +263,24 @@ func (pl *playmode) guiEventHandler(ev gui.Event) (bool, error) {
 	case gui.EventQuit:
 		return false, nil
 	case gui.EventKeyboard:
+		if pl.rewind != nil && ev.Down && ev.Mod == gui.KeyModNone && ev.Key == "Backspace" {
+			// a failed rewind is not a reason to stop playing
+			err := pl.rewind.RewindFrames(rewindFrames)
+			if err != nil {
+				logger.Log("rewind", err.Error())
+			}
+			return true, nil
+		}
+		if ev.Down && ev.Mod == gui.KeyModNone && ev.Key == "F12" {
+			// a failed screenshot is not a reason to stop playing
+			err := pl.scr.ReqFeature(gui.ReqScreenshot, gui.Screenshot{
+				Filename: gui.ScreenshotFilename(pl.vcs.Mem.Cart.Filename),
+			})
+			if err != nil {
+				logger.Log("screenshot", err.Error())
+			}
+			return true, nil
+		}
 		_, err := KeyboardEventHandler(ev, pl.vcs)
 		return err == nil, err
 	case gui.EventMouseButton:
@@ -222,6 +295,10 @@ func (pl *playmode) guiEventHandler(ev gui.Event) (bool, error) {
 }
 
 func (pl *playmode) eventHandler() (bool, error) {
+	if pl.rewind != nil {
+		pl.rewind.Check()
+	}
+
 	select {
 	case <-pl.intChan:
 		return false, nil
@@ -28,6 +28,7 @@ import (
 	"github.com/jetsetilly/gopher2600/hiscore"
 	"github.com/jetsetilly/gopher2600/patch"
 	"github.com/jetsetilly/gopher2600/recorder"
+	"github.com/jetsetilly/gopher2600/rewind"
 	"github.com/jetsetilly/gopher2600/setup"
 	"github.com/jetsetilly/gopher2600/television"
 )
@@ -37,8 +38,15 @@ type playmode struct {
 	scr     gui.GUI
 	intChan chan os.Signal
 	guiChan chan gui.Event
+
+	// rewind history. will be nil if rewinding is not allowed (eg. during
+	// recording or playback)
+	rewind *rewind.Rewind
 }
 
+// the number of frames to wind back when the rewind key is pressed
+const rewindFrames = 60
+
 // Play creates a 'playable' instance of the emulator.
 //
 // The cartload argument can be used to specify a recording to playback. The
@@ -69,6 +77,10 @@ func Play(tv television.Television, scr gui.GUI, newRecording bool, cartload car
 		return errors.New(errors.PlayError, err)
 	}
 
+	// rewinding is only allowed during regular play. it would invalidate
+	// recordings and playbacks
+	var rwnd *rewind.Rewind
+
 	// note that we attach the cartridge in three different branches below,
 	// depending on
 
@@ -142,6 +154,11 @@ func Play(tv television.Television, scr gui.GUI, newRecording bool, cartload car
 				return errors.New(errors.PlayError, err)
 			}
 		}
+
+		rwnd, err = rewind.NewRewind(vcs, rewind.DefaultLength, rewind.DefaultFrequency)
+		if err != nil {
+			return errors.New(errors.PlayError, err)
+		}
 	}
 
 	pl := &playmode{
@@ -149,6 +166,7 @@ func Play(tv television.Television, scr gui.GUI, newRecording bool, cartload car
 		scr:     scr,
 		intChan: make(chan os.Signal, 1),
 		guiChan: make(chan gui.Event, 2),
+		rewind:  rwnd,
 	}
 
 	// connect gui
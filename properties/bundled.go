@@ -0,0 +1,36 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package properties
+
+// the bundled properties database. see the package documentation for a
+// description of the format.
+//
+// entries should only be added for cartridge dumps that have been verified.
+// the hash is the SHA-1 hash of the entire cartridge file.
+//
+// the priority is cartridges that the heuristics in the disassembly and
+// cartridge packages get wrong. in particular, games that require paddle,
+// keypad or driving controllers and PAL releases that do not produce enough
+// scanlines to be detected automatically. for example:
+//
+//	paddle: Kaboom!, Breakout, Super Breakout, Warlords, Circus Atari
+//	keypad: Star Raiders (right port), Codebreaker, Basic Programming
+//	driving: Indy 500
+//
+// the SHA-1 hash for an entry can be found with a tool like sha1sum.
+const bundled = `
+# hash, title, mapping, tv spec, left port, right port, left difficulty, right difficulty
+`
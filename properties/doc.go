@@ -0,0 +1,46 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package properties is a read-only database of game properties. It is
+// similar in intent to Stella's stella.pro file. Entries are keyed by the
+// SHA-1 hash of the cartridge data, the same hash as used in the
+// cartridgeloader and setup packages.
+//
+// Each entry can specify the title of the game, the cartridge mapping, the
+// television specification, the controller type for each player port and the
+// setting of the two difficulty switches. Empty fields indicate that the
+// property should not be changed from the default.
+//
+// The bundled database is compiled into the program. It can be supplemented
+// with a file named "properties" in the resources path (see the paths
+// package). Entries in that file take precedence over bundled entries with
+// the same hash. If the file cannot be parsed then the error is logged and
+// only the bundled entries are used.
+//
+// The format of each entry is a single line of comma separated fields:
+//
+//	<SHA-1 Hash>, <title>, <mapping>, <tv spec>, <left port>, <right port>, <left difficulty>, <right difficulty>
+//
+// The mapping is any mapping ID understood by the cartridge package. The TV
+// spec is any specification understood by the television package. The
+// controller types are any of those in input.ControllerTypeList. The difficulty
+// switches are either A (pro) or B (amateur).
+//
+// Lines beginning with # are comments. Titles containing commas should be
+// quoted.
+//
+// The properties are applied by the setup package, in the AttachCartridge()
+// function.
+package properties
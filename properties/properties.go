@@ -0,0 +1,232 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package properties
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware/riot/input"
+	"github.com/jetsetilly/gopher2600/logger"
+	"github.com/jetsetilly/gopher2600/paths"
+)
+
+// the name of the supplementary properties file in the resources path
+const propertiesFile = "properties"
+
+const (
+	fieldHash int = iota
+	fieldTitle
+	fieldMapping
+	fieldTVSpec
+	fieldLeftPort
+	fieldRightPort
+	fieldLeftDifficulty
+	fieldRightDifficulty
+	numFields
+)
+
+// Difficulty is the setting of a difficulty switch in a properties entry
+type Difficulty int
+
+// List of valid Difficulty values. DifficultyUnset means that the switch
+// should not be changed from its current setting.
+const (
+	DifficultyUnset Difficulty = iota
+	DifficultyA
+	DifficultyB
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case DifficultyA:
+		return "A"
+	case DifficultyB:
+		return "B"
+	}
+	return ""
+}
+
+// Entry is a single entry in the properties database. Empty fields indicate
+// that the property is not specified.
+type Entry struct {
+	Hash    string
+	Title   string
+	Mapping string
+	TVSpec  string
+
+	// controller types for the left and right ports. only valid if the
+	// corresponding Set field is true
+	LeftPort     input.ControllerType
+	LeftPortSet  bool
+	RightPort    input.ControllerType
+	RightPortSet bool
+
+	LeftDifficulty  Difficulty
+	RightDifficulty Difficulty
+}
+
+// the database is loaded on first use
+var db struct {
+	once    sync.Once
+	entries map[string]Entry
+}
+
+// Lookup returns the properties entry for the cartridge hash. The bool
+// return value is false if there is no entry for the hash.
+func Lookup(hash string) (Entry, bool) {
+	db.once.Do(load)
+	e, ok := db.entries[hash]
+	return e, ok
+}
+
+// load the bundled database and then the supplementary file, if it exists.
+//
+// errors are logged rather than returned. load is only called once so an
+// error will only be reported once. if the supplementary file cannot be
+// parsed then none of its entries are used and the bundled entries are used
+// on their own.
+func load() {
+	db.entries = make(map[string]Entry)
+
+	err := parse(strings.NewReader(bundled), db.entries)
+	if err != nil {
+		logger.Log("properties", err.Error())
+	}
+
+	pth, err := paths.ResourcePath("", propertiesFile)
+	if err != nil {
+		logger.Log("properties", err.Error())
+		return
+	}
+
+	f, err := os.Open(pth)
+	if err != nil {
+		// silently ignore absence of supplementary file
+		return
+	}
+	defer f.Close()
+
+	// parse the supplementary file into a separate map so that a partially
+	// parsed file doesn't affect the bundled entries
+	supplementary := make(map[string]Entry)
+	err = parse(f, supplementary)
+	if err != nil {
+		logger.Log("properties", fmt.Sprintf("%s: %v", pth, err))
+		return
+	}
+
+	for k, e := range supplementary {
+		db.entries[k] = e
+	}
+}
+
+// parse the properties in the reader and add them to the entries map.
+// existing entries with the same hash are replaced.
+func parse(r io.Reader, entries map[string]Entry) error {
+	scanner := bufio.NewScanner(r)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		c := csv.NewReader(strings.NewReader(l))
+		c.FieldsPerRecord = numFields
+		c.TrimLeadingSpace = true
+
+		rec, err := c.Read()
+		if err != nil {
+			return errors.New(errors.PropertiesReadError, err, line)
+		}
+
+		for i := range rec {
+			rec[i] = strings.TrimSpace(rec[i])
+		}
+
+		e := Entry{
+			Hash:    strings.ToLower(rec[fieldHash]),
+			Title:   rec[fieldTitle],
+			Mapping: strings.ToUpper(rec[fieldMapping]),
+			TVSpec:  strings.ToUpper(rec[fieldTVSpec]),
+		}
+
+		if len(e.Hash) != 40 {
+			return errors.New(errors.PropertiesReadError, "hash should be 40 characters long", line)
+		}
+
+		if e.LeftPort, e.LeftPortSet, err = parseController(rec[fieldLeftPort]); err != nil {
+			return errors.New(errors.PropertiesReadError, err, line)
+		}
+
+		if e.RightPort, e.RightPortSet, err = parseController(rec[fieldRightPort]); err != nil {
+			return errors.New(errors.PropertiesReadError, err, line)
+		}
+
+		if e.LeftDifficulty, err = parseDifficulty(rec[fieldLeftDifficulty]); err != nil {
+			return errors.New(errors.PropertiesReadError, err, line)
+		}
+
+		if e.RightDifficulty, err = parseDifficulty(rec[fieldRightDifficulty]); err != nil {
+			return errors.New(errors.PropertiesReadError, err, line)
+		}
+
+		entries[e.Hash] = e
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.New(errors.PropertiesError, err)
+	}
+
+	return nil
+}
+
+func parseController(s string) (input.ControllerType, bool, error) {
+	if s == "" {
+		return input.JoystickType, false, nil
+	}
+
+	for i, t := range input.ControllerTypeList {
+		if strings.EqualFold(s, t) {
+			return input.ControllerType(i), true, nil
+		}
+	}
+
+	return input.JoystickType, false, errors.New(errors.PropertiesError, "unknown controller type: "+s)
+}
+
+func parseDifficulty(s string) (Difficulty, error) {
+	switch strings.ToUpper(s) {
+	case "":
+		return DifficultyUnset, nil
+	case "A":
+		return DifficultyA, nil
+	case "B":
+		return DifficultyB, nil
+	}
+
+	return DifficultyUnset, errors.New(errors.PropertiesError, "unknown difficulty setting: "+s)
+}
@@ -0,0 +1,139 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package properties
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/hardware/riot/input"
+)
+
+func TestParse(t *testing.T) {
+	const data = `
+# comment
+0123456789abcdef0123456789abcdef01234567, "Game, The", F8, PAL, Paddle, , A, B
+89abcdef0123456789abcdef0123456789ABCDEF, Other Game, , , , keypad, ,
+`
+	entries := make(map[string]Entry)
+	err := parse(strings.NewReader(data), entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	e := entries["0123456789abcdef0123456789abcdef01234567"]
+	if e.Title != "Game, The" || e.Mapping != "F8" || e.TVSpec != "PAL" {
+		t.Errorf("unexpected entry: %v", e)
+	}
+	if !e.LeftPortSet || e.LeftPort != input.PaddleType || e.RightPortSet {
+		t.Errorf("unexpected controller types: %v", e)
+	}
+	if e.LeftDifficulty != DifficultyA || e.RightDifficulty != DifficultyB {
+		t.Errorf("unexpected difficulty: %v", e)
+	}
+
+	// hash is normalised to lower case
+	e, ok := entries["89abcdef0123456789abcdef0123456789abcdef"]
+	if !ok {
+		t.Fatalf("missing entry")
+	}
+	if !e.RightPortSet || e.RightPort != input.KeypadType || e.LeftDifficulty != DifficultyUnset {
+		t.Errorf("unexpected entry: %v", e)
+	}
+
+	// wrong number of fields
+	err = parse(strings.NewReader("0123456789abcdef0123456789abcdef01234567, title"), entries)
+	if err == nil {
+		t.Errorf("expected error for too few fields")
+	}
+
+	// bad controller type
+	err = parse(strings.NewReader("0123456789abcdef0123456789abcdef01234567, t, , , Foo, , ,"), entries)
+	if err == nil {
+		t.Errorf("expected error for unknown controller type")
+	}
+}
+
+func TestBundled(t *testing.T) {
+	err := parse(strings.NewReader(bundled), make(map[string]Entry))
+	if err != nil {
+		t.Errorf("bundled properties database is invalid: %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "properties")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	// supplementary properties file in the resources path
+	const data = `
+0123456789abcdef0123456789abcdef01234567, Paddle Game, 4K, PAL, Paddle, Paddle, B, B
+`
+	err = os.MkdirAll(".gopher2600", 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(".gopher2600", propertiesFile), []byte(data), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := Lookup("0123456789abcdef0123456789abcdef01234567")
+	if !ok {
+		t.Fatalf("entry in supplementary file not found")
+	}
+	if e.Title != "Paddle Game" || e.Mapping != "4K" || e.TVSpec != "PAL" {
+		t.Errorf("unexpected entry: %v", e)
+	}
+	if !e.LeftPortSet || e.LeftPort != input.PaddleType || !e.RightPortSet || e.RightPort != input.PaddleType {
+		t.Errorf("unexpected controller types: %v", e)
+	}
+
+	// every bundled entry is also available
+	bundledEntries := make(map[string]Entry)
+	_ = parse(strings.NewReader(bundled), bundledEntries)
+	for h, b := range bundledEntries {
+		e, ok := Lookup(h)
+		if !ok || e != b {
+			t.Errorf("bundled entry not found: %s", h)
+		}
+	}
+
+	_, ok = Lookup("ffffffffffffffffffffffffffffffffffffffff")
+	if ok {
+		t.Errorf("unexpected entry for unknown hash")
+	}
+}
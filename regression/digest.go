@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -28,7 +29,6 @@ import (
 	"github.com/jetsetilly/gopher2600/database"
 	"github.com/jetsetilly/gopher2600/digest"
 	"github.com/jetsetilly/gopher2600/errors"
-	"github.com/jetsetilly/gopher2600/hardware"
 	"github.com/jetsetilly/gopher2600/setup"
 	"github.com/jetsetilly/gopher2600/television"
 )
@@ -44,9 +44,17 @@ const (
 	digestFieldState
 	digestFieldDigest
 	digestFieldNotes
+	digestFieldFrames
+	digestFieldFramesFile
+	digestFieldLogFile
+	digestFieldTags
 	numDigestFields
 )
 
+// entries created before reference frames, hash logs and tags were introduced
+// do not have the last four fields
+const minDigestFields = digestFieldFrames
+
 // DigestRegression is the simplest regression type. it works by running the
 // emulation for N frames and the digest recorded at that point. Regression
 // passes if subsequenct runs produce the same digest value
@@ -59,6 +67,17 @@ type DigestRegression struct {
 	stateFile string
 	Notes     string
 	digest    string
+
+	// frame numbers for which a reference image is stored. the image files
+	// are named using the framesFile prefix (see frameFilename() function)
+	Frames     []int
+	framesFile string
+
+	// the per-frame hash log. only used when Mode is DigestBoth
+	logFile string
+
+	// tags can be used to select entries (see Filter type)
+	Tags []string
 }
 
 func deserialiseDigestEntry(fields database.SerialisedEntry) (database.Entry, error) {
@@ -68,7 +87,7 @@ func deserialiseDigestEntry(fields database.SerialisedEntry) (database.Entry, er
 	if len(fields) > numDigestFields {
 		return nil, errors.New(errors.RegressionDigestError, "too many fields")
 	}
-	if len(fields) < numDigestFields {
+	if len(fields) < minDigestFields {
 		return nil, errors.New(errors.RegressionDigestError, "too few fields")
 	}
 
@@ -100,6 +119,27 @@ func deserialiseDigestEntry(fields database.SerialisedEntry) (database.Entry, er
 		reg.stateFile = fields[digestFieldState]
 	}
 
+	// handle reference frame fields
+	if len(fields) > digestFieldFramesFile {
+		reg.Frames, err = parseFrameList(fields[digestFieldFrames], listSep)
+		if err != nil {
+			return nil, errors.New(errors.RegressionDigestError, err)
+		}
+		reg.framesFile = fields[digestFieldFramesFile]
+	} else {
+		reg.Frames = []int{}
+	}
+
+	// handle hash log field
+	if len(fields) > digestFieldLogFile {
+		reg.logFile = fields[digestFieldLogFile]
+	}
+
+	// handle tags field
+	if len(fields) > digestFieldTags {
+		reg.Tags = parseTags(fields[digestFieldTags], listSep)
+	}
+
 	return reg, nil
 }
 
@@ -116,13 +156,28 @@ func (reg DigestRegression) String() string {
 		stateFile = "[with state]"
 	}
 
+	if len(reg.Frames) > 0 {
+		stateFile = fmt.Sprintf("%s[with %d reference frames]", stateFile, len(reg.Frames))
+	}
+
 	s.WriteString(fmt.Sprintf("[%s/%s] %s [%s] frames=%d %s", reg.ID(), reg.Mode, reg.CartLoad.ShortName(), reg.TVtype, reg.NumFrames, stateFile))
 	if reg.Notes != "" {
 		s.WriteString(fmt.Sprintf(" [%s]", reg.Notes))
 	}
+	s.WriteString(stringTags(reg.Tags))
 	return s.String()
 }
 
+// annotations implements the regression.Regressor interface
+func (reg DigestRegression) annotations() (string, []string) {
+	return reg.Notes, reg.Tags
+}
+
+// summary implements the regression.Regressor interface
+func (reg DigestRegression) summary() (string, string) {
+	return reg.CartLoad.Filename, fmt.Sprintf("%s/%s", reg.ID(), reg.Mode)
+}
+
 // Serialise implements the database.Entry interface
 func (reg *DigestRegression) Serialise() (database.SerialisedEntry, error) {
 	return database.SerialisedEntry{
@@ -134,6 +189,10 @@ func (reg *DigestRegression) Serialise() (database.SerialisedEntry, error) {
 			reg.stateFile,
 			reg.digest,
 			reg.Notes,
+			serialiseFrameList(reg.Frames),
+			reg.framesFile,
+			reg.logFile,
+			strings.Join(reg.Tags, listSep),
 		},
 		nil
 }
@@ -141,10 +200,23 @@ func (reg *DigestRegression) Serialise() (database.SerialisedEntry, error) {
 // CleanUp implements the database.Entry interface
 func (reg DigestRegression) CleanUp() error {
 	err := os.Remove(reg.stateFile)
-	if _, ok := err.(*os.PathError); ok {
-		return nil
+	if _, ok := err.(*os.PathError); !ok && err != nil {
+		return err
+	}
+
+	for _, f := range reg.Frames {
+		err = os.Remove(frameFilename(reg.framesFile, f))
+		if _, ok := err.(*os.PathError); !ok && err != nil {
+			return err
+		}
+	}
+
+	err = os.Remove(reg.logFile)
+	if _, ok := err.(*os.PathError); !ok && err != nil {
+		return err
 	}
-	return err
+
+	return nil
 }
 
 // regress implements the regression.Regressor interface
@@ -161,6 +233,9 @@ func (reg *DigestRegression) regress(newRegression bool, output io.Writer, msg s
 	// decide on digest mode and create appropriate digester
 	var dig digest.Digest
 
+	// the hash log is used for DigestBoth mode
+	var hl *hashLog
+
 	switch reg.Mode {
 	case DigestVideoOnly:
 		dig, err = digest.NewVideo(tv)
@@ -175,19 +250,63 @@ func (reg *DigestRegression) regress(newRegression bool, output io.Writer, msg s
 		}
 
 	case DigestBoth:
-		return false, "", errors.New(errors.RegressionDigestError, "video/audio digest not yet implemented")
+		hl = newHashLog(tv)
+		dig = hl
+
+		if newRegression {
+			// create a unique filename
+			reg.logFile, err = uniqueFilename("log", reg.CartLoad)
+			if err != nil {
+				return false, "", errors.New(errors.RegressionDigestError, err)
+			}
+
+			lf, err := os.Create(reg.logFile)
+			if err != nil {
+				msg := fmt.Sprintf("error creating hash log file: %s", err)
+				return false, "", errors.New(errors.RegressionDigestError, msg)
+			}
+			defer lf.Close()
+
+			hl.log = bufio.NewWriter(lf)
+		} else {
+			lf, err := os.Open(reg.logFile)
+			if err != nil {
+				msg := fmt.Sprintf("old hash log file not present (%s)", reg.logFile)
+				return false, "", errors.New(errors.RegressionDigestError, msg)
+			}
+			defer lf.Close()
+
+			hl.ref = bufio.NewReader(lf)
+			hl.dumpPrefix = filepath.Base(reg.logFile)
+		}
 
 	case DigestUndefined:
 		return false, "", errors.New(errors.RegressionDigestError, fmt.Sprintf("undefined digest mode"))
 	}
 
+	// reference frames must be within the number of frames being run
+	for _, f := range reg.Frames {
+		if f >= reg.NumFrames {
+			msg := fmt.Sprintf("reference frame %d is outside the number of frames (%d)", f, reg.NumFrames)
+			return false, "", errors.New(errors.RegressionDigestError, msg)
+		}
+	}
+
+	// grab images for reference frames
+	var grb *frameGrabber
+	if len(reg.Frames) > 0 {
+		grb = newFrameGrabber(tv, reg.Frames)
+	}
+
 	// create VCS and attach cartridge
-	vcs, err := hardware.NewVCS(tv)
+	vcs, err := newVCS(tv)
 	if err != nil {
 		return false, "", errors.New(errors.RegressionDigestError, err)
 	}
 
-	err = setup.AttachCartridge(vcs, reg.CartLoad)
+	// controller detection is not used so that changes to the detection
+	// do not change the digest
+	err = setup.AttachCartridgeNoDetect(vcs, reg.CartLoad)
 	if err != nil {
 		return false, "", errors.New(errors.RegressionDigestError, err)
 	}
@@ -220,6 +339,13 @@ func (reg *DigestRegression) regress(newRegression bool, output io.Writer, msg s
 			state = append(state, tv.String())
 		}
 
+		// there's no need to continue once the hash log has diverged from the
+		// reference log. unless we're also tracking the state, in which case
+		// we need to run for the full number of frames
+		if hl != nil && hl.divergence != "" && !reg.State {
+			return false, nil
+		}
+
 		return true, nil
 	})
 
@@ -230,6 +356,14 @@ func (reg *DigestRegression) regress(newRegression bool, output io.Writer, msg s
 	if newRegression {
 		reg.digest = dig.Hash()
 
+		if hl != nil {
+			err = hl.log.Flush()
+			if err != nil {
+				msg := fmt.Sprintf("error writing hash log file: %s", err)
+				return false, "", errors.New(errors.RegressionDigestError, msg)
+			}
+		}
+
 		if reg.State {
 			// create a unique filename
 			reg.stateFile, err = uniqueFilename("state", reg.CartLoad)
@@ -265,6 +399,28 @@ func (reg *DigestRegression) regress(newRegression bool, output io.Writer, msg s
 			}
 		}
 
+		if len(reg.Frames) > 0 {
+			// create a unique filename prefix
+			reg.framesFile, err = uniqueFilename("frame", reg.CartLoad)
+			if err != nil {
+				return false, "", errors.New(errors.RegressionDigestError, err)
+			}
+
+			for _, f := range reg.Frames {
+				img, ok := grb.grabbed[f]
+				if !ok {
+					msg := fmt.Sprintf("reference frame %d was not grabbed", f)
+					return false, "", errors.New(errors.RegressionDigestError, msg)
+				}
+
+				err = saveFrame(frameFilename(reg.framesFile, f), img)
+				if err != nil {
+					msg := fmt.Sprintf("error saving reference frame: %s", err)
+					return false, "", errors.New(errors.RegressionDigestError, msg)
+				}
+			}
+		}
+
 		return true, "", nil
 	}
 
@@ -306,8 +462,60 @@ func (reg *DigestRegression) regress(newRegression bool, output io.Writer, msg s
 
 	}
 
+	// compare grabbed frames with the reference images. a frame that
+	// doesn't match will cause a diff image to be written
+	failm := make([]string, 0)
+
+	for _, f := range reg.Frames {
+		ref, err := loadFrame(frameFilename(reg.framesFile, f))
+		if err != nil {
+			msg := fmt.Sprintf("reference frame %d cannot be loaded: %s", f, err)
+			return false, "", errors.New(errors.RegressionDigestError, msg)
+		}
+
+		img, ok := grb.grabbed[f]
+		if !ok {
+			// the emulation will have stopped early if the hash log has
+			// diverged. the frame may not have been reached
+			if hl != nil && hl.divergence != "" {
+				continue
+			}
+			msg := fmt.Sprintf("reference frame %d was not grabbed", f)
+			return false, "", errors.New(errors.RegressionDigestError, msg)
+		}
+
+		if !ref.Bounds().Eq(img.Bounds()) {
+			failm = append(failm, fmt.Sprintf("frame %d: image size mismatch: expected %dx%d (%dx%d)", f,
+				ref.Bounds().Dx(), ref.Bounds().Dy(), img.Bounds().Dx(), img.Bounds().Dy()))
+			continue
+		}
+
+		numPixels, scanlines, diff := compareFrames(ref, img)
+		if numPixels > 0 {
+			m := fmt.Sprintf("frame %d: %d pixels differ on scanlines %s", f, numPixels, summariseScanlines(scanlines))
+
+			diffFile := frameDiffFilename(reg.framesFile, f)
+			err = saveFrame(diffFile, diff)
+			if err != nil {
+				m = fmt.Sprintf("%s (error saving diff image: %s)", m, err)
+			} else {
+				m = fmt.Sprintf("%s (see %s)", m, diffFile)
+			}
+
+			failm = append(failm, m)
+		}
+	}
+
 	if dig.Hash() != reg.digest {
-		return false, "digest mismatch", nil
+		if hl != nil && hl.divergence != "" {
+			failm = append([]string{fmt.Sprintf("first divergence at %s", hl.divergence)}, failm...)
+		} else {
+			failm = append([]string{"digest mismatch"}, failm...)
+		}
+	}
+
+	if len(failm) > 0 {
+		return false, strings.Join(failm, "\n  ^^ "), nil
 	}
 
 	return true, "", nil
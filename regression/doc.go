@@ -21,6 +21,18 @@
 // test runs a ROM for a set number of frames, saving the video or audio hash
 // to the test database.
 //
+// The "both" digest mode records a hash of the video and audio for every frame
+// to a log file. When the test fails, the first frame to diverge from the log
+// is reported and the scanlines and audio samples of that frame are written to
+// a file in the current directory.
+//
+// Digest tests can optionally store reference images for selected frames. The
+// images are stored as PNG files alongside the database. When a test fails, a
+// new image is written to the current directory for each frame that does not
+// match its reference image. The differing pixels are highlighted in the new
+// image and the number of differing pixels, along with the scanlines on which
+// they occur, is reported.
+//
 // The second test is the Playback test. This is a slightly more complex test
 // that replays user input from a previously recorded session. Recorded
 // sessions take video hashes on every input trigger and so will succeed or
@@ -29,4 +41,12 @@
 // The two tests are useful for different ROMs. The digest type is useful if
 // the ROM does something immediately, say an image that is stressful on the
 // TIA. The playback type is more useful for real world ROMs (ie. games).
+//
+// Tests can be given any number of tags when they are added to the database.
+// The tests to run (or to delete) can be selected by key, by tag, or by a
+// regular expression matched against the NOTES field. Tests can also be run in
+// parallel, with the results still reported in database order.
+//
+// The results of a run can be collected in a Report and written as JUnit XML
+// or as JSON, for use by continuous integration tools.
 package regression
@@ -0,0 +1,165 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/database"
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// Filter specifies which entries in the regression database are to be
+// selected. An entry must satisfy every part of the filter to be selected. The
+// zero value selects every entry.
+type Filter struct {
+	// list of database keys. an empty list matches every key
+	Keys []string
+
+	// regular expression to match against the notes field of an entry. an
+	// empty string matches every entry
+	Notes string
+
+	// list of tags. an entry must have every tag in the list
+	Tags []string
+}
+
+// IsEmpty returns true if the filter would select every entry
+func (flt Filter) IsEmpty() bool {
+	return len(flt.Keys) == 0 && flt.Notes == "" && len(flt.Tags) == 0
+}
+
+// selectedEntry is an entry that has been selected by a filter
+type selectedEntry struct {
+	key int
+	reg Regressor
+}
+
+// selectEntries returns the list of entries in the database that match the
+// filter, in key order
+func (flt Filter) selectEntries(db *database.Session) ([]selectedEntry, error) {
+	var notes *regexp.Regexp
+	var err error
+
+	if flt.Notes != "" {
+		notes, err = regexp.Compile(flt.Notes)
+		if err != nil {
+			msg := fmt.Sprintf("invalid notes regex (%s)", err)
+			return nil, errors.New(errors.RegressionError, msg)
+		}
+	}
+
+	// convert and check keys
+	keys := make(map[int]bool)
+	for _, k := range flt.Keys {
+		v, err := strconv.Atoi(k)
+		if err != nil {
+			msg := fmt.Sprintf("invalid key [%s]", k)
+			return nil, errors.New(errors.RegressionError, msg)
+		}
+
+		_, err = db.SelectKeys(nil, v)
+		if err != nil {
+			if !errors.Is(err, errors.DatabaseSelectEmpty) {
+				return nil, err
+			}
+			return nil, errors.New(errors.RegressionError, errors.New(errors.DatabaseKeyError, v))
+		}
+
+		keys[v] = true
+	}
+
+	sel := make([]selectedEntry, 0, db.NumEntries())
+
+	for _, k := range db.SortedKeyList() {
+		if len(keys) > 0 && !keys[k] {
+			continue
+		}
+
+		ent, err := db.SelectKeys(nil, k)
+		if err != nil {
+			return nil, err
+		}
+
+		// datbase entry should also satisfy Regressor interface
+		reg, ok := ent.(Regressor)
+		if !ok {
+			return nil, errors.New(errors.PanicError, "Filter.selectEntries()", "database entry does not satisfy Regressor interface")
+		}
+
+		n, t := reg.annotations()
+
+		if notes != nil && !notes.MatchString(n) {
+			continue
+		}
+
+		if !hasTags(t, flt.Tags) {
+			continue
+		}
+
+		sel = append(sel, selectedEntry{key: k, reg: reg})
+	}
+
+	return sel, nil
+}
+
+// returns true if every tag in want is in the tags list. tags are not case
+// sensitive
+func hasTags(tags []string, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, t := range tags {
+			if strings.EqualFold(t, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseTags converts a comma separated list of tags (as used on the command
+// line) into a slice of strings. Empty tags are ignored.
+func ParseTags(tags string) []string {
+	return parseTags(tags, ",")
+}
+
+func parseTags(tags string, sep string) []string {
+	l := make([]string, 0)
+	for _, t := range strings.Split(tags, sep) {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			l = append(l, t)
+		}
+	}
+	sort.Strings(l)
+	return l
+}
+
+// tags as they should appear in the String() output of an entry
+func stringTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" #%s", strings.Join(tags, " #"))
+}
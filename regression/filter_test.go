@@ -0,0 +1,170 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/database"
+)
+
+const mockEntryID = "mock"
+
+// mockEntry implements the Regressor interface. it is used to test the
+// selection of entries and does not perform a regression test
+type mockEntry struct {
+	notes string
+	tags  []string
+	cart  string
+}
+
+func (m *mockEntry) ID() string {
+	return mockEntryID
+}
+
+func (m *mockEntry) String() string {
+	return m.notes + stringTags(m.tags)
+}
+
+func (m *mockEntry) Serialise() (database.SerialisedEntry, error) {
+	return database.SerialisedEntry{m.notes, strings.Join(m.tags, listSep)}, nil
+}
+
+func (m *mockEntry) CleanUp() error {
+	return nil
+}
+
+func (m *mockEntry) regress(_ bool, _ io.Writer, _ string) (bool, string, error) {
+	return true, "", nil
+}
+
+func (m *mockEntry) annotations() (string, []string) {
+	return m.notes, m.tags
+}
+
+func (m *mockEntry) summary() (string, string) {
+	return m.cart, "mock"
+}
+
+func deserialiseMockEntry(fields database.SerialisedEntry) (database.Entry, error) {
+	return &mockEntry{notes: fields[0], tags: parseTags(fields[1], listSep)}, nil
+}
+
+func TestFilter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regression")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := database.StartSession(filepath.Join(dir, "db"), database.ActivityCreating, func(db *database.Session) error {
+		return db.RegisterEntryType(mockEntryID, deserialiseMockEntry)
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer db.EndSession(false)
+
+	entries := []*mockEntry{
+		{notes: "pitfall intro", tags: parseTags("ntsc,video", ",")},
+		{notes: "pitfall gameplay", tags: parseTags("ntsc,audio", ",")},
+		{notes: "river raid", tags: parseTags("PAL,video", ",")},
+		{notes: "", tags: nil},
+	}
+	for _, e := range entries {
+		if err := db.Add(e); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	tests := []struct {
+		name     string
+		flt      Filter
+		expected []int
+	}{
+		{"zero value", Filter{}, []int{0, 1, 2, 3}},
+		{"keys", Filter{Keys: []string{"2", "0"}}, []int{0, 2}},
+		{"notes", Filter{Notes: "pitfall"}, []int{0, 1}},
+		{"notes anchored", Filter{Notes: "^river"}, []int{2}},
+		{"notes alternation", Filter{Notes: "intro|raid"}, []int{0, 2}},
+		{"single tag", Filter{Tags: []string{"video"}}, []int{0, 2}},
+		{"every tag", Filter{Tags: []string{"ntsc", "video"}}, []int{0}},
+		{"tag case", Filter{Tags: []string{"pal"}}, []int{2}},
+		{"unknown tag", Filter{Tags: []string{"secam"}}, []int{}},
+		{"notes and tags", Filter{Notes: "pitfall", Tags: []string{"audio"}}, []int{1}},
+		{"keys and notes", Filter{Keys: []string{"1", "2"}, Notes: "pitfall"}, []int{1}},
+	}
+
+	for _, tc := range tests {
+		sel, err := tc.flt.selectEntries(db)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+
+		keys := make([]int, 0, len(sel))
+		for _, s := range sel {
+			keys = append(keys, s.key)
+		}
+
+		if len(keys) != len(tc.expected) {
+			t.Errorf("%s: expected keys %v got %v", tc.name, tc.expected, keys)
+			continue
+		}
+		for i := range keys {
+			if keys[i] != tc.expected[i] {
+				t.Errorf("%s: expected keys %v got %v", tc.name, tc.expected, keys)
+				break
+			}
+		}
+	}
+
+	// errors
+	errs := []struct {
+		name string
+		flt  Filter
+	}{
+		{"invalid regex", Filter{Notes: "pitfall("}},
+		{"invalid key", Filter{Keys: []string{"a"}}},
+		{"missing key", Filter{Keys: []string{"10"}}},
+	}
+
+	for _, tc := range errs {
+		if _, err := tc.flt.selectEntries(db); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tags := ParseTags(" video, ntsc,,audio ")
+	expected := []string{"audio", "ntsc", "video"}
+
+	if len(tags) != len(expected) {
+		t.Fatalf("expected tags %v got %v", expected, tags)
+	}
+	for i := range tags {
+		if tags[i] != expected[i] {
+			t.Errorf("expected tags %v got %v", expected, tags)
+			break
+		}
+	}
+}
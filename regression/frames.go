@@ -0,0 +1,236 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// frameGrabber implements the television.PixelRenderer interface. it keeps a
+// copy of the television image for each of the requested frame numbers.
+//
+// the entire television image is grabbed, including the blanking areas. this
+// means that comparisons are immune from changes to the frame resizing method
+// used by the television implementation.
+type frameGrabber struct {
+	spec *television.Specification
+
+	// the frame currently being drawn
+	frameNum int
+	img      *image.RGBA
+
+	// the frames we want to grab and the images that have been grabbed
+	want    map[int]bool
+	grabbed map[int]*image.RGBA
+}
+
+func newFrameGrabber(tv television.Television, frames []int) *frameGrabber {
+	grb := &frameGrabber{
+		want:    make(map[int]bool),
+		grabbed: make(map[int]*image.RGBA),
+	}
+
+	for _, f := range frames {
+		grb.want[f] = true
+	}
+
+	spec, _ := tv.GetSpec()
+	grb.allocate(spec)
+
+	tv.AddPixelRenderer(grb)
+
+	return grb
+}
+
+func (grb *frameGrabber) allocate(spec *television.Specification) {
+	grb.spec = spec
+	grb.img = image.NewRGBA(image.Rect(0, 0, television.HorizClksScanline, spec.ScanlinesTotal))
+}
+
+// Resize implements television.PixelRenderer interface
+//
+// As with the video digest, we only handle specification changes.
+func (grb *frameGrabber) Resize(spec *television.Specification, _, _ int) error {
+	if spec != grb.spec {
+		grb.allocate(spec)
+	}
+	return nil
+}
+
+// NewFrame implements television.PixelRenderer interface
+func (grb *frameGrabber) NewFrame(frameNum int, _ bool) error {
+	if grb.want[grb.frameNum] {
+		img := image.NewRGBA(grb.img.Bounds())
+		copy(img.Pix, grb.img.Pix)
+		grb.grabbed[grb.frameNum] = img
+	}
+	grb.frameNum = frameNum
+	return nil
+}
+
+// NewScanline implements television.PixelRenderer interface
+func (grb *frameGrabber) NewScanline(_ int) error {
+	return nil
+}
+
+// SetPixel implements television.PixelRenderer interface
+func (grb *frameGrabber) SetPixel(x, y int, red, green, blue byte, vblank bool) error {
+	if vblank {
+		red, green, blue = 0, 0, 0
+	}
+	grb.img.SetRGBA(x, y, color.RGBA{R: red, G: green, B: blue, A: 255})
+	return nil
+}
+
+// EndRendering implements television.PixelRenderer interface
+func (grb *frameGrabber) EndRendering() error {
+	return nil
+}
+
+// the filename of the reference image for a frame
+func frameFilename(prefix string, frame int) string {
+	return fmt.Sprintf("%s_%d.png", prefix, frame)
+}
+
+// the filename of the image written when a frame does not match the reference
+// image. diff images are written to the current directory.
+func frameDiffFilename(prefix string, frame int) string {
+	return fmt.Sprintf("%s_%d_diff.png", filepath.Base(prefix), frame)
+}
+
+func saveFrame(filename string, img image.Image) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
+
+func loadFrame(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
+
+// compareFrames returns the number of pixels that differ between the two
+// images and the list of scanlines on which they differ. an image that
+// highlights the differing pixels is also returned.
+func compareFrames(ref image.Image, img *image.RGBA) (int, []int, *image.RGBA) {
+	diff := image.NewRGBA(img.Bounds())
+
+	numPixels := 0
+	scanlines := make([]int, 0)
+
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		diverged := false
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			c := img.RGBAAt(x, y)
+
+			r, g, b, _ := ref.At(x, y).RGBA()
+			if uint8(r>>8) != c.R || uint8(g>>8) != c.G || uint8(b>>8) != c.B {
+				numPixels++
+				diverged = true
+				diff.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				// matching pixels are drawn as a dim grayscale version of the
+				// new image so that differing pixels stand out
+				l := uint8((uint16(c.R) + uint16(c.G) + uint16(c.B)) / 12)
+				diff.SetRGBA(x, y, color.RGBA{R: l, G: l, B: l, A: 255})
+			}
+		}
+
+		if diverged {
+			scanlines = append(scanlines, y)
+		}
+	}
+
+	return numPixels, scanlines, diff
+}
+
+// summarise list of scanlines as a series of ranges. for example:
+//
+//	10-15, 20, 32-40
+func summariseScanlines(scanlines []int) string {
+	s := strings.Builder{}
+
+	for i := 0; i < len(scanlines); i++ {
+		j := i
+		for j+1 < len(scanlines) && scanlines[j+1] == scanlines[j]+1 {
+			j++
+		}
+
+		if s.Len() > 0 {
+			s.WriteString(", ")
+		}
+
+		if j == i {
+			s.WriteString(fmt.Sprintf("%d", scanlines[i]))
+		} else {
+			s.WriteString(fmt.Sprintf("%d-%d", scanlines[i], scanlines[j]))
+		}
+
+		i = j
+	}
+
+	return s.String()
+}
+
+// ParseFrameList converts a comma separated list of frame numbers (as used on
+// the command line) into a slice of ints
+func ParseFrameList(frames string) ([]int, error) {
+	return parseFrameList(frames, ",")
+}
+
+func parseFrameList(frames string, sep string) ([]int, error) {
+	l := make([]int, 0)
+
+	if strings.TrimSpace(frames) == "" {
+		return l, nil
+	}
+
+	for _, s := range strings.Split(frames, sep) {
+		f, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid frame number (%s)", s)
+		}
+		l = append(l, f)
+	}
+
+	return l, nil
+}
+
+func serialiseFrameList(frames []int) string {
+	s := make([]string, len(frames))
+	for i, f := range frames {
+		s[i] = strconv.Itoa(f)
+	}
+	return strings.Join(s, listSep)
+}
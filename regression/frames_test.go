@@ -0,0 +1,95 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regression")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	grb := newFrameGrabber(tv, []int{2})
+
+	// draw three frames. the final call to NewFrame() concludes frame 2
+	for f := 1; f <= 3; f++ {
+		_ = grb.NewFrame(f, true)
+		for y := 0; y < television.SpecNTSC.ScanlinesTotal; y++ {
+			for x := 0; x < television.HorizClksScanline; x++ {
+				_ = grb.SetPixel(x, y, byte(x), byte(y), byte(f), y < 10)
+			}
+		}
+	}
+	_ = grb.NewFrame(4, true)
+
+	img, ok := grb.grabbed[2]
+	if !ok {
+		t.Fatalf("frame 2 was not grabbed")
+	}
+	if len(grb.grabbed) != 1 {
+		t.Errorf("unexpected number of grabbed frames (%d)", len(grb.grabbed))
+	}
+
+	prefix := filepath.Join(dir, "frame")
+	err = saveFrame(frameFilename(prefix, 2), img)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	ref, err := loadFrame(frameFilename(prefix, 2))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !ref.Bounds().Eq(img.Bounds()) {
+		t.Fatalf("image size mismatch: expected %v got %v", img.Bounds(), ref.Bounds())
+	}
+
+	numPixels, scanlines, _ := compareFrames(ref, img)
+	if numPixels != 0 || len(scanlines) != 0 {
+		t.Errorf("loaded frame differs from saved frame (%d pixels)", numPixels)
+	}
+
+	// alter pixels on two ranges of scanlines and compare again
+	for _, y := range []int{20, 21, 22, 50} {
+		img.Pix[img.PixOffset(100, y)] ^= 0xff
+	}
+
+	numPixels, scanlines, diff := compareFrames(ref, img)
+	if numPixels != 4 {
+		t.Errorf("expected 4 differing pixels got %d", numPixels)
+	}
+	if s := summariseScanlines(scanlines); s != "20-22, 50" {
+		t.Errorf("unexpected scanline summary (%s)", s)
+	}
+	if c := diff.RGBAAt(100, 50); c.R != 255 || c.G != 0 || c.B != 0 {
+		t.Errorf("differing pixel not highlighted in diff image")
+	}
+}
@@ -0,0 +1,348 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// hashLog implements the television.PixelRenderer, television.AudioMixer and
+// digest.Digest interfaces. It records a hash of the video and audio of every
+// frame to a log. The log can be compared with a previously recorded log as
+// the emulation runs. Comparison stops at the first divergence.
+//
+// The log is a text file with one line per frame:
+//
+//	F <frame> <video hash> <audio hash>
+//
+// When a frame diverges from the reference log the scanlines and audio
+// samples of the frame are dumped to a separate file, to help pinpoint
+// exactly where in the frame the video or audio diverges. Each scanline is
+// written as a line and each audio sample is written as a line along with the
+// screen position at which the sample was generated:
+//
+//	F <frame> <video hash> <audio hash> <number of scanlines>
+//	S <scanline> <run length encoded pixels>
+//	...
+//	A <scanline> <horizontal position> <sample>
+//	...
+//
+// Pixels are run length encoded as a list of color and count pairs. For
+// example, 000000*68 means 68 black pixels.
+type hashLog struct {
+	// the log being written. may be nil
+	log *bufio.Writer
+
+	// the log being compared against. may be nil
+	ref *bufio.Reader
+
+	// the first divergence from the reference log. empty string if there has
+	// been no divergence
+	divergence string
+
+	// the prefix of the file the divergent frame is dumped to. the frame is
+	// not dumped if the prefix is empty
+	dumpPrefix string
+
+	// the cumulative hash of all frames
+	digest [sha1.Size]byte
+
+	// the frame currently being drawn
+	frameNum int
+
+	// pixels for the current frame. one entry per scanline. numScanlines is
+	// the number of scanlines that have been drawn to in this frame
+	rows         [][]uint32
+	numScanlines int
+
+	// audio samples for the current frame and the screen position at which
+	// each sample was generated. samplePosPending is the number of samples
+	// that are waiting for the position of the next pixel
+	samples          []uint8
+	samplePos        []pixelPos
+	samplePosPending int
+}
+
+type pixelPos struct {
+	x int
+	y int
+}
+
+// frameLogEntry is a single frame from the log
+type frameLogEntry struct {
+	frameNum  int
+	videoHash string
+	audioHash string
+}
+
+func newHashLog(tv television.Television) *hashLog {
+	hl := &hashLog{
+		samples:   make([]uint8, 0, 1024),
+		samplePos: make([]pixelPos, 0, 1024),
+	}
+
+	tv.AddPixelRenderer(hl)
+	tv.AddAudioMixer(hl)
+
+	return hl
+}
+
+// the name of the file the divergent frame is dumped to
+func divergenceFilename(prefix string, frame int) string {
+	return fmt.Sprintf("%s_%d_divergence", prefix, frame)
+}
+
+// Hash implements digest.Digest interface
+func (hl hashLog) Hash() string {
+	return fmt.Sprintf("%x", hl.digest)
+}
+
+// ResetDigest implements digest.Digest interface
+func (hl *hashLog) ResetDigest() {
+	for i := range hl.digest {
+		hl.digest[i] = 0
+	}
+}
+
+// Resize implements television.PixelRenderer interface
+func (hl *hashLog) Resize(_ *television.Specification, _, _ int) error {
+	return nil
+}
+
+// NewFrame implements television.PixelRenderer interface
+func (hl *hashLog) NewFrame(frameNum int, _ bool) error {
+	err := hl.endFrame()
+	if err != nil {
+		return err
+	}
+
+	// prepare for next frame
+	hl.frameNum = frameNum
+	for y := range hl.rows {
+		for x := range hl.rows[y] {
+			hl.rows[y][x] = 0
+		}
+	}
+	hl.numScanlines = 0
+	hl.samples = hl.samples[:0]
+	hl.samplePos = hl.samplePos[:0]
+	hl.samplePosPending = 0
+
+	return nil
+}
+
+// NewScanline implements television.PixelRenderer interface
+func (hl *hashLog) NewScanline(_ int) error {
+	return nil
+}
+
+// SetPixel implements television.PixelRenderer interface
+func (hl *hashLog) SetPixel(x, y int, red, green, blue byte, vblank bool) error {
+	for y >= len(hl.rows) {
+		hl.rows = append(hl.rows, make([]uint32, television.HorizClksScanline))
+	}
+	if y >= hl.numScanlines {
+		hl.numScanlines = y + 1
+	}
+
+	if !vblank {
+		hl.rows[y][x] = uint32(red)<<16 | uint32(green)<<8 | uint32(blue)
+	}
+
+	// audio samples are generated before the pixel for the same color clock
+	for ; hl.samplePosPending > 0; hl.samplePosPending-- {
+		hl.samplePos[len(hl.samplePos)-hl.samplePosPending] = pixelPos{x: x, y: y}
+	}
+
+	return nil
+}
+
+// EndRendering implements television.PixelRenderer interface
+func (hl *hashLog) EndRendering() error {
+	return nil
+}
+
+// SetAudio implements television.AudioMixer interface
+func (hl *hashLog) SetAudio(audioData uint8) error {
+	hl.samples = append(hl.samples, audioData)
+	hl.samplePos = append(hl.samplePos, pixelPos{})
+	hl.samplePosPending++
+	return nil
+}
+
+// EndMixing implements television.AudioMixer interface
+func (hl *hashLog) EndMixing() error {
+	return nil
+}
+
+// the run length encoding of a scanline
+func (hl *hashLog) encodeRow(y int) string {
+	s := strings.Builder{}
+	row := hl.rows[y]
+
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+		if s.Len() > 0 {
+			s.WriteString(" ")
+		}
+		s.WriteString(fmt.Sprintf("%06x*%d", row[x], n))
+		x += n
+	}
+
+	return s.String()
+}
+
+// endFrame is called at the end of every frame. the frame is hashed, written
+// to the log and compared against the reference log
+func (hl *hashLog) endFrame() error {
+	ent := frameLogEntry{frameNum: hl.frameNum}
+
+	vh := sha1.New()
+	pix := make([]byte, 3)
+	for y := 0; y < hl.numScanlines; y++ {
+		for _, p := range hl.rows[y] {
+			pix[0] = byte(p >> 16)
+			pix[1] = byte(p >> 8)
+			pix[2] = byte(p)
+			_, _ = vh.Write(pix)
+		}
+	}
+	ent.videoHash = fmt.Sprintf("%x", vh.Sum(nil))
+	ent.audioHash = fmt.Sprintf("%x", sha1.Sum(hl.samples))
+
+	// chain frame hashes
+	hl.digest = sha1.Sum([]byte(fmt.Sprintf("%x%s%s", hl.digest, ent.videoHash, ent.audioHash)))
+
+	if hl.log != nil {
+		_, err := hl.log.WriteString(fmt.Sprintf("F %d %s %s\n", ent.frameNum, ent.videoHash, ent.audioHash))
+		if err != nil {
+			return err
+		}
+	}
+
+	if hl.ref != nil && hl.divergence == "" {
+		ref, err := readFrameLogEntry(hl.ref)
+		if err != nil {
+			if err == io.EOF {
+				hl.divergence = fmt.Sprintf("frame %d: not present in reference log", hl.frameNum)
+				return nil
+			}
+			return err
+		}
+
+		hl.divergence = compareFrameLogEntries(ref, ent)
+
+		// dump the first divergent frame
+		if hl.divergence != "" && hl.dumpPrefix != "" {
+			fn := divergenceFilename(hl.dumpPrefix, hl.frameNum)
+			err = hl.dump(fn, ent)
+			if err != nil {
+				hl.divergence = fmt.Sprintf("%s (error dumping frame: %s)", hl.divergence, err)
+			} else {
+				hl.divergence = fmt.Sprintf("%s (see %s)", hl.divergence, fn)
+			}
+		}
+	}
+
+	return nil
+}
+
+// compare the reference frame with the current frame. returns a description
+// of the divergence or the empty string if there is no divergence
+func compareFrameLogEntries(ref frameLogEntry, ent frameLogEntry) string {
+	if ref.frameNum != ent.frameNum {
+		return fmt.Sprintf("frame %d: unexpected frame number (%d)", ent.frameNum, ref.frameNum)
+	}
+
+	video := ref.videoHash != ent.videoHash
+	audio := ref.audioHash != ent.audioHash
+
+	switch {
+	case video && audio:
+		return fmt.Sprintf("frame %d: video and audio diverge", ent.frameNum)
+	case video:
+		return fmt.Sprintf("frame %d: video diverges", ent.frameNum)
+	case audio:
+		return fmt.Sprintf("frame %d: audio diverges", ent.frameNum)
+	}
+
+	return ""
+}
+
+// write the scanlines and audio samples of the current frame to the named
+// file
+func (hl *hashLog) dump(filename string, ent frameLogEntry) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	_, err = w.WriteString(fmt.Sprintf("F %d %s %s %d\n", ent.frameNum, ent.videoHash, ent.audioHash, hl.numScanlines))
+	if err != nil {
+		return err
+	}
+
+	for y := 0; y < hl.numScanlines; y++ {
+		_, err = w.WriteString(fmt.Sprintf("S %d %s\n", y, hl.encodeRow(y)))
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, v := range hl.samples {
+		p := hl.samplePos[i]
+		_, err = w.WriteString(fmt.Sprintf("A %d %d %02x\n", p.y, p.x-television.HorizClksHBlank, v))
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func readFrameLogEntry(r *bufio.Reader) (frameLogEntry, error) {
+	ent := frameLogEntry{}
+
+	s, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && s == "" {
+			return ent, io.EOF
+		}
+		if err != io.EOF {
+			return ent, err
+		}
+	}
+
+	_, err = fmt.Sscanf(strings.TrimRight(s, "\n"), "F %d %s %s", &ent.frameNum, &ent.videoHash, &ent.audioHash)
+	if err != nil {
+		return ent, fmt.Errorf("malformed hash log (%v)", err)
+	}
+
+	return ent, nil
+}
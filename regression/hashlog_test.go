@@ -0,0 +1,175 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// the number of scanlines in each frame of the synthetic video
+const hashLogTestScanlines = 10
+
+// drive the hash log with a number of synthetic frames. the alter function
+// is called for every pixel and audio sample and can be used to change the
+// output for a specific frame
+func driveHashLog(t *testing.T, hl *hashLog, numFrames int, alter func(frame, x, y int) byte) {
+	t.Helper()
+
+	for f := 1; f <= numFrames+1; f++ {
+		if err := hl.NewFrame(f, true); err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		// the final call to NewFrame() is to conclude the last frame
+		if f > numFrames {
+			break
+		}
+
+		for y := 0; y < hashLogTestScanlines; y++ {
+			_ = hl.SetAudio(alter(f, -1, y))
+			for x := 0; x < television.HorizClksScanline; x++ {
+				_ = hl.SetPixel(x, y, byte(x), byte(y), alter(f, x, y), x < television.HorizClksHBlank)
+			}
+		}
+	}
+}
+
+func noAlteration(_, _, _ int) byte {
+	return 0
+}
+
+func newTestHashLog(t *testing.T) *hashLog {
+	t.Helper()
+
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return newHashLog(tv)
+}
+
+func TestHashLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regression")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	const numFrames = 6
+
+	// record log
+	rec := &bytes.Buffer{}
+	hl := newTestHashLog(t)
+	hl.log = bufio.NewWriter(rec)
+	driveHashLog(t, hl, numFrames, noAlteration)
+	if err := hl.log.Flush(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	digest := hl.Hash()
+
+	// the log has one line per frame, plus a line for the (empty) frame that
+	// precedes the first frame
+	lines := strings.Split(strings.TrimSpace(rec.String()), "\n")
+	if len(lines) != numFrames+1 {
+		t.Fatalf("expected %d lines in hash log got %d", numFrames+1, len(lines))
+	}
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "F ") || len(strings.Fields(l)) != 4 {
+			t.Fatalf("unexpected line in hash log: %s", l)
+		}
+	}
+
+	// no divergence when the output is the same
+	hl = newTestHashLog(t)
+	hl.ref = bufio.NewReader(bytes.NewReader(rec.Bytes()))
+	hl.dumpPrefix = filepath.Join(dir, "same")
+	driveHashLog(t, hl, numFrames, noAlteration)
+	if hl.divergence != "" {
+		t.Errorf("unexpected divergence: %s", hl.divergence)
+	}
+	if hl.Hash() != digest {
+		t.Errorf("digest mismatch")
+	}
+
+	// change a pixel in frame 3 and frame 4. only the first divergence should
+	// be reported and dumped
+	hl = newTestHashLog(t)
+	hl.ref = bufio.NewReader(bytes.NewReader(rec.Bytes()))
+	hl.dumpPrefix = filepath.Join(dir, "video")
+	driveHashLog(t, hl, numFrames, func(f, x, y int) byte {
+		if (f == 3 || f == 4) && x == 120 && y == 5 {
+			return 0xff
+		}
+		return 0
+	})
+
+	if !strings.HasPrefix(hl.divergence, "frame 3: video diverges") {
+		t.Errorf("unexpected divergence: %s", hl.divergence)
+	}
+	if hl.Hash() == digest {
+		t.Errorf("digest should not match")
+	}
+
+	d, err := ioutil.ReadFile(divergenceFilename(hl.dumpPrefix, 3))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	dump := strings.Split(strings.TrimSpace(string(d)), "\n")
+	if len(dump) != 1+hashLogTestScanlines*2 {
+		t.Fatalf("expected %d lines in dump got %d", 1+hashLogTestScanlines*2, len(dump))
+	}
+	if !strings.HasPrefix(dump[0], "F 3 ") {
+		t.Errorf("unexpected header line in dump: %s", dump[0])
+	}
+	if !strings.HasPrefix(dump[6], "S 5 ") || !strings.Contains(dump[6], "7805ff*1") {
+		t.Errorf("altered pixel not present in dump: %s", dump[6])
+	}
+	if dump[hashLogTestScanlines+1] != "A 0 -68 00" {
+		t.Errorf("unexpected audio line in dump: %s", dump[hashLogTestScanlines+1])
+	}
+
+	if _, err := os.Stat(divergenceFilename(hl.dumpPrefix, 4)); !os.IsNotExist(err) {
+		t.Errorf("frame after first divergence should not be dumped")
+	}
+
+	// change an audio sample in frame 2
+	hl = newTestHashLog(t)
+	hl.ref = bufio.NewReader(bytes.NewReader(rec.Bytes()))
+	hl.dumpPrefix = filepath.Join(dir, "audio")
+	driveHashLog(t, hl, numFrames, func(f, x, y int) byte {
+		if f == 2 && x == -1 && y == 7 {
+			return 0x0f
+		}
+		return 0
+	})
+
+	if !strings.HasPrefix(hl.divergence, "frame 2: audio diverges") {
+		t.Errorf("unexpected divergence: %s", hl.divergence)
+	}
+	if _, err := os.Stat(divergenceFilename(hl.dumpPrefix, 2)); err != nil {
+		t.Errorf("divergent frame was not dumped: %s", err)
+	}
+}
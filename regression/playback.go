@@ -26,7 +26,6 @@ import (
 	"github.com/jetsetilly/gopher2600/database"
 	"github.com/jetsetilly/gopher2600/digest"
 	"github.com/jetsetilly/gopher2600/errors"
-	"github.com/jetsetilly/gopher2600/hardware"
 	"github.com/jetsetilly/gopher2600/recorder"
 	"github.com/jetsetilly/gopher2600/television"
 )
@@ -36,9 +35,13 @@ const playbackEntryID = "playback"
 const (
 	playbackFieldScript int = iota
 	playbackFieldNotes
+	playbackFieldTags
 	numPlaybackFields
 )
 
+// entries created before tags were introduced do not have the tags field
+const minPlaybackFields = playbackFieldTags
+
 // PlaybackRegression represents a regression type that processes a VCS
 // recording. playback regressions can take a while to run because by their
 // nature they extend over many frames - many more than is typical with the
@@ -46,6 +49,9 @@ const (
 type PlaybackRegression struct {
 	Script string
 	Notes  string
+
+	// tags can be used to select entries (see Filter type)
+	Tags []string
 }
 
 func deserialisePlaybackEntry(fields database.SerialisedEntry) (database.Entry, error) {
@@ -55,7 +61,7 @@ func deserialisePlaybackEntry(fields database.SerialisedEntry) (database.Entry,
 	if len(fields) > numPlaybackFields {
 		return nil, errors.New(errors.RegressionPlaybackError, "too many fields")
 	}
-	if len(fields) < numPlaybackFields {
+	if len(fields) < minPlaybackFields {
 		return nil, errors.New(errors.RegressionPlaybackError, "too few fields")
 	}
 
@@ -63,6 +69,11 @@ func deserialisePlaybackEntry(fields database.SerialisedEntry) (database.Entry,
 	reg.Script = fields[playbackFieldScript]
 	reg.Notes = fields[playbackFieldNotes]
 
+	// handle tags field
+	if len(fields) > playbackFieldTags {
+		reg.Tags = parseTags(fields[playbackFieldTags], listSep)
+	}
+
 	return reg, nil
 }
 
@@ -78,14 +89,32 @@ func (reg PlaybackRegression) String() string {
 	if reg.Notes != "" {
 		s.WriteString(fmt.Sprintf(" [%s]", reg.Notes))
 	}
+	s.WriteString(stringTags(reg.Tags))
 	return s.String()
 }
 
+// annotations implements the regression.Regressor interface
+func (reg PlaybackRegression) annotations() (string, []string) {
+	return reg.Notes, reg.Tags
+}
+
+// summary implements the regression.Regressor interface. the cartridge
+// filename is stored in the playback script so the script must be read. an
+// unreadable script results in an empty filename
+func (reg PlaybackRegression) summary() (string, string) {
+	plb, err := recorder.NewPlayback(reg.Script)
+	if err != nil {
+		return "", reg.ID()
+	}
+	return plb.CartLoad.Filename, reg.ID()
+}
+
 // Serialise implements the database.Entry interface
 func (reg *PlaybackRegression) Serialise() (database.SerialisedEntry, error) {
 	return database.SerialisedEntry{
 			reg.Script,
 			reg.Notes,
+			strings.Join(reg.Tags, listSep),
 		},
 		nil
 }
@@ -119,7 +148,7 @@ func (reg *PlaybackRegression) regress(newRegression bool, output io.Writer, msg
 		return false, "", errors.New(errors.RegressionPlaybackError, err)
 	}
 
-	vcs, err := hardware.NewVCS(tv)
+	vcs, err := newVCS(tv)
 	if err != nil {
 		return false, "", errors.New(errors.RegressionPlaybackError, err)
 	}
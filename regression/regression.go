@@ -18,14 +18,16 @@ package regression
 import (
 	"fmt"
 	"io"
-	"math/rand"
-	"sort"
-	"strconv"
+	"io/ioutil"
+	"runtime"
+	"sync"
 	"time"
 
 	"github.com/jetsetilly/gopher2600/database"
 	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware"
 	"github.com/jetsetilly/gopher2600/paths"
+	"github.com/jetsetilly/gopher2600/television"
 )
 
 // ansi code for clear line
@@ -36,6 +38,10 @@ const ansiClearLine = "\033[2K"
 const regressionDBFile = "regressionDB"
 const regressionScripts = "regressionScripts"
 
+// the separator used when serialising lists in a single database field. the
+// database uses commas to separate fields so we can't use that
+const listSep = ";"
+
 // Regressor is the generic entry type in the regressionDB
 type Regressor interface {
 	database.Entry
@@ -49,6 +55,25 @@ type Regressor interface {
 	// returns: success boolean; any failure message (not always appropriate;
 	// and error state
 	regress(newRegression bool, output io.Writer, message string) (bool, string, error)
+
+	// returns the notes and the tags for the entry. used by the Filter type
+	// to select entries
+	annotations() (string, []string)
+
+	// returns the cartridge filename and the test mode. used when creating a
+	// Report
+	summary() (string, string)
+}
+
+// the seed for the random number source of every VCS created by newVCS()
+const vcsSeed = 1
+
+// newVCS should be used in preference to hardware.NewVCS() by all Regressor
+// implementations. each VCS has its own random number source, seeded with the
+// same value, so that tests are determinate regardless of the order in which
+// they are run, or whether they are run in parallel
+func newVCS(tv television.Television) (*hardware.VCS, error) {
+	return hardware.NewVCSWithSeed(tv, vcsSeed)
 }
 
 // when starting a database session we need to register what entries we will
@@ -93,11 +118,6 @@ func RegressList(output io.Writer) error {
 
 // RegressAdd adds a new regression handler to the database
 func RegressAdd(output io.Writer, reg Regressor) error {
-	// tests must be determinate so we set math.rand seed to something we know.
-	// reseed with clock on completion
-	rand.Seed(1)
-	defer rand.Seed(int64(time.Now().Second()))
-
 	if output == nil {
 		return errors.New(errors.PanicError, "RegressAdd()", "io.Writer should not be nil (use nopWriter)")
 	}
@@ -125,16 +145,17 @@ func RegressAdd(output io.Writer, reg Regressor) error {
 	return db.Add(reg)
 }
 
-// RegressDelete removes a cartridge from the regression db
-func RegressDelete(output io.Writer, confirmation io.Reader, key string) error {
+// RegressDelete removes the entries selected by the filter from the regression
+// db. The filter must not be empty.
+func RegressDelete(output io.Writer, confirmation io.Reader, flt Filter) error {
 	if output == nil {
 		return errors.New(errors.PanicError, "RegressDelete()", "io.Writer should not be nil (use nopWriter)")
 	}
 
-	v, err := strconv.Atoi(key)
-	if err != nil {
-		msg := fmt.Sprintf("invalid key [%s]", key)
-		return errors.New(errors.RegressionError, msg)
+	// an empty filter would select every entry. that's almost certainly not
+	// what is wanted
+	if flt.IsEmpty() {
+		return errors.New(errors.RegressionError, "no entries specified for deletion")
 	}
 
 	dbPth, err := paths.ResourcePath("", regressionDBFile)
@@ -148,18 +169,23 @@ func RegressDelete(output io.Writer, confirmation io.Reader, key string) error {
 	}
 	defer db.EndSession(true)
 
-	ent, err := db.SelectKeys(nil, v)
+	sel, err := flt.selectEntries(db)
 	if err != nil {
-		if !errors.Is(err, errors.DatabaseSelectEmpty) {
-			return err
-		}
+		return err
+	}
 
-		// select returned no entries; create DatabaseKeyError and wrap it in a
-		// RegressionError
-		return errors.New(errors.RegressionError, errors.New(errors.DatabaseKeyError, v))
+	if len(sel) == 0 {
+		return errors.New(errors.RegressionError, errors.New(errors.DatabaseSelectEmpty))
 	}
 
-	output.Write([]byte(fmt.Sprintf("%s\ndelete? (y/n): ", ent)))
+	if len(sel) == 1 {
+		output.Write([]byte(fmt.Sprintf("%s\ndelete? (y/n): ", sel[0].reg)))
+	} else {
+		for _, e := range sel {
+			output.Write([]byte(fmt.Sprintf("%03d %s\n", e.key, e.reg)))
+		}
+		output.Write([]byte(fmt.Sprintf("delete %d entries? (y/n): ", len(sel))))
+	}
 
 	confirm := make([]byte, 32)
 	_, err = confirmation.Read(confirm)
@@ -168,26 +194,39 @@ func RegressDelete(output io.Writer, confirmation io.Reader, key string) error {
 	}
 
 	if confirm[0] == 'y' || confirm[0] == 'Y' {
-		err = db.Delete(v)
-		if err != nil {
-			fmt.Println(1)
-			return err
+		for _, e := range sel {
+			err = db.Delete(e.key)
+			if err != nil {
+				return err
+			}
+			output.Write([]byte(fmt.Sprintf("deleted test #%03d from regression database\n", e.key)))
 		}
-		output.Write([]byte(fmt.Sprintf("deleted test #%s from regression database\n", key)))
 	}
 
 	return nil
 }
 
-// RegressRunTests runs all the tests in the regression database. filterKeys
-// list specified which entries to test. an empty keys list means that every
-// entry should be tested
-func RegressRunTests(output io.Writer, verbose bool, failOnError bool, filterKeys []string) error {
-	// tests must be determinate so we set math.rand seed to something we know.
-	// reseed with clock on completion
-	rand.Seed(1)
-	defer rand.Seed(int64(time.Now().Second()))
+// the result of a single regression test
+type regressResult struct {
+	ok    bool
+	failm string
+	err   error
+	dur   time.Duration
+}
 
+// RegressRunTests runs the tests in the regression database that are selected
+// by the filter. The zero value Filter selects every entry.
+//
+// The numWorkers argument specifies how many tests should be run in parallel.
+// Each test has its own VCS and television so tests are independent of one
+// another. A value of zero (or less) means that the number of workers will be
+// the same as the number of CPUs. The progress of individual tests is only
+// shown when numWorkers is one.
+//
+// If the rep argument is not nil then the result of every entry selected by
+// the filter, including selected entries that were not run, will be added to
+// it.
+func RegressRunTests(output io.Writer, verbose bool, failOnError bool, numWorkers int, flt Filter, rep *Report) error {
 	if output == nil {
 		return errors.New(errors.PanicError, "RegressRunEntries()", "io.Writer should not be nil (use nopWriter)")
 	}
@@ -203,22 +242,20 @@ func RegressRunTests(output io.Writer, verbose bool, failOnError bool, filterKey
 	}
 	defer db.EndSession(false)
 
-	// make sure any supplied keys list is in order
-	keysV := make([]int, 0, len(filterKeys))
-	for k := range filterKeys {
-		v, err := strconv.Atoi(filterKeys[k])
-		if err != nil {
-			msg := fmt.Sprintf("invalid key [%s]", filterKeys[k])
-			return errors.New(errors.RegressionError, msg)
-		}
-		keysV = append(keysV, v)
+	sel, err := flt.selectEntries(db)
+	if err != nil {
+		return err
 	}
-	sort.Ints(keysV)
 
 	numSucceed := 0
 	numFail := 0
 	numError := 0
 
+	// results of the tests that have been run, indexed by database key
+	completed := make(map[int]regressResult)
+
+	startTime := time.Now()
+
 	defer func() {
 		numSkipped := db.NumEntries() - numSucceed - numFail - numError
 
@@ -230,51 +267,123 @@ func RegressRunTests(output io.Writer, verbose bool, failOnError bool, filterKey
 		output.Write([]byte("\n"))
 	}()
 
-	onSelect := func(ent database.Entry) (bool, error) {
-		// datbase entry should also satisfy Regressor interface
-		reg, ok := ent.(Regressor)
-		if !ok {
-			return false, errors.New(errors.PanicError, "RegressRunTests()", "database entry does not satisfy Regressor interface")
-		}
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
 
-		// run regress() function with message. message does not have a
-		// trailing newline
+	// progress output from the regress() function only makes sense if the
+	// tests are being run one at a time
+	progress := output
+	if numWorkers > 1 {
+		progress = ioutil.Discard
+	}
+
+	// run regress() function with message. message does not have a trailing
+	// newline
+	run := func(reg Regressor) regressResult {
 		msg := fmt.Sprintf("running: %s", reg)
-		ok, failm, err := reg.regress(false, output, msg)
+		st := time.Now()
+		ok, failm, err := reg.regress(false, progress, msg)
+		return regressResult{ok: ok, failm: failm, err: err, dur: time.Since(st)}
+	}
+
+	// print completion message depending on result of regress(). returns
+	// false if no more tests should be run
+	report := func(e selectedEntry, res regressResult) bool {
+		completed[e.key] = res
 
 		// once regress() has completed we clear the line ready for the
 		// completion message
 		output.Write([]byte(ansiClearLine))
 
-		// print completion message depending on result of regress()
-		if err != nil {
+		if res.err != nil {
 			numError++
-			output.Write([]byte(fmt.Sprintf("\rerror: %s\n", reg)))
+			output.Write([]byte(fmt.Sprintf("\rerror: %s\n", e.reg)))
 
 			// output any error message on following line
 			if verbose {
-				output.Write([]byte(fmt.Sprintf("  ^^ %s\n", err)))
+				output.Write([]byte(fmt.Sprintf("  ^^ %s\n", res.err)))
 			}
 
 			if failOnError {
-				return false, nil
+				return false
 			}
-		} else if !ok {
+		} else if !res.ok {
 			numFail++
-			output.Write([]byte(fmt.Sprintf("\rfailure: %s\n", reg)))
-			if verbose && failm != "" {
-				output.Write([]byte(fmt.Sprintf("  ^^ %s\n", failm)))
+			output.Write([]byte(fmt.Sprintf("\rfailure: %s\n", e.reg)))
+			if verbose && res.failm != "" {
+				output.Write([]byte(fmt.Sprintf("  ^^ %s\n", res.failm)))
 			}
 
 		} else {
 			numSucceed++
-			output.Write([]byte(fmt.Sprintf("\rsucceed: %s\n", reg)))
+			output.Write([]byte(fmt.Sprintf("\rsucceed: %s\n", e.reg)))
 		}
 
-		return true, nil
+		return true
+	}
+
+	if numWorkers == 1 {
+		for _, e := range sel {
+			if !report(e, run(e.reg)) {
+				break // for loop
+			}
+		}
+	} else {
+		runParallel(sel, numWorkers, run, report)
 	}
 
-	db.SelectKeys(onSelect, keysV...)
+	if rep != nil {
+		rep.collect(sel, completed, startTime)
+	}
 
 	return nil
 }
+
+// run the selected entries with the specified number of workers. the report
+// function is called for each entry in the same order as the selection list.
+func runParallel(sel []selectedEntry, numWorkers int, run func(Regressor) regressResult, report func(selectedEntry, regressResult) bool) {
+	// results are sent over a channel specific to the entry. this is so that
+	// results can be reported in the same order as they would be when running
+	// serially
+	results := make([]chan regressResult, len(sel))
+	for i := range results {
+		results[i] = make(chan regressResult, 1)
+	}
+
+	// stop is closed when no more tests should be started
+	stop := make(chan bool)
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range sel {
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] <- run(sel[i].reg)
+			}
+		}()
+	}
+
+	for i := range sel {
+		if !report(sel[i], <-results[i]) {
+			close(stop)
+			break // for loop
+		}
+	}
+
+	// wait for any tests that are still running to complete
+	wg.Wait()
+}
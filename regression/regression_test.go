@@ -0,0 +1,116 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/cartridgeloader"
+)
+
+// a 4k cartridge that changes the background colour on every scanline. the
+// colours are different on every frame
+var testCartridge = []byte{
+	0xa9, 0x02, // LDA #$02
+	0x85, 0x00, // STA VSYNC
+	0x85, 0x02, // STA WSYNC
+	0x85, 0x02, // STA WSYNC
+	0x85, 0x02, // STA WSYNC
+	0xa9, 0x00, // LDA #$00
+	0x85, 0x00, // STA VSYNC
+	0xa0, 0x00, // LDY #$00
+	0x86, 0x09, // STX COLUBK
+	0xe8,       // INX
+	0x85, 0x02, // STA WSYNC
+	0x88,       // DEY
+	0xd0, 0xf8, // BNE $F010
+	0xe8,             // INX
+	0x4c, 0x00, 0xf0, // JMP $F000
+}
+
+func TestRegressRunParallel(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "regression")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the regression database is created in the working directory
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	rom := make([]byte, 4096)
+	copy(rom, testCartridge)
+	rom[0x0ffc] = 0x00
+	rom[0x0ffd] = 0xf0
+
+	cartFile := filepath.Join(dir, "test.bin")
+	if err := ioutil.WriteFile(cartFile, rom, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// entries that take longer to run are added first so that later entries
+	// will complete before earlier entries when run in parallel
+	var expected []string
+	for _, n := range []int{20, 15, 10, 5, 2} {
+		reg := &DigestRegression{
+			Mode:      DigestVideoOnly,
+			CartLoad:  cartridgeloader.NewLoader(cartFile, "AUTO"),
+			TVtype:    "NTSC",
+			NumFrames: n,
+		}
+		if err := RegressAdd(ioutil.Discard, reg); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, reg.String())
+	}
+
+	output := &strings.Builder{}
+	if err := RegressRunTests(output, false, false, 4, Filter{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []string
+	for _, l := range strings.Split(output.String(), "\n") {
+		if i := strings.Index(l, "\r"); i >= 0 {
+			l = l[i+1:]
+		}
+		if strings.HasPrefix(l, "succeed: ") || strings.HasPrefix(l, "failure: ") || strings.HasPrefix(l, "error: ") {
+			results = append(results, l)
+		}
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results but got %d:\n%s", len(expected), len(results), output)
+	}
+
+	for i := range expected {
+		if results[i] != "succeed: "+expected[i] {
+			t.Errorf("result %d: expected %q but got %q", i, "succeed: "+expected[i], results[i])
+		}
+	}
+}
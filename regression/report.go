@@ -0,0 +1,291 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jetsetilly/gopher2600/errors"
+)
+
+// ReportStatus is the outcome of a single regression test
+type ReportStatus string
+
+// List of valid ReportStatus values
+const (
+	ReportPass  ReportStatus = "pass"
+	ReportFail  ReportStatus = "fail"
+	ReportError ReportStatus = "error"
+	ReportSkip  ReportStatus = "skip"
+)
+
+// ReportEntry is the result of a single regression test
+type ReportEntry struct {
+	// the database key of the entry
+	ID int `json:"id"`
+
+	// description of the test as it would appear in the regression list
+	Name      string       `json:"name"`
+	Cartridge string       `json:"cartridge"`
+	Mode      string       `json:"mode"`
+	Tags      []string     `json:"tags"`
+	Duration  float64      `json:"duration"`
+	Status    ReportStatus `json:"status"`
+
+	// failure message, error message or reason for skipping the test
+	Message string `json:"message,omitempty"`
+}
+
+// Report collects the results of a regression run in a form that is suitable
+// for writing as a machine-readable file. Pass a Report instance to
+// RegressRunTests() and then use one of the write functions.
+type Report struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  float64       `json:"duration"`
+	Pass      int           `json:"pass"`
+	Fail      int           `json:"fail"`
+	Error     int           `json:"error"`
+	Skip      int           `json:"skip"`
+	Tests     []ReportEntry `json:"tests"`
+}
+
+// add result to report and update the totals
+func (rep *Report) add(e ReportEntry) {
+	switch e.Status {
+	case ReportPass:
+		rep.Pass++
+	case ReportFail:
+		rep.Fail++
+	case ReportError:
+		rep.Error++
+	case ReportSkip:
+		rep.Skip++
+	}
+	rep.Tests = append(rep.Tests, e)
+}
+
+// collect the results of a regression run. every entry selected by the filter
+// is added to the report. selected entries that have not been run are marked
+// as skipped. entries that were not selected are not added to the report.
+func (rep *Report) collect(sel []selectedEntry, completed map[int]regressResult, startTime time.Time) {
+	rep.Timestamp = startTime
+	rep.Duration = time.Since(startTime).Seconds()
+
+	for _, s := range sel {
+		k := s.key
+		reg := s.reg
+
+		_, tags := reg.annotations()
+		if tags == nil {
+			tags = []string{}
+		}
+
+		e := ReportEntry{
+			ID:   k,
+			Name: reg.String(),
+			Tags: tags,
+		}
+		e.Cartridge, e.Mode = reg.summary()
+
+		if res, ok := completed[k]; ok {
+			e.Duration = res.dur.Seconds()
+			if res.err != nil {
+				e.Status = ReportError
+				e.Message = res.err.Error()
+			} else if !res.ok {
+				e.Status = ReportFail
+				e.Message = res.failm
+			} else {
+				e.Status = ReportPass
+			}
+		} else {
+			e.Status = ReportSkip
+			e.Message = "not run because of an earlier error"
+		}
+
+		rep.add(e)
+	}
+}
+
+// CheckReportFilename returns an error if the file extension does not
+// indicate a supported report format. Useful for checking filenames before
+// the regression tests are started.
+func CheckReportFilename(filename string) error {
+	_, err := (&Report{}).writer(filename)
+	return err
+}
+
+// returns the write function for the format indicated by the filename
+func (rep *Report) writer(filename string) (func(io.Writer) error, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xml":
+		return rep.WriteJUnit, nil
+	case ".json":
+		return rep.WriteJSON, nil
+	}
+	msg := fmt.Sprintf("unrecognised report format (%s)", filename)
+	return nil, errors.New(errors.RegressionError, msg)
+}
+
+// Write the report to the named file. The format of the report is decided by
+// the file extension: ".xml" for JUnit XML and ".json" for JSON.
+func (rep *Report) Write(filename string) error {
+	write, err := rep.writer(filename)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.New(errors.RegressionError, err)
+	}
+
+	err = write(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return errors.New(errors.RegressionError, err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the report to the io.Writer as a JSON document
+func (rep *Report) WriteJSON(output io.Writer) error {
+	enc := json.NewEncoder(output)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(rep)
+	if err != nil {
+		return errors.New(errors.RegressionError, err)
+	}
+	return nil
+}
+
+// the JUnit XML format is not formally specified but the following is
+// understood by the common CI tools
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name       string        `xml:"name,attr"`
+	Classname  string        `xml:"classname,attr"`
+	Time       string        `xml:"time,attr"`
+	Properties *junitProps   `xml:"properties,omitempty"`
+	Failure    *junitFailure `xml:"failure,omitempty"`
+	Error      *junitFailure `xml:"error,omitempty"`
+	Skipped    *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitProps struct {
+	Props []junitProp `xml:"property"`
+}
+
+type junitProp struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteJUnit writes the report to the io.Writer as a JUnit XML document
+func (rep *Report) WriteJUnit(output io.Writer) error {
+	suite := junitSuite{
+		Name:      "regression",
+		Tests:     len(rep.Tests),
+		Failures:  rep.Fail,
+		Errors:    rep.Error,
+		Skipped:   rep.Skip,
+		Time:      fmt.Sprintf("%.3f", rep.Duration),
+		Timestamp: rep.Timestamp.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, e := range rep.Tests {
+		c := junitCase{
+			Name:      fmt.Sprintf("%03d %s", e.ID, e.Name),
+			Classname: fmt.Sprintf("regression.%s", e.Mode),
+			Time:      fmt.Sprintf("%.3f", e.Duration),
+			Properties: &junitProps{Props: []junitProp{
+				{Name: "id", Value: fmt.Sprintf("%d", e.ID)},
+				{Name: "cartridge", Value: e.Cartridge},
+				{Name: "mode", Value: e.Mode},
+				{Name: "tags", Value: strings.Join(e.Tags, ",")},
+			}},
+		}
+
+		switch e.Status {
+		case ReportFail:
+			c.Failure = &junitFailure{Message: e.Message, Type: "failure", Body: e.Message}
+		case ReportError:
+			c.Error = &junitFailure{Message: e.Message, Type: "error", Body: e.Message}
+		case ReportSkip:
+			c.Skipped = &junitSkipped{Message: e.Message}
+		}
+
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	_, err := output.Write([]byte(xml.Header))
+	if err != nil {
+		return errors.New(errors.RegressionError, err)
+	}
+
+	enc := xml.NewEncoder(output)
+	enc.Indent("", "  ")
+	err = enc.Encode(junitSuites{Suites: []junitSuite{suite}})
+	if err != nil {
+		return errors.New(errors.RegressionError, err)
+	}
+
+	_, err = output.Write([]byte("\n"))
+	if err != nil {
+		return errors.New(errors.RegressionError, err)
+	}
+
+	return nil
+}
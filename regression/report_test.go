@@ -0,0 +1,82 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package regression
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// build a report with one entry for each status
+func newTestReport() *Report {
+	sel := []selectedEntry{
+		{key: 0, reg: &mockEntry{notes: "pass", tags: []string{"ntsc", "video"}, cart: "pass.bin"}},
+		{key: 2, reg: &mockEntry{notes: "fail", cart: "fail.bin"}},
+		{key: 3, reg: &mockEntry{notes: "error <&>", cart: "error.bin"}},
+		{key: 5, reg: &mockEntry{notes: "skip", cart: "skip.bin"}},
+	}
+
+	completed := map[int]regressResult{
+		0: {ok: true, dur: 1500 * time.Millisecond},
+		2: {ok: false, failm: "digest mismatch", dur: 250 * time.Millisecond},
+		3: {err: fmt.Errorf("cartridge error"), dur: 10 * time.Millisecond},
+	}
+
+	rep := &Report{}
+	rep.collect(sel, completed, time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC))
+
+	// the duration of the report is measured by collect() so we need to set
+	// it to something known
+	rep.Duration = 2.5
+
+	return rep
+}
+
+func TestReport(t *testing.T) {
+	rep := newTestReport()
+
+	if rep.Pass != 1 || rep.Fail != 1 || rep.Error != 1 || rep.Skip != 1 {
+		t.Errorf("unexpected totals: %d pass, %d fail, %d error, %d skip", rep.Pass, rep.Fail, rep.Error, rep.Skip)
+	}
+
+	tests := []struct {
+		golden string
+		write  func(*bytes.Buffer) error
+	}{
+		{"report.json", func(b *bytes.Buffer) error { return rep.WriteJSON(b) }},
+		{"report.xml", func(b *bytes.Buffer) error { return rep.WriteJUnit(b) }},
+	}
+
+	for _, tc := range tests {
+		b := &bytes.Buffer{}
+		if err := tc.write(b); err != nil {
+			t.Fatalf("%s: %s", tc.golden, err)
+		}
+
+		golden, err := ioutil.ReadFile(filepath.Join("testdata", tc.golden))
+		if err != nil {
+			t.Fatalf("%s: %s", tc.golden, err)
+		}
+
+		if !bytes.Equal(b.Bytes(), golden) {
+			t.Errorf("%s: output does not match golden file:\n%s", tc.golden, b.String())
+		}
+	}
+}
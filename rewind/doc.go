@@ -0,0 +1,39 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package rewind keeps a history of emulation states so that the emulation
+// can be wound backwards. A snapshot of the VCS is taken at regular frame
+// intervals and stored in a ring buffer. The oldest snapshot is discarded to
+// make room for a new one when the buffer is full.
+//
+// Positions between snapshots are reached by restoring the nearest preceding
+// snapshot and then running the emulation forward with the normal
+// hardware.VCS Step() and RunForFrameCount() functions. In this way, we can
+// step back a single CPU instruction, rewind by a number of frames, or run
+// backwards until a condition is met.
+//
+// Snapshots are taken at the start of a frame. The Rewind type is registered
+// with the television as a FrameTrigger and the snapshot is taken by the
+// Check() function at the first CPU instruction boundary after the frame has
+// started. Check() should be called after every CPU instruction but does no
+// work at other times.
+//
+// Note that user input is not recorded or replayed. The input state is part
+// of each snapshot but any input that arrives after the snapshot is lost when
+// the emulation is rerun from that snapshot. Positions reached by StepBack()
+// and RunBackUntil() may therefore differ from when they were first reached.
+// RewindFrames() is not affected when there is a snapshot for the target
+// frame, which is always the case with a snapshot frequency of one.
+package rewind
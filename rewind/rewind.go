@@ -0,0 +1,374 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package rewind
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// default values for NewRewind()
+const (
+	// the maximum number of snapshots to keep
+	DefaultLength = 100
+
+	// the number of frames between each snapshot
+	DefaultFrequency = 1
+)
+
+// position of the emulation, as reported by the television
+type position struct {
+	frame    int
+	scanline int
+	horizPos int
+}
+
+// before returns true if position p occurs before position q
+func (p position) before(q position) bool {
+	if p.frame != q.frame {
+		return p.frame < q.frame
+	}
+	if p.scanline != q.scanline {
+		return p.scanline < q.scanline
+	}
+	return p.horizPos < q.horizPos
+}
+
+type entry struct {
+	state *hardware.State
+	pos   position
+}
+
+// Rewind keeps a history of VCS snapshots and uses them to wind the emulation
+// backwards
+type Rewind struct {
+	vcs *hardware.VCS
+
+	// the number of frames between each snapshot
+	frequency int
+
+	// ring buffer of snapshots. the oldest entry is at index start and there
+	// are count entries in total
+	entries []entry
+	start   int
+	count   int
+
+	// a new frame has been started since the last call to Check(). set by
+	// NewFrame()
+	newFrame bool
+}
+
+// NewRewind is the preferred method of initialisation for the Rewind type.
+// The length argument is the maximum number of snapshots to keep and the
+// frequency argument is the number of frames between each snapshot.
+func NewRewind(vcs *hardware.VCS, length int, frequency int) (*Rewind, error) {
+	if length < 1 {
+		return nil, errors.New(errors.RewindError, "history length must be at least one")
+	}
+
+	if frequency < 1 {
+		return nil, errors.New(errors.RewindError, "snapshot frequency must be at least one frame")
+	}
+
+	r := &Rewind{
+		vcs:       vcs,
+		frequency: frequency,
+		entries:   make([]entry, length),
+	}
+
+	// snapshots are only taken at the start of a frame
+	vcs.TV.AddFrameTrigger(r)
+
+	return r, nil
+}
+
+// String returns a summary of the rewind history
+func (r *Rewind) String() string {
+	if r.count == 0 {
+		return "no rewind history"
+	}
+	return fmt.Sprintf("%d snapshots [frame %d to frame %d]", r.count,
+		r.entries[r.idx(0)].pos.frame, r.entries[r.idx(r.count-1)].pos.frame)
+}
+
+// Reset discards the rewind history. It should be called whenever a new
+// cartridge is attached or the state of the VCS is changed by some means
+// other than normal emulation.
+func (r *Rewind) Reset() {
+	for i := range r.entries {
+		r.entries[i] = entry{}
+	}
+	r.start = 0
+	r.count = 0
+}
+
+// NewFrame implements the television.FrameTrigger interface
+func (r *Rewind) NewFrame(_ int, _ bool) error {
+	r.newFrame = true
+	return nil
+}
+
+// Check whether a new snapshot is required and take one if necessary. Should
+// be called after every CPU instruction.
+//
+// A snapshot is only considered at the first instruction boundary of a new
+// frame. At all other times Check() returns immediately. The exception is
+// when the history is empty, in which case a snapshot is taken straight away.
+func (r *Rewind) Check() {
+	if !r.newFrame && r.count > 0 {
+		return
+	}
+	r.newFrame = false
+
+	p := r.pos()
+
+	if r.count > 0 {
+		last := r.entries[r.idx(r.count-1)].pos
+
+		// the emulation has moved backwards without our knowledge. most
+		// likely because the VCS has been reset
+		if p.before(last) {
+			r.truncate(p)
+			if r.count > 0 {
+				last = r.entries[r.idx(r.count-1)].pos
+			}
+		}
+
+		if r.count > 0 && p.frame < last.frame+r.frequency {
+			return
+		}
+	}
+
+	e := entry{state: r.vcs.Snapshot(), pos: p}
+
+	if r.count < len(r.entries) {
+		r.entries[r.idx(r.count)] = e
+		r.count++
+	} else {
+		r.entries[r.start] = e
+		r.start = (r.start + 1) % len(r.entries)
+	}
+}
+
+// StepBack winds the emulation back by one CPU instruction
+func (r *Rewind) StepBack() error {
+	cur := r.pos()
+
+	i := r.findBefore(cur)
+	if i == -1 {
+		return errors.New(errors.RewindError, "no history before current position")
+	}
+
+	// count the number of instructions required to reach the current
+	// position from the snapshot
+	err := r.restore(i)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for r.pos().before(cur) {
+		err = r.vcs.Step(nil)
+		if err != nil {
+			return err
+		}
+		n++
+	}
+
+	// and run again from the snapshot, stopping one instruction short
+	err = r.restore(i)
+	if err != nil {
+		return err
+	}
+
+	for ; n > 1; n-- {
+		err = r.vcs.Step(nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.rewound()
+
+	return nil
+}
+
+// RewindFrames winds the emulation back to the start of the frame numFrames
+// before the current frame. If there is not enough history then the
+// emulation is wound back as far as possible.
+func (r *Rewind) RewindFrames(numFrames int) error {
+	if numFrames < 1 {
+		return errors.New(errors.RewindError, "number of frames must be at least one")
+	}
+
+	if r.count == 0 {
+		return errors.New(errors.RewindError, "no history before current position")
+	}
+
+	target := r.pos().frame - numFrames
+
+	// find newest snapshot taken at or before the target frame. if there is
+	// no such snapshot then use the oldest snapshot
+	i := 0
+	for j := r.count - 1; j >= 0; j-- {
+		if r.entries[r.idx(j)].pos.frame <= target {
+			i = j
+			break // for loop
+		}
+	}
+
+	err := r.restore(i)
+	if err != nil {
+		return err
+	}
+
+	// snapshots are taken shortly after the start of a frame but depending
+	// on the snapshot frequency there may be some frames to run through
+	// before we reach the target
+	if frame := r.entries[r.idx(i)].pos.frame; frame < target {
+		err = r.vcs.RunForFrameCount(target-frame, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.rewound()
+
+	return nil
+}
+
+// RunBackUntil winds the emulation back to the most recent CPU instruction
+// boundary at which the cond function started to return true. Returns false
+// if no such point exists in the rewind history, in which case the emulation
+// will be left at the oldest point in the history.
+func (r *Rewind) RunBackUntil(cond func() bool) (bool, error) {
+	cur := r.pos()
+
+	i := r.findBefore(cur)
+	if i == -1 {
+		return false, errors.New(errors.RewindError, "no history before current position")
+	}
+
+	// search each section of the history in turn, starting with the most
+	// recent. each section runs from a snapshot to the next snapshot (or the
+	// current position)
+	limit := cur
+	for ; i >= 0; i-- {
+		err := r.restore(i)
+		if err != nil {
+			return false, err
+		}
+
+		prev := cond()
+		n := 0
+		match := -1
+
+		for r.pos().before(limit) {
+			err = r.vcs.Step(nil)
+			if err != nil {
+				return false, err
+			}
+			n++
+
+			c := cond()
+			if c && !prev && r.pos().before(cur) {
+				match = n
+			}
+			prev = c
+		}
+
+		if match != -1 {
+			err = r.restore(i)
+			if err != nil {
+				return false, err
+			}
+
+			for ; match > 0; match-- {
+				err = r.vcs.Step(nil)
+				if err != nil {
+					return false, err
+				}
+			}
+
+			r.rewound()
+
+			return true, nil
+		}
+
+		limit = r.entries[r.idx(i)].pos
+	}
+
+	// condition never met. leave emulation at oldest snapshot
+	err := r.restore(0)
+	if err != nil {
+		return false, err
+	}
+	r.rewound()
+
+	return false, nil
+}
+
+// the current position of the emulation
+func (r *Rewind) pos() position {
+	var p position
+	p.frame, _ = r.vcs.TV.GetState(television.ReqFramenum)
+	p.scanline, _ = r.vcs.TV.GetState(television.ReqScanline)
+	p.horizPos, _ = r.vcs.TV.GetState(television.ReqHorizPos)
+	return p
+}
+
+// convert history index (where zero is the oldest entry) to index in the
+// entries array
+func (r *Rewind) idx(i int) int {
+	return (r.start + i) % len(r.entries)
+}
+
+// returns the history index of the newest entry that occurs before position
+// p. returns -1 if there is no such entry
+func (r *Rewind) findBefore(p position) int {
+	for i := r.count - 1; i >= 0; i-- {
+		if r.entries[r.idx(i)].pos.before(p) {
+			return i
+		}
+	}
+	return -1
+}
+
+// restore VCS to the state of history entry i
+func (r *Rewind) restore(i int) error {
+	return r.vcs.Restore(r.entries[r.idx(i)].state)
+}
+
+// the emulation has been wound back to the current position
+func (r *Rewind) rewound() {
+	r.truncate(r.pos())
+
+	// frames started while the emulation was being rerun are not a reason
+	// to take a snapshot
+	r.newFrame = false
+}
+
+// discard any entries that occur after position p. the history is no longer
+// valid from that point because the emulation may take a different path
+func (r *Rewind) truncate(p position) {
+	for r.count > 0 && p.before(r.entries[r.idx(r.count-1)].pos) {
+		r.entries[r.idx(r.count-1)] = entry{}
+		r.count--
+	}
+}
@@ -0,0 +1,333 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package rewind
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/cartridgeloader"
+	"github.com/jetsetilly/gopher2600/hardware"
+	"github.com/jetsetilly/gopher2600/hardware/riot/input"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// a simple 4k program that produces a complete frame every 262 scanlines and
+// changes the contents of RAM every frame
+var program = []uint8{
+	0x78,       // SEI
+	0xd8,       // CLD
+	0xa2, 0xff, // LDX #$ff
+	0x9a,       // TXS
+	0xa9, 0x02, // LDA #$02 (frame)
+	0x85, 0x00, // STA VSYNC
+	0x85, 0x02, // STA WSYNC
+	0x85, 0x02, // STA WSYNC
+	0x85, 0x02, // STA WSYNC
+	0xa9, 0x00, // LDA #$00
+	0x85, 0x00, // STA VSYNC
+	0xe6, 0x80, // INC $80
+	0xa0, 0xff, // LDY #$ff
+	0x85, 0x02, // STA WSYNC
+	0x88,       // DEY
+	0xd0, 0xfb, // BNE
+	0xa0, 0x04, // LDY #$04
+	0x85, 0x02, // STA WSYNC
+	0x88,       // DEY
+	0xd0, 0xfb, // BNE
+	0x4c, 0x05, 0xf0, // JMP frame
+}
+
+func newTestVCS(t *testing.T) *hardware.VCS {
+	t.Helper()
+
+	rom := make([]uint8, 4096)
+	copy(rom, program)
+	rom[0x0ffc], rom[0x0ffd] = 0x00, 0xf0
+
+	dir, err := ioutil.TempDir("", "gopher2600_rewind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.bin")
+	err = ioutil.WriteFile(filename, rom, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vcs, err := hardware.NewVCS(tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = vcs.AttachCartridge(cartridgeloader.NewLoader(filename, "AUTO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return vcs
+}
+
+// step the emulation by one CPU instruction, checking the rewind history
+// afterwards in the same way as the debugger and playmode loops
+func step(t *testing.T, r *Rewind) {
+	t.Helper()
+	if err := r.vcs.Step(nil); err != nil {
+		t.Fatal(err)
+	}
+	r.Check()
+}
+
+// step the emulation until the start of the frame numFrames from the current
+// frame
+func runFrames(t *testing.T, r *Rewind, numFrames int) {
+	t.Helper()
+	target := r.pos().frame + numFrames
+	for r.pos().frame < target {
+		step(t, r)
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	vcs := newTestVCS(t)
+
+	r, err := NewRewind(vcs, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first snapshot is taken immediately so there will be one more
+	// entry than the number of frames that have been run
+	runFrames(t, r, 3)
+	if r.count != 4 || r.start != 0 {
+		t.Errorf("unexpected history before wraparound: count=%d start=%d", r.count, r.start)
+	}
+
+	// run for more frames than there are entries in the history
+	runFrames(t, r, 9)
+
+	if r.count != len(r.entries) {
+		t.Fatalf("history should be full: count=%d", r.count)
+	}
+	if r.start == 0 {
+		t.Errorf("oldest entry should have moved after wraparound")
+	}
+
+	// entries should be in frame order, with the newest entry for the current
+	// frame
+	cur := r.pos().frame
+	for i := 0; i < r.count; i++ {
+		e := r.entries[r.idx(i)]
+		if e.state == nil {
+			t.Fatalf("entry %d is empty", i)
+		}
+		if e.pos.frame != cur-(r.count-1-i) {
+			t.Errorf("entry %d is for frame %d (expected %d)", i, e.pos.frame, cur-(r.count-1-i))
+		}
+	}
+}
+
+func TestSnapshotAtFrameStart(t *testing.T) {
+	vcs := newTestVCS(t)
+
+	r, err := NewRewind(vcs, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// step part way into the frame before the first snapshot is taken
+	for i := 0; i < 100; i++ {
+		if err := vcs.Step(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runFrames(t, r, 5)
+
+	// apart from the first snapshot, which is taken immediately, every
+	// snapshot is taken on the first scanline of a frame
+	for i := 1; i < r.count; i++ {
+		if p := r.entries[r.idx(i)].pos; p.scanline != 0 {
+			t.Errorf("entry %d was not taken at the start of a frame: %v", i, p)
+		}
+	}
+
+	// calls to Check() part way through a frame do not take a snapshot
+	count := r.count
+	for i := 0; i < 100; i++ {
+		step(t, r)
+	}
+	if r.count != count {
+		t.Errorf("snapshot taken part way through a frame: count=%d (expected %d)", r.count, count)
+	}
+}
+
+// user input is not recorded so input that arrives after a snapshot is lost
+// when the emulation is rerun from that snapshot. this test documents that
+// limitation
+func TestInputNotReplayed(t *testing.T) {
+	vcs := newTestVCS(t)
+
+	r, err := NewRewind(vcs, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runFrames(t, r, 2)
+
+	swcha := func() uint8 {
+		t.Helper()
+		d, err := vcs.Mem.RIOT.Peek(0x0280)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	released := swcha()
+
+	// push the joystick part way through the frame
+	for i := 0; i < 10; i++ {
+		step(t, r)
+	}
+	err = vcs.HandController0.Handle(input.Left, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step(t, r)
+	step(t, r)
+
+	pushed := swcha()
+	if pushed == released {
+		t.Fatalf("SWCHA has not changed after joystick input")
+	}
+
+	// stepping back reruns the emulation from the snapshot at the start of
+	// the frame, at which point the joystick had not been pushed
+	err = r.StepBack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := swcha(); d != released {
+		t.Errorf("SWCHA after step back is %#02x (expected %#02x)", d, released)
+	}
+}
+
+func TestStepBackAcrossFrame(t *testing.T) {
+	vcs := newTestVCS(t)
+
+	r, err := NewRewind(vcs, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runFrames(t, r, 3)
+
+	// step to the first instruction of the next frame, noting the position
+	// of the previous instruction
+	var prevPos position
+	var prevPC uint16
+
+	frame := r.pos().frame
+	for r.pos().frame == frame {
+		prevPos = r.pos()
+		prevPC = vcs.CPU.PC.Value()
+		step(t, r)
+	}
+
+	// there is a snapshot for the new frame but it is not before the current
+	// position so stepping back requires the snapshot for the previous frame
+	err = r.StepBack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.pos() != prevPos {
+		t.Errorf("position after step back is %v (expected %v)", r.pos(), prevPos)
+	}
+	if vcs.CPU.PC.Value() != prevPC {
+		t.Errorf("PC after step back is %04x (expected %04x)", vcs.CPU.PC.Value(), prevPC)
+	}
+
+	// the snapshot for the new frame is now in the future and should have
+	// been discarded
+	if last := r.entries[r.idx(r.count-1)].pos; prevPos.before(last) {
+		t.Errorf("history contains entry after current position: %v", last)
+	}
+
+	// step forward again and we should be back at the new frame
+	step(t, r)
+	if r.pos().frame != frame+1 {
+		t.Errorf("expected to be in frame %d after stepping forward (in %d)", frame+1, r.pos().frame)
+	}
+}
+
+func TestRewindPastOldest(t *testing.T) {
+	vcs := newTestVCS(t)
+
+	r, err := NewRewind(vcs, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing to rewind to
+	err = r.RewindFrames(1)
+	if err == nil {
+		t.Errorf("expected error when there is no history")
+	}
+
+	runFrames(t, r, 10)
+
+	oldest := r.entries[r.idx(0)].pos
+	cur := r.pos()
+	ram := vcs.Mem.RAM.RAM[0]
+
+	// rewinding further than the history allows leaves the emulation at the
+	// oldest entry
+	err = r.RewindFrames(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.pos() != oldest {
+		t.Errorf("position after rewind is %v (expected %v)", r.pos(), oldest)
+	}
+	if r.count != 1 {
+		t.Errorf("history should only contain the oldest entry: count=%d", r.count)
+	}
+
+	// the program increments $80 once per frame
+	if d := ram - vcs.Mem.RAM.RAM[0]; int(d) != cur.frame-oldest.frame {
+		t.Errorf("RAM has been rewound by %d frames (expected %d)", d, cur.frame-oldest.frame)
+	}
+
+	// it should still be possible to run forward and to rewind again
+	runFrames(t, r, 2)
+	err = r.RewindFrames(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package setup
+
+import (
+	"fmt"
+
+	"github.com/jetsetilly/gopher2600/disassembly"
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware"
+	"github.com/jetsetilly/gopher2600/hardware/riot/input"
+	"github.com/jetsetilly/gopher2600/logger"
+	"github.com/jetsetilly/gopher2600/symbols"
+)
+
+// detectControllers uses a disassembly of the attached cartridge to choose
+// the initial controller type for each port. ports for which automatic
+// controller switching has been turned off are not changed. this includes
+// ports that have been set by the properties database and ports for which the
+// controller type has been chosen explicitly by the user.
+func detectControllers(vcs *hardware.VCS) error {
+	if vcs.Mem.Cart.IsEjected() {
+		return nil
+	}
+
+	if !vcs.HandController0.AutoControllerType && !vcs.HandController1.AutoControllerType {
+		return nil
+	}
+
+	dsm, err := disassembly.NewDisassembly()
+	if err != nil {
+		return errors.New(errors.SetupError, err)
+	}
+
+	// ignore errors caused by loading of symbols table - we always get a
+	// standard symbols table even in the event of an error
+	symtable, _ := symbols.ReadSymbolsFile(vcs.Mem.Cart.Filename)
+
+	err = dsm.FromMemory(vcs.Mem.Cart, symtable)
+	if err != nil {
+		return errors.New(errors.SetupError, err)
+	}
+
+	ctrls := dsm.DetectControllers()
+
+	if vcs.HandController0.AutoControllerType {
+		if err := switchController(vcs.HandController0, ctrls.Left); err != nil {
+			return err
+		}
+	}
+
+	if vcs.HandController1.AutoControllerType {
+		if err := switchController(vcs.HandController1, ctrls.Right); err != nil {
+			return err
+		}
+	}
+
+	logger.Log("setup", fmt.Sprintf("detected controllers: left=%s right=%s", ctrls.Left, ctrls.Right))
+
+	return nil
+}
+
+func switchController(hc *input.HandController, hint disassembly.ControllerHint) error {
+	var err error
+
+	switch hint {
+	case disassembly.ControllerPaddle:
+		err = hc.SwitchType(input.PaddleType)
+	case disassembly.ControllerKeypad:
+		err = hc.SwitchType(input.KeypadType)
+	case disassembly.ControllerDriving:
+		err = hc.SwitchType(input.DrivingType)
+	default:
+		// no evidence either way so there is nothing to do
+		return nil
+	}
+
+	if err != nil {
+		return errors.New(errors.SetupError, err)
+	}
+
+	return nil
+}
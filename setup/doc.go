@@ -43,5 +43,10 @@
 //
 //	<DB Key>, television, <SHA-1 Hash>, <tv spec>, notes
 //
-// TV spec should be one of PAL or NTSC (or AUTO)
+// TV spec should be one of NTSC, PAL, PAL-M, PAL60 or SECAM (or AUTO)
+//
+// In addition to the setupDB, the AttachCartridge() function applies entries
+// from the read-only properties database (see the properties package). The
+// properties are applied before the setupDB entries so the setupDB can be used
+// to override the properties.
 package setup
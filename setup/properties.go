@@ -0,0 +1,64 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package setup
+
+import (
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/hardware"
+	"github.com/jetsetilly/gopher2600/hardware/riot/input"
+	"github.com/jetsetilly/gopher2600/properties"
+)
+
+// applyProperties changes the VCS according to the entry from the properties
+// database. the cartridge mapping is not applied here because it must be
+// decided before the cartridge is attached.
+func applyProperties(vcs *hardware.VCS, prop properties.Entry) error {
+	if prop.TVSpec != "" {
+		if err := vcs.TV.SetSpec(prop.TVSpec); err != nil {
+			return errors.New(errors.SetupError, err)
+		}
+	}
+
+	if prop.LeftPortSet {
+		vcs.HandController0.SetAuto(false)
+		if err := vcs.HandController0.SwitchType(prop.LeftPort); err != nil {
+			return errors.New(errors.SetupError, err)
+		}
+	}
+
+	if prop.RightPortSet {
+		vcs.HandController1.SetAuto(false)
+		if err := vcs.HandController1.SwitchType(prop.RightPort); err != nil {
+			return errors.New(errors.SetupError, err)
+		}
+	}
+
+	if prop.LeftDifficulty != properties.DifficultyUnset {
+		pro := prop.LeftDifficulty == properties.DifficultyA
+		if err := vcs.Panel.Handle(input.PanelSetPlayer0Pro, pro); err != nil {
+			return errors.New(errors.SetupError, err)
+		}
+	}
+
+	if prop.RightDifficulty != properties.DifficultyUnset {
+		pro := prop.RightDifficulty == properties.DifficultyA
+		if err := vcs.Panel.Handle(input.PanelSetPlayer1Pro, pro); err != nil {
+			return errors.New(errors.SetupError, err)
+		}
+	}
+
+	return nil
+}
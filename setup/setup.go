@@ -16,11 +16,14 @@
 package setup
 
 import (
+	"strings"
+
 	"github.com/jetsetilly/gopher2600/cartridgeloader"
 	"github.com/jetsetilly/gopher2600/database"
 	"github.com/jetsetilly/gopher2600/errors"
 	"github.com/jetsetilly/gopher2600/hardware"
 	"github.com/jetsetilly/gopher2600/paths"
+	"github.com/jetsetilly/gopher2600/properties"
 )
 
 // the location of the setupDB file
@@ -57,15 +60,72 @@ func initDBSession(db *database.Session) error {
 	return nil
 }
 
-// AttachCartridge to the VCS and apply setup information from the setupDB.
+// AttachCartridge to the VCS and apply setup information from the properties
+// database and the setupDB. The controller type for any port not specified by
+// the properties database, and for which automatic controller switching has
+// not been turned off, is chosen according to a disassembly of the cartridge.
+// Entries in the setupDB are applied after the properties and so take
+// precedence.
+//
 // This function should be preferred to the hardware.VCS.AttachCartridge()
 // function in almost all cases.
 func AttachCartridge(vcs *hardware.VCS, cartload cartridgeloader.Loader) error {
+	return attachCartridge(vcs, cartload, true)
+}
+
+// AttachCartridgeNoDetect is the same as AttachCartridge() except that the
+// controller types are not chosen according to a disassembly of the
+// cartridge. Used by the regression package so that the result of a
+// regression test does not change with improvements to the detection.
+func AttachCartridgeNoDetect(vcs *hardware.VCS, cartload cartridgeloader.Loader) error {
+	return attachCartridge(vcs, cartload, false)
+}
+
+func attachCartridge(vcs *hardware.VCS, cartload cartridgeloader.Loader, detect bool) error {
+	var prop properties.Entry
+	var hasProp bool
+
+	if cartload.Filename != "" {
+		// load cartridge data now so that we know the hash of the cartridge
+		// before it is attached. the properties database may specify the
+		// cartridge mapping
+		_, err := cartload.Load()
+		if err != nil {
+			return err
+		}
+
+		prop, hasProp = properties.Lookup(cartload.Hash)
+
+		if hasProp && prop.Mapping != "" {
+			m := strings.ToUpper(cartload.Mapping)
+			if m == "" || m == "AUTO" {
+				cartload.Mapping = prop.Mapping
+			}
+		}
+	}
+
 	err := vcs.AttachCartridge(cartload)
 	if err != nil {
 		return err
 	}
 
+	if hasProp {
+		err = applyProperties(vcs, prop)
+		if err != nil {
+			return err
+		}
+	}
+
+	// choose controller types from the cartridge disassembly. ports set by
+	// the properties database have automatic switching turned off and so
+	// are not changed. the setupDB may override these choices
+	if detect {
+		err = detectControllers(vcs)
+		if err != nil {
+			return err
+		}
+	}
+
 	dbPth, err := paths.ResourcePath("", setupDBFile)
 	if err != nil {
 		return errors.New(errors.SetupError, err)
@@ -27,7 +27,8 @@
 // It is important to note that the reference television implementation does
 // not render pixels or mix sound itself. Instead, the television interface
 // exposes two functions, AddPixelRenderer() and AddAudioMixer(). These can be
-// used to add as many renderers and mixers as required.
+// used to add as many renderers and mixers as required. AddFrameTrigger() is
+// also available for when only the start of each frame is of interest.
 //
 // The main means of communication is the Signal() function. This function
 // accepts an instance of SignalAttributes which gives details of how the
@@ -40,7 +41,7 @@
 // with Stella is no longer required.
 //
 // The reference implementation also handles framerate limiting according to
-// the current TV specification (ie. PAL or NTSC) or an aribitrary value, using
+// the current TV specification (eg. PAL or NTSC) or an aribitrary value, using
 // the SetFPSCap() function.
 //
 // Framesize adaptation is also handled by the reference implementation and is
@@ -33,6 +33,9 @@ type Television interface {
 	// AddAudioMixer registers an (additional) implementation of AudioMixer
 	AddAudioMixer(AudioMixer)
 
+	// AddFrameTrigger registers an (additional) implementation of FrameTrigger
+	AddFrameTrigger(FrameTrigger)
+
 	Signal(SignalAttributes) error
 
 	// Returns the value of the requested state. eg. the current scanline.
@@ -45,6 +48,16 @@ type Television interface {
 	// GetSpec() rather than keeping a private pointer to the specification.
 	GetSpec() (*Specification, bool)
 
+	// Set the method by which the visible area of the screen is adapted to
+	// the signal. The screen is returned to the ideal size for the current
+	// specification.
+	SetResizer(FrameResizeID) error
+
+	// Set the top and bottom scanlines of the visible area of the screen. Not
+	// all resizers honour the fixed bounds. Values of zero for both top and
+	// bottom remove the fixed bounds.
+	SetFixedBounds(top int, bottom int) error
+
 	// IsStable returns true if the television thinks the image being sent by
 	// the VCS is stable
 	IsStable() bool
@@ -88,6 +101,13 @@ type Television interface {
 
 	// Returns a copy of SignalAttributes for reference
 	GetLastSignal() SignalAttributes
+
+	// Snapshot returns a copy of the television's state. The returned value
+	// can be used with Restore() to return the television to that state
+	Snapshot() *State
+
+	// Restore television state from a copy previously made with Snapshot()
+	Restore(*State) error
 }
 
 // PixelRenderer implementations displays, or otherwise works with, visual
@@ -156,6 +176,13 @@ type PixelRenderer interface {
 	EndRendering() error
 }
 
+// FrameTrigger implementations are notified at the start of every new frame.
+// Unlike PixelRenderer, a FrameTrigger is not called for every pixel and is
+// suitable for when only the frame boundary is of interest.
+type FrameTrigger interface {
+	NewFrame(frameNum int, isStable bool) error
+}
+
 // AudioMixer implementations work with sound; most probably playing it. An
 // example of an AudioMixer that does not play sound but otherwise works with
 // it is the digest.Audio type.
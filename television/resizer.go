@@ -15,17 +15,18 @@
 
 package television
 
+import "sort"
+
 // FrameResizeID identifies the resizing method
 type FrameResizeID string
 
 // List of valid values for FrameResizeID
 const (
-	FrameResizerNone   FrameResizeID = "FrameResizerNone"
-	FrameResizerSimple FrameResizeID = "FrameResizerSimple"
+	FrameResizerNone        FrameResizeID = "FrameResizerNone"
+	FrameResizerSimple      FrameResizeID = "FrameResizerSimple"
+	FrameResizerStatistical FrameResizeID = "FrameResizerStatistical"
 )
 
-// !!TODO: more sophisticated resizer implementations
-
 // the resizer interfaces specifies the operations required by a mechanism that
 // will alter the visible frame of the television
 type resizer interface {
@@ -119,3 +120,127 @@ func (sr *simpleResizer) commit(tv *television) error {
 func (sr *simpleResizer) prepare(tv *television) {
 	sr.bottom = tv.bottom
 }
+
+// the number of frames observed by the statistical resizer
+const statisticalWindow = 60
+
+// the proportion of frames at either extreme that are considered to be
+// outliers by the statistical resizer. a value of 0.1 means that the top and
+// bottom of the screen will accommodate 90% of the frames in the window.
+const statisticalOutliers = 0.1
+
+// the minimum number of scanlines between the top and bottom of a frame for
+// it to be considered by the statistical resizer. frames with fewer scanlines
+// are most likely blank frames between screens.
+const statisticalMinVisible = 16
+
+// statisticalResizer observes the visible extents of many frames and resizes
+// the screen to accommodate most of them. frames at the extremes are treated
+// as outliers and are ignored. this means that the screen doesn't jump when
+// the visible area changes for only a frame or two, for example during screen
+// transitions.
+//
+// if the television has fixed bounds then these are used instead.
+type statisticalResizer struct {
+	// the visible extent of the current frame. a top value of -1 indicates
+	// that no visible scanlines have been seen
+	frameTop    int
+	frameBottom int
+
+	// the visible extents of the most recent frames. next is the index of the
+	// next entry to be filled
+	tops    [statisticalWindow]int
+	bottoms [statisticalWindow]int
+	next    int
+	count   int
+
+	// the specification the window was collected under. the window is reset
+	// when the specification changes
+	spec *Specification
+}
+
+func newStatisticalResizer() *statisticalResizer {
+	return &statisticalResizer{
+		frameTop:    -1,
+		frameBottom: -1,
+	}
+}
+
+func (sr statisticalResizer) id() FrameResizeID {
+	return FrameResizerStatistical
+}
+
+func (sr *statisticalResizer) examine(tv *television, sig SignalAttributes) {
+	if !sig.VBlank {
+		if sr.frameTop == -1 {
+			sr.frameTop = tv.scanline
+		}
+		sr.frameBottom = tv.scanline
+	}
+}
+
+func (sr *statisticalResizer) commit(tv *television) error {
+	if tv.spec != sr.spec {
+		sr.spec = tv.spec
+		sr.count = 0
+		sr.next = 0
+	}
+
+	top := tv.top
+	bottom := tv.bottom
+
+	if tv.fixedBottom > tv.fixedTop {
+		top = tv.fixedTop
+		bottom = tv.fixedBottom
+	} else {
+		// ignore frames from the setup phase and frames that are mostly blank
+		if tv.syncedFrameNum <= leadingFrames || sr.frameTop == -1 || sr.frameBottom-sr.frameTop < statisticalMinVisible {
+			return nil
+		}
+
+		sr.tops[sr.next] = sr.frameTop
+		sr.bottoms[sr.next] = sr.frameBottom
+		sr.next = (sr.next + 1) % statisticalWindow
+		if sr.count < statisticalWindow {
+			sr.count++
+		}
+
+		// wait until the window is full before resizing
+		if sr.count < statisticalWindow {
+			return nil
+		}
+
+		tops := sr.tops
+		bottoms := sr.bottoms
+		sort.Ints(tops[:])
+		sort.Ints(bottoms[:])
+
+		// the smallest top and the largest bottom once the outliers have
+		// been discounted
+		outliers := int(statisticalWindow * statisticalOutliers)
+		top = tops[outliers]
+		bottom = bottoms[statisticalWindow-1-outliers]
+	}
+
+	if top == tv.top && bottom == tv.bottom {
+		return nil
+	}
+
+	tv.top = top
+	tv.bottom = bottom
+
+	// call Resize() for all attached pixel rendered
+	for f := range tv.renderers {
+		err := tv.renderers[f].Resize(tv.spec, tv.top, tv.bottom-tv.top)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sr *statisticalResizer) prepare(tv *television) {
+	sr.frameTop = -1
+	sr.frameBottom = -1
+}
@@ -0,0 +1,124 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package television_test
+
+import (
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+type resizeRenderer struct {
+	top     int
+	visible int
+}
+
+func (r *resizeRenderer) Resize(_ *television.Specification, top, visible int) error {
+	r.top = top
+	r.visible = visible
+	return nil
+}
+
+func (r *resizeRenderer) NewFrame(_ int, _ bool) error                  { return nil }
+func (r *resizeRenderer) NewScanline(_ int) error                       { return nil }
+func (r *resizeRenderer) SetPixel(_, _ int, _, _, _ byte, _ bool) error { return nil }
+func (r *resizeRenderer) EndRendering() error                           { return nil }
+
+// send a frame to the television with VBLANK off between the top and bottom
+// scanlines
+func sendFrame(t *testing.T, tv television.Television, top int, bottom int) {
+	t.Helper()
+
+	for sl := 0; sl < 262; sl++ {
+		for cl := 0; cl < television.HorizClksScanline; cl++ {
+			sig := television.SignalAttributes{
+				VSync:  sl < 3,
+				HSync:  cl >= 16 && cl < 32,
+				VBlank: sl < top || sl > bottom,
+				Pixel:  television.VideoBlack,
+			}
+			if err := tv.Signal(sig); err != nil {
+				t.Fatalf(err.Error())
+			}
+		}
+	}
+}
+
+func newResizeTV(t *testing.T) (television.Television, *resizeRenderer) {
+	t.Helper()
+
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	tv.SetFPSCap(false)
+
+	r := &resizeRenderer{}
+	tv.AddPixelRenderer(r)
+
+	err = tv.SetResizer(television.FrameResizerStatistical)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return tv, r
+}
+
+func TestStatisticalResizer(t *testing.T) {
+	// frames that are all the same size
+	tv, ref := newResizeTV(t)
+	for i := 0; i < 100; i++ {
+		sendFrame(t, tv, 30, 230)
+	}
+
+	// VSYNC ends three scanlines into the frame sent by sendFrame() and the
+	// horizontal position is realigned by HSYNC, so the final color clocks of
+	// each scanline fall on the next television scanline. the visible area of
+	// scanlines 30 to 230 is therefore television scanlines 27 to 228
+	if ref.top != 27 || ref.visible != 201 {
+		t.Errorf("unexpected screen size (top %d, visible %d)", ref.top, ref.visible)
+	}
+
+	// the same frames with the occasional outlier
+	tv, r := newResizeTV(t)
+	for i := 0; i < 100; i++ {
+		if i%20 == 0 {
+			sendFrame(t, tv, 10, 250)
+		} else {
+			sendFrame(t, tv, 30, 230)
+		}
+	}
+
+	if r.top != ref.top || r.visible != ref.visible {
+		t.Errorf("outliers not ignored by statistical resizer (top %d, visible %d)", r.top, r.visible)
+	}
+
+	// fixed bounds take precedence
+	err := tv.SetFixedBounds(20, 240)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	sendFrame(t, tv, 30, 230)
+
+	if r.top != 20 || r.visible != 220 {
+		t.Errorf("unexpected screen size with fixed bounds (top %d, visible %d)", r.top, r.visible)
+	}
+
+	err = tv.SetFixedBounds(240, 20)
+	if err == nil {
+		t.Errorf("unsuitable fixed bounds unexpectedly accepted")
+	}
+}
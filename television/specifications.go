@@ -18,9 +18,9 @@ package television
 import "image/color"
 
 // SpecificationList is the list of specifications that the television may adopt
-var SpecificationList = []string{"NTSC", "PAL"}
+var SpecificationList = []string{"NTSC", "PAL", "PAL-M", "PAL60", "SECAM"}
 
-// Specification is used to define the two television specifications
+// Specification is used to define the television specifications
 type Specification struct {
 	ID     string
 	Colors []color.RGBA
@@ -91,8 +91,8 @@ func (spec *Specification) getColor(col ColorSignal) color.RGBA {
 // to the left side of the screen, waits for the 68 horizontal blank clock
 // counts, and proceeds to draw the next line below."
 //
-// Horizontal clock counts are the same for both TV specifications. Vertical
-// information should be accessed via SpecNTSC or SpecPAL.
+// Horizontal clock counts are the same for all TV specifications. Vertical
+// information should be accessed via SpecNTSC, SpecPAL, etc.
 const (
 	HorizClksHBlank   = 68
 	HorizClksVisible  = 160
@@ -109,6 +109,18 @@ var SpecNTSC *Specification
 // SpecPAL is the specification for PAL television types
 var SpecPAL *Specification
 
+// SpecPALM is the specification for PAL-M television types. PAL-M was used in
+// Brazil and combines the timing of NTSC with the PAL palette
+var SpecPALM *Specification
+
+// SpecPAL60 is the specification for PAL cartridges that output a 60Hz
+// signal. like PAL-M it combines the timing of NTSC with the PAL palette
+var SpecPAL60 *Specification
+
+// SpecSECAM is the specification for SECAM television types. SECAM uses the
+// timing of PAL but the palette is limited to eight colours
+var SpecSECAM *Specification
+
 func init() {
 	SpecNTSC = &Specification{
 		ID:                "NTSC",
@@ -139,4 +151,51 @@ func init() {
 
 	SpecPAL.ScanlineTop = SpecPAL.scanlinesVBlank + SpecPAL.ScanlinesVSync
 	SpecPAL.ScanlineBottom = SpecPAL.ScanlinesTotal - SpecPAL.ScanlinesOverscan
+
+	SpecPALM = &Specification{
+		ID:                "PAL-M",
+		Colors:            PalettePAL,
+		ScanlinesVSync:    3,
+		scanlinesVBlank:   37,
+		ScanlinesVisible:  192,
+		ScanlinesOverscan: 30,
+		ScanlinesTotal:    262,
+		FramesPerSecond:   60.0,
+		AspectBias:        0.91,
+	}
+
+	SpecPALM.ScanlineTop = SpecPALM.scanlinesVBlank + SpecPALM.ScanlinesVSync
+	SpecPALM.ScanlineBottom = SpecPALM.ScanlinesTotal - SpecPALM.ScanlinesOverscan
+
+	// the aspect bias of PAL60 is the same as PAL. the TIA in a PAL console
+	// outputs pixels at the PAL rate regardless of the number of scanlines
+	SpecPAL60 = &Specification{
+		ID:                "PAL60",
+		Colors:            PalettePAL,
+		ScanlinesVSync:    3,
+		scanlinesVBlank:   37,
+		ScanlinesVisible:  192,
+		ScanlinesOverscan: 30,
+		ScanlinesTotal:    262,
+		FramesPerSecond:   60.0,
+		AspectBias:        1.09,
+	}
+
+	SpecPAL60.ScanlineTop = SpecPAL60.scanlinesVBlank + SpecPAL60.ScanlinesVSync
+	SpecPAL60.ScanlineBottom = SpecPAL60.ScanlinesTotal - SpecPAL60.ScanlinesOverscan
+
+	SpecSECAM = &Specification{
+		ID:                "SECAM",
+		Colors:            PaletteSECAM,
+		ScanlinesVSync:    3,
+		scanlinesVBlank:   45,
+		ScanlinesVisible:  228,
+		ScanlinesOverscan: 36,
+		ScanlinesTotal:    312,
+		FramesPerSecond:   50.0,
+		AspectBias:        1.09,
+	}
+
+	SpecSECAM.ScanlineTop = SpecSECAM.scanlinesVBlank + SpecSECAM.ScanlinesVSync
+	SpecSECAM.ScanlineBottom = SpecSECAM.ScanlinesTotal - SpecSECAM.ScanlinesOverscan
 }
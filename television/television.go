@@ -43,10 +43,20 @@ const leadingFrames = 5
 // the number of synced frames required before the tv frame is considered to "stable"
 const stabilityThreshold = 20
 
+// the number of consecutive frames that must exceed the number of scanlines
+// in the current specification before the TV flips from NTSC to PAL, or from
+// PAL60 to PAL
+const longFramesAuto = 5
+
+// the number of consecutive synced frames with a scanline count suitable for
+// NTSC required before the TV flips from the PAL specification to the PAL60
+// specification
+const shortFramesPAL = 20
+
 // television is a reference implementation of the Television interface. In all
 // honesty, it's most likely the only implementation required.
 type television struct {
-	// television specification (NTSC or PAL)
+	// television specification (NTSC, PAL, etc.)
 	spec *Specification
 
 	// spec on creation ID is the string that was to ID the television
@@ -59,9 +69,16 @@ type television struct {
 	// appears to be outside of the current spec.
 	//
 	// in practice this means that if auto is true then we start with the NTSC
-	// spec and move to PAL if the number of scanlines exceeds the NTSC maximum
+	// spec and move to PAL if the number of scanlines consistently exceeds the
+	// NTSC maximum. if a PAL signal then consistently fits inside the NTSC
+	// frame then we move to PAL60. the colour system cannot be detected from
+	// the signal so SECAM and PAL-M are never chosen automatically.
 	auto bool
 
+	// the number of consecutive frames that suggest the specification should
+	// change. used by autoSpec()
+	specFrames int
+
 	// state of the television
 	//	- the current horizontal position. the position where the next pixel will be
 	//  drawn. also used to check we're receiving the correct signals at the
@@ -96,6 +113,12 @@ type television struct {
 	// frame resizer
 	resizer resizer
 
+	// fixed top and bottom of the screen as requested by the user. not all
+	// resizer implementations honour these values. a fixedBottom value that
+	// is not greater than fixedTop indicates that there are no fixed bounds
+	fixedTop    int
+	fixedBottom int
+
 	// framerate limiter
 	lmtr limiter
 
@@ -107,6 +130,9 @@ type television struct {
 
 	// list of audio mixers to consult
 	mixers []AudioMixer
+
+	// list of frame triggers to notify
+	frameTriggers []FrameTrigger
 }
 
 // NewTelevision creates a new instance of the television type, satisfying the
@@ -149,6 +175,11 @@ func (tv *television) AddAudioMixer(m AudioMixer) {
 	tv.mixers = append(tv.mixers, m)
 }
 
+// AddFrameTrigger implements the Television interface
+func (tv *television) AddFrameTrigger(f FrameTrigger) {
+	tv.frameTriggers = append(tv.frameTriggers, f)
+}
+
 // Reset implements the Television interface.
 func (tv *television) Reset() error {
 
@@ -287,12 +318,10 @@ func (tv *television) newFrame(synced bool) error {
 	}
 
 	// specification change
-	if tv.syncedFrameNum > leadingFrames && tv.syncedFrameNum < stabilityThreshold {
-		if tv.auto && !tv.syncedFrame && tv.scanline > excessScanlinesNTSC {
-			// flip from NTSC to PAL
-			if tv.spec == SpecNTSC {
-				tv.SetSpec("PAL")
-			}
+	if tv.auto {
+		err := tv.autoSpec(synced)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -313,6 +342,14 @@ func (tv *television) newFrame(synced bool) error {
 		}
 	}
 
+	// and for all frame triggers
+	for f := range tv.frameTriggers {
+		err := tv.frameTriggers[f].NewFrame(tv.frameNum, tv.IsStable())
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -330,23 +367,95 @@ func (tv *television) GetState(request StateReq) (int, error) {
 	}
 }
 
+// autoSpec changes the specification if the signal suggests that it should.
+// NTSC flips to PAL, PAL flips to PAL60 and PAL60 flips back to PAL. note that
+// the flip from NTSC to PAL changes the palette as well as the number of
+// scanlines.
+//
+// the specification only changes once a number of consecutive frames suggest
+// the change. a synced frame that fits the current specification resets the
+// count.
+func (tv *television) autoSpec(synced bool) error {
+	switch tv.spec {
+	case SpecNTSC:
+		// flip from NTSC to PAL. the signal can be in flux during the first
+		// few frames so we only consider flipping in the window between
+		// leadingFrames and stabilityThreshold
+		if tv.syncedFrameNum > leadingFrames && tv.syncedFrameNum < stabilityThreshold {
+			if !tv.syncedFrame && tv.scanline > excessScanlinesNTSC {
+				tv.specFrames++
+				if tv.specFrames >= longFramesAuto {
+					return tv.setSpec(SpecPAL)
+				}
+			} else if synced && tv.syncedFrame {
+				tv.specFrames = 0
+			}
+		}
+
+	case SpecPAL:
+		// flip from PAL to PAL60 if the signal has consistently fit inside
+		// the NTSC frame
+		if synced && tv.scanline <= SpecNTSC.ScanlinesTotal {
+			tv.specFrames++
+			if tv.specFrames >= shortFramesPAL {
+				return tv.setSpec(SpecPAL60)
+			}
+		} else {
+			tv.specFrames = 0
+		}
+
+	case SpecPAL60:
+		// flip back to PAL if the number of scanlines consistently exceeds the
+		// NTSC maximum
+		if !synced && tv.scanline > SpecPAL60.ScanlinesTotal {
+			tv.specFrames++
+			if tv.specFrames >= longFramesAuto {
+				return tv.setSpec(SpecPAL)
+			}
+		} else if synced && tv.syncedFrame {
+			tv.specFrames = 0
+		}
+	}
+
+	return nil
+}
+
 // SetSpec implements the Television interface
 func (tv *television) SetSpec(spec string) error {
 	switch strings.ToUpper(spec) {
 	case "NTSC":
-		tv.spec = SpecNTSC
 		tv.auto = false
+		return tv.setSpec(SpecNTSC)
 	case "PAL":
-		tv.spec = SpecPAL
 		tv.auto = false
+		return tv.setSpec(SpecPAL)
+	case "PAL-M":
+		tv.auto = false
+		return tv.setSpec(SpecPALM)
+	case "PAL60":
+		tv.auto = false
+		return tv.setSpec(SpecPAL60)
+	case "SECAM":
+		tv.auto = false
+		return tv.setSpec(SpecSECAM)
 	case "AUTO":
-		tv.spec = SpecNTSC
 		tv.auto = true
-
-	default:
-		return errors.New(errors.Television, fmt.Sprintf("unsupported tv specifcation (%s)", spec))
+		return tv.setSpec(SpecNTSC)
 	}
 
+	return errors.New(errors.Television, fmt.Sprintf("unsupported tv specifcation (%s)", spec))
+}
+
+// setSpec changes the specification without affecting the auto flag
+func (tv *television) setSpec(spec *Specification) error {
+	tv.spec = spec
+	tv.specFrames = 0
+	return tv.resetBounds()
+}
+
+// resetBounds sets the top and bottom of the screen to the ideal values for
+// the specification
+func (tv *television) resetBounds() error {
 	tv.top = tv.spec.ScanlineTop
 	tv.bottom = tv.spec.ScanlineBottom
 	tv.resizer.prepare(tv)
@@ -361,6 +470,40 @@ func (tv *television) SetSpec(spec string) error {
 	return nil
 }
 
+// SetResizer implements the Television interface
+func (tv *television) SetResizer(id FrameResizeID) error {
+	switch id {
+	case FrameResizerNone:
+		tv.resizer = &nullResizer{}
+	case FrameResizerSimple:
+		tv.resizer = &simpleResizer{}
+	case FrameResizerStatistical:
+		tv.resizer = newStatisticalResizer()
+	default:
+		return errors.New(errors.Television, fmt.Sprintf("unsupported frame resizer (%s)", id))
+	}
+
+	return tv.resetBounds()
+}
+
+// SetFixedBounds implements the Television interface
+func (tv *television) SetFixedBounds(top int, bottom int) error {
+	if top == 0 && bottom == 0 {
+		tv.fixedTop = 0
+		tv.fixedBottom = 0
+		return nil
+	}
+
+	if top < 0 || bottom <= top || bottom > tv.spec.ScanlinesTotal {
+		return errors.New(errors.Television, fmt.Sprintf("unsuitable frame bounds (%d to %d)", top, bottom))
+	}
+
+	tv.fixedTop = top
+	tv.fixedBottom = bottom
+
+	return nil
+}
+
 // SpecIDOnCreation implements the Television interface
 func (tv *television) SpecIDOnCreation() string {
 	return tv.specIDOnCreation
@@ -426,3 +569,153 @@ func (tv *television) GetActualFPS() float32 {
 func (tv *television) GetLastSignal() SignalAttributes {
 	return tv.lastSignal
 }
+
+// State is a copy of the television's state, as returned by Snapshot(). The
+// content is opaque and is only meaningful to the television that created it.
+type State struct {
+	spec           *Specification
+	auto           bool
+	specFrames     int
+	horizPos       int
+	frameNum       int
+	scanline       int
+	syncedFrameNum int
+	syncedFrame    bool
+	lastSignal     SignalAttributes
+	vsyncCount     int
+	top            int
+	bottom         int
+}
+
+// the version of the SavedTelevision type. increase this whenever the
+// SavedTelevision type changes
+const savedTelevisionVersion = 1
+
+// SavedTelevision is the state of the television as returned by
+// State.SaveState(). The specification is saved by its ID.
+type SavedTelevision struct {
+	Version        int
+	Spec           string
+	Auto           bool
+	SpecFrames     int
+	HorizPos       int
+	FrameNum       int
+	Scanline       int
+	SyncedFrameNum int
+	SyncedFrame    bool
+	LastSignal     SignalAttributes
+	VsyncCount     int
+	Top            int
+	Bottom         int
+}
+
+// SaveState returns the television state contained in the snapshot
+func (s *State) SaveState() SavedTelevision {
+	t := SavedTelevision{
+		Version:        savedTelevisionVersion,
+		Auto:           s.auto,
+		SpecFrames:     s.specFrames,
+		HorizPos:       s.horizPos,
+		FrameNum:       s.frameNum,
+		Scanline:       s.scanline,
+		SyncedFrameNum: s.syncedFrameNum,
+		SyncedFrame:    s.syncedFrame,
+		LastSignal:     s.lastSignal,
+		VsyncCount:     s.vsyncCount,
+		Top:            s.top,
+		Bottom:         s.bottom,
+	}
+	if s.spec != nil {
+		t.Spec = s.spec.ID
+	}
+	return t
+}
+
+// LoadState sets the snapshot to a state previously returned by SaveState().
+// Use the television's Restore() function to apply the snapshot.
+func (s *State) LoadState(t SavedTelevision) error {
+	if t.Version != savedTelevisionVersion {
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unsupported television state version (%d)", t.Version))
+	}
+
+	switch t.Spec {
+	case "":
+		s.spec = nil
+	case SpecNTSC.ID:
+		s.spec = SpecNTSC
+	case SpecPAL.ID:
+		s.spec = SpecPAL
+	case SpecPALM.ID:
+		s.spec = SpecPALM
+	case SpecPAL60.ID:
+		s.spec = SpecPAL60
+	case SpecSECAM.ID:
+		s.spec = SpecSECAM
+	default:
+		return errors.New(errors.SnapshotError, fmt.Sprintf("unknown tv specification (%s)", t.Spec))
+	}
+
+	s.auto = t.Auto
+	s.specFrames = t.SpecFrames
+	s.horizPos = t.HorizPos
+	s.frameNum = t.FrameNum
+	s.scanline = t.Scanline
+	s.syncedFrameNum = t.SyncedFrameNum
+	s.syncedFrame = t.SyncedFrame
+	s.lastSignal = t.LastSignal
+	s.vsyncCount = t.VsyncCount
+	s.top = t.Top
+	s.bottom = t.Bottom
+
+	return nil
+}
+
+// Snapshot implements the Television interface
+func (tv *television) Snapshot() *State {
+	return &State{
+		spec:           tv.spec,
+		auto:           tv.auto,
+		specFrames:     tv.specFrames,
+		horizPos:       tv.horizPos,
+		frameNum:       tv.frameNum,
+		scanline:       tv.scanline,
+		syncedFrameNum: tv.syncedFrameNum,
+		syncedFrame:    tv.syncedFrame,
+		lastSignal:     tv.lastSignal,
+		vsyncCount:     tv.vsyncCount,
+		top:            tv.top,
+		bottom:         tv.bottom,
+	}
+}
+
+// Restore implements the Television interface
+func (tv *television) Restore(s *State) error {
+	resize := tv.spec != s.spec || tv.top != s.top || tv.bottom != s.bottom
+
+	tv.spec = s.spec
+	tv.auto = s.auto
+	tv.specFrames = s.specFrames
+	tv.horizPos = s.horizPos
+	tv.frameNum = s.frameNum
+	tv.scanline = s.scanline
+	tv.syncedFrameNum = s.syncedFrameNum
+	tv.syncedFrame = s.syncedFrame
+	tv.lastSignal = s.lastSignal
+	tv.vsyncCount = s.vsyncCount
+	tv.top = s.top
+	tv.bottom = s.bottom
+
+	// any resizing information gathered for the current frame is lost
+	tv.resizer.prepare(tv)
+
+	if resize {
+		for f := range tv.renderers {
+			err := tv.renderers[f].Resize(tv.spec, tv.top, tv.bottom-tv.top)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -35,6 +35,13 @@ func TestNewTelevision(t *testing.T) {
 		t.Errorf("NTSC spec creation failed")
 	}
 
+	for _, spec := range []string{"PAL-M", "PAL60", "SECAM"} {
+		tv, err = television.NewTelevision(spec)
+		if tv == nil || err != nil {
+			t.Errorf("%s spec creation failed", spec)
+		}
+	}
+
 	tv, err = television.NewTelevision("AUTO")
 	if tv == nil || err != nil {
 		t.Errorf("AUTO spec creation failed")
@@ -45,3 +52,94 @@ func TestNewTelevision(t *testing.T) {
 		t.Errorf("'FOO' spec creation unexpectedly succeeded")
 	}
 }
+
+// send a number of frames to the television. each frame has the specified
+// number of scanlines and starts with three scanlines of VSYNC
+func sendFrames(t *testing.T, tv television.Television, numFrames int, scanlines int) {
+	t.Helper()
+
+	for f := 0; f < numFrames; f++ {
+		for y := 0; y < scanlines; y++ {
+			for x := 0; x < television.HorizClksScanline; x++ {
+				sig := television.SignalAttributes{
+					VSync: y < 3,
+					HSync: x >= 16 && x < 36,
+				}
+				if err := tv.Signal(sig); err != nil {
+					t.Fatalf(err.Error())
+				}
+			}
+		}
+	}
+}
+
+func expectSpec(t *testing.T, tv television.Television, id string) {
+	t.Helper()
+
+	spec, _ := tv.GetSpec()
+	if spec.ID != id {
+		t.Errorf("expected %s specification got %s", id, spec.ID)
+	}
+}
+
+// create an AUTO television that is not limited to the specification's frame
+// rate
+func newAutoTelevision(t *testing.T) television.Television {
+	t.Helper()
+
+	tv, err := television.NewTelevision("AUTO")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	tv.SetFPSCap(false)
+
+	return tv
+}
+
+func TestAutoSpec(t *testing.T) {
+	tv := newAutoTelevision(t)
+
+	// NTSC signal stays NTSC
+	sendFrames(t, tv, 30, 262)
+	expectSpec(t, tv, "NTSC")
+
+	tv = newAutoTelevision(t)
+
+	// isolated long frames do not cause a flip to PAL
+	sendFrames(t, tv, 7, 262)
+	sendFrames(t, tv, 1, 312)
+	sendFrames(t, tv, 1, 262)
+	sendFrames(t, tv, 3, 312)
+	sendFrames(t, tv, 1, 262)
+	expectSpec(t, tv, "NTSC")
+
+	tv = newAutoTelevision(t)
+
+	// a consistently long signal flips to PAL
+	sendFrames(t, tv, 7, 262)
+	sendFrames(t, tv, 4, 312)
+	expectSpec(t, tv, "NTSC")
+	sendFrames(t, tv, 10, 312)
+	expectSpec(t, tv, "PAL")
+
+	// PAL signal stays PAL
+	sendFrames(t, tv, 30, 312)
+	expectSpec(t, tv, "PAL")
+
+	// a signal that consistently fits in the NTSC frame flips to PAL60
+	sendFrames(t, tv, 10, 262)
+	expectSpec(t, tv, "PAL")
+	sendFrames(t, tv, 15, 262)
+	expectSpec(t, tv, "PAL60")
+
+	// isolated long frames do not cause a flip back to PAL
+	sendFrames(t, tv, 1, 312)
+	sendFrames(t, tv, 1, 262)
+	sendFrames(t, tv, 2, 312)
+	sendFrames(t, tv, 1, 262)
+	expectSpec(t, tv, "PAL60")
+
+	// a consistently long signal flips back to PAL
+	sendFrames(t, tv, 10, 312)
+	expectSpec(t, tv, "PAL")
+}
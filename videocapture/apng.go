@@ -0,0 +1,194 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package videocapture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"os"
+)
+
+// the PNG file signature
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// the offset of the acTL chunk in the file. the chunk immediately follows the
+// signature and the IHDR chunk (which is always 25 bytes)
+const apngACTLOffset = 8 + 25
+
+// apng writes frames to an animated PNG file. each frame is compressed by the
+// standard library PNG encoder and the resulting IDAT data is then
+// repackaged as the frame data of the animation.
+//
+// the file is only valid once close() has been called.
+type apng struct {
+	f *os.File
+
+	width  int
+	height int
+
+	// the frame delay as a fraction
+	delayNum uint16
+	delayDen uint16
+
+	numFrames int
+	seq       uint32
+
+	png bytes.Buffer
+	enc png.Encoder
+}
+
+func newAPNG(f *os.File, width int, height int, fps float32) (*apng, error) {
+	enc := &apng{
+		f:        f,
+		width:    width,
+		height:   height,
+		delayNum: 100,
+		delayDen: uint16(fps * 100),
+		enc:      png.Encoder{CompressionLevel: png.BestSpeed},
+	}
+
+	_, err := f.Write(pngSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(width))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(height))
+	ihdr[8] = 8  // bit depth
+	ihdr[9] = 2  // colour type (truecolour)
+	ihdr[10] = 0 // compression
+	ihdr[11] = 0 // filter
+	ihdr[12] = 0 // interlace
+	err = enc.writeChunk("IHDR", ihdr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the number of frames will be updated on close()
+	err = enc.writeACTL()
+	if err != nil {
+		return nil, err
+	}
+
+	return enc, nil
+}
+
+func (enc *apng) writeChunk(typ string, data []byte) error {
+	b := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(b, uint32(len(data)))
+	copy(b[4:], typ)
+	b = append(b, data...)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], crc32.ChecksumIEEE(b[4:len(b)-4]))
+
+	_, err := enc.f.Write(b)
+	return err
+}
+
+func (enc *apng) writeACTL() error {
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(enc.numFrames))
+	binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+	return enc.writeChunk("acTL", actl)
+}
+
+// there is no limit to the size of a PNG file
+func (enc *apng) fits(_ []uint8) bool {
+	return true
+}
+
+func (enc *apng) writeFrame(frame *image.RGBA, _ []uint8) error {
+	enc.png.Reset()
+	// the frame is fully opaque so the PNG encoder will produce truecolour
+	// data that matches the IHDR chunk
+	err := enc.enc.Encode(&enc.png, frame)
+	if err != nil {
+		return err
+	}
+
+	fctl := make([]byte, 26)
+	binary.BigEndian.PutUint32(fctl[0:], enc.seq)
+	binary.BigEndian.PutUint32(fctl[4:], uint32(enc.width))
+	binary.BigEndian.PutUint32(fctl[8:], uint32(enc.height))
+	binary.BigEndian.PutUint32(fctl[12:], 0) // x offset
+	binary.BigEndian.PutUint32(fctl[16:], 0) // y offset
+	binary.BigEndian.PutUint16(fctl[20:], enc.delayNum)
+	binary.BigEndian.PutUint16(fctl[22:], enc.delayDen)
+	fctl[24] = 0 // dispose op (none)
+	fctl[25] = 0 // blend op (source)
+	err = enc.writeChunk("fcTL", fctl)
+	if err != nil {
+		return err
+	}
+	enc.seq++
+
+	// copy IDAT chunks from the encoded PNG. the first frame uses IDAT chunks
+	// as normal so that the file is viewable as a static image by programs
+	// that do not understand APNG. subsequent frames use fdAT chunks
+	data := enc.png.Bytes()[len(pngSignature):]
+	for len(data) >= 12 {
+		l := binary.BigEndian.Uint32(data)
+		if int(l)+12 > len(data) {
+			return fmt.Errorf("malformed png data")
+		}
+		typ := string(data[4:8])
+		chunk := data[8 : 8+l]
+		data = data[12+l:]
+
+		if typ != "IDAT" {
+			continue
+		}
+
+		if enc.numFrames == 0 {
+			err = enc.writeChunk("IDAT", chunk)
+		} else {
+			fdat := make([]byte, 4, 4+len(chunk))
+			binary.BigEndian.PutUint32(fdat, enc.seq)
+			fdat = append(fdat, chunk...)
+			err = enc.writeChunk("fdAT", fdat)
+			enc.seq++
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	enc.numFrames++
+
+	return nil
+}
+
+func (enc *apng) close() error {
+	defer enc.f.Close()
+
+	err := enc.writeChunk("IEND", []byte{})
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.f.Seek(apngACTLOffset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	return enc.writeACTL()
+}
@@ -0,0 +1,327 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package videocapture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"os"
+
+	tiaAudio "github.com/jetsetilly/gopher2600/hardware/tia/audio"
+)
+
+// the length of the AVI header written by writeHeader(). the frame and audio
+// chunks start immediately after the header
+const aviHeaderLen = 326
+
+// the offset of the "movi" fourcc in the file. the offsets in the idx1 index
+// are relative to this position
+const aviMoviOffset = 322
+
+// the rate values in the AVI header are expressed as rate/scale
+const aviScale = 1000
+
+// the maximum length of an AVI file. the RIFF length and the chunk offsets in
+// the index are 32bit values but many programs treat the RIFF length as a
+// signed value so we limit the file to 2GB. the capture is continued in a new
+// file when the limit is reached
+var aviMaxLen = uint64(0x7fffffff)
+
+// AVI flags
+const (
+	aviHasIndex       = 0x10
+	aviIsInterleaved  = 0x100
+	aviIndexKeyframe  = 0x10
+	aviBitsPerPixel   = 24
+	aviBitsPerSample  = 8
+	aviFormatPCM      = 1
+	aviNumAudioChans  = 1
+	aviBytesPerSample = 1
+)
+
+type aviIndexEntry struct {
+	fourcc string
+	offset uint32
+	size   uint32
+}
+
+// avi writes uncompressed video and 8bit PCM mono audio to a RIFF AVI file.
+// the file is only valid once close() has been called.
+type avi struct {
+	f *os.File
+
+	width  int
+	height int
+	fps    float32
+
+	// number of frames and audio samples written so far
+	numFrames  int
+	numSamples int
+
+	// the offset of the next chunk relative to aviMoviOffset
+	moviLen uint32
+
+	index []aviIndexEntry
+
+	// buffer used to convert frames to bottom-up BGR
+	bgr []byte
+}
+
+func newAVI(f *os.File, width int, height int, fps float32) (*avi, error) {
+	enc := &avi{
+		f:       f,
+		width:   width,
+		height:  height,
+		fps:     fps,
+		moviLen: 4,
+		index:   make([]aviIndexEntry, 0, 1024),
+		bgr:     make([]byte, width*height*3),
+	}
+
+	// the header will be rewritten with the correct values on close(). for
+	// now we just need to occupy the space
+	err := enc.writeHeader(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return enc, nil
+}
+
+func (enc *avi) writeChunk(fourcc string, data []byte) error {
+	b := make([]byte, 8, 8+len(data)+1)
+	copy(b, fourcc)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(data)))
+	b = append(b, data...)
+
+	// chunks are padded to an even number of bytes
+	if len(data)%2 == 1 {
+		b = append(b, 0)
+	}
+
+	_, err := enc.f.Write(b)
+	if err != nil {
+		return err
+	}
+
+	enc.index = append(enc.index, aviIndexEntry{fourcc: fourcc, offset: enc.moviLen, size: uint32(len(data))})
+	enc.moviLen += uint32(len(b))
+
+	return nil
+}
+
+func (enc *avi) fits(audio []uint8) bool {
+	// always allow at least one frame in the file
+	if enc.numFrames == 0 {
+		return true
+	}
+
+	// size of chunks including the chunk header and padding
+	chunkLen := func(n int) uint64 {
+		return uint64(8 + n + n%2)
+	}
+
+	// new length of the movi list and the index
+	moviLen := uint64(enc.moviLen) + chunkLen(len(enc.bgr))
+	idxLen := uint64(len(enc.index)+1) * 16
+	if len(audio) > 0 {
+		moviLen += chunkLen(len(audio))
+		idxLen += 16
+	}
+
+	return aviMoviOffset+moviLen+8+idxLen <= aviMaxLen
+}
+
+func (enc *avi) writeFrame(frame *image.RGBA, audio []uint8) error {
+	// AVI frames are stored bottom-up in BGR order
+	i := 0
+	for y := enc.height - 1; y >= 0; y-- {
+		p := frame.Pix[y*frame.Stride:]
+		for x := 0; x < enc.width; x++ {
+			enc.bgr[i] = p[x*4+2]
+			enc.bgr[i+1] = p[x*4+1]
+			enc.bgr[i+2] = p[x*4]
+			i += 3
+		}
+	}
+
+	err := enc.writeChunk("00db", enc.bgr)
+	if err != nil {
+		return err
+	}
+	enc.numFrames++
+
+	if len(audio) > 0 {
+		err = enc.writeChunk("01wb", audio)
+		if err != nil {
+			return err
+		}
+		enc.numSamples += len(audio)
+	}
+
+	return nil
+}
+
+func (enc *avi) close() error {
+	defer enc.f.Close()
+
+	idx := &bytes.Buffer{}
+	for _, e := range enc.index {
+		idx.WriteString(e.fourcc)
+		_ = binary.Write(idx, binary.LittleEndian, uint32(aviIndexKeyframe))
+		_ = binary.Write(idx, binary.LittleEndian, e.offset)
+		_ = binary.Write(idx, binary.LittleEndian, e.size)
+	}
+
+	b := make([]byte, 8)
+	copy(b, "idx1")
+	binary.LittleEndian.PutUint32(b[4:], uint32(idx.Len()))
+
+	_, err := enc.f.Write(b)
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.f.Write(idx.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return enc.writeHeader(true)
+}
+
+// writeHeader writes the RIFF header to the start of the file. it is called
+// twice: once to reserve the space and once the number of frames and samples
+// are known. the final argument should be true once the index has been
+// written.
+func (enc *avi) writeHeader(final bool) error {
+	frameSize := uint32(enc.width * enc.height * 3)
+
+	// the television does not produce frames at exactly the rate given by the
+	// specification. to keep the video in sync with the audio we derive the
+	// frame rate from the number of audio samples generated per frame, if
+	// possible
+	rate := uint32(enc.fps * aviScale)
+	if enc.numFrames > 0 && enc.numSamples > 0 {
+		rate = uint32(float64(enc.numFrames) * tiaAudio.SampleFreq * aviScale / float64(enc.numSamples))
+	}
+
+	idxLen := uint32(8 + len(enc.index)*16)
+
+	h := &bytes.Buffer{}
+	w := func(v ...interface{}) {
+		for _, x := range v {
+			if s, ok := x.(string); ok {
+				h.WriteString(s)
+			} else {
+				_ = binary.Write(h, binary.LittleEndian, x)
+			}
+		}
+	}
+
+	// the RIFF size is not known until the index is written. until then the
+	// value will be zero
+	riffLen := uint32(0)
+	if final {
+		riffLen = aviMoviOffset + enc.moviLen + idxLen - 8
+	}
+
+	w("RIFF", riffLen, "AVI ")
+	w("LIST", uint32(294), "hdrl")
+
+	// main AVI header
+	w("avih", uint32(56))
+	w(uint32(1000000 * aviScale / rate)) // microseconds per frame
+	w(uint32(0))                         // max bytes per second
+	w(uint32(0))                         // padding granularity
+	w(uint32(aviHasIndex | aviIsInterleaved))
+	w(uint32(enc.numFrames))
+	w(uint32(0)) // initial frames
+	w(uint32(2)) // number of streams
+	w(frameSize) // suggested buffer size
+	w(uint32(enc.width), uint32(enc.height))
+	w(uint32(0), uint32(0), uint32(0), uint32(0)) // reserved
+
+	// video stream
+	w("LIST", uint32(116), "strl")
+	w("strh", uint32(56))
+	w("vids", "DIB ")
+	w(uint32(0))             // flags
+	w(uint16(0))             // priority
+	w(uint16(0))             // language
+	w(uint32(0))             // initial frames
+	w(uint32(aviScale))      // scale
+	w(rate)                  // rate
+	w(uint32(0))             // start
+	w(uint32(enc.numFrames)) // length
+	w(frameSize)             // suggested buffer size
+	w(uint32(0xffffffff))    // quality
+	w(uint32(0))             // sample size
+	w(uint16(0), uint16(0), uint16(enc.width), uint16(enc.height))
+
+	w("strf", uint32(40))
+	w(uint32(40)) // size of BITMAPINFOHEADER
+	w(int32(enc.width), int32(enc.height))
+	w(uint16(1)) // planes
+	w(uint16(aviBitsPerPixel))
+	w(uint32(0)) // compression (BI_RGB)
+	w(frameSize)
+	w(int32(0), int32(0)) // pixels per meter
+	w(uint32(0), uint32(0))
+
+	// audio stream
+	w("LIST", uint32(94), "strl")
+	w("strh", uint32(56))
+	w("auds", uint32(0))
+	w(uint32(0))                        // flags
+	w(uint16(0))                        // priority
+	w(uint16(0))                        // language
+	w(uint32(0))                        // initial frames
+	w(uint32(1))                        // scale
+	w(uint32(tiaAudio.SampleFreq))      // rate
+	w(uint32(0))                        // start
+	w(uint32(enc.numSamples))           // length
+	w(uint32(tiaAudio.SampleFreq / 30)) // suggested buffer size
+	w(uint32(0xffffffff))               // quality
+	w(uint32(aviBytesPerSample))
+	w(uint16(0), uint16(0), uint16(0), uint16(0))
+
+	w("strf", uint32(18))
+	w(uint16(aviFormatPCM))
+	w(uint16(aviNumAudioChans))
+	w(uint32(tiaAudio.SampleFreq))
+	w(uint32(tiaAudio.SampleFreq * aviBytesPerSample)) // bytes per second
+	w(uint16(aviBytesPerSample))                       // block align
+	w(uint16(aviBitsPerSample))
+	w(uint16(0)) // extra format bytes
+
+	w("LIST", enc.moviLen, "movi")
+
+	_, err := enc.f.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.f.Write(h.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.f.Seek(0, io.SeekEnd)
+	return err
+}
@@ -0,0 +1,94 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package videocapture
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+func TestAVISplit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "videocapture")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vc := NewCapture(tv)
+
+	// limit the size of each AVI file to a little more than two frames
+	frameLen := uint64(television.HorizClksVisible * pixelWidth * vc.visible * 3)
+	defer func(l uint64) { aviMaxLen = l }(aviMaxLen)
+	aviMaxLen = aviHeaderLen + frameLen*5/2
+
+	filename := filepath.Join(dir, "test.avi")
+	err = vc.Start(filename)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for f := 0; f <= 5; f++ {
+		err = vc.NewFrame(f, true)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	err = vc.Stop()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// five frames split over three files
+	expected := map[string]uint32{
+		"test.avi":   2,
+		"test_1.avi": 2,
+		"test_2.avi": 1,
+	}
+
+	for fn, n := range expected {
+		d, err := ioutil.ReadFile(filepath.Join(dir, fn))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if uint64(len(d)) > aviMaxLen {
+			t.Errorf("%s: file length (%d) exceeds limit (%d)", fn, len(d), aviMaxLen)
+		}
+
+		if int(binary.LittleEndian.Uint32(d[4:]))+8 != len(d) {
+			t.Errorf("%s: RIFF length does not match file length", fn)
+		}
+
+		if v := binary.LittleEndian.Uint32(d[48:]); v != n {
+			t.Errorf("%s: unexpected number of frames in AVI header (%d) should be (%d)", fn, v, n)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "test_3.avi")); !os.IsNotExist(err) {
+		t.Errorf("unexpected capture file test_3.avi")
+	}
+}
@@ -0,0 +1,302 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package videocapture
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jetsetilly/gopher2600/errors"
+	"github.com/jetsetilly/gopher2600/television"
+)
+
+// the width of each VCS pixel in the captured video
+const pixelWidth = 2
+
+// encoder implementations write frames to a video file
+type encoder interface {
+	// returns false if writing the frame and the audio samples would take the
+	// file over the size limit of the format
+	fits(audio []uint8) bool
+
+	// write the frame and the audio samples that accompany the frame
+	writeFrame(frame *image.RGBA, audio []uint8) error
+
+	// conclude video file
+	close() error
+}
+
+// Capture implements the television.PixelRenderer and television.AudioMixer
+// interfaces.
+type Capture struct {
+	tv television.Television
+
+	// the encoder for the current capture. nil if not capturing
+	enc      encoder
+	filename string
+
+	// the file currently being written to. this will be different to
+	// filename if the capture has been split over more than one file
+	current string
+	part    int
+
+	// number of frames written to the current file
+	numFrames int
+
+	// the most recent values from Resize()
+	top     int
+	visible int
+
+	// the frame being built. the dimensions of the captured video are decided
+	// when capturing starts and do not change. frameTop is the scanline at the
+	// top of the frame
+	frame    *image.RGBA
+	frameTop int
+
+	// whether a new frame has been started since capturing began. we don't
+	// want to capture a partial frame
+	started bool
+
+	// audio samples received during the current frame
+	audio []uint8
+}
+
+// NewCapture is the preferred method of initialisation for the Capture type.
+// The new instance is added to the television as both a PixelRenderer and an
+// AudioMixer.
+func NewCapture(tv television.Television) *Capture {
+	spec, _ := tv.GetSpec()
+
+	vc := &Capture{
+		tv:      tv,
+		top:     spec.ScanlineTop,
+		visible: spec.ScanlinesVisible,
+	}
+
+	tv.AddPixelRenderer(vc)
+	tv.AddAudioMixer(vc)
+
+	return vc
+}
+
+// Start capturing to the named file. The format of the file is decided by the
+// filename extension. See package documentation for the list of supported
+// formats.
+func (vc *Capture) Start(filename string) error {
+	if vc.enc != nil {
+		return errors.New(errors.VideoCapture, fmt.Sprintf("already capturing to %s", vc.filename))
+	}
+
+	width := television.HorizClksVisible * pixelWidth
+	height := vc.visible
+
+	err := vc.create(filename, width, height)
+	if err != nil {
+		return err
+	}
+
+	vc.filename = filename
+	vc.part = 0
+	vc.frame = image.NewRGBA(image.Rect(0, 0, width, height))
+	vc.frameTop = vc.top
+	vc.started = false
+	vc.audio = vc.audio[:0]
+
+	return nil
+}
+
+// create the named file and the encoder for it
+func (vc *Capture) create(filename string, width int, height int) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.New(errors.VideoCapture, err)
+	}
+
+	spec, _ := vc.tv.GetSpec()
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".avi":
+		vc.enc, err = newAVI(f, width, height, spec.FramesPerSecond)
+	case ".png", ".apng":
+		vc.enc, err = newAPNG(f, width, height, spec.FramesPerSecond)
+	default:
+		_ = f.Close()
+		_ = os.Remove(filename)
+		return errors.New(errors.VideoCapture, fmt.Sprintf("unsupported file type (%s)", filepath.Ext(filename)))
+	}
+
+	if err != nil {
+		vc.enc = nil
+		_ = f.Close()
+		return errors.New(errors.VideoCapture, err)
+	}
+
+	vc.current = filename
+	vc.numFrames = 0
+
+	return nil
+}
+
+// continue capturing in the next file in the sequence. the files after the
+// first are named by adding a number to the original filename. for example,
+// capture.avi is followed by capture_1.avi, capture_2.avi, etc.
+func (vc *Capture) split() error {
+	err := vc.enc.close()
+	vc.enc = nil
+	if err != nil {
+		return errors.New(errors.VideoCapture, err)
+	}
+
+	vc.part++
+	ext := filepath.Ext(vc.filename)
+	filename := fmt.Sprintf("%s_%d%s", strings.TrimSuffix(vc.filename, ext), vc.part, ext)
+
+	return vc.create(filename, vc.frame.Rect.Dx(), vc.frame.Rect.Dy())
+}
+
+// Stop capturing. Does nothing if capturing has not been started.
+//
+// If no frames have been captured then the file is removed and an error is
+// returned.
+func (vc *Capture) Stop() error {
+	if vc.enc == nil {
+		return nil
+	}
+
+	enc := vc.enc
+	vc.enc = nil
+
+	err := enc.close()
+	if err != nil {
+		return errors.New(errors.VideoCapture, err)
+	}
+
+	if vc.numFrames == 0 {
+		_ = os.Remove(vc.current)
+		return errors.New(errors.VideoCapture, fmt.Sprintf("no frames captured (%s not created)", vc.current))
+	}
+
+	return nil
+}
+
+// IsCapturing returns true if capturing has been started. The filename of the
+// capture is also returned.
+func (vc *Capture) IsCapturing() (bool, string) {
+	return vc.enc != nil, vc.filename
+}
+
+// Resize implements the television.PixelRenderer interface
+//
+// The dimensions of the captured video do not change. If the number of visible
+// scanlines increases then the bottom of the image will be cropped.
+func (vc *Capture) Resize(_ *television.Specification, topScanline int, visibleScanlines int) error {
+	vc.top = topScanline
+	vc.visible = visibleScanlines
+	vc.frameTop = topScanline
+	return nil
+}
+
+// NewFrame implements the television.PixelRenderer interface
+func (vc *Capture) NewFrame(_ int, _ bool) error {
+	if vc.enc == nil {
+		return nil
+	}
+
+	if vc.started {
+		if !vc.enc.fits(vc.audio) {
+			err := vc.split()
+			if err != nil {
+				_ = vc.Stop()
+				return err
+			}
+		}
+
+		err := vc.enc.writeFrame(vc.frame, vc.audio)
+		if err != nil {
+			// stop capturing on error. the capture file will most likely be
+			// unusable
+			_ = vc.Stop()
+			return errors.New(errors.VideoCapture, err)
+		}
+		vc.numFrames++
+	}
+
+	vc.started = true
+	vc.audio = vc.audio[:0]
+
+	// clear frame to black
+	for i := range vc.frame.Pix {
+		if i%4 == 3 {
+			vc.frame.Pix[i] = 255
+		} else {
+			vc.frame.Pix[i] = 0
+		}
+	}
+
+	return nil
+}
+
+// NewScanline implements the television.PixelRenderer interface
+func (vc *Capture) NewScanline(_ int) error {
+	return nil
+}
+
+// SetPixel implements the television.PixelRenderer interface
+func (vc *Capture) SetPixel(x, y int, red, green, blue byte, vblank bool) error {
+	if vc.enc == nil || !vc.started {
+		return nil
+	}
+
+	x -= television.HorizClksHBlank
+	y -= vc.frameTop
+	if x < 0 || y < 0 || y >= vc.frame.Rect.Dy() {
+		return nil
+	}
+
+	col := color.RGBA{R: red, G: green, B: blue, A: 255}
+	if vblank {
+		col = color.RGBA{A: 255}
+	}
+
+	for i := 0; i < pixelWidth; i++ {
+		vc.frame.SetRGBA(x*pixelWidth+i, y, col)
+	}
+
+	return nil
+}
+
+// EndRendering implements the television.PixelRenderer interface
+func (vc *Capture) EndRendering() error {
+	return vc.Stop()
+}
+
+// SetAudio implements the television.AudioMixer interface
+func (vc *Capture) SetAudio(audioData uint8) error {
+	if vc.enc == nil || !vc.started {
+		return nil
+	}
+	vc.audio = append(vc.audio, audioData)
+	return nil
+}
+
+// EndMixing implements the television.AudioMixer interface
+func (vc *Capture) EndMixing() error {
+	return vc.Stop()
+}
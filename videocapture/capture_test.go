@@ -0,0 +1,162 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+package videocapture_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jetsetilly/gopher2600/television"
+	"github.com/jetsetilly/gopher2600/videocapture"
+)
+
+// capture a number of synthetic frames to the named file
+func capture(t *testing.T, filename string, numFrames int) {
+	t.Helper()
+
+	tv, err := television.NewTelevision("NTSC")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vc := videocapture.NewCapture(tv)
+
+	err = vc.Start(filename)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the capture doesn't start until the first new frame so we need one more
+	// frame than requested
+	for f := 0; f <= numFrames; f++ {
+		_ = vc.NewFrame(f, true)
+		for y := 0; y < television.SpecNTSC.ScanlinesTotal; y++ {
+			for x := 0; x < television.HorizClksScanline; x++ {
+				_ = vc.SetPixel(x, y, byte(x), byte(y), 100, false)
+			}
+			_ = vc.SetAudio(uint8(y))
+		}
+	}
+
+	err = vc.Stop()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+}
+
+func TestAVI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "videocapture")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.avi")
+	capture(t, filename, 5)
+
+	d, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if string(d[0:4]) != "RIFF" || string(d[8:12]) != "AVI " {
+		t.Fatalf("not a RIFF AVI file")
+	}
+
+	if int(binary.LittleEndian.Uint32(d[4:]))+8 != len(d) {
+		t.Errorf("RIFF length does not match file length")
+	}
+
+	// number of frames in the main header
+	if n := binary.LittleEndian.Uint32(d[48:]); n != 5 {
+		t.Errorf("unexpected number of frames in AVI header (%d) should be (5)", n)
+	}
+}
+
+func TestAPNG(t *testing.T) {
+	dir, err := ioutil.TempDir("", "videocapture")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.png")
+	capture(t, filename, 5)
+
+	d, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the first frame of an APNG file should be readable as a regular PNG
+	img, err := png.Decode(bytes.NewReader(d))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if img.Bounds().Dx() != television.HorizClksVisible*2 {
+		t.Errorf("unexpected image width (%d)", img.Bounds().Dx())
+	}
+
+	// acTL chunk immediately follows the IHDR chunk
+	if string(d[37:41]) != "acTL" {
+		t.Fatalf("missing acTL chunk")
+	}
+	if n := binary.BigEndian.Uint32(d[41:]); n != 5 {
+		t.Errorf("unexpected number of frames in acTL chunk (%d) should be (5)", n)
+	}
+}
+
+func TestStopWithNoFrames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "videocapture")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	for _, fn := range []string{"test.avi", "test.png"} {
+		tv, err := television.NewTelevision("NTSC")
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		filename := filepath.Join(dir, fn)
+
+		vc := videocapture.NewCapture(tv)
+		err = vc.Start(filename)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		// the first frame is never captured because it is likely to be
+		// incomplete
+		_ = vc.NewFrame(0, true)
+
+		err = vc.Stop()
+		if err == nil {
+			t.Errorf("%s: expected error when stopping with no frames", fn)
+		}
+
+		if _, err := os.Stat(filename); !os.IsNotExist(err) {
+			t.Errorf("%s: file should not exist when no frames have been captured", fn)
+		}
+	}
+}
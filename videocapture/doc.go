@@ -0,0 +1,37 @@
+// This file is part of Gopher2600.
+//
+// Gopher2600 is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Gopher2600 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Gopher2600.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package videocapture allows the television output to be recorded to a video
+// file, frame by frame. Because the capture happens as the television receives
+// the signal, no frames are dropped regardless of the speed of the emulation.
+//
+// The Capture type implements both the television.PixelRenderer and the
+// television.AudioMixer interfaces. It should be created with NewCapture(),
+// which adds the Capture to the television. Capturing begins with Start() and
+// ends with Stop().
+//
+// The format of the video file is decided by the filename extension:
+//
+//	.avi	uncompressed RGB video and 8bit PCM audio
+//	.png	animated PNG (APNG). video only
+//
+// Both formats are lossless and neither require external tools. The width of
+// each pixel is doubled in the captured video to better represent the aspect
+// ratio of the VCS image.
+//
+// AVI files are limited to 2GB. When the limit is reached the capture
+// continues in a new file, named by adding a number to the original filename.
+// For example, capture.avi is followed by capture_1.avi, capture_2.avi, etc.
+package videocapture